@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"github.com/focusnest/shared-libs/insights"
+	"github.com/focusnest/shared-libs/timezone"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
@@ -62,6 +64,9 @@ type UserProductivityContext struct {
 
 	// Points
 	PointsTotal int
+
+	// Ranked insights from the shared rule engine; localized when formatted.
+	Insights []insights.Insight
 }
 
 // EnrichmentProvider fetches user productivity data for chatbot context.
@@ -75,22 +80,31 @@ type cacheEntry struct {
 }
 
 type firestoreEnrichmentProvider struct {
-	client *firestore.Client
-	logger *slog.Logger
-	mu     sync.RWMutex
-	cache  map[string]cacheEntry
+	client   *firestore.Client
+	logger   *slog.Logger
+	insights *insights.Engine
+	mu       sync.RWMutex
+	cache    map[string]cacheEntry
 }
 
 // NewFirestoreEnrichmentProvider creates an EnrichmentProvider backed by Firestore.
 func NewFirestoreEnrichmentProvider(client *firestore.Client, logger *slog.Logger) EnrichmentProvider {
 	return &firestoreEnrichmentProvider{
-		client: client,
-		logger: logger,
-		cache:  make(map[string]cacheEntry),
+		client:   client,
+		logger:   logger,
+		insights: insights.NewEngine(insights.DefaultRules()...),
+		cache:    make(map[string]cacheEntry),
 	}
 }
 
-const enrichmentCacheTTL = 5 * time.Minute
+const (
+	enrichmentCacheTTL = 5 * time.Minute
+	productivityLimit  = 100
+	maxPromptInsights  = 3
+	// insightDays is the history the insight rules compare: the last 7 days
+	// against the 7 before, whatever weekday it is.
+	insightDays = 14
+)
 
 // GetUserContext fetches productivity data for the given user, using a 5-min in-memory cache.
 func (p *firestoreEnrichmentProvider) GetUserContext(ctx context.Context, userID string) (*UserProductivityContext, error) {
@@ -124,6 +138,7 @@ type productivityDoc struct {
 	Category     string    `firestore:"category"`
 	Mood         string    `firestore:"mood"`
 	StartTime    time.Time `firestore:"start_time"`
+	Timezone     string    `firestore:"timezone"`
 }
 
 // streakStateDoc mirrors the streak_state/{uid} document.
@@ -135,7 +150,8 @@ type streakStateDoc struct {
 
 // profileDoc mirrors the fields we need from profiles/{uid}.
 type profileDoc struct {
-	PointsTotal int `firestore:"points_total"`
+	PointsTotal int    `firestore:"points_total"`
+	Timezone    string `firestore:"timezone"`
 }
 
 func (p *firestoreEnrichmentProvider) fetchUserContext(ctx context.Context, userID string) (*UserProductivityContext, error) {
@@ -144,10 +160,12 @@ func (p *firestoreEnrichmentProvider) fetchUserContext(ctx context.Context, user
 		WeekByCategory:  make(map[string]int),
 	}
 
+	// The profile timezone is only known once the fetches below finish, so the
+	// query reaches one extra day back to cover any UTC offset. insightDays
+	// always covers last week too, which starts at most 13 days back.
 	now := time.Now().UTC()
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-	weekStart := todayStart.AddDate(0, 0, -int(todayStart.Weekday()))
-	lastWeekStart := weekStart.AddDate(0, 0, -7)
+	queryStart := insightsSince(todayStart).AddDate(0, 0, -1)
 
 	g, gctx := errgroup.WithContext(ctx)
 
@@ -156,9 +174,9 @@ func (p *firestoreEnrichmentProvider) fetchUserContext(ctx context.Context, user
 	g.Go(func() error {
 		iter := p.client.Collection("users").Doc(userID).Collection("productivities").
 			Where("deleted", "==", false).
-			Where("anchor", ">=", lastWeekStart).
+			Where("anchor", ">=", queryStart).
 			OrderBy("anchor", firestore.Desc).
-			Limit(100).
+			Limit(productivityLimit).
 			Documents(gctx)
 		defer iter.Stop()
 
@@ -220,6 +238,17 @@ func (p *firestoreEnrichmentProvider) fetchUserContext(ctx context.Context, user
 		return nil, err
 	}
 
+	// Bucket days and hours in the user's timezone.
+	tz := ""
+	if profile != nil {
+		tz = profile.Timezone
+	}
+	loc := timezone.Load(tz)
+	now = now.In(loc)
+	todayStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	weekStart := todayStart.AddDate(0, 0, -int(todayStart.Weekday()))
+	lastWeekStart := weekStart.AddDate(0, 0, -7)
+
 	// Aggregate productivities
 	timeModeCount := make(map[string]int)
 	hourBuckets := make(map[int]int) // hour (0-23) -> total minutes
@@ -271,7 +300,7 @@ func (p *firestoreEnrichmentProvider) fetchUserContext(ctx context.Context, user
 
 		// Most productive hour — bucket each session's start hour
 		if !d.StartTime.IsZero() && mins > 0 {
-			hourBuckets[d.StartTime.Hour()] += mins
+			hourBuckets[d.StartTime.In(loc).Hour()] += mins
 		}
 	}
 
@@ -340,9 +369,61 @@ func (p *firestoreEnrichmentProvider) fetchUserContext(ctx context.Context, user
 		uctx.PointsTotal = profile.PointsTotal
 	}
 
+	uctx.Insights = p.evaluateInsights(docs, insightsSince(todayStart), now)
+
 	return uctx, nil
 }
 
+// insightsSince is the local midnight insightDays-1 days before todayStart.
+func insightsSince(todayStart time.Time) time.Time {
+	return todayStart.AddDate(0, 0, -(insightDays - 1))
+}
+
+// evaluateInsights runs the shared insight rules over the fetched sessions and
+// keeps the top few. When the query hit its limit, the data only reaches back
+// to the oldest returned session.
+func (p *firestoreEnrichmentProvider) evaluateInsights(docs []productivityDoc, since, now time.Time) []insights.Insight {
+	data := &insights.Data{Since: since, Now: now, Sessions: make([]insights.Session, 0, len(docs))}
+	for _, d := range docs {
+		if d.StartTime.IsZero() {
+			continue
+		}
+		mins := d.TimeElapsed / 60
+		if mins <= 0 && d.TimeElapsed > 0 {
+			mins = 1
+		}
+		data.Sessions = append(data.Sessions, insights.Session{
+			Start:    d.StartTime.In(now.Location()),
+			Minutes:  mins,
+			Category: d.Category,
+			Mood:     d.Mood,
+			TimeMode: d.TimeMode,
+		})
+	}
+	if len(docs) >= productivityLimit {
+		// Docs are newest first.
+		if oldest := docs[len(docs)-1].StartTime.In(now.Location()); oldest.After(data.Since) {
+			data.Since = oldest
+		}
+	}
+	found := p.insights.Evaluate(data)
+	if len(found) > maxPromptInsights {
+		found = found[:maxPromptInsights]
+	}
+	return found
+}
+
+// docDay returns the local midnight of the day a session counts towards. A
+// session that recorded its own timezone keeps that day even after the user
+// moves; the day is then re-expressed in loc so it lines up with todayStart.
+func docDay(d productivityDoc, loc *time.Location) time.Time {
+	local := d.StartTime.In(loc)
+	if d.Timezone != "" {
+		local = d.StartTime.In(timezone.Load(d.Timezone))
+	}
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
 // calculateStreakFromDocs computes current and longest streak from productivity docs.
 func calculateStreakFromDocs(docs []productivityDoc, todayStart time.Time) (current, longest int) {
 	if len(docs) == 0 {
@@ -353,7 +434,7 @@ func calculateStreakFromDocs(docs []productivityDoc, todayStart time.Time) (curr
 	activeDays := make(map[string]bool)
 	for _, d := range docs {
 		if !d.StartTime.IsZero() {
-			day := d.StartTime.UTC().Format("2006-01-02")
+			day := docDay(d, todayStart.Location()).Format("2006-01-02")
 			activeDays[day] = true
 		}
 	}
@@ -408,7 +489,7 @@ func deriveStreakStatus(docs []productivityDoc, todayStart time.Time, streakDoc
 	var lastProd time.Time
 	for _, d := range docs {
 		if !d.StartTime.IsZero() {
-			day := time.Date(d.StartTime.Year(), d.StartTime.Month(), d.StartTime.Day(), 0, 0, 0, 0, time.UTC)
+			day := docDay(d, todayStart.Location())
 			if day.After(lastProd) {
 				lastProd = day
 			}
@@ -531,6 +612,17 @@ func FormatEnrichmentPrompt(uctx *UserProductivityContext, lang string) string {
 		b.WriteString("\n")
 	}
 
+	// Insights
+	if len(uctx.Insights) > 0 {
+		lines := make([]string, 0, len(uctx.Insights))
+		for _, in := range uctx.Insights {
+			lines = append(lines, insights.Localize(in, lang))
+		}
+		b.WriteString("Insights: ")
+		b.WriteString(strings.Join(lines, "; "))
+		b.WriteString("\n")
+	}
+
 	// Recent sessions
 	if len(uctx.RecentSessions) > 0 {
 		b.WriteString("Recent: ")
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/focusnest/shared-libs/insights"
 )
 
 func TestFormatEnrichmentPrompt_Nil(t *testing.T) {
@@ -171,6 +173,27 @@ func TestCalculateStreakFromDocs_GapSkipsToday(t *testing.T) {
 	}
 }
 
+func TestCalculateStreakFromDocs_UserTimezone(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	// Both sessions fall on the same UTC day but on consecutive Jakarta days.
+	docs := []productivityDoc{
+		{StartTime: today.Add(1 * time.Hour)},
+		{StartTime: today.Add(-1 * time.Hour)},
+	}
+	if current, _ := calculateStreakFromDocs(docs, today); current != 2 {
+		t.Errorf("expected current streak 2 in Asia/Jakarta, got %d", current)
+	}
+
+	// A session recorded in UTC keeps its UTC day.
+	docs[0].Timezone = "UTC"
+	if current, _ := calculateStreakFromDocs(docs, today); current != 1 {
+		t.Errorf("expected current streak 1 with a UTC session, got %d", current)
+	}
+}
+
 func TestCacheTTLBehavior(t *testing.T) {
 	p := &firestoreEnrichmentProvider{
 		cache: make(map[string]cacheEntry),
@@ -236,6 +259,65 @@ func TestCacheConcurrentAccess(t *testing.T) {
 	wg.Wait()
 }
 
+func TestFormatEnrichmentPrompt_Insights(t *testing.T) {
+	uctx := &UserProductivityContext{
+		WeekSessions: 4,
+		WeekMinutes:  120,
+		Insights: []insights.Insight{
+			{Key: insights.KeyCategoryDown, Args: []any{"Study", 30}},
+			{Key: insights.KeyTimeOfDayLonger, Args: []any{40, insights.TermMorning}},
+		},
+	}
+
+	en := FormatEnrichmentPrompt(uctx, "en")
+	if !strings.Contains(en, "Insights: Study time dropped 30% vs last week; You focus 40% longer in the morning than at other times of day\n") {
+		t.Errorf("missing EN insights, got:\n%s", en)
+	}
+	id := FormatEnrichmentPrompt(uctx, "id")
+	if !strings.Contains(id, "Waktu Study turun 30% dibanding minggu lalu; Kamu fokus 40% lebih lama di pagi hari") {
+		t.Errorf("missing ID insights, got:\n%s", id)
+	}
+}
+
+func TestEvaluateInsights_LimitedHistory(t *testing.T) {
+	p := &firestoreEnrichmentProvider{insights: insights.NewEngine(insights.DefaultRules()...)}
+	now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+	docs := make([]productivityDoc, 0, productivityLimit)
+	for i := 0; i < productivityLimit; i++ {
+		// Every two hours: the limit cuts the list off partway through last week.
+		docs = append(docs, productivityDoc{StartTime: now.Add(-time.Duration(2*i) * time.Hour), TimeElapsed: 1500, Category: "Work"})
+	}
+
+	for _, in := range p.evaluateInsights(docs, now.AddDate(0, 0, -14), now) {
+		if in.Rule == "weekly_trend" {
+			t.Errorf("trend insight from truncated history: %+v", in)
+		}
+	}
+}
+
+func TestEvaluateInsights_TrendMidWeek(t *testing.T) {
+	p := &firestoreEnrichmentProvider{insights: insights.NewEngine(insights.DefaultRules()...)}
+	// A Wednesday: last week's Sunday start is only 10 days back.
+	now := time.Date(2025, 11, 19, 20, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 11, 19, 0, 0, 0, 0, time.UTC)
+	var docs []productivityDoc
+	for day := 0; day < insightDays; day++ {
+		elapsed := 1800 // 30 minutes a day in the previous 7 days
+		if day < 7 {
+			elapsed = 3600
+		}
+		docs = append(docs, productivityDoc{StartTime: today.AddDate(0, 0, -day).Add(9 * time.Hour), TimeElapsed: elapsed, Category: "Work"})
+	}
+
+	var found bool
+	for _, in := range p.evaluateInsights(docs, insightsSince(today), now) {
+		found = found || in.Key == insights.KeyTotalUp
+	}
+	if !found {
+		t.Errorf("no weekly trend insight on a Wednesday with 14 days of history")
+	}
+}
+
 // mockEnrichmentProvider is a test helper for other tests.
 type mockEnrichmentProvider struct {
 	data *UserProductivityContext
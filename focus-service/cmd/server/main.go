@@ -12,12 +12,15 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	sharedauth "github.com/focusnest/shared-libs/auth"
+	"github.com/focusnest/shared-libs/events"
 	"github.com/focusnest/shared-libs/logging"
 	sharedserver "github.com/focusnest/shared-libs/server"
+	"github.com/focusnest/shared-libs/timezone"
 
 	"github.com/focusnest/focus-service/internal/config"
 	"github.com/focusnest/focus-service/internal/httpapi"
 	"github.com/focusnest/focus-service/internal/productivity"
+	"github.com/focusnest/focus-service/internal/room"
 	"github.com/focusnest/focus-service/internal/storage"
 )
 
@@ -30,7 +33,7 @@ func main() {
 
 	logger := logging.NewLogger("focus-service")
 
-	repo, cleanup, err := newRepository(ctx, cfg)
+	repo, roomRepo, tzLookup, cleanup, err := newRepository(ctx, cfg)
 	if err != nil {
 		panic(fmt.Errorf("repository init error: %w", err))
 	}
@@ -48,11 +51,23 @@ func main() {
 	ids := productivity.NewUUIDGenerator()
 
 	// Initialize productivity service
-	productivityService, err := productivity.NewService(repo, clock, ids)
+	productivityService, err := productivity.NewService(repo, clock, ids, events.LogPublisher{Logger: logger})
 	if err != nil {
 		panic(fmt.Errorf("productivity service init error: %w", err))
 	}
 
+	roomService, err := room.NewService(roomRepo, productivityService, clock, logger)
+	if err != nil {
+		panic(fmt.Errorf("room service init error: %w", err))
+	}
+	// Settle finished Pomodoros even when no member is left to open the room.
+	// Scheduled deployments sweep from one caller instead of every replica.
+	if cfg.RoomSweep == config.RoomSweepBackground {
+		sweepCtx, stopSweeper := context.WithCancel(ctx)
+		defer stopSweeper()
+		go roomService.RunSweeper(sweepCtx)
+	}
+
 	verifier, err := sharedauth.NewVerifier(sharedauth.Config{
 		Mode:     cfg.Auth.Mode,
 		JWKSURL:  cfg.Auth.JWKSURL,
@@ -63,13 +78,23 @@ func main() {
 		panic(fmt.Errorf("auth verifier error: %w", err))
 	}
 
-	router := sharedserver.NewRouter("focus-service", func(r chi.Router) {
+	router := sharedserver.NewStreamingRouter("focus-service", func(r chi.Router) {
+		if cfg.RoomSweep == config.RoomSweepScheduled {
+			httpapi.RegisterRoomSweepRoute(r, roomService)
+		}
+
 		r.Group(func(r chi.Router) {
 			r.Use(sharedauth.Middleware(verifier))
+			r.Use(timezone.Middleware(timezone.NewResolver(tzLookup, logger)))
 
 			// Register productivity routes
 			httpapi.RegisterRoutes(r, productivityService, storageSvc)
+			httpapi.RegisterRoomRoutes(r, roomService)
 		})
+	}, func(r chi.Router) {
+		// Room event streams stay open past the request timeout.
+		r.Use(sharedauth.Middleware(verifier))
+		httpapi.RegisterRoomStreamRoutes(r, roomService)
 	})
 
 	srv := &http.Server{
@@ -85,12 +110,15 @@ func main() {
 	}
 }
 
-func newRepository(ctx context.Context, cfg config.Config) (productivity.Repository, func(), error) {
+// newRepository also returns the room store and the profile timezone lookup;
+// the lookup is nil for the in-memory store, so requests without X-Timezone
+// use timezone.Default.
+func newRepository(ctx context.Context, cfg config.Config) (productivity.Repository, room.Repository, timezone.Lookup, func(), error) {
 	switch cfg.DataStore {
 	case config.DataStoreFirestore:
 		if cfg.Firestore.EmulatorHost != "" {
 			if err := os.Setenv("FIRESTORE_EMULATOR_HOST", cfg.Firestore.EmulatorHost); err != nil {
-				return nil, nil, fmt.Errorf("set FIRESTORE_EMULATOR_HOST: %w", err)
+				return nil, nil, nil, nil, fmt.Errorf("set FIRESTORE_EMULATOR_HOST: %w", err)
 			}
 		}
 
@@ -101,16 +129,16 @@ func newRepository(ctx context.Context, cfg config.Config) (productivity.Reposit
 		}
 		client, err := firestore.NewClientWithDatabase(ctx, cfg.GCPProjectID, databaseID)
 		if err != nil {
-			return nil, nil, fmt.Errorf("firestore client: %w", err)
+			return nil, nil, nil, nil, fmt.Errorf("firestore client: %w", err)
 		}
 
 		repo := productivity.NewFirestoreRepository(client)
 		cleanup := func() {
 			_ = client.Close()
 		}
-		return repo, cleanup, nil
+		return repo, room.NewFirestoreRepository(client), productivity.NewTimezoneLookup(client), cleanup, nil
 	default:
 		repo := productivity.NewMemoryRepository()
-		return repo, func() {}, nil
+		return repo, room.NewMemoryRepository(), nil, func() {}, nil
 	}
 }
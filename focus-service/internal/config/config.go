@@ -13,6 +13,7 @@ type Config struct {
 	Port         string
 	GCPProjectID string
 	DataStore    DataStore
+	RoomSweep    RoomSweep
 	Auth         AuthConfig
 	Firestore    FirestoreConfig
 	Storage      StorageConfig
@@ -28,6 +29,18 @@ const (
 	DataStoreFirestore DataStore = "firestore"
 )
 
+// RoomSweep selects who runs the periodic room sweep.
+type RoomSweep string
+
+const (
+	// RoomSweepBackground sweeps from a ticker in this process. Every
+	// replica sweeps, so use it for local development and single instances.
+	RoomSweepBackground RoomSweep = "background"
+	// RoomSweepScheduled leaves the sweep to one external caller, such as
+	// Cloud Scheduler, posting to /internal/rooms/sweep.
+	RoomSweepScheduled RoomSweep = "scheduled"
+)
+
 // AuthConfig stores authentication middleware setup.
 type AuthConfig struct {
 	Mode     sharedauth.Mode
@@ -52,6 +65,7 @@ func Load() (Config, error) {
 		Port:         envconfig.Get("PORT", "8080"),
 		GCPProjectID: envconfig.Get("GCP_PROJECT_ID", ""),
 		DataStore:    DataStore(strings.ToLower(envconfig.Get("DATASTORE", string(DataStoreMemory)))),
+		RoomSweep:    RoomSweep(strings.ToLower(envconfig.Get("ROOM_SWEEP", string(RoomSweepBackground)))),
 		Auth: AuthConfig{
 			Mode:    sharedauth.Mode(strings.ToLower(envconfig.Get("AUTH_MODE", string(sharedauth.ModeNoop)))),
 			JWKSURL: envconfig.Get("CLERK_JWKS_URL", ""),
@@ -88,6 +102,12 @@ func validate(cfg Config) error {
 		return fmt.Errorf("unsupported datastore: %s", cfg.DataStore)
 	}
 
+	switch cfg.RoomSweep {
+	case RoomSweepBackground, RoomSweepScheduled:
+	default:
+		return fmt.Errorf("unsupported room sweep: %s", cfg.RoomSweep)
+	}
+
 	if strings.TrimSpace(cfg.Storage.Bucket) == "" {
 		return fmt.Errorf("FOCUS_STORAGE_BUCKET is required")
 	}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"mime/multipart"
 	"net/http"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +15,8 @@ import (
 
 	"github.com/focusnest/focus-service/internal/productivity"
 	"github.com/focusnest/focus-service/internal/storage"
+	"github.com/focusnest/shared-libs/imageupload"
+	"github.com/focusnest/shared-libs/timezone"
 )
 
 const (
@@ -27,24 +28,9 @@ const (
 )
 
 var (
-	validCategories        = productivity.ValidCategories
-	validTimeModes         = productivity.ValidTimeModes
-	validMoods             = productivity.ValidMoods
-	allowedImageExtensions = map[string]struct{}{
-		".jpg":  {},
-		".jpeg": {},
-		".png":  {},
-		".webp": {},
-		".heic": {},
-		".heif": {},
-	}
-	allowedImageMIMEs = map[string]struct{}{
-		"image/jpeg": {},
-		"image/png":  {},
-		"image/webp": {},
-		"image/heic": {},
-		"image/heif": {},
-	}
+	validCategories = productivity.ValidCategories
+	validTimeModes  = productivity.ValidTimeModes
+	validMoods      = productivity.ValidMoods
 )
 
 type handler struct {
@@ -123,6 +109,7 @@ func (h *handler) listProductivities(w http.ResponseWriter, r *http.Request) {
 		PageToken: pageToken,
 		Month:     month,
 		Year:      year,
+		Timezone:  r.Header.Get(timezone.Header),
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
@@ -203,7 +190,7 @@ func (h *handler) createProductivity(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusInternalServerError, "image uploads are not configured")
 			return
 		}
-		if err := validateImageFile(imageHeader); err != nil {
+		if err := imageupload.ValidateFile(imageHeader); err != nil {
 			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -230,6 +217,7 @@ func (h *handler) createProductivity(w http.ResponseWriter, r *http.Request) {
 		Image:        storedImagePath,
 		StartTime:    req.StartTime.UTC(),
 		EndTime:      req.EndTime.UTC(),
+		Timezone:     r.Header.Get(timezone.Header),
 	}
 	if err := input.Validate(); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -305,7 +293,7 @@ func (h *handler) updateProductivity(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusBadRequest, "provide either image file or image_url, not both")
 			return
 		}
-		if err := validateImageFile(imageHeader); err != nil {
+		if err := imageupload.ValidateFile(imageHeader); err != nil {
 			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -653,22 +641,6 @@ func formValue(values map[string][]string, key string) (string, bool) {
 	return "", false
 }
 
-func validateImageFile(header *multipart.FileHeader) error {
-	if header == nil {
-		return fmt.Errorf("invalid image upload")
-	}
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	if _, ok := allowedImageExtensions[ext]; !ok {
-		return fmt.Errorf("unsupported image type; allowed formats: jpg, jpeg, png, webp, heic, heif")
-	}
-	if ct := strings.ToLower(header.Header.Get("Content-Type")); ct != "" {
-		if _, ok := allowedImageMIMEs[ct]; !ok {
-			return fmt.Errorf("unsupported image content type; allowed: image/jpeg, image/png, image/webp, image/heic, image/heif")
-		}
-	}
-	return nil
-}
-
 func (h *handler) resolveImageURL(ctx context.Context, raw string) string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
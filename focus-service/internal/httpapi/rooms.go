@@ -0,0 +1,326 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/focusnest/focus-service/internal/room"
+	"github.com/focusnest/shared-libs/timezone"
+)
+
+const (
+	maxRoomPayloadBytes = 16 << 10 // 16KB
+	// streamPingInterval keeps idle event streams alive through proxies.
+	streamPingInterval = 15 * time.Second
+	streamRetryMillis  = 3000
+	// sweepTimeout stays under the 60-second request timeout.
+	sweepTimeout = 50 * time.Second
+)
+
+type roomHandler struct {
+	service *room.Service
+}
+
+type createRoomRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+type setTimerRequest struct {
+	State            room.TimerState `json:"state"`
+	RemainingSeconds int             `json:"remaining_seconds"`
+}
+
+type startPomodoroRequest struct {
+	FocusMinutes int `json:"focus_minutes"`
+	BreakMinutes int `json:"break_minutes"`
+	Cycles       int `json:"cycles"`
+}
+
+// RegisterRoomRoutes mounts the co-focus room endpoints except the event
+// stream, which RegisterRoomStreamRoutes mounts outside the request timeout.
+func RegisterRoomRoutes(r chi.Router, svc *room.Service) {
+	h := &roomHandler{service: svc}
+	r.Route("/v1/rooms", func(r chi.Router) {
+		r.Post("/", h.createRoom)
+		r.Get("/{code}", h.getRoom)
+		r.Post("/{code}/join", h.joinRoom)
+		r.Post("/{code}/leave", h.leaveRoom)
+		r.Put("/{code}/timer", h.setTimer)
+		r.Post("/{code}/pomodoro", h.startPomodoro)
+		r.Delete("/{code}/pomodoro", h.stopPomodoro)
+	})
+}
+
+// RegisterRoomStreamRoutes mounts the long-lived Server-Sent Events stream of
+// a room. It ends when the caller leaves, the room closes or the client
+// disconnects.
+func RegisterRoomStreamRoutes(r chi.Router, svc *room.Service) {
+	h := &roomHandler{service: svc}
+	r.Get("/v1/rooms/{code}/events", h.streamRoom)
+}
+
+// RegisterRoomSweepRoute mounts POST /internal/rooms/sweep for a scheduler
+// to run room.Service.Sweep. The gateway does not proxy /internal, so only
+// callers allowed to invoke the service directly reach it.
+func RegisterRoomSweepRoute(r chi.Router, svc *room.Service) {
+	h := &roomHandler{service: svc}
+	r.Post("/internal/rooms/sweep", h.sweepRooms)
+}
+
+func (h *roomHandler) sweepRooms(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), sweepTimeout)
+	defer cancel()
+	if err := h.service.Sweep(ctx); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *roomHandler) createRoom(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+	var req createRoomRequest
+	if r.ContentLength != 0 {
+		if err := decodeRoomRequest(w, r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+	defer cancel()
+
+	view, err := h.service.Create(ctx, userID, room.CreateInput{
+		Name:     req.Name,
+		Category: req.Category,
+		Timezone: r.Header.Get(timezone.Header),
+	})
+	if err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, view)
+}
+
+func (h *roomHandler) getRoom(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+	defer cancel()
+
+	view, err := h.service.Get(ctx, userID, chi.URLParam(r, "code"))
+	if err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (h *roomHandler) joinRoom(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+	defer cancel()
+
+	view, err := h.service.Join(ctx, userID, chi.URLParam(r, "code"), r.Header.Get(timezone.Header))
+	if err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (h *roomHandler) leaveRoom(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+	defer cancel()
+
+	if err := h.service.Leave(ctx, userID, chi.URLParam(r, "code")); err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *roomHandler) setTimer(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+	var req setTimerRequest
+	if err := decodeRoomRequest(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+	defer cancel()
+
+	view, err := h.service.SetTimer(ctx, userID, chi.URLParam(r, "code"), room.TimerInput{
+		State:            req.State,
+		RemainingSeconds: req.RemainingSeconds,
+	})
+	if err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (h *roomHandler) startPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+	var req startPomodoroRequest
+	if r.ContentLength != 0 {
+		if err := decodeRoomRequest(w, r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+	defer cancel()
+
+	view, err := h.service.StartPomodoro(ctx, userID, chi.URLParam(r, "code"), room.PomodoroInput{
+		FocusMinutes: req.FocusMinutes,
+		BreakMinutes: req.BreakMinutes,
+		Cycles:       req.Cycles,
+	})
+	if err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (h *roomHandler) stopPomodoro(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+	defer cancel()
+
+	view, err := h.service.StopPomodoro(ctx, userID, chi.URLParam(r, "code"))
+	if err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// streamRoom writes a "room" event with the full room state on every change
+// and phase boundary, comment pings in between, and a final "closed" event
+// when the caller leaves or the room closes.
+func (h *roomHandler) streamRoom(w http.ResponseWriter, r *http.Request) {
+	userID := headerUserID(r)
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, "missing user ID")
+		return
+	}
+
+	views, err := h.service.Stream(r.Context(), userID, chi.URLParam(r, "code"))
+	if err != nil {
+		respondRoomServiceError(w, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The server write timeout would cut the stream; pings keep it alive.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case view, ok := <-views:
+			if !ok {
+				if r.Context().Err() == nil {
+					fmt.Fprint(w, "event: closed\ndata: {}\n\n")
+					_ = rc.Flush()
+				}
+				return
+			}
+			data, err := json.Marshal(view)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: room\ndata: %s\n\n", data)
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func respondRoomServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, room.ErrRoomNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, room.ErrNotHost):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, room.ErrRoomClosed), errors.Is(err, room.ErrRoomFull),
+		errors.Is(err, room.ErrPomodoroRunning), errors.Is(err, room.ErrNoPomodoro):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, room.ErrInvalidInput):
+		msg := strings.TrimSpace(err.Error())
+		if i := strings.Index(msg, ":"); i >= 0 {
+			msg = strings.TrimSpace(msg[i+1:])
+		}
+		writeError(w, http.StatusBadRequest, msg)
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func decodeRoomRequest(w http.ResponseWriter, r *http.Request, dst any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRoomPayloadBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("invalid JSON payload")
+	}
+	return nil
+}
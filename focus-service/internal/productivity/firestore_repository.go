@@ -10,6 +10,9 @@ import (
 	firestorepb "google.golang.org/genproto/googleapis/firestore/v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/focusnest/shared-libs/profilestats"
+	"github.com/focusnest/shared-libs/timezone"
 )
 
 type firestoreRepository struct {
@@ -23,11 +26,176 @@ func NewFirestoreRepository(client *firestore.Client) Repository {
 
 const productivitiesCollection = "productivities"
 
+// NewTimezoneLookup reads the timezone preference user-service stores on
+// profiles/{uid}.
+func NewTimezoneLookup(client *firestore.Client) timezone.Lookup {
+	return timezone.LookupFunc(func(ctx context.Context, userID string) (string, error) {
+		doc, err := client.Collection("profiles").Doc(userID).Get(ctx)
+		if status.Code(err) == codes.NotFound {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		tz, _ := doc.Data()[timezone.ProfileField].(string)
+		return tz, nil
+	})
+}
+
 func (r *firestoreRepository) userCollection(userID string) *firestore.CollectionRef {
 	return r.client.Collection("users").Doc(userID).Collection(productivitiesCollection)
 }
 
-func (r *firestoreRepository) Create(ctx context.Context, entry Entry) error {
+func (r *firestoreRepository) statsRef(userID string) *firestore.DocumentRef {
+	return r.client.Collection("users").Doc(userID).Collection(profilestats.Collection).Doc(profilestats.DocID)
+}
+
+// readStats loads the user's profile counters within tx. It returns nil for
+// users not counted yet, or counted under another Version: scanning their
+// history here would hold the write for it, so the write skips the counters
+// and rebuildStats counts them once it commits. Like every read it must come
+// before the transaction's writes.
+func (r *firestoreRepository) readStats(tx *firestore.Transaction, userID string) (*profilestats.Counters, error) {
+	doc, err := tx.Get(r.statsRef(userID))
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if profilestats.StoredVersion(doc.Data()) != profilestats.Version {
+		return nil, nil
+	}
+	var stats profilestats.Counters
+	if err := doc.DataTo(&stats); err != nil {
+		return nil, fmt.Errorf("decode profile stats: %w", err)
+	}
+	stats.RefreshXP()
+	return &stats, nil
+}
+
+// rebuildStats counts a user readStats skipped from history, with their
+// profile timezone placing sessions recorded without one, and awards the
+// achievements the counters unlock. It runs in its own transaction after the
+// session write committed; reading the counters first orders it with
+// concurrent writes, and it stops if another write counted them meanwhile.
+// The level is unknown before, so nothing is announced. If it fails the
+// counters stay uncounted until the next write or a reconcile.
+func (r *firestoreRepository) rebuildStats(ctx context.Context, userID string, at time.Time) {
+	_ = r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if current, err := r.readStats(tx, userID); err != nil || current != nil {
+			return err
+		}
+		fallback := ""
+		profile, err := tx.Get(r.client.Collection("profiles").Doc(userID))
+		if err == nil {
+			fallback, _ = profile.Data()[timezone.ProfileField].(string)
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		iter := tx.Documents(r.userCollection(userID).
+			Select("start_time", "deleted", "num_cycle", "category", "timezone", "time_elapsed", "time_mode"))
+		defer iter.Stop()
+		var sessions []profilestats.Session
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			session, err := storedSession(doc)
+			if err != nil {
+				return err
+			}
+			if session != nil {
+				sessions = append(sessions, *session)
+			}
+		}
+		stats := profilestats.Build(sessions, timezone.Load(fallback).String())
+		_, err = r.writeStats(tx, userID, &stats, nil, nil, at)
+		return err
+	})
+}
+
+// writeStats swaps before for after in stats, which readStats loaded in the
+// same transaction, awards the achievements the counters now unlock and
+// reports the level before and after. Nil stats, for a user not counted yet,
+// are left to rebuildStats. It reads the award documents, so it must come
+// before the transaction's other writes.
+func (r *firestoreRepository) writeStats(tx *firestore.Transaction, userID string, stats *profilestats.Counters, before, after *profilestats.Session, at time.Time) (LevelChange, error) {
+	if stats == nil {
+		return LevelChange{}, nil
+	}
+	change := LevelChange{Previous: stats.Level}
+	stats.Replace(before, after)
+	stats.UpdatedAt = at
+	change.Level, change.XP = stats.Level, stats.XP
+
+	var awards []*firestore.DocumentRef
+	for _, id := range stats.Achievements() {
+		awards = append(awards, r.client.Collection("profiles").Doc(userID).Collection(profilestats.AwardCollection).Doc(id))
+	}
+	var held []*firestore.DocumentSnapshot
+	if len(awards) > 0 {
+		var err error
+		if held, err = tx.GetAll(awards); err != nil {
+			return LevelChange{}, err
+		}
+	}
+	for _, doc := range held {
+		if doc.Exists() {
+			continue
+		}
+		award := profilestats.Award{Source: profilestats.AwardSourceAchievement, AwardedAt: at}
+		if err := tx.Create(doc.Ref, award); err != nil {
+			return LevelChange{}, err
+		}
+	}
+	return change, tx.Set(r.statsRef(userID), *stats)
+}
+
+func entrySession(entry Entry) *profilestats.Session {
+	return &profilestats.Session{
+		StartTime:   entry.StartTime,
+		Timezone:    entry.Timezone,
+		NumCycle:    entry.NumCycle,
+		Category:    entry.Category,
+		TimeElapsed: entry.TimeElapsed,
+		TimeMode:    entry.TimeMode,
+	}
+}
+
+// storedSession reads the counted fields of a productivity document. It
+// returns nil for deleted entries, which are no longer counted.
+func storedSession(doc *firestore.DocumentSnapshot) (*profilestats.Session, error) {
+	var payload struct {
+		StartTime   time.Time `firestore:"start_time"`
+		Timezone    string    `firestore:"timezone"`
+		NumCycle    int       `firestore:"num_cycle"`
+		Category    string    `firestore:"category"`
+		TimeElapsed int       `firestore:"time_elapsed"`
+		TimeMode    string    `firestore:"time_mode"`
+		Deleted     bool      `firestore:"deleted"`
+	}
+	if err := doc.DataTo(&payload); err != nil {
+		return nil, err
+	}
+	if payload.Deleted {
+		return nil, nil
+	}
+	return &profilestats.Session{
+		StartTime:   payload.StartTime,
+		Timezone:    payload.Timezone,
+		NumCycle:    payload.NumCycle,
+		Category:    payload.Category,
+		TimeElapsed: payload.TimeElapsed,
+		TimeMode:    payload.TimeMode,
+	}, nil
+}
+
+func (r *firestoreRepository) Create(ctx context.Context, entry Entry) (LevelChange, error) {
 	data := map[string]any{
 		"activity_name": entry.ActivityName,
 		"time_elapsed":  entry.TimeElapsed,
@@ -39,6 +207,7 @@ func (r *firestoreRepository) Create(ctx context.Context, entry Entry) error {
 		"image":         entry.Image,
 		"start_time":    entry.StartTime,
 		"end_time":      entry.EndTime,
+		"timezone":      entry.Timezone,
 		"created_at":    entry.CreatedAt,
 		"updated_at":    entry.UpdatedAt,
 		"deleted":       false,
@@ -46,14 +215,35 @@ func (r *firestoreRepository) Create(ctx context.Context, entry Entry) error {
 		"anchor": entry.StartTime,
 	}
 
-	_, err := r.userCollection(entry.UserID).Doc(entry.ID).Create(ctx, data)
-	if status.Code(err) == codes.AlreadyExists {
-		return ErrConflict
+	ref := r.userCollection(entry.UserID).Doc(entry.ID)
+	var (
+		change    LevelChange
+		uncounted bool
+	)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err == nil {
+			return ErrConflict
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		stats, err := r.readStats(tx, entry.UserID)
+		if err != nil {
+			return err
+		}
+		uncounted = stats == nil
+		change, err = r.writeStats(tx, entry.UserID, stats, nil, entrySession(entry), entry.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return tx.Create(ref, data)
+	})
+	if err == nil && uncounted {
+		r.rebuildStats(ctx, entry.UserID, entry.CreatedAt)
 	}
-	return err
+	return change, err
 }
 
-func (r *firestoreRepository) Update(ctx context.Context, entry Entry) error {
+func (r *firestoreRepository) Update(ctx context.Context, entry Entry) (LevelChange, error) {
 	data := map[string]any{
 		"activity_name": entry.ActivityName,
 		"time_elapsed":  entry.TimeElapsed,
@@ -68,8 +258,41 @@ func (r *firestoreRepository) Update(ctx context.Context, entry Entry) error {
 		"updated_at":    entry.UpdatedAt,
 		"anchor":        entry.StartTime,
 	}
-	_, err := r.userCollection(entry.UserID).Doc(entry.ID).Set(ctx, data, firestore.MergeAll)
-	return err
+	ref := r.userCollection(entry.UserID).Doc(entry.ID)
+	var (
+		change    LevelChange
+		uncounted bool
+	)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		before, err := storedSession(doc)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return ErrNotFound
+		}
+		stats, err := r.readStats(tx, entry.UserID)
+		if err != nil {
+			return err
+		}
+		uncounted = stats == nil
+		change, err = r.writeStats(tx, entry.UserID, stats, before, entrySession(entry), entry.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return tx.Set(ref, data, firestore.MergeAll)
+	})
+	if err == nil && uncounted {
+		r.rebuildStats(ctx, entry.UserID, entry.UpdatedAt)
+	}
+	return change, err
 }
 
 func (r *firestoreRepository) GetByID(ctx context.Context, userID, entryID string) (Entry, error) {
@@ -86,25 +309,46 @@ func (r *firestoreRepository) GetByID(ctx context.Context, userID, entryID strin
 	return snapshotToEntry(userID, doc)
 }
 
-func (r *firestoreRepository) Delete(ctx context.Context, userID, entryID string, deletedAt time.Time) error {
+func (r *firestoreRepository) Delete(ctx context.Context, userID, entryID string, deletedAt time.Time) (LevelChange, error) {
 	ref := r.userCollection(userID).Doc(entryID)
-	doc, err := ref.Get(ctx)
-	if status.Code(err) == codes.NotFound {
-		return ErrNotFound
-	}
-	if err != nil {
-		return err
-	}
-	if deleted, ok := doc.Data()["deleted"].(bool); ok && deleted {
-		return ErrNotFound
-	}
-
-	_, err = ref.Update(ctx, []firestore.Update{
-		{Path: "deleted", Value: true},
-		{Path: "updated_at", Value: deletedAt},
-		{Path: "deleted_at", Value: deletedAt},
+	var (
+		change    LevelChange
+		uncounted bool
+	)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		before, err := storedSession(doc)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return ErrNotFound
+		}
+		stats, err := r.readStats(tx, userID)
+		if err != nil {
+			return err
+		}
+		uncounted = stats == nil
+		change, err = r.writeStats(tx, userID, stats, before, nil, deletedAt)
+		if err != nil {
+			return err
+		}
+		return tx.Update(ref, []firestore.Update{
+			{Path: "deleted", Value: true},
+			{Path: "updated_at", Value: deletedAt},
+			{Path: "deleted_at", Value: deletedAt},
+		})
 	})
-	return err
+	if err == nil && uncounted {
+		r.rebuildStats(ctx, userID, deletedAt)
+	}
+	return change, err
 }
 
 func (r *firestoreRepository) ListByRange(
@@ -266,6 +510,7 @@ func snapshotToEntry(userID string, doc *firestore.DocumentSnapshot) (Entry, err
 		Image        string    `firestore:"image"`
 		StartTime    time.Time `firestore:"start_time"`
 		EndTime      time.Time `firestore:"end_time"`
+		Timezone     string    `firestore:"timezone"`
 		CreatedAt    time.Time `firestore:"created_at"`
 		UpdatedAt    time.Time `firestore:"updated_at"`
 		DeletedAt    time.Time `firestore:"deleted_at"`
@@ -287,6 +532,7 @@ func snapshotToEntry(userID string, doc *firestore.DocumentSnapshot) (Entry, err
 		Image:        payload.Image,
 		StartTime:    payload.StartTime,
 		EndTime:      payload.EndTime,
+		Timezone:     payload.Timezone,
 		CreatedAt:    payload.CreatedAt,
 		UpdatedAt:    payload.UpdatedAt,
 	}
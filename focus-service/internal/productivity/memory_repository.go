@@ -19,7 +19,7 @@ func NewMemoryRepository() Repository {
 	}
 }
 
-func (r *memoryRepository) Create(_ context.Context, entry Entry) error {
+func (r *memoryRepository) Create(_ context.Context, entry Entry) (LevelChange, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -30,26 +30,26 @@ func (r *memoryRepository) Create(_ context.Context, entry Entry) error {
 	}
 
 	if _, exists := userStore[entry.ID]; exists {
-		return ErrConflict
+		return LevelChange{}, ErrConflict
 	}
 
 	userStore[entry.ID] = entry
-	return nil
+	return LevelChange{}, nil
 }
 
-func (r *memoryRepository) Update(_ context.Context, entry Entry) error {
+func (r *memoryRepository) Update(_ context.Context, entry Entry) (LevelChange, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	userStore, ok := r.store[entry.UserID]
 	if !ok {
-		return ErrNotFound
+		return LevelChange{}, ErrNotFound
 	}
 	if _, exists := userStore[entry.ID]; !exists {
-		return ErrNotFound
+		return LevelChange{}, ErrNotFound
 	}
 	userStore[entry.ID] = entry
-	return nil
+	return LevelChange{}, nil
 }
 
 func (r *memoryRepository) GetByID(_ context.Context, userID, entryID string) (Entry, error) {
@@ -69,25 +69,25 @@ func (r *memoryRepository) GetByID(_ context.Context, userID, entryID string) (E
 	return entry, nil
 }
 
-func (r *memoryRepository) Delete(_ context.Context, userID, entryID string, deletedAt time.Time) error {
+func (r *memoryRepository) Delete(_ context.Context, userID, entryID string, deletedAt time.Time) (LevelChange, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	userStore, ok := r.store[userID]
 	if !ok {
-		return ErrNotFound
+		return LevelChange{}, ErrNotFound
 	}
 
 	entry, ok := userStore[entryID]
 	if !ok || entry.DeletedAt != nil {
-		return ErrNotFound
+		return LevelChange{}, ErrNotFound
 	}
 
 	entry.DeletedAt = &deletedAt
 	entry.UpdatedAt = deletedAt
 	userStore[entryID] = entry
 
-	return nil
+	return LevelChange{}, nil
 }
 
 func (r *memoryRepository) ListByRange(_ context.Context, userID string, startInclusive, endExclusive time.Time, pagination Pagination) ([]Entry, PageInfo, error) {
@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/focusnest/shared-libs/events"
+	"github.com/focusnest/shared-libs/pubsub"
+	"github.com/focusnest/shared-libs/timezone"
 )
 
 // Entry is a single productivity event captured by the user.
@@ -22,6 +26,7 @@ type Entry struct {
 	Image        string     `json:"image,omitempty"`
 	StartTime    time.Time  `json:"start_time"`
 	EndTime      time.Time  `json:"end_time"`
+	Timezone     string     `json:"timezone,omitempty"` // user's timezone when the entry was created; pins its local date
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	DeletedAt    *time.Time `json:"-"`
@@ -60,6 +65,7 @@ var ValidMoods = []string{
 
 // CreateInput captures the data required to create a new entry.
 type CreateInput struct {
+	ID           string // optional; a stable ID makes a retried create fail with ErrConflict
 	UserID       string
 	ActivityName string
 	TimeElapsed  int
@@ -71,6 +77,7 @@ type CreateInput struct {
 	Image        string
 	StartTime    time.Time
 	EndTime      time.Time
+	Timezone     string
 }
 
 // PatchInput captures partial updates for an entry.
@@ -94,13 +101,15 @@ type ListInput struct {
 	PageToken string
 	Month     *int
 	Year      *int
+	Timezone  string // month boundaries are local to this timezone
 }
 
 // MonthHistoryInput captures parameters for monthly history.
 type MonthHistoryInput struct {
-	UserID string
-	Month  int
-	Year   int
+	UserID   string
+	Month    int
+	Year     int
+	Timezone string
 }
 
 // DayStatus represents the status of a single day in monthly history.
@@ -301,12 +310,22 @@ type PageInfo struct {
 	NextToken string `json:"nextToken,omitempty"`
 }
 
-// Repository encapsulates persistence for productivity entries.
+// LevelChange is the user's XP level before and after a write, from the
+// profile counters kept with the entries. It is zero when a store keeps no
+// counters.
+type LevelChange struct {
+	Previous int
+	Level    int
+	XP       int
+}
+
+// Repository encapsulates persistence for productivity entries. Writes
+// report the level change they caused.
 type Repository interface {
-	Create(ctx context.Context, entry Entry) error
+	Create(ctx context.Context, entry Entry) (LevelChange, error)
 	GetByID(ctx context.Context, userID, entryID string) (Entry, error)
-	Update(ctx context.Context, entry Entry) error
-	Delete(ctx context.Context, userID, entryID string, deletedAt time.Time) error
+	Update(ctx context.Context, entry Entry) (LevelChange, error)
+	Delete(ctx context.Context, userID, entryID string, deletedAt time.Time) (LevelChange, error)
 	ListByRange(ctx context.Context, userID string, startInclusive, endExclusive time.Time, pagination Pagination) ([]Entry, PageInfo, error)
 }
 
@@ -334,13 +353,15 @@ type IDGenerator interface {
 
 // Service orchestrates the domain operations for productivity entries.
 type Service struct {
-	repo  Repository
-	clock Clock
-	ids   IDGenerator
+	repo   Repository
+	clock  Clock
+	ids    IDGenerator
+	events events.Publisher // nil drops level-up events
 }
 
 // NewService constructs a Service instance with the provided collaborators.
-func NewService(repo Repository, clock Clock, ids IDGenerator) (*Service, error) {
+// publisher may be nil to skip level-up events.
+func NewService(repo Repository, clock Clock, ids IDGenerator, publisher events.Publisher) (*Service, error) {
 	if repo == nil {
 		return nil, errors.New("repo is required")
 	}
@@ -350,7 +371,22 @@ func NewService(repo Repository, clock Clock, ids IDGenerator) (*Service, error)
 	if ids == nil {
 		return nil, errors.New("id generator is required")
 	}
-	return &Service{repo: repo, clock: clock, ids: ids}, nil
+	return &Service{repo: repo, clock: clock, ids: ids, events: publisher}, nil
+}
+
+// announceLevel publishes a level-up when a write raised the user's level.
+// The write stands if publishing fails; the event is a notification.
+func (s *Service) announceLevel(ctx context.Context, userID string, change LevelChange) {
+	if s.events == nil || change.Previous == 0 || change.Level <= change.Previous {
+		return
+	}
+	_ = s.events.Publish(ctx, pubsub.TopicUserEvents, events.TypeUserLeveledUp, events.UserLeveledUp{
+		UserID:        userID,
+		PreviousLevel: change.Previous,
+		Level:         change.Level,
+		XP:            change.XP,
+		LeveledUpAt:   s.clock.Now().UTC(),
+	})
 }
 
 // Create registers a new productivity entry for the given user.
@@ -359,9 +395,13 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (Entry, error)
 		return Entry{}, fmt.Errorf("%w: %s", ErrInvalidInput, err.Error())
 	}
 
+	id := input.ID
+	if id == "" {
+		id = s.ids.NewID()
+	}
 	now := s.clock.Now().UTC()
 	entry := Entry{
-		ID:           s.ids.NewID(),
+		ID:           id,
 		UserID:       input.UserID,
 		ActivityName: strings.TrimSpace(input.ActivityName),
 		TimeElapsed:  input.TimeElapsed,
@@ -373,13 +413,16 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (Entry, error)
 		Image:        strings.TrimSpace(input.Image),
 		StartTime:    input.StartTime.UTC(),
 		EndTime:      input.EndTime.UTC(),
+		Timezone:     timezone.Load(input.Timezone).String(),
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
 
-	if err := s.repo.Create(ctx, entry); err != nil {
+	change, err := s.repo.Create(ctx, entry)
+	if err != nil {
 		return Entry{}, err
 	}
+	s.announceLevel(ctx, entry.UserID, change)
 
 	return entry, nil
 }
@@ -398,9 +441,11 @@ func (s *Service) Update(ctx context.Context, userID, entryID string, patch Patc
 		return Entry{}, fmt.Errorf("%w: %s", ErrInvalidInput, err.Error())
 	}
 	updated.UpdatedAt = s.clock.Now().UTC()
-	if err := s.repo.Update(ctx, updated); err != nil {
+	change, err := s.repo.Update(ctx, updated)
+	if err != nil {
 		return Entry{}, err
 	}
+	s.announceLevel(ctx, userID, change)
 	return updated, nil
 }
 
@@ -417,21 +462,26 @@ func (s *Service) Delete(ctx context.Context, userID, entryID string) error {
 	if userID == "" || entryID == "" {
 		return ErrNotFound
 	}
-	return s.repo.Delete(ctx, userID, entryID, s.clock.Now().UTC())
+	change, err := s.repo.Delete(ctx, userID, entryID, s.clock.Now().UTC())
+	if err != nil {
+		return err
+	}
+	s.announceLevel(ctx, userID, change)
+	return nil
 }
 
-// ListMonth returns entries for the month containing the provided anchor time.
+// ListMonth returns entries for the month containing the provided anchor time,
+// with month boundaries in the anchor's location.
 // The repository applies time-window filtering on a canonical "anchor" field.
 func (s *Service) ListMonth(ctx context.Context, userID string, anchor time.Time, pagination Pagination) ([]Entry, PageInfo, error) {
 	if userID == "" {
 		return nil, PageInfo{}, ErrNotFound
 	}
 
-	// Month boundaries in UTC (repository uses them on "anchor").
-	monthStart := time.Date(anchor.Year(), anchor.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(anchor.Year(), anchor.Month(), 1, 0, 0, 0, 0, anchor.Location())
 	monthEnd := monthStart.AddDate(0, 1, 0)
 
-	return s.repo.ListByRange(ctx, userID, monthStart, monthEnd, pagination)
+	return s.repo.ListByRange(ctx, userID, monthStart.UTC(), monthEnd.UTC(), pagination)
 }
 
 // List returns entries based on the provided list input.
@@ -456,8 +506,10 @@ func (s *Service) List(ctx context.Context, input ListInput) (ListResponse, erro
 	// Handle month/year filtering
 	var startTime, endTime time.Time
 	if input.Month != nil && input.Year != nil {
-		startTime = time.Date(*input.Year, time.Month(*input.Month), 1, 0, 0, 0, 0, time.UTC)
-		endTime = startTime.AddDate(0, 1, 0)
+		loc := timezone.Load(input.Timezone)
+		startTime = time.Date(*input.Year, time.Month(*input.Month), 1, 0, 0, 0, 0, loc)
+		endTime = startTime.AddDate(0, 1, 0).UTC()
+		startTime = startTime.UTC()
 	} else {
 		// Default to all time
 		startTime = time.Time{}
@@ -498,7 +550,9 @@ func (s *Service) GetMonthHistory(ctx context.Context, input MonthHistoryInput)
 	}
 
 	// Set defaults to current month/year if not provided
-	now := s.clock.Now().UTC()
+	loc := timezone.Load(input.Timezone)
+	now := s.clock.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
 	if input.Month == 0 {
 		input.Month = int(now.Month())
 	}
@@ -507,10 +561,10 @@ func (s *Service) GetMonthHistory(ctx context.Context, input MonthHistoryInput)
 	}
 
 	// Get all entries for the month
-	monthStart := time.Date(input.Year, time.Month(input.Month), 1, 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(input.Year, time.Month(input.Month), 1, 0, 0, 0, 0, loc)
 	monthEnd := monthStart.AddDate(0, 1, 0)
 
-	entries, _, err := s.repo.ListByRange(ctx, input.UserID, monthStart, monthEnd, Pagination{PageSize: 1000})
+	entries, _, err := s.repo.ListByRange(ctx, input.UserID, monthStart.UTC(), monthEnd.UTC(), Pagination{PageSize: 1000})
 	if err != nil {
 		return MonthHistoryResponse{}, err
 	}
@@ -519,15 +573,15 @@ func (s *Service) GetMonthHistory(ctx context.Context, input MonthHistoryInput)
 	dayMap := make(map[string]*DayStatus)
 
 	// Initialize all days in the month
-	daysInMonth := time.Date(input.Year, time.Month(input.Month+1), 0, 0, 0, 0, 0, time.UTC).Day()
+	daysInMonth := time.Date(input.Year, time.Month(input.Month+1), 0, 0, 0, 0, 0, loc).Day()
 	for day := 1; day <= daysInMonth; day++ {
-		date := time.Date(input.Year, time.Month(input.Month), day, 0, 0, 0, 0, time.UTC)
+		date := time.Date(input.Year, time.Month(input.Month), day, 0, 0, 0, 0, loc)
 		dateStr := date.Format("2006-01-02")
 
 		status := "upcoming"
-		if date.Before(now.Truncate(24 * time.Hour)) {
+		if date.Before(today) {
 			status = "active"
-		} else if date.Equal(now.Truncate(24 * time.Hour)) {
+		} else if date.Equal(today) {
 			status = "today"
 		}
 
@@ -539,9 +593,9 @@ func (s *Service) GetMonthHistory(ctx context.Context, input MonthHistoryInput)
 		}
 	}
 
-	// Aggregate entries by day
+	// Aggregate entries by day; an entry keeps the local date it was recorded on.
 	for _, entry := range entries {
-		dayStr := entry.StartTime.Format("2006-01-02")
+		dayStr := entry.StartTime.In(entryLocation(entry, loc)).Format("2006-01-02")
 		if dayStatus, exists := dayMap[dayStr]; exists {
 			dayStatus.TotalElapsedSeconds += entry.TimeElapsed
 			dayStatus.Sessions++
@@ -551,7 +605,7 @@ func (s *Service) GetMonthHistory(ctx context.Context, input MonthHistoryInput)
 	// Convert to slice
 	days := make([]DayStatus, 0, len(dayMap))
 	for day := 1; day <= daysInMonth; day++ {
-		date := time.Date(input.Year, time.Month(input.Month), day, 0, 0, 0, 0, time.UTC)
+		date := time.Date(input.Year, time.Month(input.Month), day, 0, 0, 0, 0, loc)
 		dateStr := date.Format("2006-01-02")
 		if dayStatus, exists := dayMap[dateStr]; exists {
 			days = append(days, *dayStatus)
@@ -564,3 +618,12 @@ func (s *Service) GetMonthHistory(ctx context.Context, input MonthHistoryInput)
 		Days:  days,
 	}, nil
 }
+
+// entryLocation returns the timezone an entry was recorded in, or fallback for
+// entries created before timezones were stored.
+func entryLocation(entry Entry, fallback *time.Location) *time.Location {
+	if timezone.Valid(entry.Timezone) {
+		return timezone.Load(entry.Timezone)
+	}
+	return fallback
+}
@@ -0,0 +1,85 @@
+package productivity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/events"
+)
+
+// levelRepo is a memory repository that reports a fixed level change.
+type levelRepo struct {
+	Repository
+	change LevelChange
+}
+
+func (r *levelRepo) Create(ctx context.Context, entry Entry) (LevelChange, error) {
+	if _, err := r.Repository.Create(ctx, entry); err != nil {
+		return LevelChange{}, err
+	}
+	return r.change, nil
+}
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+type fixedIDs struct{}
+
+func (fixedIDs) NewID() string { return "e1" }
+
+type recordingPublisher struct{ payloads []any }
+
+func (p *recordingPublisher) Publish(_ context.Context, _ string, eventType string, payload any) error {
+	if eventType == events.TypeUserLeveledUp {
+		p.payloads = append(p.payloads, payload)
+	}
+	return nil
+}
+
+func TestCreatePublishesLevelUp(t *testing.T) {
+	now := time.Date(2025, 11, 20, 9, 0, 0, 0, time.UTC)
+	input := CreateInput{
+		UserID:       "u1",
+		ActivityName: "Essay",
+		TimeElapsed:  1800,
+		NumCycle:     1,
+		TimeMode:     "Pomodoro",
+		Category:     "Study",
+		StartTime:    now.Add(-30 * time.Minute),
+		EndTime:      now,
+	}
+	tests := []struct {
+		name   string
+		change LevelChange
+		want   bool
+	}{
+		{name: "level up", change: LevelChange{Previous: 2, Level: 3, XP: 310}, want: true},
+		{name: "same level", change: LevelChange{Previous: 3, Level: 3, XP: 350}},
+		{name: "not counted", change: LevelChange{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			repo := &levelRepo{Repository: NewMemoryRepository(), change: tt.change}
+			svc, err := NewService(repo, fixedClock{now}, fixedIDs{}, publisher)
+			if err != nil {
+				t.Fatalf("NewService: %v", err)
+			}
+			if _, err := svc.Create(context.Background(), input); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if !tt.want {
+				if len(publisher.payloads) != 0 {
+					t.Fatalf("events = %+v, want none", publisher.payloads)
+				}
+				return
+			}
+			want := events.UserLeveledUp{UserID: "u1", PreviousLevel: 2, Level: 3, XP: 310, LeveledUpAt: now}
+			if len(publisher.payloads) != 1 || publisher.payloads[0] != want {
+				t.Fatalf("events = %+v, want %+v", publisher.payloads, want)
+			}
+		})
+	}
+}
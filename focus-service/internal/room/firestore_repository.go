@@ -0,0 +1,156 @@
+package room
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const roomsCollection = "rooms"
+
+type firestoreRepository struct {
+	client *firestore.Client
+}
+
+// NewFirestoreRepository instantiates a Firestore-backed repository. Watch
+// uses snapshot listeners, so streams on different instances see the same
+// changes.
+func NewFirestoreRepository(client *firestore.Client) Repository {
+	return &firestoreRepository{client: client}
+}
+
+func (r *firestoreRepository) doc(roomID string) *firestore.DocumentRef {
+	return r.client.Collection(roomsCollection).Doc(roomID)
+}
+
+func (r *firestoreRepository) Create(ctx context.Context, room Room) error {
+	room.Active = room.active()
+	_, err := r.doc(room.ID).Create(ctx, room)
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrCodeTaken
+	}
+	return err
+}
+
+func (r *firestoreRepository) Get(ctx context.Context, roomID string) (Room, error) {
+	snap, err := r.doc(roomID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return Room{}, ErrRoomNotFound
+	}
+	if err != nil {
+		return Room{}, err
+	}
+	return decodeRoom(snap)
+}
+
+func (r *firestoreRepository) Update(ctx context.Context, roomID string, fn func(*Room) error) (Room, error) {
+	var (
+		result Room
+		fnErr  error
+	)
+	ref := r.doc(roomID)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		fnErr = nil
+		snap, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrRoomNotFound
+		}
+		if err != nil {
+			return err
+		}
+		current, err := decodeRoom(snap)
+		if err != nil {
+			return err
+		}
+		room, _ := decodeRoom(snap) // a separate copy for fn to mutate
+		if err := fn(&room); err != nil {
+			// Not a transaction failure: commit nothing and report fnErr.
+			result, fnErr = current, err
+			return nil
+		}
+		room.Active = room.active()
+		result = room
+		return tx.Set(ref, room)
+	})
+	if err != nil {
+		return Room{}, err
+	}
+	return result, fnErr
+}
+
+func (r *firestoreRepository) Watch(ctx context.Context, roomID string) (<-chan Room, error) {
+	iter := r.doc(roomID).Snapshots(ctx)
+	first, err := iter.Next()
+	if err != nil {
+		iter.Stop()
+		return nil, err
+	}
+	if !first.Exists() {
+		iter.Stop()
+		return nil, ErrRoomNotFound
+	}
+	room, err := decodeRoom(first)
+	if err != nil {
+		iter.Stop()
+		return nil, err
+	}
+
+	ch := make(chan Room, 1)
+	ch <- room
+	go func() {
+		defer close(ch)
+		defer iter.Stop()
+		for {
+			snap, err := iter.Next()
+			if err != nil || !snap.Exists() {
+				return
+			}
+			room, err := decodeRoom(snap)
+			if err != nil {
+				continue
+			}
+			select {
+			case ch <- room:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// ListActive queries the stored Active flag, so it needs no composite index.
+func (r *firestoreRepository) ListActive(ctx context.Context, after string, limit int) ([]Room, error) {
+	q := r.client.Collection(roomsCollection).
+		Where("active", "==", true).
+		OrderBy(firestore.DocumentID, firestore.Asc).
+		Limit(limit)
+	if after != "" {
+		q = q.StartAfter(after)
+	}
+	snaps, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	rooms := make([]Room, 0, len(snaps))
+	for _, snap := range snaps {
+		room, err := decodeRoom(snap)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+func decodeRoom(snap *firestore.DocumentSnapshot) (Room, error) {
+	var room Room
+	if err := snap.DataTo(&room); err != nil {
+		return Room{}, fmt.Errorf("unmarshal room: %w", err)
+	}
+	room.ID = snap.Ref.ID
+	return room, nil
+}
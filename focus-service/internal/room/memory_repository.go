@@ -0,0 +1,131 @@
+package room
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type memoryRepository struct {
+	mu       sync.Mutex
+	rooms    map[string]Room
+	watchers map[string]map[chan Room]struct{}
+}
+
+// NewMemoryRepository returns an in-memory repository intended for local
+// development. Watchers only see changes made through the same process.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		rooms:    make(map[string]Room),
+		watchers: make(map[string]map[chan Room]struct{}),
+	}
+}
+
+func (r *memoryRepository) Create(_ context.Context, room Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.rooms[room.ID]; exists {
+		return ErrCodeTaken
+	}
+	room.Active = room.active()
+	r.rooms[room.ID] = clone(room)
+	return nil
+}
+
+func (r *memoryRepository) Get(_ context.Context, roomID string) (Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	room, ok := r.rooms[roomID]
+	if !ok {
+		return Room{}, ErrRoomNotFound
+	}
+	return clone(room), nil
+}
+
+func (r *memoryRepository) Update(_ context.Context, roomID string, fn func(*Room) error) (Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.rooms[roomID]
+	if !ok {
+		return Room{}, ErrRoomNotFound
+	}
+	room := clone(current)
+	if err := fn(&room); err != nil {
+		return clone(current), err
+	}
+	room.Active = room.active()
+	r.rooms[roomID] = clone(room)
+	for ch := range r.watchers[roomID] {
+		// Drop a stale pending update in favour of the latest state.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- clone(room)
+	}
+	return room, nil
+}
+
+func (r *memoryRepository) Watch(ctx context.Context, roomID string) (<-chan Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	room, ok := r.rooms[roomID]
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	ch := make(chan Room, 1)
+	ch <- clone(room)
+	if r.watchers[roomID] == nil {
+		r.watchers[roomID] = make(map[chan Room]struct{})
+	}
+	r.watchers[roomID][ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.watchers[roomID], ch)
+		if len(r.watchers[roomID]) == 0 {
+			delete(r.watchers, roomID)
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (r *memoryRepository) ListActive(_ context.Context, after string, limit int) ([]Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rooms []Room
+	for id, room := range r.rooms {
+		if room.Active && id > after {
+			rooms = append(rooms, clone(room))
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	if len(rooms) > limit {
+		rooms = rooms[:limit]
+	}
+	return rooms, nil
+}
+
+// clone deep-copies the parts of a room that Update callbacks mutate.
+func clone(room Room) Room {
+	room.Members = append([]Member(nil), room.Members...)
+	room.MemberIDs = append([]string(nil), room.MemberIDs...)
+	if room.Pomodoro != nil {
+		p := *room.Pomodoro
+		p.Participants = make(map[string]Participant, len(room.Pomodoro.Participants))
+		for id, part := range room.Pomodoro.Participants {
+			part.Intervals = append([]Interval(nil), part.Intervals...)
+			p.Participants[id] = part
+		}
+		room.Pomodoro = &p
+	}
+	if room.LastSession != nil {
+		s := *room.LastSession
+		s.Results = append([]SessionResult(nil), room.LastSession.Results...)
+		room.LastSession = &s
+	}
+	return room
+}
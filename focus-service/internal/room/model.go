@@ -0,0 +1,261 @@
+// Package room implements live co-focus rooms: members share presence and
+// timer state in real time, and the host can run a shared Pomodoro whose
+// finished sessions become productivity entries for every participant.
+package room
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Status is the lifecycle state of a room.
+type Status string
+
+const (
+	StatusOpen   Status = "open"
+	StatusClosed Status = "closed" // the last member left, or nobody connected for roomIdleTTL
+)
+
+// TimerState is what a member's timer is doing.
+type TimerState string
+
+const (
+	TimerIdle     TimerState = "idle"
+	TimerFocusing TimerState = "focusing"
+	TimerBreak    TimerState = "break"
+)
+
+// Timer is a member's own timer, used while no shared Pomodoro runs.
+type Timer struct {
+	State  TimerState `firestore:"state"`
+	EndsAt *time.Time `firestore:"ends_at"`
+}
+
+// Member is a user in a room.
+type Member struct {
+	UserID    string    `firestore:"user_id"`
+	Timezone  string    `firestore:"timezone"`
+	JoinedAt  time.Time `firestore:"joined_at"`
+	Connected bool      `firestore:"connected"` // has an open event stream
+	LastSeen  time.Time `firestore:"last_seen"` // refreshed by stream heartbeats
+	Timer     Timer     `firestore:"timer"`
+}
+
+// Interval is a stretch of presence in a Pomodoro session: the member was in
+// the room with a stream connected. End is nil while that lasts.
+type Interval struct {
+	Start time.Time  `firestore:"start"`
+	End   *time.Time `firestore:"end"`
+}
+
+// Participant tracks one member's presence during a Pomodoro session.
+type Participant struct {
+	Timezone  string     `firestore:"timezone"`
+	Intervals []Interval `firestore:"intervals"`
+}
+
+// Pomodoro is a shared session: Cycles focus phases separated by breaks,
+// with no break after the last focus phase. Phases are derived from
+// StartedAt, so no ticking state is stored.
+type Pomodoro struct {
+	Seq          int                    `firestore:"seq"` // per room, part of the entry IDs
+	FocusSeconds int                    `firestore:"focus_seconds"`
+	BreakSeconds int                    `firestore:"break_seconds"`
+	Cycles       int                    `firestore:"cycles"`
+	StartedAt    time.Time              `firestore:"started_at"`
+	StoppedAt    *time.Time             `firestore:"stopped_at"` // set when the host ends it early
+	Participants map[string]Participant `firestore:"participants"`
+}
+
+// SessionResult is one participant's share of a finished session.
+type SessionResult struct {
+	UserID       string    `json:"user_id" firestore:"user_id"`
+	FocusSeconds int       `json:"focus_seconds" firestore:"focus_seconds"`
+	Cycles       int       `json:"cycles" firestore:"cycles"`
+	Timezone     string    `json:"-" firestore:"timezone"`
+	StartTime    time.Time `json:"-" firestore:"start_time"`
+	EndTime      time.Time `json:"-" firestore:"end_time"`
+}
+
+// SessionSummary describes the last finished Pomodoro. Recorded turns true
+// once every result has a productivity entry.
+type SessionSummary struct {
+	Seq       int             `json:"seq" firestore:"seq"`
+	StartedAt time.Time       `json:"started_at" firestore:"started_at"`
+	EndedAt   time.Time       `json:"ended_at" firestore:"ended_at"`
+	Results   []SessionResult `json:"results" firestore:"results"`
+	Recorded  bool            `json:"-" firestore:"recorded"`
+}
+
+// Room is the stored state of a co-focus room. The ID doubles as the join code.
+type Room struct {
+	ID          string          `firestore:"-"`
+	Name        string          `firestore:"name"`
+	Category    string          `firestore:"category"`
+	HostID      string          `firestore:"host_id"`
+	Status      Status          `firestore:"status"`
+	Members     []Member        `firestore:"members"`
+	MemberIDs   []string        `firestore:"member_ids"` // mirrors Members for array-contains queries
+	Pomodoro    *Pomodoro       `firestore:"pomodoro"`
+	Sessions    int             `firestore:"sessions"` // Pomodoros started so far
+	LastSession *SessionSummary `firestore:"last_session"`
+	Active      bool            `firestore:"active"` // mirrors active() for the sweep query
+	CreatedAt   time.Time       `firestore:"created_at"`
+	UpdatedAt   time.Time       `firestore:"updated_at"`
+}
+
+// active reports whether the sweeper still has work in the room: it is open,
+// runs a Pomodoro, or owes productivity entries for its last session.
+// Repositories store the result in Active on every write.
+func (r *Room) active() bool {
+	return r.Status == StatusOpen || r.Pomodoro != nil || (r.LastSession != nil && !r.LastSession.Recorded)
+}
+
+// idle reports whether an open room has had no member online for
+// roomIdleTTL. Activity is the latest heartbeat, join or room update.
+func (r *Room) idle(now time.Time) bool {
+	last := r.UpdatedAt
+	for _, m := range r.Members {
+		if m.online(now) {
+			return false
+		}
+		if m.LastSeen.After(last) {
+			last = m.LastSeen
+		}
+	}
+	return now.Sub(last) >= roomIdleTTL
+}
+
+// close ends the room and any Pomodoro still running in it.
+func (r *Room) close(now time.Time) {
+	r.Status = StatusClosed
+	if r.Pomodoro != nil && !r.Pomodoro.finished(now) {
+		r.Pomodoro.StoppedAt = &now
+	}
+	r.UpdatedAt = now
+}
+
+// member returns the index of userID in Members, or -1.
+func (r *Room) member(userID string) int {
+	for i, m := range r.Members {
+		if m.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// online reports whether m holds an event stream whose heartbeat is fresh.
+func (m Member) online(now time.Time) bool {
+	return m.Connected && now.Sub(m.LastSeen) < presenceTTL
+}
+
+// dropStale marks members whose stream stopped heartbeating as disconnected
+// and ends their Pomodoro presence at the last heartbeat. It reports whether
+// anything changed.
+func (r *Room) dropStale(now time.Time) bool {
+	changed := false
+	for i, m := range r.Members {
+		if !m.Connected || m.online(now) {
+			continue
+		}
+		r.Members[i].Connected = false
+		if r.Pomodoro != nil {
+			r.Pomodoro.leave(m.UserID, m.LastSeen)
+		}
+		changed = true
+	}
+	return changed
+}
+
+// indexMembers refreshes MemberIDs after Members changed.
+func (r *Room) indexMembers() {
+	r.MemberIDs = make([]string, len(r.Members))
+	for i, m := range r.Members {
+		r.MemberIDs[i] = m.UserID
+	}
+}
+
+// MemberView is a member as broadcast to the room.
+type MemberView struct {
+	UserID           string     `json:"user_id"`
+	IsHost           bool       `json:"is_host,omitempty"`
+	Online           bool       `json:"online"`
+	State            TimerState `json:"state"`
+	EndsAt           *time.Time `json:"ends_at,omitempty"`
+	RemainingSeconds int        `json:"remaining_seconds"`
+}
+
+// PomodoroView is the shared session as broadcast to the room.
+type PomodoroView struct {
+	FocusSeconds     int        `json:"focus_seconds"`
+	BreakSeconds     int        `json:"break_seconds"`
+	Cycles           int        `json:"cycles"`
+	Cycle            int        `json:"cycle"` // 1-based
+	Phase            TimerState `json:"phase"`
+	StartedAt        time.Time  `json:"started_at"`
+	PhaseEndsAt      time.Time  `json:"phase_ends_at"`
+	RemainingSeconds int        `json:"remaining_seconds"`
+}
+
+// View is the room state sent to clients. Remaining times are computed at
+// ServerTime; clients count down from the *_ends_at timestamps.
+type View struct {
+	ID          string          `json:"id"` // also the join code
+	Name        string          `json:"name"`
+	Category    string          `json:"category"`
+	HostID      string          `json:"host_id"`
+	Status      Status          `json:"status"`
+	Members     []MemberView    `json:"members"`
+	Pomodoro    *PomodoroView   `json:"pomodoro,omitempty"`
+	LastSession *SessionSummary `json:"last_session,omitempty"`
+	ServerTime  time.Time       `json:"server_time"`
+}
+
+// CreateInput describes a new room.
+type CreateInput struct {
+	Name     string
+	Category string
+	Timezone string
+}
+
+// TimerInput sets a member's own timer.
+type TimerInput struct {
+	State            TimerState
+	RemainingSeconds int
+}
+
+// PomodoroInput configures a shared Pomodoro. Zero values use the defaults.
+type PomodoroInput struct {
+	FocusMinutes int
+	BreakMinutes int
+	Cycles       int
+}
+
+// Repository stores rooms.
+type Repository interface {
+	// Create stores a new room, or returns ErrCodeTaken if the ID is in use.
+	Create(ctx context.Context, room Room) error
+	Get(ctx context.Context, roomID string) (Room, error)
+	// Update applies fn atomically. If fn returns an error nothing is written
+	// and the room as read is returned with that error.
+	Update(ctx context.Context, roomID string, fn func(*Room) error) (Room, error)
+	// Watch sends the current room and then every change until ctx ends.
+	Watch(ctx context.Context, roomID string) (<-chan Room, error)
+	// ListActive returns up to limit active rooms (see Room.active) with IDs
+	// after the given one, in ID order, so callers can page through them.
+	ListActive(ctx context.Context, after string, limit int) ([]Room, error)
+}
+
+// Domain errors.
+var (
+	ErrRoomNotFound    = errors.New("room not found")
+	ErrRoomClosed      = errors.New("room is closed")
+	ErrRoomFull        = errors.New("room is full")
+	ErrNotHost         = errors.New("only the host can do that")
+	ErrPomodoroRunning = errors.New("a shared pomodoro is running")
+	ErrNoPomodoro      = errors.New("no shared pomodoro is running")
+	ErrCodeTaken       = errors.New("room code taken")
+	ErrInvalidInput    = errors.New("invalid input")
+)
@@ -0,0 +1,143 @@
+package room
+
+import (
+	"sort"
+	"time"
+)
+
+// end returns when the session finishes: early if stopped, otherwise after
+// the last focus phase.
+func (p *Pomodoro) end() time.Time {
+	natural := p.StartedAt.Add(p.focus()*time.Duration(p.Cycles) + p.rest()*time.Duration(p.Cycles-1))
+	if p.StoppedAt != nil && p.StoppedAt.Before(natural) {
+		return *p.StoppedAt
+	}
+	return natural
+}
+
+func (p *Pomodoro) finished(now time.Time) bool {
+	return !now.Before(p.end())
+}
+
+func (p *Pomodoro) focus() time.Duration { return time.Duration(p.FocusSeconds) * time.Second }
+func (p *Pomodoro) rest() time.Duration  { return time.Duration(p.BreakSeconds) * time.Second }
+
+// phaseAt returns the 1-based cycle, the phase and when that phase ends.
+// ok is false once the session has finished.
+func (p *Pomodoro) phaseAt(now time.Time) (cycle int, phase TimerState, endsAt time.Time, ok bool) {
+	if p.finished(now) {
+		return 0, TimerIdle, time.Time{}, false
+	}
+	elapsed := now.Sub(p.StartedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	length := p.focus() + p.rest()
+	k := int(elapsed / length)
+	cycleStart := p.StartedAt.Add(length * time.Duration(k))
+	if elapsed-length*time.Duration(k) < p.focus() {
+		return k + 1, TimerFocusing, cycleStart.Add(p.focus()), true
+	}
+	return k + 1, TimerBreak, cycleStart.Add(length), true
+}
+
+// join opens a presence interval for userID at now.
+func (p *Pomodoro) join(userID, tz string, now time.Time) {
+	if p.Participants == nil {
+		p.Participants = map[string]Participant{}
+	}
+	part := p.Participants[userID]
+	part.Timezone = tz
+	if n := len(part.Intervals); n == 0 || part.Intervals[n-1].End != nil {
+		part.Intervals = append(part.Intervals, Interval{Start: now})
+	}
+	p.Participants[userID] = part
+}
+
+// leave closes userID's open presence interval at now, or at its start when
+// now is earlier (a heartbeat from before the interval opened).
+func (p *Pomodoro) leave(userID string, now time.Time) {
+	part, ok := p.Participants[userID]
+	if !ok {
+		return
+	}
+	if n := len(part.Intervals); n > 0 && part.Intervals[n-1].End == nil {
+		end := maxTime(now, part.Intervals[n-1].Start)
+		part.Intervals[n-1].End = &end
+	}
+	p.Participants[userID] = part
+}
+
+// present reports whether userID is in the session right now.
+func (p *Pomodoro) present(userID string) bool {
+	part, ok := p.Participants[userID]
+	n := len(part.Intervals)
+	return ok && n > 0 && part.Intervals[n-1].End == nil
+}
+
+// summarize credits each participant with the focus time they were connected
+// for. A cycle counts when the participant attended any of its focus phase.
+// Participants with less than minEntrySeconds of focus get no result.
+func (p *Pomodoro) summarize() SessionSummary {
+	end := p.end()
+	summary := SessionSummary{Seq: p.Seq, StartedAt: p.StartedAt, EndedAt: end, Results: []SessionResult{}}
+	length := p.focus() + p.rest()
+	for userID, part := range p.Participants {
+		result := SessionResult{UserID: userID, Timezone: part.Timezone}
+		var focused time.Duration
+		for k := 0; k < p.Cycles; k++ {
+			phaseStart := p.StartedAt.Add(length * time.Duration(k))
+			phaseEnd := minTime(phaseStart.Add(p.focus()), end)
+			var attended time.Duration
+			for _, iv := range part.Intervals {
+				from, to := clip(iv, p.StartedAt, end)
+				if overlap := minTime(to, phaseEnd).Sub(maxTime(from, phaseStart)); overlap > 0 {
+					attended += overlap
+				}
+			}
+			if attended > 0 {
+				result.Cycles++
+				focused += attended
+			}
+		}
+		result.FocusSeconds = int(focused / time.Second)
+		if result.FocusSeconds < minEntrySeconds {
+			continue
+		}
+		for i, iv := range part.Intervals {
+			from, to := clip(iv, p.StartedAt, end)
+			if i == 0 || from.Before(result.StartTime) {
+				result.StartTime = from
+			}
+			if to.After(result.EndTime) {
+				result.EndTime = to
+			}
+		}
+		summary.Results = append(summary.Results, result)
+	}
+	sort.Slice(summary.Results, func(i, j int) bool { return summary.Results[i].UserID < summary.Results[j].UserID })
+	return summary
+}
+
+// clip bounds an interval to [start, end]; open intervals run to end.
+func clip(iv Interval, start, end time.Time) (time.Time, time.Time) {
+	to := end
+	if iv.End != nil {
+		to = minTime(*iv.End, end)
+	}
+	return maxTime(iv.Start, start), to
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
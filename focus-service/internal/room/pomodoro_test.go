@@ -0,0 +1,94 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+var sessionStart = time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)
+
+func at(minutes float64) time.Time {
+	return sessionStart.Add(time.Duration(minutes * float64(time.Minute)))
+}
+
+func testPomodoro(cycles int) *Pomodoro {
+	return &Pomodoro{Seq: 1, FocusSeconds: 25 * 60, BreakSeconds: 5 * 60, Cycles: cycles, StartedAt: sessionStart}
+}
+
+func TestPomodoroPhaseAt(t *testing.T) {
+	p := testPomodoro(2)
+	tests := []struct {
+		now    time.Time
+		cycle  int
+		phase  TimerState
+		endsAt time.Time
+		ok     bool
+	}{
+		{now: at(0), cycle: 1, phase: TimerFocusing, endsAt: at(25), ok: true},
+		{now: at(24.5), cycle: 1, phase: TimerFocusing, endsAt: at(25), ok: true},
+		{now: at(25), cycle: 1, phase: TimerBreak, endsAt: at(30), ok: true},
+		{now: at(30), cycle: 2, phase: TimerFocusing, endsAt: at(55), ok: true},
+		// No break after the last focus phase.
+		{now: at(55), ok: false},
+	}
+	for _, tt := range tests {
+		cycle, phase, endsAt, ok := p.phaseAt(tt.now)
+		if ok != tt.ok || cycle != tt.cycle || (ok && (phase != tt.phase || !endsAt.Equal(tt.endsAt))) {
+			t.Errorf("phaseAt(+%v) = %d %s %v %v, want %d %s %v %v",
+				tt.now.Sub(sessionStart), cycle, phase, endsAt, ok, tt.cycle, tt.phase, tt.endsAt, tt.ok)
+		}
+	}
+	if end := p.end(); !end.Equal(at(55)) {
+		t.Errorf("end = %v, want +55m", end.Sub(sessionStart))
+	}
+
+	stopped := at(40)
+	p.StoppedAt = &stopped
+	if !p.finished(at(40)) || p.finished(at(39)) {
+		t.Errorf("a Pomodoro stopped at +40m should finish exactly then")
+	}
+}
+
+func TestPomodoroSummarize(t *testing.T) {
+	p := testPomodoro(2)
+	p.join("full", "Asia/Jakarta", at(0))
+	// Joins late, steps out during the first break and comes back.
+	p.join("late", "UTC", at(20))
+	p.leave("late", at(27))
+	p.join("late", "UTC", at(35))
+	// Only around for the break.
+	p.join("break", "UTC", at(26))
+	p.leave("break", at(29))
+	// Under a minute of focus.
+	p.join("brief", "UTC", at(0))
+	p.leave("brief", at(0.5))
+
+	summary := p.summarize()
+	if len(summary.Results) != 2 {
+		t.Fatalf("results = %+v, want full and late only", summary.Results)
+	}
+	full, late := summary.Results[0], summary.Results[1]
+	if full.UserID != "full" || full.FocusSeconds != 50*60 || full.Cycles != 2 || full.Timezone != "Asia/Jakarta" {
+		t.Errorf("full = %+v, want 50 minutes over 2 cycles", full)
+	}
+	if !full.StartTime.Equal(at(0)) || !full.EndTime.Equal(at(55)) {
+		t.Errorf("full ran %v–%v, want the whole session", full.StartTime, full.EndTime)
+	}
+	if late.UserID != "late" || late.FocusSeconds != 25*60 || late.Cycles != 2 {
+		t.Errorf("late = %+v, want 5 + 20 minutes over 2 cycles", late)
+	}
+	if !late.StartTime.Equal(at(20)) || !late.EndTime.Equal(at(55)) {
+		t.Errorf("late ran %v–%v, want +20m to the end", late.StartTime, late.EndTime)
+	}
+}
+
+func TestPomodoroLeaveBeforeIntervalStart(t *testing.T) {
+	p := testPomodoro(1)
+	p.join("u", "UTC", at(5))
+	// A heartbeat from before the interval opened must not end it earlier.
+	p.leave("u", at(1))
+	iv := p.Participants["u"].Intervals[0]
+	if iv.End == nil || !iv.End.Equal(at(5)) {
+		t.Fatalf("interval = %+v, want it closed at its start", iv)
+	}
+}
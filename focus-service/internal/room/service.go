@@ -0,0 +1,671 @@
+package room
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/focusnest/focus-service/internal/productivity"
+)
+
+const (
+	maxMembers    = 10
+	maxNameLength = 60
+	defaultName   = "Co-focus room"
+	// codeAlphabet omits look-alike characters (0/O, 1/I).
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLength   = 6
+
+	defaultFocusMinutes = 25
+	defaultBreakMinutes = 5
+	defaultCycles       = 4
+
+	// minEntrySeconds is the least focus time that produces an entry.
+	minEntrySeconds = 60
+
+	// heartbeatInterval refreshes LastSeen while a stream is open; members
+	// not seen for presenceTTL show as offline even if a stream died uncleanly.
+	heartbeatInterval = 30 * time.Second
+	presenceTTL       = 90 * time.Second
+
+	// sweepInterval is how often RunSweeper settles finished Pomodoros and
+	// disconnects members whose streams stopped heartbeating.
+	sweepInterval = 30 * time.Second
+	// sweepPageSize bounds each ListActive read of a sweep.
+	sweepPageSize = 100
+	// roomIdleTTL closes open rooms nobody has been connected to for that long.
+	roomIdleTTL = 15 * time.Minute
+)
+
+// errUnchanged makes an Update callback skip the write.
+var errUnchanged = errors.New("unchanged")
+
+// EntryRecorder creates productivity entries; *productivity.Service satisfies it.
+type EntryRecorder interface {
+	Create(ctx context.Context, input productivity.CreateInput) (productivity.Entry, error)
+}
+
+// Service orchestrates co-focus rooms.
+type Service struct {
+	repo    Repository
+	entries EntryRecorder
+	clock   productivity.Clock
+	logger  *slog.Logger
+}
+
+// NewService constructs a Service instance with the provided collaborators.
+func NewService(repo Repository, entries EntryRecorder, clock productivity.Clock, logger *slog.Logger) (*Service, error) {
+	if repo == nil {
+		return nil, errors.New("repo is required")
+	}
+	if entries == nil {
+		return nil, errors.New("entry recorder is required")
+	}
+	if clock == nil {
+		return nil, errors.New("clock is required")
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{repo: repo, entries: entries, clock: clock, logger: logger}, nil
+}
+
+// Create opens a room hosted by userID.
+func (s *Service) Create(ctx context.Context, userID string, input CreateInput) (View, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = defaultName
+	}
+	if len([]rune(name)) > maxNameLength {
+		return View{}, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidInput, maxNameLength)
+	}
+	category := strings.TrimSpace(input.Category)
+	if category == "" {
+		category = "Study"
+	}
+	if !validCategory(category) {
+		return View{}, fmt.Errorf("%w: category must be one of: %s", ErrInvalidInput, strings.Join(productivity.ValidCategories, ", "))
+	}
+
+	now := s.clock.Now().UTC()
+	room := Room{
+		Name:     name,
+		Category: category,
+		HostID:   userID,
+		Status:   StatusOpen,
+		Members: []Member{{
+			UserID:   userID,
+			Timezone: input.Timezone,
+			JoinedAt: now,
+			LastSeen: now,
+			Timer:    Timer{State: TimerIdle},
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	room.indexMembers()
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := newCode()
+		if err != nil {
+			return View{}, err
+		}
+		room.ID = code
+		err = s.repo.Create(ctx, room)
+		if errors.Is(err, ErrCodeTaken) {
+			continue
+		}
+		if err != nil {
+			return View{}, fmt.Errorf("create room: %w", err)
+		}
+		return s.view(room, now), nil
+	}
+	return View{}, errors.New("could not allocate a room code")
+}
+
+// Join adds userID to the room. Joining a room you are in refreshes your
+// timezone and is otherwise a no-op.
+func (s *Service) Join(ctx context.Context, userID, roomID, tz string) (View, error) {
+	roomID = normalizeCode(roomID)
+	now := s.clock.Now().UTC()
+	room, err := s.repo.Update(ctx, roomID, func(r *Room) error {
+		if r.Status == StatusClosed {
+			return ErrRoomClosed
+		}
+		i := r.member(userID)
+		if i >= 0 {
+			r.Members[i].Timezone = tz
+		} else {
+			if len(r.Members) >= maxMembers {
+				return ErrRoomFull
+			}
+			r.Members = append(r.Members, Member{UserID: userID, Timezone: tz, JoinedAt: now, LastSeen: now, Timer: Timer{State: TimerIdle}})
+			r.indexMembers()
+		}
+		// A new member joins the Pomodoro once their stream connects.
+		if i >= 0 && r.Members[i].online(now) && r.Pomodoro != nil && !r.Pomodoro.finished(now) {
+			r.Pomodoro.join(userID, tz, now)
+		}
+		r.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return View{}, err
+	}
+	return s.view(room, now), nil
+}
+
+// Leave removes userID from the room. The host role passes to the longest
+// present member, and the room closes when nobody is left, which also ends a
+// running Pomodoro.
+func (s *Service) Leave(ctx context.Context, userID, roomID string) error {
+	roomID = normalizeCode(roomID)
+	now := s.clock.Now().UTC()
+	_, err := s.repo.Update(ctx, roomID, func(r *Room) error {
+		i := r.member(userID)
+		if i < 0 {
+			return ErrRoomNotFound
+		}
+		r.Members = append(r.Members[:i], r.Members[i+1:]...)
+		r.indexMembers()
+		if r.Pomodoro != nil {
+			r.Pomodoro.leave(userID, now)
+		}
+		r.UpdatedAt = now
+		if len(r.Members) == 0 {
+			r.close(now)
+			r.HostID = ""
+		} else if r.HostID == userID {
+			r.HostID = r.Members[0].UserID
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.settle(ctx, roomID)
+}
+
+// Get returns the room as seen by a member.
+func (s *Service) Get(ctx context.Context, userID, roomID string) (View, error) {
+	roomID = normalizeCode(roomID)
+	if err := s.settle(ctx, roomID); err != nil {
+		return View{}, err
+	}
+	room, err := s.repo.Get(ctx, roomID)
+	if err != nil {
+		return View{}, err
+	}
+	if room.member(userID) < 0 {
+		return View{}, ErrRoomNotFound
+	}
+	return s.view(room, s.clock.Now().UTC()), nil
+}
+
+// SetTimer updates userID's own timer. It is rejected while a shared
+// Pomodoro runs, since members then follow the shared phases.
+func (s *Service) SetTimer(ctx context.Context, userID, roomID string, input TimerInput) (View, error) {
+	roomID = normalizeCode(roomID)
+	now := s.clock.Now().UTC()
+	timer := Timer{State: input.State}
+	switch input.State {
+	case TimerIdle:
+	case TimerFocusing, TimerBreak:
+		if input.RemainingSeconds <= 0 || input.RemainingSeconds > 24*60*60 {
+			return View{}, fmt.Errorf("%w: remaining_seconds must be between 1 and 86400", ErrInvalidInput)
+		}
+		endsAt := now.Add(time.Duration(input.RemainingSeconds) * time.Second)
+		timer.EndsAt = &endsAt
+	default:
+		return View{}, fmt.Errorf("%w: state must be idle, focusing or break", ErrInvalidInput)
+	}
+
+	room, err := s.repo.Update(ctx, roomID, func(r *Room) error {
+		i := r.member(userID)
+		if i < 0 {
+			return ErrRoomNotFound
+		}
+		if r.Pomodoro != nil && !r.Pomodoro.finished(now) {
+			return ErrPomodoroRunning
+		}
+		r.Members[i].Timer = timer
+		r.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return View{}, err
+	}
+	return s.view(room, now), nil
+}
+
+// StartPomodoro starts a shared Pomodoro for everyone in the room. Only the
+// host can start one.
+func (s *Service) StartPomodoro(ctx context.Context, userID, roomID string, input PomodoroInput) (View, error) {
+	roomID = normalizeCode(roomID)
+	focus, rest, cycles := input.FocusMinutes, input.BreakMinutes, input.Cycles
+	if focus == 0 {
+		focus = defaultFocusMinutes
+	}
+	if rest == 0 {
+		rest = defaultBreakMinutes
+	}
+	if cycles == 0 {
+		cycles = defaultCycles
+	}
+	if focus < 1 || focus > 120 || rest < 1 || rest > 60 || cycles < 1 || cycles > 12 {
+		return View{}, fmt.Errorf("%w: focus_minutes must be 1-120, break_minutes 1-60 and cycles 1-12", ErrInvalidInput)
+	}
+	if err := s.settle(ctx, roomID); err != nil {
+		return View{}, err
+	}
+
+	now := s.clock.Now().UTC()
+	room, err := s.repo.Update(ctx, roomID, func(r *Room) error {
+		if r.member(userID) < 0 {
+			return ErrRoomNotFound
+		}
+		if r.Status == StatusClosed {
+			return ErrRoomClosed
+		}
+		if r.HostID != userID {
+			return ErrNotHost
+		}
+		if r.Pomodoro != nil {
+			return ErrPomodoroRunning
+		}
+		r.Sessions++
+		p := &Pomodoro{
+			Seq:          r.Sessions,
+			FocusSeconds: focus * 60,
+			BreakSeconds: rest * 60,
+			Cycles:       cycles,
+			StartedAt:    now,
+		}
+		for i, m := range r.Members {
+			if m.online(now) {
+				p.join(m.UserID, m.Timezone, now)
+			}
+			r.Members[i].Timer = Timer{State: TimerIdle}
+		}
+		r.Pomodoro = p
+		r.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return View{}, err
+	}
+	return s.view(room, now), nil
+}
+
+// StopPomodoro ends the shared Pomodoro early; time focused so far is still
+// recorded. Only the host can stop it.
+func (s *Service) StopPomodoro(ctx context.Context, userID, roomID string) (View, error) {
+	roomID = normalizeCode(roomID)
+	now := s.clock.Now().UTC()
+	_, err := s.repo.Update(ctx, roomID, func(r *Room) error {
+		if r.member(userID) < 0 {
+			return ErrRoomNotFound
+		}
+		if r.HostID != userID {
+			return ErrNotHost
+		}
+		if r.Pomodoro == nil || r.Pomodoro.finished(now) {
+			return ErrNoPomodoro
+		}
+		r.Pomodoro.StoppedAt = &now
+		r.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return View{}, err
+	}
+	if err := s.settle(ctx, roomID); err != nil {
+		return View{}, err
+	}
+	return s.Get(ctx, userID, roomID)
+}
+
+// Stream sends the room to userID on every change and at every Pomodoro
+// phase boundary until ctx ends, the user leaves or the room closes. While it runs the member
+// shows as online.
+func (s *Service) Stream(ctx context.Context, userID, roomID string) (<-chan View, error) {
+	roomID = normalizeCode(roomID)
+	room, err := s.repo.Get(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.member(userID) < 0 {
+		return nil, ErrRoomNotFound
+	}
+	if room.Status == StatusClosed {
+		return nil, ErrRoomClosed
+	}
+	updates, err := s.repo.Watch(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.touch(ctx, userID, roomID, true); err != nil {
+		return nil, err
+	}
+
+	out := make(chan View)
+	go func() {
+		defer close(out)
+		defer func() {
+			// The request context is done; use a fresh one to mark the member offline.
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := s.touch(ctx, userID, roomID, false); err != nil && !errors.Is(err, ErrRoomNotFound) {
+				s.logger.Warn("room disconnect failed", slog.String("room_id", roomID), slog.Any("error", err))
+			}
+		}()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		boundary := time.NewTimer(time.Hour)
+		boundary.Stop()
+		defer boundary.Stop()
+
+		var current Room
+		send := func() bool {
+			now := s.clock.Now().UTC()
+			if p := current.Pomodoro; p != nil {
+				if p.finished(now) {
+					if err := s.settle(ctx, roomID); err != nil && ctx.Err() == nil {
+						s.logger.Error("room settle failed", slog.String("room_id", roomID), slog.Any("error", err))
+					}
+				} else if _, _, endsAt, ok := p.phaseAt(now); ok {
+					boundary.Reset(endsAt.Sub(now))
+				}
+			}
+			select {
+			case out <- s.view(current, now):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case room, ok := <-updates:
+				if !ok {
+					return
+				}
+				current = room
+				if room.member(userID) < 0 {
+					return
+				}
+				if !send() || room.Status == StatusClosed {
+					return
+				}
+			case <-boundary.C:
+				if !send() {
+					return
+				}
+			case <-heartbeat.C:
+				if err := s.touch(ctx, userID, roomID, true); err != nil {
+					if ctx.Err() == nil {
+						s.logger.Warn("room heartbeat failed", slog.String("room_id", roomID), slog.Any("error", err))
+					}
+					if errors.Is(err, ErrRoomNotFound) {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// touch records a member's presence. A running Pomodoro only credits
+// members while they are connected, so connecting opens a presence interval
+// and disconnecting closes it. A stream that died without disconnecting is
+// closed at its last heartbeat first.
+func (s *Service) touch(ctx context.Context, userID, roomID string, connected bool) error {
+	now := s.clock.Now().UTC()
+	_, err := s.repo.Update(ctx, roomID, func(r *Room) error {
+		i := r.member(userID)
+		if i < 0 {
+			return ErrRoomNotFound
+		}
+		r.dropStale(now)
+		m := &r.Members[i]
+		m.Connected = connected
+		m.LastSeen = now
+		if p := r.Pomodoro; p != nil && !p.finished(now) {
+			if connected {
+				p.join(userID, m.Timezone, now)
+			} else {
+				p.leave(userID, now)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// settle finishes a Pomodoro that has ended and records an entry for every
+// participant. Entry IDs are derived from the room, session and user, so
+// concurrent or retried settles never create duplicates.
+func (s *Service) settle(ctx context.Context, roomID string) error {
+	now := s.clock.Now().UTC()
+	room, err := s.repo.Update(ctx, roomID, func(r *Room) error {
+		if r.Pomodoro == nil || !r.Pomodoro.finished(now) {
+			return errUnchanged
+		}
+		r.dropStale(now)
+		summary := r.Pomodoro.summarize()
+		r.LastSession = &summary
+		r.Pomodoro = nil
+		r.UpdatedAt = now
+		return nil
+	})
+	if err != nil && !errors.Is(err, errUnchanged) {
+		return err
+	}
+	last := room.LastSession
+	if last == nil || last.Recorded {
+		return nil
+	}
+
+	for _, result := range last.Results {
+		_, err := s.entries.Create(ctx, productivity.CreateInput{
+			ID:           fmt.Sprintf("room-%s-%d-%s", room.ID, last.Seq, result.UserID),
+			UserID:       result.UserID,
+			ActivityName: room.Name,
+			TimeElapsed:  result.FocusSeconds,
+			NumCycle:     result.Cycles,
+			TimeMode:     "Pomodoro",
+			Category:     room.Category,
+			StartTime:    result.StartTime,
+			EndTime:      result.EndTime,
+			Timezone:     result.Timezone,
+		})
+		if err != nil && !errors.Is(err, productivity.ErrConflict) {
+			return fmt.Errorf("record room entry: %w", err)
+		}
+	}
+
+	_, err = s.repo.Update(ctx, roomID, func(r *Room) error {
+		if r.LastSession == nil || r.LastSession.Seq != last.Seq || r.LastSession.Recorded {
+			return errUnchanged
+		}
+		r.LastSession.Recorded = true
+		return nil
+	})
+	if err != nil && !errors.Is(err, errUnchanged) {
+		return err
+	}
+	return nil
+}
+
+// Sweep settles every finished Pomodoro, ends the presence of members whose
+// stream stopped heartbeating and closes rooms idle for roomIdleTTL, so
+// sessions are recorded even when no member is left to open the room. It
+// reads active rooms a page at a time. Concurrent sweeps are safe but
+// redundant; deployments run it from one scheduled caller.
+func (s *Service) Sweep(ctx context.Context) error {
+	var (
+		errs  []error
+		after string
+	)
+	for {
+		rooms, err := s.repo.ListActive(ctx, after, sweepPageSize)
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("list active rooms: %w", err))...)
+		}
+		for _, room := range rooms {
+			if err := s.sweepRoom(ctx, room); err != nil {
+				errs = append(errs, fmt.Errorf("room %s: %w", room.ID, err))
+			}
+		}
+		if len(rooms) < sweepPageSize {
+			return errors.Join(errs...)
+		}
+		after = rooms[len(rooms)-1].ID
+	}
+}
+
+// sweepRoom is Sweep for one room as listed.
+func (s *Service) sweepRoom(ctx context.Context, room Room) error {
+	now := s.clock.Now().UTC()
+	stale := func(r *Room) bool {
+		changed := r.dropStale(now)
+		if r.Status == StatusOpen && r.idle(now) {
+			r.close(now)
+			changed = true
+		}
+		return changed
+	}
+	if stale(&room) {
+		updated, err := s.repo.Update(ctx, room.ID, func(r *Room) error {
+			if !stale(r) {
+				return errUnchanged
+			}
+			return nil
+		})
+		if errors.Is(err, ErrRoomNotFound) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, errUnchanged) {
+			return err
+		}
+		room = updated
+	}
+	if room.Pomodoro != nil || (room.LastSession != nil && !room.LastSession.Recorded) {
+		if err := s.settle(ctx, room.ID); err != nil && !errors.Is(err, ErrRoomNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunSweeper calls Sweep every sweepInterval until ctx ends.
+func (s *Service) RunSweeper(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("room sweep failed", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// view projects a room for clients at now.
+func (s *Service) view(room Room, now time.Time) View {
+	v := View{
+		ID:          room.ID,
+		Name:        room.Name,
+		Category:    room.Category,
+		HostID:      room.HostID,
+		Status:      room.Status,
+		Members:     make([]MemberView, 0, len(room.Members)),
+		LastSession: room.LastSession,
+		ServerTime:  now,
+	}
+
+	var (
+		shared      bool
+		phase       TimerState
+		phaseEndsAt time.Time
+	)
+	if p := room.Pomodoro; p != nil {
+		if cycle, ph, endsAt, ok := p.phaseAt(now); ok {
+			shared, phase, phaseEndsAt = true, ph, endsAt
+			v.Pomodoro = &PomodoroView{
+				FocusSeconds:     p.FocusSeconds,
+				BreakSeconds:     p.BreakSeconds,
+				Cycles:           p.Cycles,
+				Cycle:            cycle,
+				Phase:            ph,
+				StartedAt:        p.StartedAt,
+				PhaseEndsAt:      endsAt,
+				RemainingSeconds: remaining(endsAt, now),
+			}
+		}
+	}
+
+	for _, m := range room.Members {
+		mv := MemberView{
+			UserID: m.UserID,
+			IsHost: m.UserID == room.HostID,
+			Online: m.online(now),
+			State:  TimerIdle,
+		}
+		switch {
+		case shared && room.Pomodoro.present(m.UserID):
+			endsAt := phaseEndsAt
+			mv.State, mv.EndsAt = phase, &endsAt
+		case m.Timer.State != TimerIdle && m.Timer.EndsAt != nil && now.Before(*m.Timer.EndsAt):
+			mv.State, mv.EndsAt = m.Timer.State, m.Timer.EndsAt
+		}
+		if mv.EndsAt != nil {
+			mv.RemainingSeconds = remaining(*mv.EndsAt, now)
+		}
+		v.Members = append(v.Members, mv)
+	}
+	return v
+}
+
+func remaining(endsAt, now time.Time) int {
+	if d := endsAt.Sub(now); d > 0 {
+		return int((d + time.Second - 1) / time.Second)
+	}
+	return 0
+}
+
+func validCategory(category string) bool {
+	for _, c := range productivity.ValidCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func newCode() (string, error) {
+	buf := make([]byte, codeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// normalizeCode accepts codes typed in lower case or with surrounding spaces.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,293 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/focusnest/focus-service/internal/productivity"
+)
+
+// fakeClock is a productivity.Clock moved by hand.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// fakeRecorder stores entries by ID like the productivity repository, so a
+// repeated ID fails with ErrConflict.
+type fakeRecorder struct {
+	mu      sync.Mutex
+	entries map[string]productivity.CreateInput
+	calls   int
+	failFor string // user whose entries fail to save
+}
+
+func (r *fakeRecorder) Create(_ context.Context, input productivity.CreateInput) (productivity.Entry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if input.UserID == r.failFor {
+		return productivity.Entry{}, errors.New("unavailable")
+	}
+	if _, ok := r.entries[input.ID]; ok {
+		return productivity.Entry{}, productivity.ErrConflict
+	}
+	r.entries[input.ID] = input
+	return productivity.Entry{ID: input.ID}, nil
+}
+
+func newTestService(t *testing.T) (*Service, Repository, *fakeClock, *fakeRecorder) {
+	t.Helper()
+	repo := NewMemoryRepository()
+	clock := &fakeClock{now: sessionStart}
+	recorder := &fakeRecorder{entries: map[string]productivity.CreateInput{}}
+	svc, err := NewService(repo, recorder, clock, nil)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return svc, repo, clock, recorder
+}
+
+// heartbeat advances the clock a minute at a time up to until, refreshing
+// the presence of online users on the way like open streams do.
+func heartbeat(t *testing.T, svc *Service, clock *fakeClock, roomID string, until time.Time, online ...string) {
+	t.Helper()
+	for now := clock.Now(); now.Before(until); {
+		now = minTime(now.Add(time.Minute), until)
+		clock.set(now)
+		for _, userID := range online {
+			if err := svc.touch(context.Background(), userID, roomID, true); err != nil {
+				t.Fatalf("touch %s: %v", userID, err)
+			}
+		}
+	}
+}
+
+func TestRoomCreditsConnectedTime(t *testing.T) {
+	svc, repo, clock, recorder := newTestService(t)
+	ctx := context.Background()
+
+	room, err := svc.Create(ctx, "host", CreateInput{Name: "Finals", Timezone: "Asia/Jakarta"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, userID := range []string{"member", "ghost"} {
+		if _, err := svc.Join(ctx, userID, room.ID, "UTC"); err != nil {
+			t.Fatalf("Join %s: %v", userID, err)
+		}
+	}
+	for _, userID := range []string{"host", "ghost"} {
+		if err := svc.touch(ctx, userID, room.ID, true); err != nil {
+			t.Fatalf("connect %s: %v", userID, err)
+		}
+	}
+	if _, err := svc.StartPomodoro(ctx, "host", room.ID, PomodoroInput{Cycles: 2}); err != nil {
+		t.Fatalf("StartPomodoro: %v", err)
+	}
+
+	// ghost's stream dies without disconnecting; member connects at +5m and
+	// disconnects at +15m; the host leaves at +40m.
+	heartbeat(t, svc, clock, room.ID, at(5), "host")
+	if err := svc.touch(ctx, "member", room.ID, true); err != nil {
+		t.Fatalf("connect member: %v", err)
+	}
+	heartbeat(t, svc, clock, room.ID, at(15), "host", "member")
+	if err := svc.touch(ctx, "member", room.ID, false); err != nil {
+		t.Fatalf("disconnect member: %v", err)
+	}
+	heartbeat(t, svc, clock, room.ID, at(40), "host")
+	if err := svc.Leave(ctx, "host", room.ID); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+
+	clock.set(at(60))
+	if err := svc.Sweep(ctx); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	stored, _ := repo.Get(ctx, room.ID)
+	if stored.HostID != "member" || stored.Pomodoro != nil || stored.LastSession == nil || !stored.LastSession.Recorded {
+		t.Fatalf("room after sweep = %+v, want member hosting and the session recorded", stored)
+	}
+	want := map[string]struct{ seconds, cycles int }{
+		"host":   {35 * 60, 2},
+		"member": {10 * 60, 1},
+	}
+	if len(recorder.entries) != len(want) {
+		t.Fatalf("entries = %+v, want host and member only", recorder.entries)
+	}
+	for userID, w := range want {
+		entry, ok := recorder.entries["room-"+room.ID+"-1-"+userID]
+		if !ok || entry.TimeElapsed != w.seconds || entry.NumCycle != w.cycles {
+			t.Errorf("%s entry = %+v, want %ds over %d cycles", userID, entry, w.seconds, w.cycles)
+		}
+	}
+}
+
+func TestSweepClosesIdleRooms(t *testing.T) {
+	svc, repo, clock, _ := newTestService(t)
+	ctx := context.Background()
+
+	live, _ := svc.Create(ctx, "a", CreateInput{})
+	idle, _ := svc.Create(ctx, "b", CreateInput{})
+	if err := svc.touch(ctx, "a", live.ID, true); err != nil {
+		t.Fatalf("connect a: %v", err)
+	}
+
+	heartbeat(t, svc, clock, live.ID, at(10), "a")
+	if err := svc.Sweep(ctx); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if stored, _ := repo.Get(ctx, idle.ID); stored.Status != StatusOpen {
+		t.Fatalf("idle room closed after 10 minutes")
+	}
+
+	heartbeat(t, svc, clock, live.ID, at(20), "a")
+	if err := svc.Sweep(ctx); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if stored, _ := repo.Get(ctx, live.ID); stored.Status != StatusOpen {
+		t.Fatalf("room with a connected member closed")
+	}
+	stored, _ := repo.Get(ctx, idle.ID)
+	if stored.Status != StatusClosed || stored.Active {
+		t.Fatalf("idle room = %+v, want closed and no longer swept", stored)
+	}
+	if _, err := svc.Stream(ctx, "b", idle.ID); !errors.Is(err, ErrRoomClosed) {
+		t.Fatalf("Stream on a closed room error = %v, want ErrRoomClosed", err)
+	}
+	if rooms, _ := repo.ListActive(ctx, "", sweepPageSize); len(rooms) != 1 || rooms[0].ID != live.ID {
+		t.Fatalf("active rooms = %+v, want only the live room", rooms)
+	}
+}
+
+func TestSettleIsIdempotent(t *testing.T) {
+	svc, repo, clock, recorder := newTestService(t)
+	ctx := context.Background()
+
+	room, _ := svc.Create(ctx, "a", CreateInput{})
+	if _, err := svc.Join(ctx, "b", room.ID, "UTC"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	for _, userID := range []string{"a", "b"} {
+		_ = svc.touch(ctx, userID, room.ID, true)
+	}
+	if _, err := svc.StartPomodoro(ctx, "a", room.ID, PomodoroInput{Cycles: 1}); err != nil {
+		t.Fatalf("StartPomodoro: %v", err)
+	}
+	heartbeat(t, svc, clock, room.ID, at(26), "a", "b")
+
+	// b's entry fails: a's is saved but the session stays unrecorded.
+	recorder.failFor = "b"
+	if err := svc.settle(ctx, room.ID); err == nil {
+		t.Fatal("settle succeeded although an entry failed")
+	}
+	if stored, _ := repo.Get(ctx, room.ID); stored.LastSession == nil || stored.LastSession.Recorded {
+		t.Fatalf("last session = %+v, want it summarized but unrecorded", stored.LastSession)
+	}
+
+	// The retry hits ErrConflict for a and still records b.
+	recorder.failFor = ""
+	if err := svc.Sweep(ctx); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if len(recorder.entries) != 2 {
+		t.Fatalf("entries = %+v, want one per participant", recorder.entries)
+	}
+	calls := recorder.calls
+	for i := 0; i < 2; i++ {
+		if err := svc.settle(ctx, room.ID); err != nil {
+			t.Fatalf("settle: %v", err)
+		}
+	}
+	if err := svc.Sweep(ctx); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if recorder.calls != calls || len(recorder.entries) != 2 {
+		t.Fatalf("a recorded session was settled again: %d calls, %d entries", recorder.calls-calls, len(recorder.entries))
+	}
+	if stored, _ := repo.Get(ctx, room.ID); !stored.LastSession.Recorded {
+		t.Fatalf("last session not marked recorded")
+	}
+}
+
+func TestStreamEnds(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	ctx := context.Background()
+	room, _ := svc.Create(ctx, "a", CreateInput{})
+	if _, err := svc.Join(ctx, "b", room.ID, "UTC"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	next := func(views <-chan View) (View, bool) {
+		t.Helper()
+		select {
+		case v, ok := <-views:
+			return v, ok
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream did not respond")
+			return View{}, false
+		}
+	}
+	drained := func(views <-chan View) {
+		t.Helper()
+		for {
+			if _, ok := next(views); !ok {
+				return
+			}
+		}
+	}
+
+	// Leaving ends the member's stream.
+	views, err := svc.Stream(ctx, "b", room.ID)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if v, ok := next(views); !ok || v.ID != room.ID {
+		t.Fatalf("first view = %+v, %v", v, ok)
+	}
+	if err := svc.Leave(ctx, "b", room.ID); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+	drained(views)
+
+	// Cancelling the request ends the stream and marks the member offline.
+	streamCtx, cancel := context.WithCancel(ctx)
+	views, err = svc.Stream(streamCtx, "a", room.ID)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	// The first view may predate the connect; the next change shows it.
+	for {
+		v, ok := next(views)
+		if !ok {
+			t.Fatal("stream closed before a showed online")
+		}
+		if len(v.Members) == 1 && v.Members[0].Online {
+			break
+		}
+	}
+	cancel()
+	drained(views)
+	if stored, _ := repo.Get(ctx, room.ID); stored.Members[0].Connected {
+		t.Fatal("a is still connected after the stream ended")
+	}
+
+	if _, err := svc.Stream(ctx, "b", room.ID); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("Stream for a non-member = %v, want ErrRoomNotFound", err)
+	}
+}
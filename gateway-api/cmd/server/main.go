@@ -14,6 +14,7 @@ import (
 	"github.com/focusnest/gateway-api/internal/httpapi"
 	"github.com/focusnest/gateway-api/internal/revenuecat"
 	"github.com/focusnest/shared-libs/auth"
+	"github.com/focusnest/shared-libs/sharelink"
 )
 
 func main() {
@@ -112,7 +113,17 @@ func main() {
 		logger.Warn("RESEND_API_KEY not set — feedback endpoint disabled")
 	}
 
-	router := httpapi.Router(verifier, targets, premiumChecker, feedbackHandler, logger)
+	// Optional: reject forged or expired share tokens at the edge
+	var shareSigner *sharelink.Signer
+	if cfg.ShareLinkSecret != "" {
+		shareSigner, err = sharelink.NewSigner(cfg.ShareLinkSecret)
+		if err != nil {
+			logger.Error("invalid SHARE_LINK_SECRET", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	router := httpapi.Router(verifier, targets, premiumChecker, feedbackHandler, shareSigner, logger)
 
 	addr := ":" + cfg.Port
 	logger.Info("listening", slog.String("addr", addr))
@@ -192,6 +203,9 @@ func loadConfig() (*config.Config, error) {
 		cfg.FeedbackSenderEmail = "Focuzen Feedback <feedback@contact.focuzenapp.com>"
 	}
 
+	// Share links
+	cfg.ShareLinkSecret = os.Getenv("SHARE_LINK_SECRET")
+
 	// GCP / Firestore
 	cfg.GCPProjectID = os.Getenv("GCP_PROJECT_ID")
 	cfg.FirestoreEmulatorHost = os.Getenv("FIRESTORE_EMULATOR_HOST")
@@ -28,6 +28,9 @@ type Config struct {
 	FeedbackRecipientEmail string // e.g. hello@focuzenapp.com
 	FeedbackSenderEmail    string // e.g. Focuzen Feedback <feedback@focuzenapp.com>
 
+	// Share links: optional; when set, public share tokens are verified before proxying
+	ShareLinkSecret string
+
 	// GCP / Firestore (optional – if blank, Firestore persistence is skipped)
 	GCPProjectID       string
 	FirestoreEmulatorHost string
@@ -16,6 +16,7 @@ import (
 	"github.com/focusnest/gateway-api/internal/feedback"
 	"github.com/focusnest/gateway-api/internal/revenuecat"
 	"github.com/focusnest/shared-libs/auth"
+	"github.com/focusnest/shared-libs/sharelink"
 )
 
 type Targets struct {
@@ -25,13 +26,30 @@ type Targets struct {
 	Chatbot   *url.URL
 }
 
-func Router(verifier auth.Verifier, targets Targets, premiumChecker *revenuecat.Client, feedbackHandler *feedback.Handler, logger *slog.Logger) http.Handler {
+func Router(verifier auth.Verifier, targets Targets, premiumChecker *revenuecat.Client, feedbackHandler *feedback.Handler, shareSigner *sharelink.Signer, logger *slog.Logger) http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 
+	// Room event streams (SSE) stay open until the member leaves, so this one
+	// route is mounted outside the request timeout.
+	r.Group(func(r chi.Router) {
+		r.Use(auth.Middleware(verifier))
+		r.Use(injectUserIDHeader())
+		r.Get("/v1/rooms/{code}/events", proxyHandler(targets.Activity, nil, logger).ServeHTTP)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(60 * time.Second))
+		timedRoutes(r, verifier, targets, premiumChecker, feedbackHandler, shareSigner, logger)
+	})
+
+	return r
+}
+
+// timedRoutes registers every route that runs under the request timeout.
+func timedRoutes(r chi.Router, verifier auth.Verifier, targets Targets, premiumChecker *revenuecat.Client, feedbackHandler *feedback.Handler, shareSigner *sharelink.Signer, logger *slog.Logger) {
 	// Unauthenticated health.
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -39,6 +57,20 @@ func Router(verifier auth.Verifier, targets Targets, premiumChecker *revenuecat.
 		_, _ = w.Write([]byte(`{"ok":true}`))
 	})
 
+	// Public share link snapshots. The signed token in the path is the only
+	// credential, so no Clerk auth and no caller identity is forwarded.
+	r.Group(func(r chi.Router) {
+		r.Use(stripUserIDHeader())
+		r.With(verifyShareToken(shareSigner)).Handle("/v1/public/shares/{token}", proxyHandler(targets.Analytics, nil, logger))
+	})
+
+	// Clerk webhooks carry a Svix signature instead of a session token;
+	// user-service verifies it.
+	r.Group(func(r chi.Router) {
+		r.Use(stripUserIDHeader())
+		r.Method(http.MethodPost, "/v1/webhooks/clerk", proxyHandler(targets.User, nil, logger))
+	})
+
 	// Everything else is authenticated.
 	r.Group(func(r chi.Router) {
 		r.Use(auth.Middleware(verifier))
@@ -50,6 +82,9 @@ func Router(verifier auth.Verifier, targets Targets, premiumChecker *revenuecat.
 		r.Handle("/v1/productivities", proxyHandler(targets.Activity, nil, logger))
 		r.Handle("/v1/productivities/*", proxyHandler(targets.Activity, nil, logger))
 
+		r.Handle("/v1/rooms", proxyHandler(targets.Activity, nil, logger))
+		r.Handle("/v1/rooms/*", proxyHandler(targets.Activity, nil, logger))
+
 		r.Handle("/v1/progress", proxyHandler(targets.Analytics, premiumChecker, logger))
 		r.Handle("/v1/progress/*", proxyHandler(targets.Analytics, premiumChecker, logger))
 
@@ -68,13 +103,19 @@ func Router(verifier auth.Verifier, targets Targets, premiumChecker *revenuecat.
 		r.Handle("/v1/mindfulness", proxyHandler(targets.User, nil, logger))
 		r.Handle("/v1/mindfulness/*", proxyHandler(targets.User, nil, logger))
 
+		r.Handle("/v1/friends", proxyHandler(targets.User, nil, logger))
+		r.Handle("/v1/friends/*", proxyHandler(targets.User, nil, logger))
+		r.Handle("/v1/points", proxyHandler(targets.User, nil, logger))
+		r.Handle("/v1/points/*", proxyHandler(targets.User, nil, logger))
+		r.Handle("/v1/badges", proxyHandler(targets.User, nil, logger))
+		r.Handle("/v1/badges/*", proxyHandler(targets.User, nil, logger))
+		r.Handle("/v1/profiles/*", proxyHandler(targets.User, nil, logger))
+
 		// Feedback — handled directly in the gateway (Resend + Firestore).
 		if feedbackHandler != nil {
 			r.Post("/v1/feedback", feedbackHandler.ServeHTTP)
 		}
 	})
-
-	return r
 }
 
 func injectUserIDHeader() func(http.Handler) http.Handler {
@@ -88,6 +129,35 @@ func injectUserIDHeader() func(http.Handler) http.Handler {
 	}
 }
 
+func stripUserIDHeader() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Del("X-User-ID")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verifyShareToken answers 404 for forged or expired share tokens without
+// reaching the upstream. Revocation is still checked by progress-service.
+// A nil signer skips the check.
+func verifyShareToken(signer *sharelink.Signer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if signer == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := signer.Verify(chi.URLParam(r, "token"), time.Now()); err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"share link not found"}`))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func proxyHandler(target *url.URL, premiumChecker *revenuecat.Client, logger *slog.Logger) http.Handler {
 	if target == nil {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
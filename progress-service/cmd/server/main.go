@@ -13,6 +13,8 @@ import (
 	sharedauth "github.com/focusnest/shared-libs/auth"
 	"github.com/focusnest/shared-libs/logging"
 	sharedserver "github.com/focusnest/shared-libs/server"
+	"github.com/focusnest/shared-libs/sharelink"
+	"github.com/focusnest/shared-libs/timezone"
 
 	"github.com/focusnest/progress-service/internal/config"
 	"github.com/focusnest/progress-service/internal/httpapi"
@@ -41,7 +43,16 @@ func main() {
 
 	// Initialize progress service
 	progressRepo := progress.NewFirestoreRepository(client)
-	progressService := progress.NewService(progressRepo)
+	var shareSigner *sharelink.Signer
+	if cfg.ShareLinkSecret != "" {
+		shareSigner, err = sharelink.NewSigner(cfg.ShareLinkSecret)
+		if err != nil {
+			panic(fmt.Errorf("share link signer: %w", err))
+		}
+	} else {
+		logger.Warn("SHARE_LINK_SECRET not set — share links disabled")
+	}
+	progressService := progress.NewService(progressRepo, progress.WithShareSigner(shareSigner))
 
 	verifier, err := sharedauth.NewVerifier(sharedauth.Config{
 		Mode:     cfg.Auth.Mode,
@@ -54,8 +65,12 @@ func main() {
 	}
 
 	router := sharedserver.NewRouter("progress-service", func(r chi.Router) {
+		// Share link snapshots are public; the signed token is the credential.
+		httpapi.RegisterPublicRoutes(r, progressService)
+
 		r.Group(func(r chi.Router) {
 			r.Use(sharedauth.Middleware(verifier))
+			r.Use(timezone.Middleware(timezone.NewResolver(progress.NewTimezoneLookup(client), logger)))
 
 			// Register progress routes
 			httpapi.RegisterRoutes(r, progressService)
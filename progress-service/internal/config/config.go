@@ -6,6 +6,7 @@ import (
 
 	sharedauth "github.com/focusnest/shared-libs/auth"
 	"github.com/focusnest/shared-libs/envconfig"
+	"github.com/focusnest/shared-libs/sharelink"
 )
 
 // Config encapsulates the runtime configuration for the progress service.
@@ -14,6 +15,8 @@ type Config struct {
 	GCPProjectID string
 	Auth         AuthConfig
 	Firestore    FirestoreConfig
+	// ShareLinkSecret signs public share links; sharing is disabled when empty.
+	ShareLinkSecret string
 }
 
 // AuthConfig stores authentication middleware setup.
@@ -42,6 +45,7 @@ func Load() (Config, error) {
 		Firestore: FirestoreConfig{
 			EmulatorHost: envconfig.Get("FIRESTORE_EMULATOR_HOST", ""),
 		},
+		ShareLinkSecret: envconfig.Get("SHARE_LINK_SECRET", ""),
 	}
 
 	if err := validate(cfg); err != nil {
@@ -60,6 +64,10 @@ func validate(cfg Config) error {
 		return fmt.Errorf("gcp project id required")
 	}
 
+	if cfg.ShareLinkSecret != "" && len(cfg.ShareLinkSecret) < sharelink.MinSecretLength {
+		return fmt.Errorf("SHARE_LINK_SECRET must be at least %d bytes", sharelink.MinSecretLength)
+	}
+
 	switch cfg.Auth.Mode {
 	case sharedauth.ModeClerk:
 		if cfg.Auth.JWKSURL == "" {
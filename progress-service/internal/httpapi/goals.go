@@ -0,0 +1,227 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/focusnest/progress-service/internal/progress"
+)
+
+const maxJSONPayloadBytes = 16 << 10
+
+type createGoalRequest struct {
+	Title      string              `json:"title"`
+	Period     progress.GoalPeriod `json:"period"`
+	Metric     progress.GoalMetric `json:"metric"`
+	Target     int                 `json:"target"`
+	Category   string              `json:"category"`
+	DaysOfWeek []int               `json:"days_of_week"`
+	StartDate  string              `json:"start_date"`
+	EndDate    string              `json:"end_date"`
+}
+
+type updateGoalRequest struct {
+	Title      *string `json:"title"`
+	Target     *int    `json:"target"`
+	Category   *string `json:"category"`
+	DaysOfWeek *[]int  `json:"days_of_week"`
+	StartDate  *string `json:"start_date"`
+	EndDate    *string `json:"end_date"`
+}
+
+// GET /v1/progress/goals
+func listGoals(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		goals, err := service.ListGoals(ctx, userID)
+		if err != nil {
+			writeGoalError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"goals": goals})
+	}
+}
+
+// POST /v1/progress/goals
+func createGoal(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var req createGoalRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		goal, err := service.CreateGoal(ctx, userID, progress.GoalInput{
+			Title:      req.Title,
+			Period:     req.Period,
+			Metric:     req.Metric,
+			Target:     req.Target,
+			Category:   req.Category,
+			DaysOfWeek: req.DaysOfWeek,
+			StartDate:  req.StartDate,
+			EndDate:    req.EndDate,
+			Timezone:   requestTimezone(r),
+		})
+		if err != nil {
+			writeGoalError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, goal)
+	}
+}
+
+// GET /v1/progress/goals/{goalID}
+func getGoal(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		goal, err := service.GetGoal(ctx, userID, chi.URLParam(r, "goalID"))
+		if err != nil {
+			writeGoalError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, goal)
+	}
+}
+
+// PATCH /v1/progress/goals/{goalID}
+func updateGoal(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var req updateGoalRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		goal, err := service.UpdateGoal(ctx, userID, chi.URLParam(r, "goalID"), progress.GoalPatch{
+			Title:      req.Title,
+			Target:     req.Target,
+			Category:   req.Category,
+			DaysOfWeek: req.DaysOfWeek,
+			StartDate:  req.StartDate,
+			EndDate:    req.EndDate,
+		})
+		if err != nil {
+			writeGoalError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, goal)
+	}
+}
+
+// DELETE /v1/progress/goals/{goalID}
+func deleteGoal(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		if err := service.DeleteGoal(ctx, userID, chi.URLParam(r, "goalID")); err != nil {
+			writeGoalError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GET /v1/progress/goals/{goalID}/progress?periods=N
+func getGoalProgress(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var periods int
+		if raw := r.URL.Query().Get("periods"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid periods")
+				return
+			}
+			periods = n
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetGoalProgress(ctx, userID, chi.URLParam(r, "goalID"), progress.GoalProgressInput{
+			Timezone: requestTimezone(r),
+			Periods:  periods,
+		})
+		if err != nil {
+			writeGoalError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONPayloadBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON payload")
+		return false
+	}
+	return true
+}
+
+func writeGoalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, progress.ErrMissingUserID), errors.Is(err, progress.ErrInvalidGoal):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, progress.ErrGoalNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
 	"github.com/focusnest/progress-service/internal/progress"
+	"github.com/focusnest/shared-libs/insights"
 )
 
 const (
@@ -26,11 +28,36 @@ func RegisterRoutes(r chi.Router, service progress.Service) {
 		r.Use(middleware.Recoverer)
 
 		r.Get("/summary", getSummary(service))
+		r.Get("/heatmap", getHeatmap(service))
+		r.Get("/matrix", getMatrix(service))
+		r.Get("/moods", getMoodAnalytics(service))
+		r.Get("/time-modes", getTimeModeReport(service))
+		r.Get("/records", getRecords(service))
+		r.Get("/records/check", checkRecords(service))
+		r.Get("/recap", getRecap(service))
+		r.Get("/insights", getInsights(service))
+
+		r.Route("/goals", func(r chi.Router) {
+			r.Get("/", listGoals(service))
+			r.Post("/", createGoal(service))
+			r.Get("/{goalID}", getGoal(service))
+			r.Patch("/{goalID}", updateGoal(service))
+			r.Delete("/{goalID}", deleteGoal(service))
+			r.Get("/{goalID}/progress", getGoalProgress(service))
+		})
+
+		r.Route("/share-links", func(r chi.Router) {
+			r.Get("/", listShareLinks(service))
+			r.Post("/", createShareLink(service))
+			r.Delete("/{linkID}", revokeShareLink(service))
+		})
 
 		r.Route("/streak", func(r chi.Router) {
 			r.Get("/monthly", getMonthlyStreak(service))
 			r.Get("/weekly", getWeeklyStreak(service))
 			r.Get("/current", getCurrentStreak(service))
+			r.Get("/history", getStreakHistory(service))
+			r.Get("/categories", getCategoryStreaks(service))
 			r.Post("/recover", recoverStreak(service))
 		})
 	})
@@ -45,42 +72,281 @@ func getSummary(service progress.Service) http.HandlerFunc {
 			return
 		}
 
-		rangeParam := r.URL.Query().Get("range")
-		if rangeParam == "" {
-			rangeParam = string(progress.SummaryRangeWeek)
+		input, ok := parseSummaryInput(w, r)
+		if !ok {
+			return
 		}
-		category := r.URL.Query().Get("category")
-		var reference time.Time
-		if raw := r.URL.Query().Get("reference_date"); raw != "" {
-			t, err := time.Parse(dateLayout, raw)
-			if err != nil {
-				writeError(w, http.StatusBadRequest, "invalid reference_date, use YYYY-MM-DD")
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetSummary(ctx, userID, input)
+		if err != nil {
+			writeRangeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GET /v1/progress/matrix?range=week|month|3months|year&category=&reference_date=YYYY-MM-DD
+func getMatrix(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		input, ok := parseSummaryInput(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetMatrix(ctx, userID, input)
+		if err != nil {
+			writeRangeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GET /v1/progress/moods?range=week|month|3months|year&category=&reference_date=YYYY-MM-DD
+func getMoodAnalytics(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		input, ok := parseSummaryInput(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetMoodAnalytics(ctx, userID, input)
+		if err != nil {
+			writeRangeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GET /v1/progress/time-modes?range=week|month|3months|year&category=&reference_date=YYYY-MM-DD
+func getTimeModeReport(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		input, ok := parseSummaryInput(w, r)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetTimeModeReport(ctx, userID, input)
+		if err != nil {
+			writeRangeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GET /v1/progress/records
+func getRecords(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetRecords(ctx, userID, requestTimezone(r))
+		if err != nil {
+			writeRecordsError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GET /v1/progress/records/check?entry_id=...
+// Called after a productivity entry is created to find the records it broke.
+func checkRecords(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.CheckRecords(ctx, userID, r.URL.Query().Get("entry_id"), requestTimezone(r))
+		if err != nil {
+			writeRecordsError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeRecordsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, progress.ErrMissingUserID), errors.Is(err, progress.ErrMissingEntryID):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, progress.ErrEntryNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// GET /v1/progress/insights?lang=en|id&limit=N
+func getInsights(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var limit int
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "invalid limit")
 				return
 			}
-			reference = t
+			limit = n
 		}
 
-		timezone := r.Header.Get("X-Timezone")
-		if timezone == "" {
-			timezone = r.Header.Get("x-timezone")
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetInsights(ctx, userID, progress.InsightsInput{
+			Timezone: requestTimezone(r),
+			Lang:     r.URL.Query().Get("lang"),
+			Limit:    limit,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, progress.ErrMissingUserID):
+				writeError(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, insights.ErrUnsupportedLang):
+				writeError(w, http.StatusBadRequest, "invalid lang, use en or id")
+			default:
+				writeError(w, http.StatusInternalServerError, "internal server error")
+			}
+			return
 		}
-		if timezone == "" {
-			timezone = r.URL.Query().Get("timezone")
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// parseSummaryInput reads the range, category, reference_date and timezone
+// parameters shared by the range-based endpoints. It writes a 400 and returns
+// false when reference_date is malformed.
+func parseSummaryInput(w http.ResponseWriter, r *http.Request) (progress.SummaryInput, bool) {
+	rangeParam := r.URL.Query().Get("range")
+	if rangeParam == "" {
+		rangeParam = string(progress.SummaryRangeWeek)
+	}
+	var reference time.Time
+	if raw := r.URL.Query().Get("reference_date"); raw != "" {
+		t, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid reference_date, use YYYY-MM-DD")
+			return progress.SummaryInput{}, false
 		}
+		reference = t
+	}
+	return progress.SummaryInput{
+		Range:         progress.SummaryRange(rangeParam),
+		Category:      r.URL.Query().Get("category"),
+		ReferenceDate: reference,
+		Timezone:      requestTimezone(r),
+	}, true
+}
+
+// writeRangeError maps errors from the range-based endpoints to HTTP responses.
+func writeRangeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "internal server error"
+	if errors.Is(err, progress.ErrInvalidSummaryRange) || errors.Is(err, progress.ErrMissingUserID) {
+		status = http.StatusBadRequest
+		message = err.Error()
+	}
+	writeError(w, status, message)
+}
+
+// GET /v1/progress/heatmap?year=YYYY or ?end_date=YYYY-MM-DD
+// Without year, returns the trailing 365 days ending on end_date (default today).
+func getHeatmap(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var input progress.HeatmapInput
+		if raw := r.URL.Query().Get("year"); raw != "" {
+			year, err := strconv.Atoi(raw)
+			if err != nil || year < minYear || year > maxYear {
+				writeError(w, http.StatusBadRequest, "invalid year (1970–2100)")
+				return
+			}
+			input.Year = year
+		}
+		if raw := r.URL.Query().Get("end_date"); raw != "" {
+			if input.Year != 0 {
+				writeError(w, http.StatusBadRequest, "use either year or end_date, not both")
+				return
+			}
+			t, err := time.Parse(dateLayout, raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid end_date, use YYYY-MM-DD")
+				return
+			}
+			input.EndDate = t
+		}
+		input.Timezone = requestTimezone(r)
 
 		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
 		defer cancel()
 
-		resp, err := service.GetSummary(ctx, userID, progress.SummaryInput{
-			Range:         progress.SummaryRange(rangeParam),
-			Category:      category,
-			ReferenceDate: reference,
-			Timezone:      timezone,
-		})
+		resp, err := service.GetHeatmap(ctx, userID, input)
 		if err != nil {
 			status := http.StatusInternalServerError
 			message := "internal server error"
-			if errors.Is(err, progress.ErrInvalidSummaryRange) || errors.Is(err, progress.ErrMissingUserID) {
+			if errors.Is(err, progress.ErrMissingUserID) {
 				status = http.StatusBadRequest
 				message = err.Error()
 			}
@@ -92,7 +358,7 @@ func getSummary(service progress.Service) http.HandlerFunc {
 	}
 }
 
-// GET /v1/progress/streaks/month?date=YYYY-MM-DD
+// GET /v1/progress/streaks/month?date=YYYY-MM-DD&category=
 func getMonthlyStreak(service progress.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := headerUserID(r)
@@ -125,7 +391,7 @@ func getMonthlyStreak(service progress.Service) http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
 		defer cancel()
 
-		data, err := service.GetMonthlyStreak(ctx, userID, int(month), year, timezone)
+		data, err := service.GetMonthlyStreak(ctx, userID, int(month), year, timezone, r.URL.Query().Get("category"))
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal server error")
 			return
@@ -135,7 +401,7 @@ func getMonthlyStreak(service progress.Service) http.HandlerFunc {
 	}
 }
 
-// GET /v1/progress/streaks/week?date=YYYY-MM-DD
+// GET /v1/progress/streaks/week?date=YYYY-MM-DD&category=
 func getWeeklyStreak(service progress.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := headerUserID(r)
@@ -162,7 +428,7 @@ func getWeeklyStreak(service progress.Service) http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
 		defer cancel()
 
-		data, err := service.GetWeeklyStreak(ctx, userID, target, timezone)
+		data, err := service.GetWeeklyStreak(ctx, userID, target, timezone, r.URL.Query().Get("category"))
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal server error")
 			return
@@ -172,7 +438,7 @@ func getWeeklyStreak(service progress.Service) http.HandlerFunc {
 	}
 }
 
-// GET /v1/progress/streak/current
+// GET /v1/progress/streak/current?category=
 // Returns the current (last 30 days) streak data with status (active/grace/expired) and recovery quota.
 // Optional header: X-Timezone (IANA, e.g. Asia/Jakarta); default Asia/Jakarta.
 func getCurrentStreak(service progress.Service) http.HandlerFunc {
@@ -190,7 +456,7 @@ func getCurrentStreak(service progress.Service) http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
 		defer cancel()
 
-		data, err := service.GetCurrentStreak(ctx, userID, timezone)
+		data, err := service.GetCurrentStreak(ctx, userID, timezone, r.URL.Query().Get("category"))
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal server error")
 			return
@@ -200,6 +466,52 @@ func getCurrentStreak(service progress.Service) http.HandlerFunc {
 	}
 }
 
+// GET /v1/progress/streak/history
+// Lists every streak, newest first. Optional header: X-Timezone (IANA); default Asia/Jakarta.
+func getStreakHistory(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetStreakHistory(ctx, userID, requestTimezone(r))
+		if err != nil {
+			writeRangeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// GET /v1/progress/streak/categories
+// Current and longest streak for every category the user has been active in.
+func getCategoryStreaks(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.GetCategoryStreaks(ctx, userID, requestTimezone(r))
+		if err != nil {
+			writeRangeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
 // Helpers
 
 // headerUserID gets the user ID from headers, case-insensitive.
@@ -210,6 +522,17 @@ func headerUserID(r *http.Request) string {
 	return r.Header.Get("x-user-id")
 }
 
+// requestTimezone reads the IANA timezone from X-Timezone or the timezone query param.
+func requestTimezone(r *http.Request) string {
+	if tz := r.Header.Get("X-Timezone"); tz != "" {
+		return tz
+	}
+	if tz := r.Header.Get("x-timezone"); tz != "" {
+		return tz
+	}
+	return r.URL.Query().Get("timezone")
+}
+
 // optionalDate parses YYYY-MM-DD; if empty, returns today UTC.
 func optionalDate(s string) (time.Time, bool) {
 	if s == "" {
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/focusnest/progress-service/internal/progress"
+	"github.com/focusnest/progress-service/internal/recap"
+)
+
+// GET /v1/progress/recap?format=png|svg|json&lang=en|id&reference_date=YYYY-MM-DD
+// Renders the weekly recap card for the ISO week containing reference_date.
+func getRecap(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "png"
+		}
+		if format != "png" && format != "svg" && format != "json" {
+			writeError(w, http.StatusBadRequest, "invalid format, use png, svg or json")
+			return
+		}
+		lang, err := recap.NormalizeLang(r.URL.Query().Get("lang"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid lang, use en or id")
+			return
+		}
+		var reference time.Time
+		if raw := r.URL.Query().Get("reference_date"); raw != "" {
+			t, err := time.Parse(dateLayout, raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid reference_date, use YYYY-MM-DD")
+				return
+			}
+			reference = t
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		data, err := service.GetRecap(ctx, userID, progress.RecapInput{
+			ReferenceDate: reference,
+			Timezone:      requestTimezone(r),
+		})
+		if err != nil {
+			writeRangeError(w, err)
+			return
+		}
+
+		var (
+			body        []byte
+			contentType string
+		)
+		switch format {
+		case "json":
+			writeJSON(w, http.StatusOK, data)
+			return
+		case "svg":
+			body, err = recap.RenderSVG(data, lang)
+			contentType = "image/svg+xml"
+		default:
+			body, err = recap.RenderPNG(data, lang)
+			contentType = "image/png"
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to render recap")
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "private, max-age=300")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}
+}
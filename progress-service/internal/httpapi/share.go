@@ -0,0 +1,254 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/focusnest/progress-service/internal/progress"
+	"github.com/focusnest/progress-service/internal/recap"
+)
+
+type createShareLinkRequest struct {
+	Range             progress.SummaryRange `json:"range"`
+	ReferenceDate     string                `json:"reference_date"`
+	IncludeCategories bool                  `json:"include_categories"`
+	ExpiresInDays     int                   `json:"expires_in_days"`
+}
+
+// RegisterPublicRoutes mounts the unauthenticated share link routes. Mount it
+// outside the auth middleware group.
+func RegisterPublicRoutes(r chi.Router, service progress.Service) {
+	r.Route("/v1/public/shares", func(r chi.Router) {
+		r.Use(middleware.Recoverer)
+		r.Get("/{token}", getSharedSnapshot(service))
+	})
+}
+
+// GET /v1/progress/share-links
+func listShareLinks(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		links, err := service.ListShareLinks(ctx, userID)
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{"share_links": links})
+	}
+}
+
+// POST /v1/progress/share-links
+func createShareLink(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var req createShareLinkRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+		var reference time.Time
+		if req.ReferenceDate != "" {
+			t, err := time.Parse(dateLayout, req.ReferenceDate)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid reference_date, use YYYY-MM-DD")
+				return
+			}
+			reference = t
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.CreateShareLink(ctx, userID, progress.ShareLinkInput{
+			Range:             req.Range,
+			ReferenceDate:     reference,
+			IncludeCategories: req.IncludeCategories,
+			ExpiresInDays:     req.ExpiresInDays,
+			Timezone:          requestTimezone(r),
+		})
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, resp)
+	}
+}
+
+// DELETE /v1/progress/share-links/{linkID}
+func revokeShareLink(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		if err := service.RevokeShareLink(ctx, userID, chi.URLParam(r, "linkID")); err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GET /v1/public/shares/{token}?format=html|json&lang=en|id
+// Public; the token is the only credential.
+func getSharedSnapshot(service progress.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "html"
+		}
+		if format != "html" && format != "json" {
+			writeError(w, http.StatusBadRequest, "invalid format, use html or json")
+			return
+		}
+		lang, err := recap.NormalizeLang(r.URL.Query().Get("lang"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid lang, use en or id")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		snapshot, err := service.GetSharedSnapshot(ctx, chi.URLParam(r, "token"))
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		// Short cache so a revoked link stops resolving quickly.
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("X-Robots-Tag", "noindex")
+		if format == "json" {
+			writeJSON(w, http.StatusOK, snapshot)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := shareTemplate.Execute(&buf, shareView{Snapshot: snapshot, Labels: shareLabels[lang], Lang: lang}); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to render snapshot")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+func writeShareError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, progress.ErrMissingUserID), errors.Is(err, progress.ErrInvalidShareLink), errors.Is(err, progress.ErrInvalidSummaryRange):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, progress.ErrShareLinkNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, progress.ErrSharingDisabled):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+type shareText struct {
+	Title, Focused, Sessions, ActiveDays, Streak, Categories, Days string
+}
+
+var shareLabels = map[string]shareText{
+	recap.LangEN: {
+		Title:      "Focus progress",
+		Focused:    "Focused",
+		Sessions:   "Sessions",
+		ActiveDays: "Active days",
+		Streak:     "Current streak",
+		Categories: "By category",
+		Days:       "days",
+	},
+	recap.LangID: {
+		Title:      "Progres fokus",
+		Focused:    "Waktu fokus",
+		Sessions:   "Sesi",
+		ActiveDays: "Hari aktif",
+		Streak:     "Streak saat ini",
+		Categories: "Per kategori",
+		Days:       "hari",
+	},
+}
+
+type shareView struct {
+	Snapshot *progress.ShareSnapshot
+	Labels   shareText
+	Lang     string
+}
+
+var shareTemplate = template.Must(template.New("share").Funcs(template.FuncMap{
+	"duration": func(seconds int) string {
+		minutes := seconds / 60
+		if minutes < 60 {
+			return fmt.Sprintf("%dm", minutes)
+		}
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>FocusNest · {{.Labels.Title}}</title>
+<style>
+body{font-family:Helvetica,Arial,sans-serif;background:#f5f3ff;color:#1f1b2d;margin:0;padding:24px}
+main{max-width:420px;margin:0 auto;background:#fff;border-radius:16px;padding:24px}
+h1{font-size:20px;margin:0 0 4px}p.range{color:#6b6680;margin:0 0 20px}
+dl{display:grid;grid-template-columns:1fr auto;gap:8px 16px;margin:0}dt{color:#6b6680}dd{margin:0;font-weight:bold;text-align:right}
+h2{font-size:16px;margin:24px 0 8px}
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Labels.Title}}</h1>
+<p class="range">{{.Snapshot.From}} – {{.Snapshot.To}}</p>
+<dl>
+<dt>{{.Labels.Focused}}</dt><dd>{{duration .Snapshot.TimeElapsed}}</dd>
+<dt>{{.Labels.Sessions}}</dt><dd>{{.Snapshot.Sessions}}</dd>
+<dt>{{.Labels.ActiveDays}}</dt><dd>{{.Snapshot.ActiveDays}}</dd>
+<dt>{{.Labels.Streak}}</dt><dd>{{.Snapshot.CurrentStreak}} {{.Labels.Days}}</dd>
+</dl>
+{{- if .Snapshot.Categories}}
+<h2>{{.Labels.Categories}}</h2>
+<dl>
+{{- range .Snapshot.Categories}}
+<dt>{{.Category}}</dt><dd>{{duration .TimeElapsed}}</dd>
+{{- end}}
+</dl>
+{{- end}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,141 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// getCategoryCurrentStreak is GetCurrentStreak for a single category. It uses
+// the same 30-day window and expiry/grace rules, but per-category streaks have
+// no recovery, so nothing is read from or written to the streak state.
+func (s *service) getCategoryCurrentStreak(ctx context.Context, userID string, loc *time.Location, category string) (*StreakData, error) {
+	now := time.Now().In(loc)
+	today := truncateToDay(now)
+	startDate := today.AddDate(0, 0, -30)
+
+	summaries, err := s.repo.GetDailySummaries(ctx, userID, startDate, today.AddDate(0, 0, 1), loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
+	}
+	dayMap := activeDayMap(summaries, loc, category)
+
+	days := make([]DayStatus, 0)
+	for d := startDate; !d.After(today); d = d.AddDate(0, 0, 1) {
+		dayStr := d.Format(dateLayout)
+		status := "skipped"
+		if dayMap[dayStr] {
+			status = "done"
+		}
+		days = append(days, DayStatus{Date: dayStr, Day: d.Format("Monday"), Status: status})
+	}
+
+	totalStreak, run, overflows := s.calculateStreaks(days, now)
+	lastProd := getLastProductiveDate(days, today)
+	if overflows && lastProd != "" {
+		run = s.historicalStreak(ctx, userID, lastProd, loc, category)
+		if run > totalStreak {
+			totalStreak = run
+		}
+	}
+
+	resp := &StreakData{
+		TotalStreak:           totalStreak,
+		Days:                  days,
+		RecoveryQuotaPerMonth: recoveryQuota,
+		Category:              category,
+	}
+	resp.CurrentStreak, resp.Status, resp.GraceEndsAt, resp.ExpiredAt = streakStatusFor(run, lastProd, today, loc)
+	return resp, nil
+}
+
+// GetCategoryStreaks returns the current and longest streak of every category
+// the user has been active in, computed from the full history. Categories are
+// grouped case-insensitively, as in GetRecords.
+func (s *service) GetCategoryStreaks(ctx context.Context, userID string, timezone string) (*CategoryStreaksResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	loc := s.resolveLocation(timezone)
+	entries, err := s.listAllProductivities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := truncateToDay(time.Now().In(loc))
+
+	byCategory := make(map[string]map[string]bool)
+	for cat, list := range groupByCategory(entries) {
+		for _, entry := range list {
+			if entry.StartTime.IsZero() {
+				continue
+			}
+			day := entry.StartTime.In(entryLocation(entry, loc))
+			if truncateToDay(day).After(today) {
+				continue
+			}
+			if byCategory[cat] == nil {
+				byCategory[cat] = make(map[string]bool)
+			}
+			byCategory[cat][day.Format(dateLayout)] = true
+		}
+	}
+
+	resp := &CategoryStreaksResponse{Timezone: loc.String(), Categories: make([]CategoryStreak, 0, len(byCategory))}
+	for cat, active := range byCategory {
+		keys := sortedKeys(active)
+		lastProd := keys[len(keys)-1]
+
+		var (
+			longest, run int
+			prev         time.Time
+		)
+		for _, key := range keys {
+			d, _ := time.ParseInLocation(dateLayout, key, loc)
+			if run > 0 && d.Equal(prev.AddDate(0, 0, 1)) {
+				run++
+			} else {
+				run = 1
+			}
+			prev = d
+			longest = max(longest, run)
+		}
+
+		cs := CategoryStreak{Category: cat, LongestStreak: longest, LastActiveDate: lastProd}
+		cs.CurrentStreak, cs.Status, cs.GraceEndsAt, cs.ExpiredAt = streakStatusFor(run, lastProd, today, loc)
+		resp.Categories = append(resp.Categories, cs)
+	}
+	sort.Slice(resp.Categories, func(i, j int) bool {
+		a, b := resp.Categories[i], resp.Categories[j]
+		if a.CurrentStreak != b.CurrentStreak {
+			return a.CurrentStreak > b.CurrentStreak
+		}
+		return a.Category < b.Category
+	})
+	return resp, nil
+}
+
+// streakStatusFor applies GetCurrentStreak's expiry and grace rules to a run of
+// active days ending on lastProd, for streaks without recovery state: active
+// until the day after lastProd, then graceDays of grace showing the old value,
+// then expired at 0.
+func streakStatusFor(run int, lastProd string, today time.Time, loc *time.Location) (current int, status, graceEndsAt, expiredAt string) {
+	if lastProd == "" {
+		return 0, StreakStatusActive, "", ""
+	}
+	lastProdT, err := time.ParseInLocation(dateLayout, lastProd, loc)
+	if err != nil {
+		return 0, StreakStatusActive, "", ""
+	}
+	expiredDate := lastProdT.AddDate(0, 0, daysUntilExpiry)
+	if !today.After(expiredDate) {
+		return run, StreakStatusActive, "", ""
+	}
+	expiredAt = expiredDate.Format(dateLayout)
+	graceEnd := expiredDate.AddDate(0, 0, graceDays-1)
+	if today.After(graceEnd) {
+		return 0, StreakStatusExpired, "", expiredAt
+	}
+	return run, StreakStatusGrace, graceEnd.Format(dateLayout), expiredAt
+}
@@ -0,0 +1,119 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStreakStatusFor(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	today := time.Date(2025, 11, 20, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name        string
+		run         int
+		lastProd    string
+		wantCurrent int
+		wantStatus  string
+		wantGrace   string
+		wantExpired string
+	}{
+		{name: "No activity", wantStatus: StreakStatusActive},
+		{name: "Active today", run: 3, lastProd: "2025-11-20", wantCurrent: 3, wantStatus: StreakStatusActive},
+		{name: "Active yesterday", run: 3, lastProd: "2025-11-19", wantCurrent: 3, wantStatus: StreakStatusActive},
+		{name: "Grace", run: 3, lastProd: "2025-11-18", wantCurrent: 3, wantStatus: StreakStatusGrace, wantGrace: "2025-11-21", wantExpired: "2025-11-19"},
+		{name: "Last grace day", run: 3, lastProd: "2025-11-17", wantCurrent: 3, wantStatus: StreakStatusGrace, wantGrace: "2025-11-20", wantExpired: "2025-11-18"},
+		{name: "Expired", run: 3, lastProd: "2025-11-15", wantStatus: StreakStatusExpired, wantExpired: "2025-11-16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, status, grace, expired := streakStatusFor(tt.run, tt.lastProd, today, loc)
+			if current != tt.wantCurrent || status != tt.wantStatus || grace != tt.wantGrace || expired != tt.wantExpired {
+				t.Errorf("streakStatusFor() = %d %q %q %q", current, status, grace, expired)
+			}
+		})
+	}
+}
+
+func TestGetCategoryStreaks(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	today := truncateToDay(time.Now().In(loc))
+	at := func(daysAgo int) time.Time { return today.AddDate(0, 0, -daysAgo).Add(9 * time.Hour) }
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		{StartTime: at(10), TimeElapsed: 600, Category: "Study"},
+		{StartTime: at(9), TimeElapsed: 600, Category: "Study"},
+		{StartTime: at(8), TimeElapsed: 600, Category: "Study"},
+		{StartTime: at(2), TimeElapsed: 600, Category: " study"}, // same category as "Study"
+		{StartTime: at(1), TimeElapsed: 600, Category: "Study"},
+		{StartTime: at(0), TimeElapsed: 600, Category: "Study"},
+		{StartTime: at(6), TimeElapsed: 600, Category: "Workout"},
+		{StartTime: at(5), TimeElapsed: 600, Category: "Workout"},
+	}}
+	svc := NewService(repo)
+
+	resp, err := svc.GetCategoryStreaks(context.Background(), "user-1", "Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("GetCategoryStreaks() error = %v", err)
+	}
+	if len(resp.Categories) != 2 {
+		t.Fatalf("categories = %+v", resp.Categories)
+	}
+	study, workout := resp.Categories[0], resp.Categories[1]
+	if study.Category != "Study" || study.CurrentStreak != 3 || study.LongestStreak != 3 || study.Status != StreakStatusActive {
+		t.Errorf("study = %+v", study)
+	}
+	if workout.Category != "Workout" || workout.CurrentStreak != 0 || workout.LongestStreak != 2 || workout.Status != StreakStatusExpired {
+		t.Errorf("workout = %+v", workout)
+	}
+}
+
+func TestGetCategoryStreaksRecordedTimezone(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	day := truncateToDay(time.Now().In(jakarta)).AddDate(0, 0, -5)
+	// 23:00 and 01:00 the next day in Jakarta: two local days there, but the
+	// same day in New York, where the user has since moved.
+	entries := []ProductivityEntry{
+		{StartTime: day.Add(23 * time.Hour), TimeElapsed: 600, Category: "Study"},
+		{StartTime: day.Add(25 * time.Hour), TimeElapsed: 600, Category: "Study"},
+	}
+
+	for _, tt := range []struct {
+		timezone string
+		longest  int
+	}{
+		{timezone: "Asia/Jakarta", longest: 2},
+		{timezone: "", longest: 1},
+	} {
+		recorded := make([]ProductivityEntry, len(entries))
+		for i, e := range entries {
+			e.Timezone = tt.timezone
+			recorded[i] = e
+		}
+		svc := NewService(&fakeRepository{entries: recorded})
+		resp, err := svc.GetCategoryStreaks(context.Background(), "user-1", "America/New_York")
+		if err != nil {
+			t.Fatalf("GetCategoryStreaks() error = %v", err)
+		}
+		if len(resp.Categories) != 1 || resp.Categories[0].LongestStreak != tt.longest {
+			t.Errorf("recorded timezone %q: categories = %+v, want longest %d", tt.timezone, resp.Categories, tt.longest)
+		}
+	}
+}
+
+func TestActiveDayMap(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	summaries := []*DailySummary{
+		{Date: time.Date(2025, 11, 17, 0, 0, 0, 0, loc), Categories: map[string]int{"Study": 30}},
+		{Date: time.Date(2025, 11, 18, 0, 0, 0, 0, loc), Categories: map[string]int{"Work": 30, "Study": 0}},
+	}
+
+	if got := activeDayMap(summaries, loc, ""); len(got) != 2 {
+		t.Errorf("global active days = %v, want 2", got)
+	}
+	got := activeDayMap(summaries, loc, "study")
+	if len(got) != 1 || !got["2025-11-17"] {
+		t.Errorf("study active days = %v, want only 2025-11-17", got)
+	}
+}
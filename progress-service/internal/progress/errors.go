@@ -13,4 +13,18 @@ var (
 	ErrStreakNotRecoverable = errors.New("streak is not recoverable")
 	// ErrRecoveryQuotaExceeded indicates monthly recovery quota (5) is exceeded.
 	ErrRecoveryQuotaExceeded = errors.New("recovery quota exceeded for this month")
+	// ErrMissingEntryID indicates a required productivity entry id was absent.
+	ErrMissingEntryID = errors.New("entry id is required")
+	// ErrEntryNotFound indicates the productivity entry does not exist for the user.
+	ErrEntryNotFound = errors.New("entry not found")
+	// ErrInvalidGoal indicates a goal failed validation; details are wrapped.
+	ErrInvalidGoal = errors.New("invalid goal")
+	// ErrGoalNotFound indicates the goal does not exist for the user.
+	ErrGoalNotFound = errors.New("goal not found")
+	// ErrInvalidShareLink indicates share link options failed validation.
+	ErrInvalidShareLink = errors.New("invalid share link")
+	// ErrShareLinkNotFound indicates an unknown, revoked, expired or forged share link.
+	ErrShareLinkNotFound = errors.New("share link not found")
+	// ErrSharingDisabled indicates no share link secret is configured.
+	ErrSharingDisabled = errors.New("share links are not configured")
 )
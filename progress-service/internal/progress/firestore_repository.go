@@ -9,11 +9,14 @@ import (
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/focusnest/shared-libs/timezone"
 )
 
 const (
 	streakStateColl        = "streak_state"
 	streakRecoveryQuotaColl = "streak_recovery_quota"
+	streakRecoveriesColl    = "streak_recoveries"
 	recoveryQuotaLimit     = 5
 )
 
@@ -26,36 +29,44 @@ func NewFirestoreRepository(client *firestore.Client) Repository {
 	return &firestoreRepository{client: client}
 }
 
-func (r *firestoreRepository) GetDailySummaries(ctx context.Context, userID string, startDate, endDate time.Time) ([]*DailySummary, error) {
+// NewTimezoneLookup reads the timezone preference user-service stores on
+// profiles/{uid}.
+func NewTimezoneLookup(client *firestore.Client) timezone.Lookup {
+	return timezone.LookupFunc(func(ctx context.Context, userID string) (string, error) {
+		doc, err := client.Collection("profiles").Doc(userID).Get(ctx)
+		if isNotFound(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		tz, _ := doc.Data()[timezone.ProfileField].(string)
+		return tz, nil
+	})
+}
+
+func (r *firestoreRepository) GetDailySummaries(ctx context.Context, userID string, startDate, endDate time.Time, loc *time.Location) ([]*DailySummary, error) {
 	// Always aggregate from productivities (single source of truth).
 	// daily_summaries is not maintained by the app flow and may contain stale data.
-	return r.aggregateFromProductivities(ctx, userID, startDate, endDate)
+	return r.aggregateFromProductivities(ctx, userID, startDate, endDate, loc)
 }
 
 // aggregateFromProductivities reads from productivities collection and creates daily summaries
-func (r *firestoreRepository) aggregateFromProductivities(ctx context.Context, userID string, startDate, endDate time.Time) ([]*DailySummary, error) {
+func (r *firestoreRepository) aggregateFromProductivities(ctx context.Context, userID string, startDate, endDate time.Time, loc *time.Location) ([]*DailySummary, error) {
 	entries, err := r.fetchProductivities(ctx, userID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	// Use Asia/Jakarta timezone for day grouping so that e.g. 05:14 WIB is
-	// counted on the correct local date, not the UTC date (which would be the previous day).
-	loc, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		loc = time.UTC
-	}
-
 	dayMap := make(map[string]*DailySummary)
 	for _, entry := range entries {
 		if entry.StartTime.IsZero() {
 			continue
 		}
-		mins := entry.TimeElapsed / 60
-		if mins <= 0 && entry.TimeElapsed > 0 {
-			mins = 1
-		}
-		localStart := entry.StartTime.In(loc)
+		mins := entryMinutes(entry)
+		// Group by the local date the session was recorded on (e.g. 05:14 WIB
+		// stays on its WIB date), keyed in loc so callers can look days up.
+		localStart := entry.StartTime.In(entryLocation(entry, loc))
 		dayStr := localStart.Format("2006-01-02")
 		dayDate := time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, loc)
 		if summary, exists := dayMap[dayStr]; exists {
@@ -93,7 +104,9 @@ func (r *firestoreRepository) fetchProductivities(ctx context.Context, userID st
 		Where("start_time", ">=", startDate).
 		Where("start_time", "<", endDate).
 		OrderBy("start_time", firestore.Asc).
+		Select("start_time", "end_time", "time_elapsed", "category", "mood", "time_mode", "num_cycle", "timezone", "deleted").
 		Documents(ctx)
+	defer iter.Stop()
 
 	var entries []ProductivityEntry
 	for {
@@ -110,6 +123,10 @@ func (r *firestoreRepository) fetchProductivities(ctx context.Context, userID st
 			EndTime     time.Time `firestore:"end_time"`
 			TimeElapsed int       `firestore:"time_elapsed"`
 			Category    string    `firestore:"category"`
+			Mood        string    `firestore:"mood"`
+			TimeMode    string    `firestore:"time_mode"`
+			NumCycle    int       `firestore:"num_cycle"`
+			Timezone    string    `firestore:"timezone"`
 			Deleted     bool      `firestore:"deleted"`
 		}
 		if err := doc.DataTo(&payload); err != nil {
@@ -119,10 +136,15 @@ func (r *firestoreRepository) fetchProductivities(ctx context.Context, userID st
 			continue
 		}
 		entries = append(entries, ProductivityEntry{
+			ID:          doc.Ref.ID,
 			StartTime:   payload.StartTime,
 			EndTime:     payload.EndTime,
 			TimeElapsed: payload.TimeElapsed,
 			Category:    payload.Category,
+			Mood:        payload.Mood,
+			TimeMode:    payload.TimeMode,
+			NumCycle:    payload.NumCycle,
+			Timezone:    payload.Timezone,
 		})
 	}
 
@@ -130,7 +152,7 @@ func (r *firestoreRepository) fetchProductivities(ctx context.Context, userID st
 }
 
 func (r *firestoreRepository) GetProgressStats(ctx context.Context, userID string, startDate, endDate time.Time) (*ProgressStats, error) {
-	summaries, err := r.GetDailySummaries(ctx, userID, startDate, endDate)
+	summaries, err := r.GetDailySummaries(ctx, userID, startDate, endDate, timezone.Load(""))
 	if err != nil {
 		return nil, err
 	}
@@ -202,19 +224,40 @@ func (r *firestoreRepository) GetRecoveryQuota(ctx context.Context, userID strin
 	return q.Count, nil
 }
 
-func (r *firestoreRepository) IncrementRecoveryQuota(ctx context.Context, userID string, yearMonth string) (int, error) {
-	docID := userID + "_" + yearMonth
-	ref := r.client.Collection(streakRecoveryQuotaColl).Doc(docID)
+// RecoverStreak spends one of the month's recoveries on the expiry in event,
+// in one transaction with the streak state override and the recovery event,
+// so a failure leaves none of them written. The event has one document per
+// user and expiry, and the state is re-checked inside the transaction, so a
+// retry cannot spend the quota twice.
+func (r *firestoreRepository) RecoverStreak(ctx context.Context, userID string, yearMonth string, event *RecoveryEvent) (int, error) {
+	stateRef := r.client.Collection(streakStateColl).Doc(userID)
+	quotaRef := r.client.Collection(streakRecoveryQuotaColl).Doc(userID + "_" + yearMonth)
+	eventRef := r.client.Collection(streakRecoveriesColl).Doc(userID + "_" + event.ExpiredAt)
 	var newCount int
 	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		doc, err := tx.Get(ref)
+		stateDoc, err := tx.Get(stateRef)
+		if isNotFound(err) {
+			return ErrStreakNotRecoverable
+		}
+		if err != nil {
+			return err
+		}
+		var state StreakState
+		if err := stateDoc.DataTo(&state); err != nil {
+			return fmt.Errorf("unmarshal streak_state: %w", err)
+		}
+		if state.ExpiredAt != event.ExpiredAt || state.OverrideStreakValue > 0 {
+			return ErrStreakNotRecoverable
+		}
+
+		quotaDoc, err := tx.Get(quotaRef)
 		var count int
 		if err != nil && !isNotFound(err) {
 			return err
 		}
 		if err == nil {
 			var q RecoveryQuota
-			if err := doc.DataTo(&q); err != nil {
+			if err := quotaDoc.DataTo(&q); err != nil {
 				return err
 			}
 			count = q.Count
@@ -224,12 +267,28 @@ func (r *firestoreRepository) IncrementRecoveryQuota(ctx context.Context, userID
 		}
 		count++
 		newCount = count
-		return tx.Set(ref, &RecoveryQuota{
+
+		now := time.Now().UTC()
+		state.UserID = userID
+		state.OverrideStreakValue = state.StreakValueBeforeExpired
+		state.UpdatedAt = now
+		event.UserID = userID
+		event.StreakValue = state.StreakValueBeforeExpired
+		if event.RecoveredAt.IsZero() {
+			event.RecoveredAt = now
+		}
+		if err := tx.Set(quotaRef, &RecoveryQuota{
 			UserID:    userID,
 			YearMonth: yearMonth,
 			Count:     count,
-			UpdatedAt: time.Now().UTC(),
-		})
+			UpdatedAt: now,
+		}); err != nil {
+			return err
+		}
+		if err := tx.Set(stateRef, &state); err != nil {
+			return err
+		}
+		return tx.Set(eventRef, event)
 	})
 	if err != nil {
 		return 0, err
@@ -237,6 +296,143 @@ func (r *firestoreRepository) IncrementRecoveryQuota(ctx context.Context, userID
 	return newCount, nil
 }
 
+func (r *firestoreRepository) ListRecoveryEvents(ctx context.Context, userID string) ([]RecoveryEvent, error) {
+	iter := r.client.Collection(streakRecoveriesColl).Where("user_id", "==", userID).Documents(ctx)
+	defer iter.Stop()
+
+	var events []RecoveryEvent
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var event RecoveryEvent
+		if err := doc.DataTo(&event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (r *firestoreRepository) goals(userID string) *firestore.CollectionRef {
+	return r.client.Collection("users").Doc(userID).Collection("goals")
+}
+
+func (r *firestoreRepository) CreateGoal(ctx context.Context, userID string, goal *Goal) error {
+	ref := r.goals(userID).NewDoc()
+	if _, err := ref.Create(ctx, goal); err != nil {
+		return err
+	}
+	goal.ID = ref.ID
+	return nil
+}
+
+func (r *firestoreRepository) GetGoal(ctx context.Context, userID, goalID string) (*Goal, error) {
+	doc, err := r.goals(userID).Doc(goalID).Get(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var goal Goal
+	if err := doc.DataTo(&goal); err != nil {
+		return nil, fmt.Errorf("unmarshal goal: %w", err)
+	}
+	goal.ID = doc.Ref.ID
+	return &goal, nil
+}
+
+func (r *firestoreRepository) ListGoals(ctx context.Context, userID string) ([]Goal, error) {
+	iter := r.goals(userID).Documents(ctx)
+	defer iter.Stop()
+
+	var goals []Goal
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var goal Goal
+		if err := doc.DataTo(&goal); err != nil {
+			continue
+		}
+		goal.ID = doc.Ref.ID
+		goals = append(goals, goal)
+	}
+	return goals, nil
+}
+
+func (r *firestoreRepository) UpdateGoal(ctx context.Context, userID string, goal *Goal) error {
+	_, err := r.goals(userID).Doc(goal.ID).Set(ctx, goal)
+	return err
+}
+
+func (r *firestoreRepository) DeleteGoal(ctx context.Context, userID, goalID string) error {
+	_, err := r.goals(userID).Doc(goalID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreRepository) CreateShareLink(ctx context.Context, link *ShareLink) error {
+	ref := r.client.Collection("share_links").NewDoc()
+	if _, err := ref.Create(ctx, link); err != nil {
+		return err
+	}
+	link.ID = ref.ID
+	return nil
+}
+
+func (r *firestoreRepository) GetShareLink(ctx context.Context, linkID string) (*ShareLink, error) {
+	doc, err := r.client.Collection("share_links").Doc(linkID).Get(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var link ShareLink
+	if err := doc.DataTo(&link); err != nil {
+		return nil, fmt.Errorf("unmarshal share link: %w", err)
+	}
+	link.ID = doc.Ref.ID
+	return &link, nil
+}
+
+func (r *firestoreRepository) ListShareLinks(ctx context.Context, userID string) ([]ShareLink, error) {
+	iter := r.client.Collection("share_links").Where("user_id", "==", userID).Documents(ctx)
+	defer iter.Stop()
+
+	var links []ShareLink
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var link ShareLink
+		if err := doc.DataTo(&link); err != nil {
+			continue
+		}
+		link.ID = doc.Ref.ID
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func (r *firestoreRepository) UpdateShareLink(ctx context.Context, link *ShareLink) error {
+	_, err := r.client.Collection("share_links").Doc(link.ID).Set(ctx, link)
+	return err
+}
+
 func isNotFound(err error) bool {
 	return err != nil && status.Code(err) == codes.NotFound
 }
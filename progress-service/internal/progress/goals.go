@@ -0,0 +1,346 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxGoalTitleLength = 80
+	maxGoalTarget      = 100000
+	defaultGoalPeriods = 4
+	maxGoalPeriods     = 52
+)
+
+// CreateGoal validates and stores a new goal.
+func (s *service) CreateGoal(ctx context.Context, userID string, input GoalInput) (*Goal, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	loc := s.resolveLocation(input.Timezone)
+	goal := &Goal{
+		Title:      strings.TrimSpace(input.Title),
+		Period:     input.Period,
+		Metric:     input.Metric,
+		Target:     input.Target,
+		Category:   strings.TrimSpace(input.Category),
+		DaysOfWeek: input.DaysOfWeek,
+		StartDate:  input.StartDate,
+		EndDate:    input.EndDate,
+	}
+	if goal.Metric == "" {
+		goal.Metric = GoalMetricMinutes
+	}
+	if goal.StartDate == "" {
+		goal.StartDate = time.Now().In(loc).Format(dateLayout)
+	}
+	if err := normalizeGoal(goal); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	goal.CreatedAt = now
+	goal.UpdatedAt = now
+	if err := s.repo.CreateGoal(ctx, userID, goal); err != nil {
+		return nil, fmt.Errorf("create goal: %w", err)
+	}
+	return goal, nil
+}
+
+// ListGoals returns the user's goals, newest first.
+func (s *service) ListGoals(ctx context.Context, userID string) ([]Goal, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	goals, err := s.repo.ListGoals(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list goals: %w", err)
+	}
+	sort.SliceStable(goals, func(i, j int) bool { return goals[i].CreatedAt.After(goals[j].CreatedAt) })
+	if goals == nil {
+		goals = []Goal{}
+	}
+	return goals, nil
+}
+
+func (s *service) GetGoal(ctx context.Context, userID, goalID string) (*Goal, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	goal, err := s.repo.GetGoal(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal == nil {
+		return nil, ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+// UpdateGoal applies patch and re-validates the goal. Period and metric are
+// fixed after creation so past progress keeps its meaning.
+func (s *service) UpdateGoal(ctx context.Context, userID, goalID string, patch GoalPatch) (*Goal, error) {
+	goal, err := s.GetGoal(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Title != nil {
+		goal.Title = strings.TrimSpace(*patch.Title)
+	}
+	if patch.Target != nil {
+		goal.Target = *patch.Target
+	}
+	if patch.Category != nil {
+		goal.Category = strings.TrimSpace(*patch.Category)
+	}
+	if patch.DaysOfWeek != nil {
+		goal.DaysOfWeek = *patch.DaysOfWeek
+	}
+	if patch.StartDate != nil {
+		goal.StartDate = *patch.StartDate
+	}
+	if patch.EndDate != nil {
+		goal.EndDate = *patch.EndDate
+	}
+	if err := normalizeGoal(goal); err != nil {
+		return nil, err
+	}
+	goal.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateGoal(ctx, userID, goal); err != nil {
+		return nil, fmt.Errorf("update goal: %w", err)
+	}
+	return goal, nil
+}
+
+func (s *service) DeleteGoal(ctx context.Context, userID, goalID string) error {
+	if _, err := s.GetGoal(ctx, userID, goalID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteGoal(ctx, userID, goalID); err != nil {
+		return fmt.Errorf("delete goal: %w", err)
+	}
+	return nil
+}
+
+// normalizeGoal validates a goal and canonicalizes DaysOfWeek (sorted, unique).
+func normalizeGoal(goal *Goal) error {
+	if len(goal.Title) > maxGoalTitleLength {
+		return fmt.Errorf("%w: title must be at most %d characters", ErrInvalidGoal, maxGoalTitleLength)
+	}
+	switch goal.Period {
+	case GoalPeriodDaily, GoalPeriodWeekly, GoalPeriodMonthly:
+	default:
+		return fmt.Errorf("%w: period must be daily, weekly or monthly", ErrInvalidGoal)
+	}
+	switch goal.Metric {
+	case GoalMetricMinutes, GoalMetricSessions:
+	default:
+		return fmt.Errorf("%w: metric must be minutes or sessions", ErrInvalidGoal)
+	}
+	if goal.Target <= 0 || goal.Target > maxGoalTarget {
+		return fmt.Errorf("%w: target must be between 1 and %d", ErrInvalidGoal, maxGoalTarget)
+	}
+	if len(goal.DaysOfWeek) > 0 {
+		if goal.Period != GoalPeriodDaily {
+			return fmt.Errorf("%w: days_of_week is only allowed for daily goals", ErrInvalidGoal)
+		}
+		seen := make(map[int]bool)
+		days := make([]int, 0, len(goal.DaysOfWeek))
+		for _, d := range goal.DaysOfWeek {
+			if d < 1 || d > 7 {
+				return fmt.Errorf("%w: days_of_week values must be 1 (Monday) to 7 (Sunday)", ErrInvalidGoal)
+			}
+			if !seen[d] {
+				seen[d] = true
+				days = append(days, d)
+			}
+		}
+		sort.Ints(days)
+		goal.DaysOfWeek = days
+	} else {
+		goal.DaysOfWeek = nil
+	}
+	start, err := time.Parse(dateLayout, goal.StartDate)
+	if err != nil {
+		return fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidGoal)
+	}
+	if goal.EndDate != "" {
+		end, err := time.Parse(dateLayout, goal.EndDate)
+		if err != nil {
+			return fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrInvalidGoal)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidGoal)
+		}
+	}
+	return nil
+}
+
+// GetGoalProgress reports the running period and up to input.Periods past
+// periods. Days are bucketed in the requested timezone exactly like GetSummary:
+// weeks start on Monday and months on the 1st.
+func (s *service) GetGoalProgress(ctx context.Context, userID, goalID string, input GoalProgressInput) (*GoalProgressResponse, error) {
+	goal, err := s.GetGoal(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	loc := s.resolveLocation(input.Timezone)
+	now := time.Now().In(loc)
+	limit := input.Periods
+	if limit <= 0 {
+		limit = defaultGoalPeriods
+	}
+	limit = min(limit, maxGoalPeriods)
+
+	periods, current := s.goalPeriods(goal, now, limit, loc)
+	resp := &GoalProgressResponse{Goal: *goal, Timezone: loc.String(), History: make([]GoalPeriodProgress, 0, len(periods))}
+	if current == nil && len(periods) == 0 {
+		return resp, nil
+	}
+
+	from, to := now, now
+	if current != nil {
+		from, to = current[0], current[1]
+	}
+	for _, p := range periods {
+		from = minTime(from, p[0])
+		to = maxTime(to, p[1])
+	}
+	entries, err := s.repo.ListProductivities(ctx, userID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+
+	for _, p := range periods {
+		resp.History = append(resp.History, goalResult(goal, p[0], p[1], goalValue(goal, entries, p[0], p[1])))
+	}
+	if current != nil {
+		value := goalValue(goal, entries, current[0], current[1])
+		resp.Current = projectGoal(goalResult(goal, current[0], current[1], value), current[0], current[1], now)
+	}
+	return resp, nil
+}
+
+// goalPeriods returns up to limit completed periods (newest first) and the
+// running period, if any, as local [start, end) pairs.
+func (s *service) goalPeriods(goal *Goal, now time.Time, limit int, loc *time.Location) ([][2]time.Time, *[2]time.Time) {
+	activeFrom, _ := time.ParseInLocation(dateLayout, goal.StartDate, loc)
+	activeUntil := time.Time{} // exclusive; zero means open-ended
+	if goal.EndDate != "" {
+		end, _ := time.ParseInLocation(dateLayout, goal.EndDate, loc)
+		activeUntil = end.AddDate(0, 0, 1)
+	}
+	active := func(start, end time.Time) bool {
+		if !end.After(activeFrom) {
+			return false
+		}
+		if !activeUntil.IsZero() && !start.Before(activeUntil) {
+			return false
+		}
+		if goal.Period == GoalPeriodDaily && len(goal.DaysOfWeek) > 0 {
+			iso := mondayIndex(start.Weekday()) + 1
+			for _, d := range goal.DaysOfWeek {
+				if d == iso {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	}
+
+	var current *[2]time.Time
+	start, end := s.goalPeriodBounds(goal.Period, now)
+	if active(start, end) {
+		current = &[2]time.Time{start, end}
+	}
+
+	var past [][2]time.Time
+	for len(past) < limit && end.After(activeFrom) {
+		start, end = s.goalPeriodBounds(goal.Period, start.AddDate(0, 0, -1))
+		if !end.After(activeFrom) {
+			break
+		}
+		if active(start, end) {
+			past = append(past, [2]time.Time{start, end})
+		}
+	}
+	return past, current
+}
+
+// goalPeriodBounds returns the local [start, end) of the period containing t,
+// using the same week and month windows as GetSummary.
+func (s *service) goalPeriodBounds(period GoalPeriod, t time.Time) (time.Time, time.Time) {
+	rng := SummaryRangeWeek
+	switch period {
+	case GoalPeriodWeekly:
+	case GoalPeriodMonthly:
+		rng = SummaryRangeMonth
+	default:
+		start := truncateToDay(t)
+		return start, start.AddDate(0, 0, 1)
+	}
+	start, end, _ := s.summaryBounds(rng, t)
+	return start, end
+}
+
+func goalValue(goal *Goal, entries []ProductivityEntry, start, end time.Time) int {
+	value := 0
+	for _, entry := range entries {
+		if entry.StartTime.Before(start) || !entry.StartTime.Before(end) || !matchesCategory(entry, goal.Category) {
+			continue
+		}
+		if goal.Metric == GoalMetricSessions {
+			value++
+		} else {
+			value += entryMinutes(entry)
+		}
+	}
+	return value
+}
+
+func goalResult(goal *Goal, start, end time.Time, value int) GoalPeriodProgress {
+	return GoalPeriodProgress{
+		StartDate: start.Format(dateLayout),
+		EndDate:   end.AddDate(0, 0, -1).Format(dateLayout),
+		Value:     value,
+		Target:    goal.Target,
+		Percent:   math.Round(float64(value)/float64(goal.Target)*1000) / 10,
+		Completed: value >= goal.Target,
+	}
+}
+
+// projectGoal extrapolates the pace so far over the whole period.
+func projectGoal(p GoalPeriodProgress, start, end, now time.Time) *GoalCurrentProgress {
+	cur := &GoalCurrentProgress{GoalPeriodProgress: p, ProjectedValue: p.Value, OnTrack: p.Completed}
+	elapsed := now.Sub(start)
+	total := end.Sub(start)
+	if p.Completed || elapsed <= 0 || p.Value == 0 {
+		return cur
+	}
+	pace := float64(p.Value) / elapsed.Seconds() // per second
+	cur.ProjectedValue = int(math.Round(pace * total.Seconds()))
+	cur.OnTrack = cur.ProjectedValue >= p.Target
+	eta := now.Add(time.Duration(float64(p.Target-p.Value) / pace * float64(time.Second)))
+	if eta.Before(end) {
+		cur.ProjectedCompletionDate = eta.Format(dateLayout)
+	}
+	return cur
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
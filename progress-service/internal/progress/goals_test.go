@@ -0,0 +1,171 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeGoal(t *testing.T) {
+	valid := func() Goal {
+		return Goal{Title: "Deep work", Period: GoalPeriodDaily, Metric: GoalMetricMinutes, Target: 60, StartDate: "2025-11-01"}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(g *Goal)
+		wantErr bool
+	}{
+		{name: "Valid", mutate: func(g *Goal) {}},
+		{name: "Unknown period", mutate: func(g *Goal) { g.Period = "yearly" }, wantErr: true},
+		{name: "Unknown metric", mutate: func(g *Goal) { g.Metric = "pages" }, wantErr: true},
+		{name: "Zero target", mutate: func(g *Goal) { g.Target = 0 }, wantErr: true},
+		{name: "Bad start date", mutate: func(g *Goal) { g.StartDate = "01-11-2025" }, wantErr: true},
+		{name: "End before start", mutate: func(g *Goal) { g.EndDate = "2025-10-31" }, wantErr: true},
+		{name: "Day out of range", mutate: func(g *Goal) { g.DaysOfWeek = []int{0} }, wantErr: true},
+		{name: "Days on weekly goal", mutate: func(g *Goal) { g.Period = GoalPeriodWeekly; g.DaysOfWeek = []int{1} }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := valid()
+			tt.mutate(&g)
+			err := normalizeGoal(&g)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeGoal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidGoal) {
+				t.Errorf("error %v should wrap ErrInvalidGoal", err)
+			}
+		})
+	}
+
+	g := valid()
+	g.DaysOfWeek = []int{5, 1, 3, 1}
+	if err := normalizeGoal(&g); err != nil {
+		t.Fatalf("normalizeGoal() error = %v", err)
+	}
+	if !reflect.DeepEqual(g.DaysOfWeek, []int{1, 3, 5}) {
+		t.Errorf("DaysOfWeek = %v, want [1 3 5]", g.DaysOfWeek)
+	}
+}
+
+func TestGoalPeriods(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	svc := &service{loc: loc}
+	now := time.Date(2025, 11, 20, 10, 0, 0, 0, loc) // Thursday
+
+	dates := func(periods [][2]time.Time) []string {
+		out := make([]string, len(periods))
+		for i, p := range periods {
+			out[i] = p[0].Format(dateLayout)
+		}
+		return out
+	}
+
+	t.Run("Daily on selected weekdays", func(t *testing.T) {
+		goal := &Goal{Period: GoalPeriodDaily, StartDate: "2025-11-03", DaysOfWeek: []int{1, 3, 5}}
+		past, current := svc.goalPeriods(goal, now, 4, loc)
+		if current != nil {
+			t.Errorf("Thursday should not be a goal day, got %v", current)
+		}
+		want := []string{"2025-11-19", "2025-11-17", "2025-11-14", "2025-11-12"}
+		if got := dates(past); !reflect.DeepEqual(got, want) {
+			t.Errorf("past = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Weekly stops at start date", func(t *testing.T) {
+		goal := &Goal{Period: GoalPeriodWeekly, StartDate: "2025-11-05"}
+		past, current := svc.goalPeriods(goal, now, 4, loc)
+		if current == nil || current[0].Format(dateLayout) != "2025-11-17" {
+			t.Fatalf("current = %v, want week of 2025-11-17", current)
+		}
+		want := []string{"2025-11-10", "2025-11-03"}
+		if got := dates(past); !reflect.DeepEqual(got, want) {
+			t.Errorf("past = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Ended goal has no current period", func(t *testing.T) {
+		goal := &Goal{Period: GoalPeriodMonthly, StartDate: "2025-08-15", EndDate: "2025-10-10"}
+		past, current := svc.goalPeriods(goal, now, 12, loc)
+		if current != nil {
+			t.Errorf("current = %v, want nil", current)
+		}
+		want := []string{"2025-10-01", "2025-09-01", "2025-08-01"}
+		if got := dates(past); !reflect.DeepEqual(got, want) {
+			t.Errorf("past = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestProjectGoal(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	start := time.Date(2025, 11, 17, 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 7)
+	goal := &Goal{Target: 300}
+
+	cur := projectGoal(goalResult(goal, start, end, 100), start, end, start.AddDate(0, 0, 2))
+	if cur.ProjectedValue != 350 || !cur.OnTrack || cur.ProjectedCompletionDate != "2025-11-23" {
+		t.Errorf("on pace = %+v", cur)
+	}
+	if cur.Percent != 33.3 || cur.EndDate != "2025-11-23" {
+		t.Errorf("result = %+v", cur.GoalPeriodProgress)
+	}
+
+	cur = projectGoal(goalResult(goal, start, end, 50), start, end, start.AddDate(0, 0, 2))
+	if cur.ProjectedValue != 175 || cur.OnTrack || cur.ProjectedCompletionDate != "" {
+		t.Errorf("behind pace = %+v", cur)
+	}
+}
+
+func TestGetGoalProgress(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	today := truncateToDay(time.Now().In(loc))
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		{StartTime: today, TimeElapsed: 1200, Category: "Study"},
+		{StartTime: today.AddDate(0, 0, -1).Add(9 * time.Hour), TimeElapsed: 2400, Category: "Study"},
+		{StartTime: today.AddDate(0, 0, -1).Add(13 * time.Hour), TimeElapsed: 3000, Category: "Work"},
+	}}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	goal, err := svc.CreateGoal(ctx, "user-1", GoalInput{
+		Title:     "Study every day",
+		Period:    GoalPeriodDaily,
+		Target:    30,
+		Category:  "Study",
+		StartDate: today.AddDate(0, 0, -7).Format(dateLayout),
+		Timezone:  "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("CreateGoal() error = %v", err)
+	}
+	if goal.Metric != GoalMetricMinutes {
+		t.Errorf("metric = %q, want minutes by default", goal.Metric)
+	}
+
+	resp, err := svc.GetGoalProgress(ctx, "user-1", goal.ID, GoalProgressInput{Timezone: "Asia/Jakarta", Periods: 3})
+	if err != nil {
+		t.Fatalf("GetGoalProgress() error = %v", err)
+	}
+	if resp.Current == nil || resp.Current.Value != 20 || resp.Current.Completed {
+		t.Errorf("current = %+v", resp.Current)
+	}
+	if len(resp.History) != 3 {
+		t.Fatalf("history = %+v", resp.History)
+	}
+	if h := resp.History[0]; h.StartDate != today.AddDate(0, 0, -1).Format(dateLayout) || h.Value != 40 || !h.Completed {
+		t.Errorf("yesterday = %+v", h)
+	}
+	if h := resp.History[1]; h.Value != 0 || h.Completed {
+		t.Errorf("two days ago = %+v", h)
+	}
+
+	if _, err := svc.GetGoalProgress(ctx, "user-1", "missing", GoalProgressInput{}); !errors.Is(err, ErrGoalNotFound) {
+		t.Errorf("missing goal error = %v, want ErrGoalNotFound", err)
+	}
+}
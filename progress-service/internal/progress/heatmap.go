@@ -0,0 +1,139 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const heatmapTrailingDays = 365
+
+// GetHeatmap returns one cell per local day for the requested window. Intensity
+// levels are relative to the user's own active days, so a light user and a heavy
+// user both get a meaningful spread of colors.
+func (s *service) GetHeatmap(ctx context.Context, userID string, input HeatmapInput) (*HeatmapResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	loc := s.resolveLocation(input.Timezone)
+
+	var startLocal, endLocal time.Time // [start, end)
+	if input.Year > 0 {
+		startLocal = time.Date(input.Year, time.January, 1, 0, 0, 0, 0, loc)
+		endLocal = startLocal.AddDate(1, 0, 0)
+	} else {
+		end := input.EndDate
+		if end.IsZero() {
+			end = time.Now().In(loc)
+		}
+		// Date-only inputs arrive as UTC midnight; keep the calendar day, not the instant.
+		endLocal = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+		startLocal = endLocal.AddDate(0, 0, -heatmapTrailingDays)
+	}
+
+	// One range query for the whole window; everything else is in-memory bucketing.
+	entries, err := s.repo.ListProductivities(ctx, userID, startLocal.UTC(), endLocal.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+
+	type dayAgg struct {
+		minutes    int
+		sessions   int
+		categories map[string]int
+	}
+	days := make(map[string]*dayAgg)
+	for _, entry := range entries {
+		if entry.StartTime.IsZero() {
+			continue
+		}
+		key := entry.StartTime.In(loc).Format(dateLayout)
+		agg, ok := days[key]
+		if !ok {
+			agg = &dayAgg{categories: make(map[string]int)}
+			days[key] = agg
+		}
+		mins := entryMinutes(entry)
+		agg.minutes += mins
+		agg.sessions++
+		agg.categories[entry.Category] += mins
+	}
+
+	active := make([]int, 0, len(days))
+	for _, agg := range days {
+		active = append(active, agg.minutes)
+	}
+	thresholds := heatmapThresholds(active)
+
+	resp := &HeatmapResponse{
+		StartDate:       startLocal.Format(dateLayout),
+		EndDate:         endLocal.AddDate(0, 0, -1).Format(dateLayout),
+		Timezone:        loc.String(),
+		LevelThresholds: thresholds,
+		Cells:           make([]HeatmapCell, 0, heatmapTrailingDays+1),
+	}
+	for d := startLocal; d.Before(endLocal); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dateLayout)
+		cell := HeatmapCell{Date: key}
+		if agg, ok := days[key]; ok {
+			cell.Minutes = agg.minutes
+			cell.Sessions = agg.sessions
+			cell.DominantCategory = topKey(agg.categories)
+			cell.Level = heatmapLevel(agg.minutes, thresholds)
+
+			resp.TotalMinutes += agg.minutes
+			resp.TotalSessions += agg.sessions
+			resp.ActiveDays++
+			if agg.minutes > resp.MaxMinutes {
+				resp.MaxMinutes = agg.minutes
+			}
+		}
+		resp.Cells = append(resp.Cells, cell)
+	}
+	return resp, nil
+}
+
+// heatmapThresholds returns the 25th, 50th and 75th percentile of the given daily
+// minutes (nearest-rank). Days at or below thresholds[i] get level i+1; anything
+// above the last threshold gets level 4.
+func heatmapThresholds(dailyMinutes []int) []int {
+	if len(dailyMinutes) == 0 {
+		return []int{0, 0, 0}
+	}
+	sorted := append([]int(nil), dailyMinutes...)
+	sort.Ints(sorted)
+	quantile := func(p float64) int {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	return []int{quantile(0.25), quantile(0.50), quantile(0.75)}
+}
+
+func heatmapLevel(minutes int, thresholds []int) int {
+	for i, t := range thresholds {
+		if minutes <= t {
+			return i + 1
+		}
+	}
+	return len(thresholds) + 1
+}
+
+// dominantCategory returns the category with the most minutes; ties go to the
+// alphabetically first key so responses are stable. Empty keys are ignored.
+func topKey(counts map[string]int) string {
+	var (
+		best      string
+		bestValue = -1
+	)
+	for key, n := range counts {
+		if key == "" {
+			continue
+		}
+		if n > bestValue || (n == bestValue && key < best) {
+			best = key
+			bestValue = n
+		}
+	}
+	return best
+}
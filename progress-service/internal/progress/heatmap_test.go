@@ -0,0 +1,228 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeRepository serves productivities and recovery events from memory; other
+// methods are no-ops.
+type fakeRepository struct {
+	entries    []ProductivityEntry
+	recoveries []RecoveryEvent
+	goals      map[string]Goal
+	links      map[string]ShareLink
+}
+
+func (f *fakeRepository) GetDailySummaries(ctx context.Context, userID string, startDate, endDate time.Time, loc *time.Location) ([]*DailySummary, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) GetProgressStats(ctx context.Context, userID string, startDate, endDate time.Time) (*ProgressStats, error) {
+	return &ProgressStats{}, nil
+}
+
+func (f *fakeRepository) ListProductivities(ctx context.Context, userID string, startDate, endDate time.Time) ([]ProductivityEntry, error) {
+	var out []ProductivityEntry
+	for _, e := range f.entries {
+		if !e.StartTime.Before(startDate) && e.StartTime.Before(endDate) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) GetStreakState(ctx context.Context, userID string) (*StreakState, error) {
+	return nil, nil
+}
+
+func (f *fakeRepository) SetStreakState(ctx context.Context, userID string, state *StreakState) error {
+	return nil
+}
+
+func (f *fakeRepository) GetRecoveryQuota(ctx context.Context, userID string, yearMonth string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeRepository) RecoverStreak(ctx context.Context, userID string, yearMonth string, event *RecoveryEvent) (int, error) {
+	f.recoveries = append(f.recoveries, *event)
+	return 1, nil
+}
+
+func (f *fakeRepository) ListRecoveryEvents(ctx context.Context, userID string) ([]RecoveryEvent, error) {
+	return f.recoveries, nil
+}
+
+func (f *fakeRepository) CreateGoal(ctx context.Context, userID string, goal *Goal) error {
+	if f.goals == nil {
+		f.goals = make(map[string]Goal)
+	}
+	goal.ID = fmt.Sprintf("goal-%d", len(f.goals)+1)
+	f.goals[goal.ID] = *goal
+	return nil
+}
+
+func (f *fakeRepository) GetGoal(ctx context.Context, userID, goalID string) (*Goal, error) {
+	goal, ok := f.goals[goalID]
+	if !ok {
+		return nil, nil
+	}
+	return &goal, nil
+}
+
+func (f *fakeRepository) ListGoals(ctx context.Context, userID string) ([]Goal, error) {
+	var out []Goal
+	for _, g := range f.goals {
+		out = append(out, g)
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) UpdateGoal(ctx context.Context, userID string, goal *Goal) error {
+	f.goals[goal.ID] = *goal
+	return nil
+}
+
+func (f *fakeRepository) DeleteGoal(ctx context.Context, userID, goalID string) error {
+	delete(f.goals, goalID)
+	return nil
+}
+
+func (f *fakeRepository) CreateShareLink(ctx context.Context, link *ShareLink) error {
+	if f.links == nil {
+		f.links = make(map[string]ShareLink)
+	}
+	link.ID = fmt.Sprintf("share-%d", len(f.links)+1)
+	f.links[link.ID] = *link
+	return nil
+}
+
+func (f *fakeRepository) GetShareLink(ctx context.Context, linkID string) (*ShareLink, error) {
+	link, ok := f.links[linkID]
+	if !ok {
+		return nil, nil
+	}
+	return &link, nil
+}
+
+func (f *fakeRepository) ListShareLinks(ctx context.Context, userID string) ([]ShareLink, error) {
+	var out []ShareLink
+	for _, l := range f.links {
+		if l.UserID == userID {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) UpdateShareLink(ctx context.Context, link *ShareLink) error {
+	f.links[link.ID] = *link
+	return nil
+}
+
+func TestHeatmapThresholds(t *testing.T) {
+	tests := []struct {
+		name     string
+		minutes  []int
+		expected []int
+	}{
+		{name: "No active days", minutes: nil, expected: []int{0, 0, 0}},
+		{name: "Single day", minutes: []int{30}, expected: []int{30, 30, 30}},
+		{name: "Unsorted input", minutes: []int{50, 10, 40, 20, 30}, expected: []int{20, 30, 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := heatmapThresholds(tt.minutes)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("heatmapThresholds() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHeatmapLevel(t *testing.T) {
+	thresholds := []int{20, 30, 40}
+	tests := []struct {
+		minutes  int
+		expected int
+	}{
+		{minutes: 5, expected: 1},
+		{minutes: 20, expected: 1},
+		{minutes: 25, expected: 2},
+		{minutes: 40, expected: 3},
+		{minutes: 41, expected: 4},
+	}
+
+	for _, tt := range tests {
+		if got := heatmapLevel(tt.minutes, thresholds); got != tt.expected {
+			t.Errorf("heatmapLevel(%d) = %d, want %d", tt.minutes, got, tt.expected)
+		}
+	}
+}
+
+func TestTopKey(t *testing.T) {
+	if got := topKey(map[string]int{"Work": 30, "Study": 30, "": 90}); got != "Study" {
+		t.Errorf("topKey() = %q, want %q", got, "Study")
+	}
+	if got := topKey(map[string]int{}); got != "" {
+		t.Errorf("topKey() = %q, want empty", got)
+	}
+}
+
+func TestGetHeatmap(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		// 23:30 UTC on Dec 31 is Jan 1 in Jakarta.
+		{StartTime: time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC), TimeElapsed: 1800, Category: "Work"},
+		{StartTime: time.Date(2025, 1, 1, 3, 0, 0, 0, loc), TimeElapsed: 600, Category: "Study"},
+		{StartTime: time.Date(2025, 3, 10, 9, 0, 0, 0, loc), TimeElapsed: 3600, Category: "Study"},
+		{StartTime: time.Date(2026, 1, 1, 9, 0, 0, 0, loc), TimeElapsed: 3600, Category: "Study"},
+	}}
+	svc := NewService(repo)
+
+	resp, err := svc.GetHeatmap(context.Background(), "user-1", HeatmapInput{Year: 2025, Timezone: "Asia/Jakarta"})
+	if err != nil {
+		t.Fatalf("GetHeatmap() error = %v", err)
+	}
+
+	if len(resp.Cells) != 365 {
+		t.Fatalf("len(cells) = %d, want 365", len(resp.Cells))
+	}
+	if resp.StartDate != "2025-01-01" || resp.EndDate != "2025-12-31" {
+		t.Errorf("window = %s..%s, want 2025-01-01..2025-12-31", resp.StartDate, resp.EndDate)
+	}
+	if resp.ActiveDays != 2 || resp.TotalSessions != 3 || resp.TotalMinutes != 100 || resp.MaxMinutes != 60 {
+		t.Errorf("totals = %+v", resp)
+	}
+
+	first := resp.Cells[0]
+	if first.Minutes != 40 || first.Sessions != 2 || first.DominantCategory != "Work" || first.Level != 1 {
+		t.Errorf("Jan 1 cell = %+v", first)
+	}
+	if empty := resp.Cells[1]; empty.Level != 0 || empty.Minutes != 0 {
+		t.Errorf("Jan 2 cell = %+v, want empty", empty)
+	}
+}
+
+func TestGetHeatmapTrailingWindow(t *testing.T) {
+	svc := NewService(&fakeRepository{})
+
+	resp, err := svc.GetHeatmap(context.Background(), "user-1", HeatmapInput{
+		EndDate:  time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC),
+		Timezone: "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("GetHeatmap() error = %v", err)
+	}
+	if resp.EndDate != "2025-11-20" || resp.StartDate != "2024-11-21" || len(resp.Cells) != 365 {
+		t.Errorf("window = %s..%s (%d cells)", resp.StartDate, resp.EndDate, len(resp.Cells))
+	}
+
+	if _, err := svc.GetHeatmap(context.Background(), " ", HeatmapInput{}); err != ErrMissingUserID {
+		t.Errorf("GetHeatmap() with blank user error = %v, want ErrMissingUserID", err)
+	}
+}
@@ -0,0 +1,69 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/focusnest/shared-libs/insights"
+)
+
+const (
+	// insightWindowDays is how far back the insight rules look.
+	insightWindowDays   = 28
+	defaultInsightLimit = 5
+	maxInsightLimit     = 20
+)
+
+// GetInsights runs the insight rules over the last insightWindowDays of sessions
+// (today included) in the requested timezone and returns them ranked.
+func (s *service) GetInsights(ctx context.Context, userID string, input InsightsInput) (*InsightsResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	lang, err := insights.NormalizeLang(input.Lang)
+	if err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultInsightLimit
+	}
+	limit = min(limit, maxInsightLimit)
+
+	loc := s.resolveLocation(input.Timezone)
+	now := time.Now().In(loc)
+	today := truncateToDay(now)
+	from := today.AddDate(0, 0, -(insightWindowDays - 1))
+	entries, err := s.repo.ListProductivities(ctx, userID, from.UTC(), today.AddDate(0, 0, 1).UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+
+	data := &insights.Data{Since: from, Now: now, Sessions: make([]insights.Session, 0, len(entries))}
+	for _, entry := range entries {
+		if entry.StartTime.IsZero() {
+			continue
+		}
+		data.Sessions = append(data.Sessions, insights.Session{
+			Start:    entry.StartTime.In(loc),
+			Minutes:  entryMinutes(entry),
+			Category: entry.Category,
+			Mood:     entry.Mood,
+			TimeMode: entry.TimeMode,
+		})
+	}
+
+	found := s.insights.Generate(data, lang, limit)
+	if found == nil {
+		found = []insights.Insight{}
+	}
+	return &InsightsResponse{
+		Timezone: loc.String(),
+		Lang:     lang,
+		From:     from.Format(dateLayout),
+		To:       today.Format(dateLayout),
+		Insights: found,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/insights"
+)
+
+func insightEntries(today time.Time) []ProductivityEntry {
+	at := func(daysAgo, hour int) time.Time {
+		return today.AddDate(0, 0, -daysAgo).Add(time.Duration(hour) * time.Hour)
+	}
+	return []ProductivityEntry{
+		// Previous week: Study 120 min, Work 60 min.
+		{StartTime: at(12, 8), TimeElapsed: 2400, Category: "Study"},
+		{StartTime: at(11, 8), TimeElapsed: 2400, Category: "Study"},
+		{StartTime: at(10, 8), TimeElapsed: 2400, Category: "Study"},
+		{StartTime: at(9, 23), TimeElapsed: 1800, Category: "Work", Mood: "Capek"},
+		{StartTime: at(8, 23), TimeElapsed: 1800, Category: "Work", Mood: "Capek"},
+		// This week: Study 70 min, Work 60 min.
+		{StartTime: at(5, 8), TimeElapsed: 2100, Category: "Study"},
+		{StartTime: at(4, 8), TimeElapsed: 2100, Category: "Study"},
+		{StartTime: at(3, 23), TimeElapsed: 1800, Category: "Work", Mood: "Capek"},
+		{StartTime: at(2, 23), TimeElapsed: 1800, Category: "Work", Mood: "Capek"},
+	}
+}
+
+func TestGetInsights(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	today := truncateToDay(time.Now().In(loc))
+	svc := NewService(&fakeRepository{entries: insightEntries(today)})
+
+	resp, err := svc.GetInsights(context.Background(), "user-1", InsightsInput{Timezone: "Asia/Jakarta"})
+	if err != nil {
+		t.Fatalf("GetInsights() error = %v", err)
+	}
+	want := []string{
+		"Sessions after 22:00 are mostly tagged Capek (100%)",
+		"Study time dropped 42% vs last week",
+		"Your focus time dropped 28% vs last week",
+		"You focus 27% longer in the morning than at other times of day",
+	}
+	if len(resp.Insights) != len(want) {
+		t.Fatalf("insights = %+v", resp.Insights)
+	}
+	for i, w := range want {
+		if resp.Insights[i].Message != w {
+			t.Errorf("insights[%d] = %q, want %q", i, resp.Insights[i].Message, w)
+		}
+	}
+	if got := resp.Insights[0]; got.Rule != "late_night_mood" || got.Confidence != 0.5 || got.Score != 0.5 {
+		t.Errorf("late night insight = %+v", got)
+	}
+	if resp.From != today.AddDate(0, 0, -27).Format(dateLayout) || resp.Lang != insights.LangEN {
+		t.Errorf("response = %+v", resp)
+	}
+
+	resp, err = svc.GetInsights(context.Background(), "user-1", InsightsInput{Timezone: "Asia/Jakarta", Lang: "ID", Limit: 2})
+	if err != nil {
+		t.Fatalf("GetInsights(id) error = %v", err)
+	}
+	if len(resp.Insights) != 2 || resp.Insights[1].Message != "Waktu Study turun 42% dibanding minggu lalu" {
+		t.Errorf("localized insights = %+v", resp.Insights)
+	}
+
+	if _, err := svc.GetInsights(context.Background(), "user-1", InsightsInput{Lang: "fr"}); !errors.Is(err, insights.ErrUnsupportedLang) {
+		t.Errorf("unsupported lang error = %v", err)
+	}
+}
+
+type fixedRule struct{}
+
+func (fixedRule) ID() string { return "fixed" }
+
+func (fixedRule) Evaluate(*insights.Data) []insights.Insight {
+	return []insights.Insight{{Key: insights.KeyTotalUp, Args: []any{10}, Confidence: 1.5, Score: 0.3}}
+}
+
+func TestGetInsightsCustomRules(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	svc := &service{repo: &fakeRepository{}, loc: loc, insights: insights.NewEngine(fixedRule{})}
+
+	resp, err := svc.GetInsights(context.Background(), "user-1", InsightsInput{})
+	if err != nil {
+		t.Fatalf("GetInsights() error = %v", err)
+	}
+	if len(resp.Insights) != 1 {
+		t.Fatalf("insights = %+v", resp.Insights)
+	}
+	got := resp.Insights[0]
+	if got.Rule != "fixed" || got.Confidence != 1 || got.Message != "Your focus time rose 10% vs last week" {
+		t.Errorf("insight = %+v", got)
+	}
+}
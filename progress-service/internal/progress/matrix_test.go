@@ -0,0 +1,73 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetMatrix(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		// Tuesday 09:40–10:40 with 30 focused minutes: one third lands in 09:00.
+		{
+			StartTime:   time.Date(2025, 11, 18, 9, 40, 0, 0, loc),
+			EndTime:     time.Date(2025, 11, 18, 10, 40, 0, 0, loc),
+			TimeElapsed: 1800,
+			Category:    "Work",
+		},
+		// Sunday 22:00 without an end time falls back to TimeElapsed.
+		{
+			StartTime:   time.Date(2025, 11, 23, 22, 0, 0, 0, loc),
+			TimeElapsed: 900,
+			Category:    "Study",
+		},
+	}}
+	svc := NewService(repo)
+
+	resp, err := svc.GetMatrix(context.Background(), "user-1", SummaryInput{
+		Range:         SummaryRangeWeek,
+		ReferenceDate: time.Date(2025, 11, 20, 0, 0, 0, 0, loc),
+		Timezone:      "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("GetMatrix() error = %v", err)
+	}
+
+	if len(resp.Rows) != 7 || resp.Rows[0].Weekday != "Monday" || resp.Rows[6].Weekday != "Sunday" {
+		t.Fatalf("unexpected rows: %d, first %q", len(resp.Rows), resp.Rows[0].Weekday)
+	}
+	tue := resp.Rows[1]
+	if tue.Hours[9].Minutes != 10 || tue.Hours[10].Minutes != 20 {
+		t.Errorf("Tuesday 09/10 minutes = %v/%v, want 10/20", tue.Hours[9].Minutes, tue.Hours[10].Minutes)
+	}
+	if tue.Hours[9].Sessions != 1 || tue.Hours[10].Sessions != 0 {
+		t.Errorf("Tuesday sessions = %d/%d, want 1/0", tue.Hours[9].Sessions, tue.Hours[10].Sessions)
+	}
+	if got := resp.Rows[6].Hours[22].Minutes; got != 15 {
+		t.Errorf("Sunday 22:00 minutes = %v, want 15", got)
+	}
+	if resp.TotalMinutes != 45 || resp.TotalSessions != 2 {
+		t.Errorf("totals = %v minutes / %d sessions", resp.TotalMinutes, resp.TotalSessions)
+	}
+	if resp.PeakWeekday != "Tuesday" || resp.PeakHour == nil || *resp.PeakHour != 10 {
+		t.Errorf("peak = %q %v", resp.PeakWeekday, resp.PeakHour)
+	}
+
+	filtered, err := svc.GetMatrix(context.Background(), "user-1", SummaryInput{
+		Range:         SummaryRangeWeek,
+		Category:      "study",
+		ReferenceDate: time.Date(2025, 11, 20, 0, 0, 0, 0, loc),
+		Timezone:      "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("GetMatrix() error = %v", err)
+	}
+	if filtered.TotalSessions != 1 || filtered.TotalMinutes != 15 {
+		t.Errorf("filtered totals = %v minutes / %d sessions", filtered.TotalMinutes, filtered.TotalSessions)
+	}
+
+	if _, err := svc.GetMatrix(context.Background(), "user-1", SummaryInput{Range: "decade"}); err != ErrInvalidSummaryRange {
+		t.Errorf("GetMatrix() with bad range error = %v, want ErrInvalidSummaryRange", err)
+	}
+}
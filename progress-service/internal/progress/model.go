@@ -3,6 +3,8 @@ package progress
 import (
 	"context"
 	"time"
+
+	"github.com/focusnest/shared-libs/insights"
 )
 
 // DailySummary represents a daily progress summary
@@ -43,6 +45,7 @@ type StreakData struct {
 	ExpiredAt              string      `json:"expired_at,omitempty"`       // YYYY-MM-DD; when streak expired
 	RecoveryUsedThisMonth  int         `json:"recovery_used_this_month"`  // premium: recoveries used in current month
 	RecoveryQuotaPerMonth  int         `json:"recovery_quota_per_month"`  // premium: 5
+	Category               string      `json:"category,omitempty"`         // set for per-category streaks
 }
 
 // StreakState is persisted per user for expired/grace and recovery override.
@@ -64,6 +67,16 @@ type RecoveryQuota struct {
 	UpdatedAt time.Time `firestore:"updated_at"`
 }
 
+// RecoveryEvent records one streak recovery. ExpiredAt is the first missed day
+// the recovery covered, in the timezone used when recovering.
+type RecoveryEvent struct {
+	UserID      string    `firestore:"user_id" json:"-"`
+	ExpiredAt   string    `firestore:"expired_at" json:"expired_at"`
+	StreakValue int       `firestore:"streak_value" json:"streak_value"` // streak length that was saved
+	Timezone    string    `firestore:"timezone" json:"-"`
+	RecoveredAt time.Time `firestore:"recovered_at" json:"recovered_at"`
+}
+
 // SummaryRange represents the supported summary windows.
 type SummaryRange string
 
@@ -101,12 +114,346 @@ type SummaryResponse struct {
 	MostProductiveHourEnd   *time.Time      `json:"most_productive_hour_end"`
 }
 
+// HeatmapInput captures query parameters for the activity heatmap.
+// When Year is set the window is that calendar year, otherwise it is the
+// trailing 365 days ending on EndDate (defaults to today).
+type HeatmapInput struct {
+	Year     int
+	EndDate  time.Time
+	Timezone string
+}
+
+// HeatmapCell is a single local day in the activity heatmap.
+type HeatmapCell struct {
+	Date             string `json:"date"` // YYYY-MM-DD
+	Minutes          int    `json:"minutes"`
+	Sessions         int    `json:"sessions"`
+	DominantCategory string `json:"dominant_category,omitempty"`
+	Level            int    `json:"level"` // 0 (no activity) … 4 (top quartile)
+}
+
+// HeatmapResponse is returned by the heatmap endpoint.
+type HeatmapResponse struct {
+	StartDate       string        `json:"start_date"`
+	EndDate         string        `json:"end_date"`
+	Timezone        string        `json:"timezone"`
+	TotalMinutes    int           `json:"total_minutes"`
+	TotalSessions   int           `json:"total_sessions"`
+	ActiveDays      int           `json:"active_days"`
+	MaxMinutes      int           `json:"max_minutes"`
+	LevelThresholds []int         `json:"level_thresholds"` // inclusive upper bounds (minutes) for levels 1-3
+	Cells           []HeatmapCell `json:"cells"`
+}
+
+// MatrixCell is one weekday/hour slot of the productivity matrix.
+type MatrixCell struct {
+	Minutes  float64 `json:"minutes"`
+	Sessions int     `json:"sessions"`
+}
+
+// MatrixRow holds the 24 hourly cells (00–23, local time) for one weekday.
+type MatrixRow struct {
+	Weekday string       `json:"weekday"`
+	Hours   []MatrixCell `json:"hours"`
+}
+
+// MatrixResponse is a weekday × hour-of-day breakdown of focus time. Rows are
+// ordered Monday to Sunday.
+type MatrixResponse struct {
+	Range         SummaryRange `json:"range"`
+	StartDate     string       `json:"start_date"`
+	EndDate       string       `json:"end_date"`
+	Timezone      string       `json:"timezone"`
+	Category      string       `json:"category,omitempty"`
+	TotalMinutes  float64      `json:"total_minutes"`
+	TotalSessions int          `json:"total_sessions"`
+	PeakWeekday   string       `json:"peak_weekday,omitempty"`
+	PeakHour      *int         `json:"peak_hour,omitempty"`
+	Rows          []MatrixRow  `json:"rows"`
+}
+
+// MoodStat aggregates the sessions tagged with one mood.
+type MoodStat struct {
+	Mood              string  `json:"mood"`
+	Sessions          int     `json:"sessions"`
+	Minutes           int     `json:"minutes"`
+	Share             float64 `json:"share"` // fraction of tagged sessions
+	AvgSessionMinutes float64 `json:"avg_session_minutes"`
+}
+
+// MoodTimelineBucket holds focused seconds per mood for one summary bucket
+// (same labels as SummaryResponse.TimeDistribution).
+type MoodTimelineBucket struct {
+	Label       string         `json:"label"`
+	TimeElapsed map[string]int `json:"time_elapsed"`
+}
+
+// MoodTimeOfDay counts sessions per mood within a part of the day.
+type MoodTimeOfDay struct {
+	Segment      string         `json:"segment"`
+	Sessions     map[string]int `json:"sessions"`
+	DominantMood string         `json:"dominant_mood,omitempty"`
+}
+
+// MoodCount pairs a mood with a count.
+type MoodCount struct {
+	Mood  string `json:"mood"`
+	Count int    `json:"count"`
+}
+
+// MoodResponse is returned by the mood analytics endpoint.
+type MoodResponse struct {
+	Range             SummaryRange         `json:"range"`
+	StartDate         string               `json:"start_date"`
+	EndDate           string               `json:"end_date"`
+	Timezone          string               `json:"timezone"`
+	Category          string               `json:"category,omitempty"`
+	TotalSessions     int                  `json:"total_sessions"`
+	UntaggedSessions  int                  `json:"untagged_sessions"`
+	Distribution      []MoodStat           `json:"distribution"`
+	Timeline          []MoodTimelineBucket `json:"timeline"`
+	TimeOfDay         []MoodTimeOfDay      `json:"time_of_day"`
+	StreakBreaks      int                  `json:"streak_breaks"`
+	BeforeStreakBreak []MoodCount     `json:"before_streak_break"`
+}
+
+// TimeModeCategoryStat breaks a time mode down by category.
+type TimeModeCategoryStat struct {
+	Category     string  `json:"category"`
+	Sessions     int     `json:"sessions"`
+	TotalMinutes int     `json:"total_minutes"`
+	AvgMinutes   float64 `json:"avg_minutes"`
+	AvgCycles    float64 `json:"avg_cycles"`
+}
+
+// TimeModeStat describes how a user performs with one time mode. Consistency is
+// the share of sessions that reached at least 80% of the mode's median length.
+type TimeModeStat struct {
+	TimeMode         string                 `json:"time_mode"`
+	Sessions         int                    `json:"sessions"`
+	TotalMinutes     int                    `json:"total_minutes"`
+	AvgMinutes       float64                `json:"avg_minutes"`
+	AvgCycles        float64                `json:"avg_cycles"`
+	Consistency      float64                `json:"consistency"`
+	TypicalTimeOfDay string                 `json:"typical_time_of_day"`
+	PeakHour         int                    `json:"peak_hour"`
+	TopMoods         []MoodCount            `json:"top_moods"`
+	Categories       []TimeModeCategoryStat `json:"categories"`
+}
+
+// TimeModeReport compares time modes over a summary window.
+type TimeModeReport struct {
+	Range         SummaryRange   `json:"range"`
+	StartDate     string         `json:"start_date"`
+	EndDate       string         `json:"end_date"`
+	Timezone      string         `json:"timezone"`
+	Category      string         `json:"category,omitempty"`
+	TotalSessions int            `json:"total_sessions"`
+	Modes         []TimeModeStat `json:"modes"`
+}
+
+// Record is a personal best. Date is when it was achieved: the session or day,
+// the Monday of the best week, the 1st of the best month, or the last day of the
+// longest streak.
+type Record struct {
+	Value   int    `json:"value"`
+	Unit    string `json:"unit"` // minutes | cycles | days
+	Date    string `json:"date"`
+	EntryID string `json:"entry_id,omitempty"`
+}
+
+// RecordSet groups the personal bests for one scope (all-time or a category).
+// Records are nil until there is data for them.
+type RecordSet struct {
+	LongestSession   *Record `json:"longest_session,omitempty"`
+	MostMinutesInDay *Record `json:"most_minutes_in_day,omitempty"`
+	BestWeek         *Record `json:"best_week,omitempty"`
+	BestMonth        *Record `json:"best_month,omitempty"`
+	MostCyclesInDay  *Record `json:"most_cycles_in_day,omitempty"`
+	LongestStreak    *Record `json:"longest_streak,omitempty"`
+}
+
+// RecordsResponse is returned by the records endpoint.
+type RecordsResponse struct {
+	Timezone   string               `json:"timezone"`
+	All        RecordSet            `json:"all"`
+	Categories map[string]RecordSet `json:"categories"`
+}
+
+// BrokenRecord describes a record set by a specific entry.
+type BrokenRecord struct {
+	Scope    string  `json:"scope"` // "all" or the category name
+	Record   string  `json:"record"`
+	Previous *Record `json:"previous,omitempty"`
+	Current  Record  `json:"current"`
+}
+
+// RecordCheckResponse lists the records a new entry broke.
+type RecordCheckResponse struct {
+	EntryID string         `json:"entry_id"`
+	Broken  []BrokenRecord `json:"broken"`
+}
+
+// RecapInput selects the week for a recap card.
+type RecapInput struct {
+	ReferenceDate time.Time
+	Timezone      string
+}
+
+// RecapCategory is one of the top categories on a recap card.
+type RecapCategory struct {
+	Category string `json:"category"`
+	Minutes  int    `json:"minutes"`
+}
+
+// RecapDay is one bar of the recap's daily chart.
+type RecapDay struct {
+	Date    string `json:"date"`
+	Weekday int    `json:"weekday"` // 0 = Monday … 6 = Sunday
+	Minutes int    `json:"minutes"`
+}
+
+// Recap is the data behind the shareable weekly recap card.
+type Recap struct {
+	WeekStart     string          `json:"week_start"`
+	WeekEnd       string          `json:"week_end"`
+	Timezone      string          `json:"timezone"`
+	TotalMinutes  int             `json:"total_minutes"`
+	TotalSessions int             `json:"total_sessions"`
+	CurrentStreak int             `json:"current_streak"`
+	TopCategories []RecapCategory `json:"top_categories"`
+	Days          []RecapDay      `json:"days"`
+	BestHour      *int            `json:"best_hour,omitempty"` // local hour, 0–23
+}
+
+// How a historical streak ended.
+const (
+	StreakEndedOngoing   = "ongoing"
+	StreakEndedExpired   = "expired"
+	StreakEndedRecovered = "recovered" // recovered, but activity did not resume within grace
+)
+
+// StreakRun is one streak in the user's history. Length counts active days;
+// days bridged by a recovery are not counted.
+type StreakRun struct {
+	StartDate  string          `json:"start_date"`
+	EndDate    string          `json:"end_date"`
+	Length     int             `json:"length"`
+	EndedBy    string          `json:"ended_by"`
+	Recoveries []RecoveryEvent `json:"recoveries"`
+}
+
+// StreakHistoryResponse lists every streak, newest first.
+type StreakHistoryResponse struct {
+	Timezone      string      `json:"timezone"`
+	LongestStreak int         `json:"longest_streak"`
+	Streaks       []StreakRun `json:"streaks"`
+}
+
+// GoalPeriod is how often a goal resets.
+type GoalPeriod string
+
+const (
+	GoalPeriodDaily   GoalPeriod = "daily"
+	GoalPeriodWeekly  GoalPeriod = "weekly"
+	GoalPeriodMonthly GoalPeriod = "monthly"
+)
+
+// GoalMetric is what a goal counts.
+type GoalMetric string
+
+const (
+	GoalMetricMinutes  GoalMetric = "minutes"
+	GoalMetricSessions GoalMetric = "sessions"
+)
+
+// Goal is a user's focus target, e.g. 600 minutes of Study per week, or 60
+// minutes every weekday (daily with DaysOfWeek 1–5). StartDate and EndDate bound
+// the periods the goal is active for; EndDate is optional.
+type Goal struct {
+	ID         string     `json:"id" firestore:"-"`
+	Title      string     `json:"title" firestore:"title"`
+	Period     GoalPeriod `json:"period" firestore:"period"`
+	Metric     GoalMetric `json:"metric" firestore:"metric"`
+	Target     int        `json:"target" firestore:"target"`
+	Category   string     `json:"category,omitempty" firestore:"category"`
+	DaysOfWeek []int      `json:"days_of_week,omitempty" firestore:"days_of_week"` // ISO 1=Mon … 7=Sun; daily goals only
+	StartDate  string     `json:"start_date" firestore:"start_date"`
+	EndDate    string     `json:"end_date,omitempty" firestore:"end_date"`
+	CreatedAt  time.Time  `json:"created_at" firestore:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" firestore:"updated_at"`
+}
+
+// GoalInput carries the fields to create a goal. StartDate defaults to today in
+// Timezone.
+type GoalInput struct {
+	Title      string
+	Period     GoalPeriod
+	Metric     GoalMetric
+	Target     int
+	Category   string
+	DaysOfWeek []int
+	StartDate  string
+	EndDate    string
+	Timezone   string
+}
+
+// GoalPatch updates a goal; nil fields are left unchanged. An empty EndDate or
+// Category clears it.
+type GoalPatch struct {
+	Title      *string
+	Target     *int
+	Category   *string
+	DaysOfWeek *[]int
+	StartDate  *string
+	EndDate    *string
+}
+
+// GoalProgressInput selects how much history GetGoalProgress returns.
+type GoalProgressInput struct {
+	Timezone string
+	Periods  int // past periods to include; default 4
+}
+
+// GoalPeriodProgress is the result for one goal period.
+type GoalPeriodProgress struct {
+	StartDate string  `json:"start_date"`
+	EndDate   string  `json:"end_date"`
+	Value     int     `json:"value"`
+	Target    int     `json:"target"`
+	Percent   float64 `json:"percent"`
+	Completed bool    `json:"completed"`
+}
+
+// GoalCurrentProgress adds a pace-based projection to the running period.
+type GoalCurrentProgress struct {
+	GoalPeriodProgress
+	ProjectedValue          int    `json:"projected_value"`
+	OnTrack                 bool   `json:"on_track"`
+	ProjectedCompletionDate string `json:"projected_completion_date,omitempty"`
+}
+
+// GoalProgressResponse is returned by the goal progress endpoint. Current is nil
+// when no period is running today (outside the goal's dates or an unscheduled
+// weekday); History is newest first.
+type GoalProgressResponse struct {
+	Goal     Goal                 `json:"goal"`
+	Timezone string               `json:"timezone"`
+	Current  *GoalCurrentProgress `json:"current"`
+	History  []GoalPeriodProgress `json:"history"`
+}
+
 // ProductivityEntry represents a raw productivity session used for analytics.
 type ProductivityEntry struct {
+	ID          string
 	StartTime   time.Time
 	EndTime     time.Time
 	TimeElapsed int
 	Category    string
+	Mood        string
+	TimeMode    string
+	NumCycle    int
+	Timezone    string // timezone the session was recorded in; empty for older entries
 }
 
 // MonthlyStreakData represents monthly streak data
@@ -116,6 +463,7 @@ type MonthlyStreakData struct {
 	TotalStreak   int         `json:"total_streak"`
 	CurrentStreak int         `json:"current_streak"`
 	Days          []DayStatus `json:"days"`
+	Category      string      `json:"category,omitempty"`
 }
 
 // WeeklyStreakData represents weekly streak data
@@ -124,6 +472,26 @@ type WeeklyStreakData struct {
 	TotalStreak   int         `json:"total_streak"`
 	CurrentStreak int         `json:"current_streak"`
 	Days          []DayStatus `json:"days"`
+	Category      string      `json:"category,omitempty"`
+}
+
+// CategoryStreak is the streak for a single category. Per-category streaks
+// follow the same expiry and grace rules as the global streak but cannot be
+// recovered.
+type CategoryStreak struct {
+	Category       string `json:"category"`
+	CurrentStreak  int    `json:"current_streak"`
+	LongestStreak  int    `json:"longest_streak"`
+	Status         string `json:"status"` // active | grace | expired
+	GraceEndsAt    string `json:"grace_ends_at,omitempty"`
+	ExpiredAt      string `json:"expired_at,omitempty"`
+	LastActiveDate string `json:"last_active_date"`
+}
+
+// CategoryStreaksResponse lists every category the user has been active in.
+type CategoryStreaksResponse struct {
+	Timezone   string           `json:"timezone"`
+	Categories []CategoryStreak `json:"categories"`
 }
 
 // DayStatus represents the status of a single day
@@ -133,23 +501,129 @@ type DayStatus struct {
 	Status string `json:"status"` // active, skipped, upcoming
 }
 
+// InsightsInput selects the language and number of insights to return.
+type InsightsInput struct {
+	Timezone string
+	Lang     string // en | id; default en
+	Limit    int    // default 5
+}
+
+// InsightsResponse is the ranked list of insights for the last four weeks.
+type InsightsResponse struct {
+	Timezone string             `json:"timezone"`
+	Lang     string             `json:"lang"`
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Insights []insights.Insight `json:"insights"`
+}
+
+// ShareLink is a public, read-only link to a progress snapshot. Links live in
+// the top-level share_links collection so they can be resolved from a token
+// alone.
+type ShareLink struct {
+	ID                string       `json:"id" firestore:"-"`
+	UserID            string       `json:"-" firestore:"user_id"`
+	Range             SummaryRange `json:"range" firestore:"range"`
+	ReferenceDate     string       `json:"reference_date" firestore:"reference_date"` // YYYY-MM-DD; the shared period contains it
+	IncludeCategories bool         `json:"include_categories" firestore:"include_categories"`
+	Timezone          string       `json:"timezone" firestore:"timezone"`
+	CreatedAt         time.Time    `json:"created_at" firestore:"created_at"`
+	ExpiresAt         time.Time    `json:"expires_at" firestore:"expires_at"`
+	RevokedAt         *time.Time   `json:"revoked_at,omitempty" firestore:"revoked_at"`
+}
+
+// ShareLinkInput carries the options for a new share link. ReferenceDate
+// defaults to today and ExpiresInDays to 30 (max 90).
+type ShareLinkInput struct {
+	Range             SummaryRange
+	ReferenceDate     time.Time
+	IncludeCategories bool
+	ExpiresInDays     int
+	Timezone          string
+}
+
+// ShareLinkResponse is returned once, on creation; the token is not stored.
+type ShareLinkResponse struct {
+	ShareLink
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
+
+// ShareCategory is the focused time of one category in a shared snapshot.
+type ShareCategory struct {
+	Category    string `json:"category"`
+	TimeElapsed int    `json:"time_elapsed"` // seconds
+}
+
+// ShareSnapshot is the public view of a share link. It only carries totals;
+// activity names, moods and the owner's identity are never exposed.
+type ShareSnapshot struct {
+	Range         SummaryRange    `json:"range"`
+	From          string          `json:"from"`
+	To            string          `json:"to"`
+	Timezone      string          `json:"timezone"`
+	TimeElapsed   int             `json:"time_elapsed"` // seconds
+	Sessions      int             `json:"sessions"`
+	ActiveDays    int             `json:"active_days"`
+	CurrentStreak int             `json:"current_streak"`
+	Categories    []ShareCategory `json:"categories,omitempty"`
+	ExpiresAt     time.Time       `json:"expires_at"`
+}
+
 // Repository defines the interface for progress data access
 type Repository interface {
-	GetDailySummaries(ctx context.Context, userID string, startDate, endDate time.Time) ([]*DailySummary, error)
+	// GetDailySummaries buckets sessions into local days. Each session uses the
+	// timezone it was recorded in, falling back to loc for older entries.
+	GetDailySummaries(ctx context.Context, userID string, startDate, endDate time.Time, loc *time.Location) ([]*DailySummary, error)
 	GetProgressStats(ctx context.Context, userID string, startDate, endDate time.Time) (*ProgressStats, error)
 	ListProductivities(ctx context.Context, userID string, startDate, endDate time.Time) ([]ProductivityEntry, error)
 	GetStreakState(ctx context.Context, userID string) (*StreakState, error)
 	SetStreakState(ctx context.Context, userID string, state *StreakState) error
 	GetRecoveryQuota(ctx context.Context, userID string, yearMonth string) (int, error)
-	IncrementRecoveryQuota(ctx context.Context, userID string, yearMonth string) (int, error)
+	// RecoverStreak atomically spends a recovery from the yearMonth quota,
+	// sets the state override for event.ExpiredAt and stores event. It
+	// returns the month's new count, ErrStreakNotRecoverable when the state
+	// no longer matches, or ErrRecoveryQuotaExceeded.
+	RecoverStreak(ctx context.Context, userID string, yearMonth string, event *RecoveryEvent) (int, error)
+	ListRecoveryEvents(ctx context.Context, userID string) ([]RecoveryEvent, error)
+	CreateGoal(ctx context.Context, userID string, goal *Goal) error
+	GetGoal(ctx context.Context, userID, goalID string) (*Goal, error)
+	ListGoals(ctx context.Context, userID string) ([]Goal, error)
+	UpdateGoal(ctx context.Context, userID string, goal *Goal) error
+	DeleteGoal(ctx context.Context, userID, goalID string) error
+	CreateShareLink(ctx context.Context, link *ShareLink) error
+	// GetShareLink returns nil when the link does not exist.
+	GetShareLink(ctx context.Context, linkID string) (*ShareLink, error)
+	ListShareLinks(ctx context.Context, userID string) ([]ShareLink, error)
+	UpdateShareLink(ctx context.Context, link *ShareLink) error
 }
 
 // Service defines the progress service interface
 type Service interface {
 	GetProgress(ctx context.Context, userID string, startDate, endDate time.Time) (*ProgressStats, error)
-	GetMonthlyStreak(ctx context.Context, userID string, month, year int, timezone string) (*MonthlyStreakData, error)
-	GetWeeklyStreak(ctx context.Context, userID string, targetDate time.Time, timezone string) (*WeeklyStreakData, error)
-	GetCurrentStreak(ctx context.Context, userID string, timezone string) (*StreakData, error)
+	GetMonthlyStreak(ctx context.Context, userID string, month, year int, timezone, category string) (*MonthlyStreakData, error)
+	GetWeeklyStreak(ctx context.Context, userID string, targetDate time.Time, timezone, category string) (*WeeklyStreakData, error)
+	GetCurrentStreak(ctx context.Context, userID string, timezone, category string) (*StreakData, error)
 	RecoverStreak(ctx context.Context, userID string, isPremium bool, timezone string) (*StreakData, error)
 	GetSummary(ctx context.Context, userID string, input SummaryInput) (*SummaryResponse, error)
+	GetHeatmap(ctx context.Context, userID string, input HeatmapInput) (*HeatmapResponse, error)
+	GetMatrix(ctx context.Context, userID string, input SummaryInput) (*MatrixResponse, error)
+	GetMoodAnalytics(ctx context.Context, userID string, input SummaryInput) (*MoodResponse, error)
+	GetTimeModeReport(ctx context.Context, userID string, input SummaryInput) (*TimeModeReport, error)
+	GetRecords(ctx context.Context, userID string, timezone string) (*RecordsResponse, error)
+	CheckRecords(ctx context.Context, userID, entryID string, timezone string) (*RecordCheckResponse, error)
+	GetRecap(ctx context.Context, userID string, input RecapInput) (*Recap, error)
+	GetStreakHistory(ctx context.Context, userID string, timezone string) (*StreakHistoryResponse, error)
+	GetCategoryStreaks(ctx context.Context, userID string, timezone string) (*CategoryStreaksResponse, error)
+	CreateGoal(ctx context.Context, userID string, input GoalInput) (*Goal, error)
+	ListGoals(ctx context.Context, userID string) ([]Goal, error)
+	GetGoal(ctx context.Context, userID, goalID string) (*Goal, error)
+	UpdateGoal(ctx context.Context, userID, goalID string, patch GoalPatch) (*Goal, error)
+	DeleteGoal(ctx context.Context, userID, goalID string) error
+	GetGoalProgress(ctx context.Context, userID, goalID string, input GoalProgressInput) (*GoalProgressResponse, error)
+	GetInsights(ctx context.Context, userID string, input InsightsInput) (*InsightsResponse, error)
+	CreateShareLink(ctx context.Context, userID string, input ShareLinkInput) (*ShareLinkResponse, error)
+	ListShareLinks(ctx context.Context, userID string) ([]ShareLink, error)
+	RevokeShareLink(ctx context.Context, userID, linkID string) error
+	GetSharedSnapshot(ctx context.Context, token string) (*ShareSnapshot, error)
 }
@@ -0,0 +1,196 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Time-of-day segments, in local hours. Night wraps past midnight.
+const (
+	SegmentMorning   = "morning"   // 05:00–11:59
+	SegmentAfternoon = "afternoon" // 12:00–16:59
+	SegmentEvening   = "evening"   // 17:00–20:59
+	SegmentNight     = "night"     // 21:00–04:59
+)
+
+var timeOfDaySegments = []string{SegmentMorning, SegmentAfternoon, SegmentEvening, SegmentNight}
+
+// timeOfDay maps a local hour to its segment.
+func timeOfDay(hour int) string {
+	switch {
+	case hour >= 5 && hour < 12:
+		return SegmentMorning
+	case hour >= 12 && hour < 17:
+		return SegmentAfternoon
+	case hour >= 17 && hour < 21:
+		return SegmentEvening
+	default:
+		return SegmentNight
+	}
+}
+
+// GetMoodAnalytics summarizes the moods users tag on their sessions over the same
+// windows as GetSummary. Sessions without a mood count toward TotalSessions and
+// UntaggedSessions only.
+//
+// The streak-break analysis ignores the category filter, since streaks are
+// global: a break is an active day followed by an inactive one, and the mood
+// counted is the last tagged mood logged on the active day.
+func (s *service) GetMoodAnalytics(ctx context.Context, userID string, input SummaryInput) (*MoodResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	rng, ref, startLocal, endLocal, err := s.summaryWindow(input)
+	if err != nil {
+		return nil, err
+	}
+	loc := ref.Location()
+	// One extra day so a break on the window's last day can be detected.
+	entries, err := s.repo.ListProductivities(ctx, userID, startLocal.UTC(), endLocal.AddDate(0, 0, 1).UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+	category := strings.TrimSpace(input.Category)
+
+	resp := &MoodResponse{
+		Range:        rng,
+		StartDate:    startLocal.Format(dateLayout),
+		EndDate:      endLocal.AddDate(0, 0, -1).Format(dateLayout),
+		Timezone:     loc.String(),
+		Category:     category,
+		Distribution: []MoodStat{},
+	}
+
+	type moodAgg struct {
+		sessions int
+		seconds  int
+		minutes  int
+	}
+	moods := make(map[string]*moodAgg)
+	byMood := make(map[string][]ProductivityEntry)
+	segments := make(map[string]map[string]int, len(timeOfDaySegments))
+	for _, seg := range timeOfDaySegments {
+		segments[seg] = make(map[string]int)
+	}
+	tagged := 0
+	for _, entry := range entries {
+		if !entry.StartTime.Before(endLocal) || !matchesCategory(entry, category) {
+			continue
+		}
+		resp.TotalSessions++
+		mood := strings.TrimSpace(entry.Mood)
+		if mood == "" {
+			resp.UntaggedSessions++
+			continue
+		}
+		tagged++
+		agg, ok := moods[mood]
+		if !ok {
+			agg = &moodAgg{}
+			moods[mood] = agg
+		}
+		agg.sessions++
+		agg.seconds += entry.TimeElapsed
+		agg.minutes += entryMinutes(entry)
+		byMood[mood] = append(byMood[mood], entry)
+		segments[timeOfDay(entry.StartTime.In(loc).Hour())][mood]++
+	}
+
+	for mood, agg := range moods {
+		resp.Distribution = append(resp.Distribution, MoodStat{
+			Mood:              mood,
+			Sessions:          agg.sessions,
+			Minutes:           agg.minutes,
+			Share:             math.Round(float64(agg.sessions)/float64(tagged)*1000) / 1000,
+			AvgSessionMinutes: roundMinutes(float64(agg.seconds) / 60 / float64(agg.sessions)),
+		})
+	}
+	sort.Slice(resp.Distribution, func(i, j int) bool {
+		a, b := resp.Distribution[i], resp.Distribution[j]
+		if a.Sessions != b.Sessions {
+			return a.Sessions > b.Sessions
+		}
+		return a.Mood < b.Mood
+	})
+
+	// Reuse the summary bucketing per mood so timeline labels line up with /summary.
+	for _, bucket := range s.buildDistribution(rng, startLocal, ref, nil, loc) {
+		resp.Timeline = append(resp.Timeline, MoodTimelineBucket{Label: bucket.Label, TimeElapsed: make(map[string]int)})
+	}
+	for mood, list := range byMood {
+		for i, bucket := range s.buildDistribution(rng, startLocal, ref, list, loc) {
+			if bucket.TimeElapsed > 0 {
+				resp.Timeline[i].TimeElapsed[mood] = bucket.TimeElapsed
+			}
+		}
+	}
+
+	for _, seg := range timeOfDaySegments {
+		resp.TimeOfDay = append(resp.TimeOfDay, MoodTimeOfDay{
+			Segment:      seg,
+			Sessions:     segments[seg],
+			DominantMood: topKey(segments[seg]),
+		})
+	}
+
+	resp.StreakBreaks, resp.BeforeStreakBreak = moodsBeforeStreakBreaks(entries, startLocal, endLocal, time.Now().In(loc), loc)
+	return resp, nil
+}
+
+// moodsBeforeStreakBreaks finds active days in [start, end) whose next day had no
+// sessions and counts the last tagged mood of each. The next day must already be
+// over relative to now, otherwise the streak may still continue.
+func moodsBeforeStreakBreaks(entries []ProductivityEntry, start, end, now time.Time, loc *time.Location) (int, []MoodCount) {
+	type dayInfo struct {
+		lastMood string
+		lastAt   time.Time
+	}
+	days := make(map[string]*dayInfo)
+	for _, entry := range entries {
+		local := entry.StartTime.In(loc)
+		key := local.Format(dateLayout)
+		info, ok := days[key]
+		if !ok {
+			info = &dayInfo{}
+			days[key] = info
+		}
+		if mood := strings.TrimSpace(entry.Mood); mood != "" && !local.Before(info.lastAt) {
+			info.lastMood = mood
+			info.lastAt = local
+		}
+	}
+
+	today := truncateToDay(now)
+	breaks := 0
+	counts := make(map[string]int)
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		info, active := days[d.Format(dateLayout)]
+		next := d.AddDate(0, 0, 1)
+		if !active || !next.Before(today) {
+			continue
+		}
+		if _, nextActive := days[next.Format(dateLayout)]; nextActive {
+			continue
+		}
+		breaks++
+		if info.lastMood != "" {
+			counts[info.lastMood]++
+		}
+	}
+
+	out := make([]MoodCount, 0, len(counts))
+	for mood, n := range counts {
+		out = append(out, MoodCount{Mood: mood, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Mood < out[j].Mood
+	})
+	return breaks, out
+}
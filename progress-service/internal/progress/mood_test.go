@@ -0,0 +1,74 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	tests := map[int]string{
+		0:  SegmentNight,
+		4:  SegmentNight,
+		5:  SegmentMorning,
+		11: SegmentMorning,
+		12: SegmentAfternoon,
+		17: SegmentEvening,
+		21: SegmentNight,
+	}
+	for hour, expected := range tests {
+		if got := timeOfDay(hour); got != expected {
+			t.Errorf("timeOfDay(%d) = %q, want %q", hour, got, expected)
+		}
+	}
+}
+
+func TestGetMoodAnalytics(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	at := func(day, hour int) time.Time { return time.Date(2025, 11, day, hour, 0, 0, 0, loc) }
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		{StartTime: at(17, 8), TimeElapsed: 1800, Category: "Work", Mood: "Fokus"},
+		{StartTime: at(17, 14), TimeElapsed: 3600, Category: "Work", Mood: "Fokus"},
+		{StartTime: at(17, 22), TimeElapsed: 600, Category: "Study", Mood: "Capek"},
+		// Nothing on the 18th or 21st: streaks end on the 17th ("Capek" last) and the 20th.
+		{StartTime: at(19, 9), TimeElapsed: 1200, Category: "Study"},
+		{StartTime: at(20, 9), TimeElapsed: 1200, Category: "Study", Mood: "Semangat"},
+	}}
+	svc := NewService(repo)
+
+	resp, err := svc.GetMoodAnalytics(context.Background(), "user-1", SummaryInput{
+		Range:         SummaryRangeWeek,
+		ReferenceDate: at(20, 0),
+		Timezone:      "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("GetMoodAnalytics() error = %v", err)
+	}
+
+	if resp.TotalSessions != 5 || resp.UntaggedSessions != 1 {
+		t.Errorf("sessions = %d total / %d untagged", resp.TotalSessions, resp.UntaggedSessions)
+	}
+	if len(resp.Distribution) != 3 {
+		t.Fatalf("len(distribution) = %d, want 3", len(resp.Distribution))
+	}
+	fokus := resp.Distribution[0]
+	if fokus.Mood != "Fokus" || fokus.Sessions != 2 || fokus.Share != 0.5 || fokus.AvgSessionMinutes != 45 {
+		t.Errorf("Fokus stat = %+v", fokus)
+	}
+
+	if len(resp.Timeline) != 7 || resp.Timeline[0].TimeElapsed["Fokus"] != 5400 {
+		t.Errorf("timeline = %+v", resp.Timeline)
+	}
+
+	segments := make(map[string]MoodTimeOfDay)
+	for _, seg := range resp.TimeOfDay {
+		segments[seg.Segment] = seg
+	}
+	if segments[SegmentNight].DominantMood != "Capek" || segments[SegmentMorning].Sessions["Semangat"] != 1 {
+		t.Errorf("time of day = %+v", resp.TimeOfDay)
+	}
+
+	if resp.StreakBreaks != 2 || len(resp.BeforeStreakBreak) != 2 || resp.BeforeStreakBreak[0].Mood != "Capek" {
+		t.Errorf("streak breaks = %d %+v", resp.StreakBreaks, resp.BeforeStreakBreak)
+	}
+}
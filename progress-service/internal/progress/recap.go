@@ -0,0 +1,88 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// recapTopCategories caps the categories listed on a recap card.
+const recapTopCategories = 3
+
+// GetRecap assembles the weekly recap for the ISO week containing
+// input.ReferenceDate (default: this week).
+func (s *service) GetRecap(ctx context.Context, userID string, input RecapInput) (*Recap, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	_, ref, startLocal, endLocal, err := s.summaryWindow(SummaryInput{
+		Range:         SummaryRangeWeek,
+		ReferenceDate: input.ReferenceDate,
+		Timezone:      input.Timezone,
+	})
+	if err != nil {
+		return nil, err
+	}
+	loc := ref.Location()
+	entries, err := s.repo.ListProductivities(ctx, userID, startLocal.UTC(), endLocal.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+	// A past week shows the streak as it stood on its last day; the current
+	// week shows the live streak.
+	var streak int
+	if weekEnd := endLocal.AddDate(0, 0, -1); weekEnd.Before(truncateToDay(time.Now().In(loc))) {
+		if streak, err = s.streakAsOf(ctx, userID, weekEnd, loc); err != nil {
+			return nil, err
+		}
+	} else {
+		live, err := s.GetCurrentStreak(ctx, userID, input.Timezone, "")
+		if err != nil {
+			return nil, err
+		}
+		streak = live.CurrentStreak
+	}
+
+	recap := &Recap{
+		WeekStart:     startLocal.Format(dateLayout),
+		WeekEnd:       endLocal.AddDate(0, 0, -1).Format(dateLayout),
+		Timezone:      loc.String(),
+		CurrentStreak: streak,
+		TopCategories: []RecapCategory{},
+		Days:          make([]RecapDay, 7),
+	}
+	for i := range recap.Days {
+		recap.Days[i] = RecapDay{Date: startLocal.AddDate(0, 0, i).Format(dateLayout), Weekday: i}
+	}
+
+	categories := make(map[string]int)
+	for _, entry := range entries {
+		mins := entryMinutes(entry)
+		recap.TotalMinutes += mins
+		recap.TotalSessions++
+		recap.Days[mondayIndex(entry.StartTime.In(loc).Weekday())].Minutes += mins
+		if entry.Category != "" {
+			categories[entry.Category] += mins
+		}
+	}
+	for cat, mins := range categories {
+		recap.TopCategories = append(recap.TopCategories, RecapCategory{Category: cat, Minutes: mins})
+	}
+	sort.Slice(recap.TopCategories, func(i, j int) bool {
+		if recap.TopCategories[i].Minutes != recap.TopCategories[j].Minutes {
+			return recap.TopCategories[i].Minutes > recap.TopCategories[j].Minutes
+		}
+		return recap.TopCategories[i].Category < recap.TopCategories[j].Category
+	})
+	if len(recap.TopCategories) > recapTopCategories {
+		recap.TopCategories = recap.TopCategories[:recapTopCategories]
+	}
+
+	if start, _ := s.calculateMostProductiveHour(entries, ref, loc); start != nil {
+		hour := start.In(loc).Hour()
+		recap.BestHour = &hour
+	}
+	return recap, nil
+}
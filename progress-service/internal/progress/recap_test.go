@@ -0,0 +1,56 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetRecap(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	at := func(day, hour int) time.Time { return time.Date(2025, 11, day, hour, 0, 0, 0, loc) }
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		{StartTime: at(17, 9), EndTime: at(17, 10), TimeElapsed: 3600, Category: "Work"},
+		{StartTime: at(18, 9), TimeElapsed: 1800, Category: "Study"},
+		{StartTime: at(19, 20), TimeElapsed: 1200, Category: "Read"},
+		{StartTime: at(23, 9), TimeElapsed: 600, Category: "Journal"},
+		{StartTime: at(24, 9), TimeElapsed: 600, Category: "Work"}, // next week
+	}}
+	svc := NewService(repo)
+
+	recap, err := svc.GetRecap(context.Background(), "user-1", RecapInput{ReferenceDate: at(20, 0), Timezone: "Asia/Jakarta"})
+	if err != nil {
+		t.Fatalf("GetRecap() error = %v", err)
+	}
+
+	if recap.WeekStart != "2025-11-17" || recap.WeekEnd != "2025-11-23" {
+		t.Errorf("week = %s..%s", recap.WeekStart, recap.WeekEnd)
+	}
+	if recap.TotalMinutes != 120 || recap.TotalSessions != 4 {
+		t.Errorf("totals = %d minutes / %d sessions", recap.TotalMinutes, recap.TotalSessions)
+	}
+	if len(recap.TopCategories) != 3 || recap.TopCategories[0].Category != "Work" || recap.TopCategories[2].Category != "Read" {
+		t.Errorf("top categories = %+v", recap.TopCategories)
+	}
+	if len(recap.Days) != 7 || recap.Days[0].Minutes != 60 || recap.Days[6].Minutes != 10 || recap.Days[6].Date != "2025-11-23" {
+		t.Errorf("days = %+v", recap.Days)
+	}
+	if recap.BestHour == nil || *recap.BestHour != 9 {
+		t.Errorf("best hour = %v, want 9", recap.BestHour)
+	}
+	// The streak stands as of Sunday: the 17–19 run expired, and the 24th is
+	// next week.
+	if recap.CurrentStreak != 1 {
+		t.Errorf("current streak = %d, want 1", recap.CurrentStreak)
+	}
+
+	// A recovery on the 20th bridges the gap.
+	repo.recoveries = []RecoveryEvent{{ExpiredAt: "2025-11-20", StreakValue: 3}}
+	recap, err = svc.GetRecap(context.Background(), "user-1", RecapInput{ReferenceDate: at(20, 0), Timezone: "Asia/Jakarta"})
+	if err != nil {
+		t.Fatalf("GetRecap() error = %v", err)
+	}
+	if recap.CurrentStreak != 4 {
+		t.Errorf("recovered streak = %d, want 4", recap.CurrentStreak)
+	}
+}
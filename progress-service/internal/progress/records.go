@@ -0,0 +1,266 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Record names, as used in RecordSet JSON fields and BrokenRecord.Record.
+const (
+	RecordLongestSession   = "longest_session"
+	RecordMostMinutesInDay = "most_minutes_in_day"
+	RecordBestWeek         = "best_week"
+	RecordBestMonth        = "best_month"
+	RecordMostCyclesInDay  = "most_cycles_in_day"
+	RecordLongestStreak    = "longest_streak"
+
+	// RecordScopeAll is the BrokenRecord.Scope for all-time records.
+	RecordScopeAll = "all"
+
+	// recordCheckMonths is how far back CheckRecords looks, from the start of
+	// the current month, so a check never reads the full history.
+	recordCheckMonths = 12
+)
+
+// GetRecords computes all-time and per-category personal bests from the user's
+// full productivity history. Streak records use raw activity days; recovered
+// days are not counted.
+func (s *service) GetRecords(ctx context.Context, userID string, timezone string) (*RecordsResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	loc := s.resolveLocation(timezone)
+	entries, err := s.listAllProductivities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	all, categories := recordsByScope(entries, loc)
+	return &RecordsResponse{
+		Timezone:   loc.String(),
+		All:        all,
+		Categories: categories,
+	}, nil
+}
+
+// CheckRecords reports the records that entryID broke, by comparing the
+// records computed with and without it. Ties do not count as broken: the earlier
+// achievement keeps the record. Only the last recordCheckMonths full months and
+// the current one are compared, so an older entry is not found.
+func (s *service) CheckRecords(ctx context.Context, userID, entryID string, timezone string) (*RecordCheckResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	if strings.TrimSpace(entryID) == "" {
+		return nil, ErrMissingEntryID
+	}
+	loc := s.resolveLocation(timezone)
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month()-recordCheckMonths, 1, 0, 0, 0, 0, loc)
+	entries, err := s.listProductivitiesSince(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		target  *ProductivityEntry
+		without = make([]ProductivityEntry, 0, len(entries))
+	)
+	for i := range entries {
+		if entries[i].ID == entryID {
+			target = &entries[i]
+			continue
+		}
+		without = append(without, entries[i])
+	}
+	if target == nil {
+		return nil, ErrEntryNotFound
+	}
+
+	resp := &RecordCheckResponse{EntryID: entryID, Broken: []BrokenRecord{}}
+	resp.Broken = append(resp.Broken, brokenRecords(RecordScopeAll, computeRecords(without, loc), computeRecords(entries, loc))...)
+
+	if key := categoryKey(target.Category); key != "" {
+		var before, after []ProductivityEntry
+		for _, e := range entries {
+			if categoryKey(e.Category) != key {
+				continue
+			}
+			after = append(after, e)
+			if e.ID != entryID {
+				before = append(before, e)
+			}
+		}
+		scope := categoryLabels(entries)[key]
+		resp.Broken = append(resp.Broken, brokenRecords(scope, computeRecords(before, loc), computeRecords(after, loc))...)
+	}
+	return resp, nil
+}
+
+// listAllProductivities loads the user's full history, oldest first.
+func (s *service) listAllProductivities(ctx context.Context, userID string) ([]ProductivityEntry, error) {
+	return s.listProductivitiesSince(ctx, userID, time.Unix(0, 0))
+}
+
+// listProductivitiesSince loads the entries started from from on, oldest first.
+func (s *service) listProductivitiesSince(ctx context.Context, userID string, from time.Time) ([]ProductivityEntry, error) {
+	entries, err := s.repo.ListProductivities(ctx, userID, from.UTC(), time.Now().UTC().Add(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].StartTime.Before(entries[j].StartTime) })
+	return entries, nil
+}
+
+// categoryKey folds a category the way matchesCategory compares them, so
+// "Study" and "study" are one category.
+func categoryKey(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// categoryLabels maps each categoryKey to the spelling of its most recent
+// entry; entries are sorted oldest first.
+func categoryLabels(entries []ProductivityEntry) map[string]string {
+	labels := make(map[string]string)
+	for _, e := range entries {
+		if key := categoryKey(e.Category); key != "" {
+			labels[key] = strings.TrimSpace(e.Category)
+		}
+	}
+	return labels
+}
+
+// groupByCategory groups entries case-insensitively under their categoryLabels
+// label. Entries without a category are left out.
+func groupByCategory(entries []ProductivityEntry) map[string][]ProductivityEntry {
+	labels := categoryLabels(entries)
+	groups := make(map[string][]ProductivityEntry, len(labels))
+	for _, e := range entries {
+		if key := categoryKey(e.Category); key != "" {
+			groups[labels[key]] = append(groups[labels[key]], e)
+		}
+	}
+	return groups
+}
+
+func recordsByScope(entries []ProductivityEntry, loc *time.Location) (RecordSet, map[string]RecordSet) {
+	byCategory := groupByCategory(entries)
+	categories := make(map[string]RecordSet, len(byCategory))
+	for cat, list := range byCategory {
+		categories[cat] = computeRecords(list, loc)
+	}
+	return computeRecords(entries, loc), categories
+}
+
+// computeRecords derives every personal best from entries sorted oldest first.
+// A record is only replaced by a strictly greater value.
+func computeRecords(entries []ProductivityEntry, loc *time.Location) RecordSet {
+	var set RecordSet
+	type dayAgg struct {
+		minutes int
+		cycles  int
+	}
+	days := make(map[string]*dayAgg)
+	weeks := make(map[string]int)
+	months := make(map[string]int)
+	for _, e := range entries {
+		if e.StartTime.IsZero() {
+			continue
+		}
+		local := e.StartTime.In(loc)
+		day := truncateToDay(local)
+		key := day.Format(dateLayout)
+		mins := entryMinutes(e)
+
+		if set.LongestSession == nil || mins > set.LongestSession.Value {
+			set.LongestSession = &Record{Value: mins, Unit: "minutes", Date: key, EntryID: e.ID}
+		}
+
+		agg, ok := days[key]
+		if !ok {
+			agg = &dayAgg{}
+			days[key] = agg
+		}
+		agg.minutes += mins
+		agg.cycles += e.NumCycle
+
+		weekStart := day.AddDate(0, 0, -mondayIndex(day.Weekday()))
+		weeks[weekStart.Format(dateLayout)] += mins
+		months[time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, loc).Format(dateLayout)] += mins
+	}
+	if len(days) == 0 {
+		return set
+	}
+
+	set.MostMinutesInDay = bestOf(days, "minutes", func(a *dayAgg) int { return a.minutes })
+	set.MostCyclesInDay = bestOf(days, "cycles", func(a *dayAgg) int { return a.cycles })
+	if set.MostCyclesInDay.Value == 0 {
+		set.MostCyclesInDay = nil
+	}
+	set.BestWeek = bestOf(weeks, "minutes", func(v int) int { return v })
+	set.BestMonth = bestOf(months, "minutes", func(v int) int { return v })
+
+	keys := sortedKeys(days)
+	run := 0
+	var prev time.Time
+	for _, key := range keys {
+		d, _ := time.ParseInLocation(dateLayout, key, loc)
+		if run > 0 && d.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = d
+		if set.LongestStreak == nil || run > set.LongestStreak.Value {
+			set.LongestStreak = &Record{Value: run, Unit: "days", Date: key}
+		}
+	}
+	return set
+}
+
+// bestOf returns the largest value in a date-keyed map; ties go to the earliest
+// date.
+func bestOf[T any](byDate map[string]T, unit string, value func(T) int) *Record {
+	var best *Record
+	for _, key := range sortedKeys(byDate) {
+		if v := value(byDate[key]); best == nil || v > best.Value {
+			best = &Record{Value: v, Unit: unit, Date: key}
+		}
+	}
+	return best
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// brokenRecords lists the records whose value grew from before to after.
+func brokenRecords(scope string, before, after RecordSet) []BrokenRecord {
+	var out []BrokenRecord
+	pairs := []struct {
+		name          string
+		before, after *Record
+	}{
+		{RecordLongestSession, before.LongestSession, after.LongestSession},
+		{RecordMostMinutesInDay, before.MostMinutesInDay, after.MostMinutesInDay},
+		{RecordBestWeek, before.BestWeek, after.BestWeek},
+		{RecordBestMonth, before.BestMonth, after.BestMonth},
+		{RecordMostCyclesInDay, before.MostCyclesInDay, after.MostCyclesInDay},
+		{RecordLongestStreak, before.LongestStreak, after.LongestStreak},
+	}
+	for _, p := range pairs {
+		// With no previous record there is nothing to break.
+		if p.after == nil || p.before == nil || p.after.Value <= p.before.Value {
+			continue
+		}
+		out = append(out, BrokenRecord{Scope: scope, Record: p.name, Previous: p.before, Current: *p.after})
+	}
+	return out
+}
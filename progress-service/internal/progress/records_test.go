@@ -0,0 +1,109 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func recordEntries(loc *time.Location) []ProductivityEntry {
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 0, 0, 0, loc)
+	}
+	return []ProductivityEntry{
+		{ID: "a", StartTime: at(10, 6, 9), TimeElapsed: 3000, Category: "Work", NumCycle: 2},
+		{ID: "b", StartTime: at(10, 7, 9), TimeElapsed: 1500, Category: "Study", NumCycle: 1},
+		{ID: "c", StartTime: at(10, 8, 9), TimeElapsed: 1500, Category: "Work", NumCycle: 1},
+		{ID: "d", StartTime: at(10, 8, 14), TimeElapsed: 2400, Category: "Work", NumCycle: 3},
+		{ID: "e", StartTime: at(11, 3, 9), TimeElapsed: 5400, Category: "Work", NumCycle: 1},
+	}
+}
+
+func TestComputeRecords(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	set := computeRecords(recordEntries(loc), loc)
+
+	check := func(name string, got *Record, value int, date string) {
+		t.Helper()
+		if got == nil || got.Value != value || got.Date != date {
+			t.Errorf("%s = %+v, want %d on %s", name, got, value, date)
+		}
+	}
+	check(RecordLongestSession, set.LongestSession, 90, "2025-11-03")
+	check(RecordMostMinutesInDay, set.MostMinutesInDay, 90, "2025-11-03")
+	check(RecordMostCyclesInDay, set.MostCyclesInDay, 4, "2025-10-08")
+	check(RecordBestWeek, set.BestWeek, 140, "2025-10-06")
+	check(RecordBestMonth, set.BestMonth, 140, "2025-10-01")
+	check(RecordLongestStreak, set.LongestStreak, 3, "2025-10-08")
+	if set.LongestSession.EntryID != "e" {
+		t.Errorf("longest session entry = %q, want e", set.LongestSession.EntryID)
+	}
+
+	if empty := computeRecords(nil, loc); empty.LongestSession != nil || empty.LongestStreak != nil {
+		t.Errorf("records without entries = %+v, want empty", empty)
+	}
+}
+
+func TestCheckRecords(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	// Checks only look back a year, so move the fixtures by whole weeks onto a
+	// recent Monday, the 4th to 7th, which keeps "e" in the next month.
+	monday := truncateToDay(time.Now().In(loc)).AddDate(0, -2, 0)
+	for monday.Weekday() != time.Monday || monday.Day() < 4 || monday.Day() > 7 {
+		monday = monday.AddDate(0, 0, -1)
+	}
+	shift := int(monday.Sub(time.Date(2025, 10, 6, 0, 0, 0, 0, loc)).Hours() / 24)
+	entries := recordEntries(loc)
+	for i := range entries {
+		entries[i].StartTime = entries[i].StartTime.AddDate(0, 0, shift)
+	}
+	// Older than the check window, so it cannot hold a record.
+	entries = append(entries, ProductivityEntry{ID: "old", StartTime: monday.AddDate(-2, 0, 0), TimeElapsed: 36000, Category: "Work"})
+	svc := NewService(&fakeRepository{entries: entries})
+
+	resp, err := svc.CheckRecords(context.Background(), "user-1", "e", "Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("CheckRecords() error = %v", err)
+	}
+	broken := make(map[string]BrokenRecord)
+	for _, b := range resp.Broken {
+		broken[b.Scope+"/"+b.Record] = b
+	}
+	longest, ok := broken["all/"+RecordLongestSession]
+	if !ok || longest.Previous == nil || longest.Previous.Value != 50 || longest.Current.Value != 90 {
+		t.Errorf("all-time longest session not reported as broken: %+v", resp.Broken)
+	}
+	if _, ok := broken["Work/"+RecordLongestSession]; !ok {
+		t.Errorf("Work longest session not reported as broken: %+v", resp.Broken)
+	}
+	if _, ok := broken["all/"+RecordBestMonth]; ok {
+		t.Errorf("best month should not be broken (90 < 140): %+v", resp.Broken)
+	}
+
+	// "b" is a short session, but it extends the best week. It is the first
+	// Study entry, so there is no Study record to break yet.
+	resp, err = svc.CheckRecords(context.Background(), "user-1", "b", "Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("CheckRecords() error = %v", err)
+	}
+	broken = make(map[string]BrokenRecord)
+	for _, b := range resp.Broken {
+		broken[b.Scope+"/"+b.Record] = b
+	}
+	if _, ok := broken["all/"+RecordLongestSession]; ok {
+		t.Errorf("b should not break the all-time longest session: %+v", resp.Broken)
+	}
+	if _, ok := broken["all/"+RecordBestWeek]; !ok {
+		t.Errorf("b should break the all-time best week: %+v", resp.Broken)
+	}
+	if _, ok := broken["Study/"+RecordLongestSession]; ok {
+		t.Errorf("b should not break a Study record that did not exist: %+v", resp.Broken)
+	}
+
+	if _, err := svc.CheckRecords(context.Background(), "user-1", "missing", ""); err != ErrEntryNotFound {
+		t.Errorf("CheckRecords() unknown entry error = %v, want ErrEntryNotFound", err)
+	}
+	if _, err := svc.CheckRecords(context.Background(), "user-1", "old", ""); err != ErrEntryNotFound {
+		t.Errorf("CheckRecords() entry outside the window error = %v, want ErrEntryNotFound", err)
+	}
+}
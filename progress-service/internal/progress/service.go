@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/focusnest/shared-libs/insights"
+	"github.com/focusnest/shared-libs/sharelink"
+	"github.com/focusnest/shared-libs/timezone"
 )
 
 const (
@@ -15,25 +19,36 @@ const (
 )
 
 type service struct {
-	repo Repository
-	loc  *time.Location
+	repo     Repository
+	loc      *time.Location
+	insights *insights.Engine
+	shares   *sharelink.Signer
 }
 
 // NewService creates a new progress service with Asia/Jakarta as default location
-func NewService(repo Repository) Service {
+func NewService(repo Repository, opts ...func(*service)) Service {
 	loc, err := time.LoadLocation("Asia/Jakarta")
 	if err != nil {
 		loc = time.UTC
 	}
-	return &service{repo: repo, loc: loc}
+	return NewServiceWithLocation(repo, loc, opts...)
 }
 
 // NewServiceWithLocation allows injecting a custom time.Location
-func NewServiceWithLocation(repo Repository, loc *time.Location) Service {
+func NewServiceWithLocation(repo Repository, loc *time.Location, opts ...func(*service)) Service {
 	if loc == nil {
 		loc = time.UTC
 	}
-	return &service{repo: repo, loc: loc}
+	s := &service{repo: repo, loc: loc, insights: insights.NewEngine(insights.DefaultRules()...)}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// WithShareSigner enables public share links signed by signer.
+func WithShareSigner(signer *sharelink.Signer) func(*service) {
+	return func(s *service) { s.shares = signer }
 }
 
 func (s *service) GetProgress(ctx context.Context, userID string, startDate, endDate time.Time) (*ProgressStats, error) {
@@ -44,21 +59,11 @@ func (s *service) GetSummary(ctx context.Context, userID string, input SummaryIn
 	if strings.TrimSpace(userID) == "" {
 		return nil, ErrMissingUserID
 	}
-	rng := input.Range
-	if rng == "" {
-		rng = SummaryRangeWeek
-	}
-	loc := s.resolveLocation(input.Timezone)
-	ref := input.ReferenceDate
-	if ref.IsZero() {
-		ref = time.Now().In(loc)
-	} else {
-		ref = ref.In(loc)
-	}
-	startLocal, endLocal, err := s.summaryBounds(rng, ref)
+	rng, ref, startLocal, endLocal, err := s.summaryWindow(input)
 	if err != nil {
 		return nil, err
 	}
+	loc := ref.Location()
 	entries, err := s.repo.ListProductivities(ctx, userID, startLocal.UTC(), endLocal.UTC())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list productivities: %w", err)
@@ -72,7 +77,7 @@ func (s *service) GetSummary(ctx context.Context, userID string, input SummaryIn
 	)
 	for _, entry := range entries {
 		totalFrame += entry.TimeElapsed
-		if category == "" || strings.EqualFold(entry.Category, category) {
+		if matchesCategory(entry, category) {
 			totalFiltered += entry.TimeElapsed
 			totalSessions++
 			filtered = append(filtered, entry)
@@ -94,7 +99,9 @@ func (s *service) GetSummary(ctx context.Context, userID string, input SummaryIn
 }
 
 // GetMonthlyStreak returns monthly streak data
-func (s *service) GetMonthlyStreak(ctx context.Context, userID string, month, year int, timezone string) (*MonthlyStreakData, error) {
+// An optional category restricts "done" days to that category; the global
+// streak cache is neither read nor written in that case.
+func (s *service) GetMonthlyStreak(ctx context.Context, userID string, month, year int, timezone, category string) (*MonthlyStreakData, error) {
 	loc := s.resolveLocation(timezone)
 	// Calculate local month boundaries in service location, then rely on repo using those as-is
 	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
@@ -102,17 +109,14 @@ func (s *service) GetMonthlyStreak(ctx context.Context, userID string, month, ye
 
 	// For Firestore queries it's common to store UTC; here we assume caller passes UTC boundaries if needed.
 	// If you need strict UTC conversion: use monthStart.UTC(), monthEnd.UTC().
-	summaries, err := s.repo.GetDailySummaries(ctx, userID, monthStart, monthEnd)
+	summaries, err := s.repo.GetDailySummaries(ctx, userID, monthStart, monthEnd, loc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
 	}
 
 	// Create day status map
-	dayMap := make(map[string]bool)
-	for _, summary := range summaries {
-		dayStr := summary.Date.In(loc).Format("2006-01-02")
-		dayMap[dayStr] = true
-	}
+	category = strings.TrimSpace(category)
+	dayMap := activeDayMap(summaries, loc, category)
 
 	// Generate all days in the month
 	days := make([]DayStatus, 0)
@@ -142,7 +146,7 @@ func (s *service) GetMonthlyStreak(ctx context.Context, userID string, month, ye
 	totalStreak, currentStreak, overflows := s.calculateStreaks(days, now)
 	lastProd := getLastProductiveDate(days, truncateToDay(now))
 	if overflows && lastProd != "" {
-		currentStreak = s.getTrueGlobalStreak(ctx, userID, lastProd, loc, false)
+		currentStreak = s.overflowStreak(ctx, userID, lastProd, loc, category)
 		if currentStreak > totalStreak {
 			totalStreak = currentStreak
 		}
@@ -154,11 +158,13 @@ func (s *service) GetMonthlyStreak(ctx context.Context, userID string, month, ye
 		TotalStreak:   totalStreak,
 		CurrentStreak: currentStreak,
 		Days:          days,
+		Category:      category,
 	}, nil
 }
 
 // GetWeeklyStreak returns weekly streak data (Monday–Sunday)
-func (s *service) GetWeeklyStreak(ctx context.Context, userID string, targetDate time.Time, timezone string) (*WeeklyStreakData, error) {
+// An optional category behaves as in GetMonthlyStreak.
+func (s *service) GetWeeklyStreak(ctx context.Context, userID string, targetDate time.Time, timezone, category string) (*WeeklyStreakData, error) {
 	loc := s.resolveLocation(timezone)
 	td := targetDate.In(loc)
 	weekStart := truncateToDay(td)
@@ -167,16 +173,13 @@ func (s *service) GetWeeklyStreak(ctx context.Context, userID string, targetDate
 	}
 	weekEnd := weekStart.AddDate(0, 0, 7)
 
-	summaries, err := s.repo.GetDailySummaries(ctx, userID, weekStart, weekEnd)
+	summaries, err := s.repo.GetDailySummaries(ctx, userID, weekStart, weekEnd, loc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
 	}
 
-	dayMap := make(map[string]bool)
-	for _, summary := range summaries {
-		dayStr := summary.Date.In(loc).Format("2006-01-02")
-		dayMap[dayStr] = true
-	}
+	category = strings.TrimSpace(category)
+	dayMap := activeDayMap(summaries, loc, category)
 
 	days := make([]DayStatus, 0)
 	now := time.Now().In(loc)
@@ -205,7 +208,7 @@ func (s *service) GetWeeklyStreak(ctx context.Context, userID string, targetDate
 	totalStreak, currentStreak, overflows := s.calculateStreaks(days, now)
 	lastProd := getLastProductiveDate(days, truncateToDay(now))
 	if overflows && lastProd != "" {
-		currentStreak = s.getTrueGlobalStreak(ctx, userID, lastProd, loc, false)
+		currentStreak = s.overflowStreak(ctx, userID, lastProd, loc, category)
 		if currentStreak > totalStreak {
 			totalStreak = currentStreak
 		}
@@ -220,20 +223,48 @@ func (s *service) GetWeeklyStreak(ctx context.Context, userID string, targetDate
 		TotalStreak:   totalStreak,
 		CurrentStreak: currentStreak,
 		Days:          days,
+		Category:      category,
 	}, nil
 }
 
+// activeDayMap returns the local dates with activity, optionally restricted to
+// one category (case-insensitive).
+func activeDayMap(summaries []*DailySummary, loc *time.Location, category string) map[string]bool {
+	dayMap := make(map[string]bool)
+	for _, summary := range summaries {
+		if category != "" && !summaryHasCategory(summary, category) {
+			continue
+		}
+		dayMap[summary.Date.In(loc).Format(dateLayout)] = true
+	}
+	return dayMap
+}
+
+func summaryHasCategory(summary *DailySummary, category string) bool {
+	for cat, mins := range summary.Categories {
+		if mins > 0 && strings.EqualFold(cat, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// overflowStreak resolves a streak that runs past the query window: the global
+// streak goes through the cached getTrueGlobalStreak, category streaks are
+// always counted from history so they never touch the global cache.
+func (s *service) overflowStreak(ctx context.Context, userID, lastProd string, loc *time.Location, category string) int {
+	if category == "" {
+		return s.getTrueGlobalStreak(ctx, userID, lastProd, loc, false)
+	}
+	return s.historicalStreak(ctx, userID, lastProd, loc, category)
+}
+
 // resolveLocation returns time.Location for the given IANA timezone; defaults to service loc (Asia/Jakarta).
 func (s *service) resolveLocation(tz string) *time.Location {
-	tz = strings.TrimSpace(tz)
-	if tz == "" {
-		return s.loc
-	}
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
+	if !timezone.Valid(tz) {
 		return s.loc
 	}
-	return loc
+	return timezone.Load(tz)
 }
 
 // getLastProductiveDate returns the latest date string (YYYY-MM-DD) in days that has status "done" and is <= today.
@@ -318,6 +349,11 @@ func (s *service) getTrueGlobalStreak(ctx context.Context, userID string, lastPr
 
 // calculateHistoricalStreak counts backwards in chunks to find the true streak
 func (s *service) calculateHistoricalStreak(ctx context.Context, userID string, endDateStr string, loc *time.Location) int {
+	return s.historicalStreak(ctx, userID, endDateStr, loc, "")
+}
+
+// historicalStreak is calculateHistoricalStreak with an optional category filter.
+func (s *service) historicalStreak(ctx context.Context, userID string, endDateStr string, loc *time.Location, category string) int {
 	endT, err := time.ParseInLocation(dateLayout, endDateStr, loc)
 	if err != nil {
 		return 0
@@ -329,15 +365,12 @@ func (s *service) calculateHistoricalStreak(ctx context.Context, userID string,
 	targetDate := endT
 
 	for {
-		summaries, err := s.repo.GetDailySummaries(ctx, userID, currentStart, currentEnd)
+		summaries, err := s.repo.GetDailySummaries(ctx, userID, currentStart, currentEnd, loc)
 		if err != nil {
 			return currentStreak
 		}
 
-		dayMap := make(map[string]bool)
-		for _, sum := range summaries {
-			dayMap[sum.Date.In(loc).Format(dateLayout)] = true
-		}
+		dayMap := activeDayMap(summaries, loc, category)
 
 		for d := targetDate; !d.Before(currentStart); d = d.AddDate(0, 0, -1) {
 			if dayMap[d.Format(dateLayout)] {
@@ -355,14 +388,18 @@ func (s *service) calculateHistoricalStreak(ctx context.Context, userID string,
 
 // GetCurrentStreak returns current running streak (last 30 days window) with status/grace/expired and recovery quota.
 // timezone: IANA timezone (e.g. Asia/Jakarta); used for "today". Empty = Asia/Jakarta.
-func (s *service) GetCurrentStreak(ctx context.Context, userID string, timezone string) (*StreakData, error) {
+// A non-empty category delegates to getCategoryCurrentStreak.
+func (s *service) GetCurrentStreak(ctx context.Context, userID string, timezone, category string) (*StreakData, error) {
 	loc := s.resolveLocation(timezone)
+	if category = strings.TrimSpace(category); category != "" {
+		return s.getCategoryCurrentStreak(ctx, userID, loc, category)
+	}
 	now := time.Now().In(loc)
 	today := truncateToDay(now)
 	endDate := today
 	startDate := endDate.AddDate(0, 0, -30)
 
-	summaries, err := s.repo.GetDailySummaries(ctx, userID, startDate, endDate.AddDate(0, 0, 1))
+	summaries, err := s.repo.GetDailySummaries(ctx, userID, startDate, endDate.AddDate(0, 0, 1), loc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
 	}
@@ -569,21 +606,21 @@ func (s *service) RecoverStreak(ctx context.Context, userID string, isPremium bo
 		return nil, ErrStreakNotRecoverable
 	}
 
-	count, err := s.repo.IncrementRecoveryQuota(ctx, userID, yearMonth)
+	// The state keeps ExpiredAt so GetCurrentStreak uses the override until
+	// new activity after that date.
+	count, err := s.repo.RecoverStreak(ctx, userID, yearMonth, &RecoveryEvent{
+		ExpiredAt:   state.ExpiredAt,
+		Timezone:    loc.String(),
+		RecoveredAt: now.UTC(),
+	})
 	if err != nil {
-		if err == ErrRecoveryQuotaExceeded {
+		if err == ErrRecoveryQuotaExceeded || err == ErrStreakNotRecoverable {
 			return nil, err
 		}
-		return nil, fmt.Errorf("increment recovery quota: %w", err)
-	}
-
-	state.OverrideStreakValue = state.StreakValueBeforeExpired
-	// Keep ExpiredAt so GetCurrentStreak knows to use override until new activity after that date
-	if err := s.repo.SetStreakState(ctx, userID, state); err != nil {
-		return nil, fmt.Errorf("set streak state: %w", err)
+		return nil, fmt.Errorf("recover streak: %w", err)
 	}
 
-	data, err := s.GetCurrentStreak(ctx, userID, timezone)
+	data, err := s.GetCurrentStreak(ctx, userID, timezone, "")
 	if err != nil {
 		return nil, err
 	}
@@ -624,6 +661,32 @@ func (s *service) calculateStreaks(days []DayStatus, now time.Time) (totalStreak
 	return
 }
 
+// summaryWindow resolves the defaulted range, the reference time in the caller's
+// location, and the local [start, end) window for range-based endpoints.
+func (s *service) summaryWindow(input SummaryInput) (SummaryRange, time.Time, time.Time, time.Time, error) {
+	rng := input.Range
+	if rng == "" {
+		rng = SummaryRangeWeek
+	}
+	loc := s.resolveLocation(input.Timezone)
+	ref := input.ReferenceDate
+	if ref.IsZero() {
+		ref = time.Now().In(loc)
+	} else {
+		ref = ref.In(loc)
+	}
+	start, end, err := s.summaryBounds(rng, ref)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, time.Time{}, err
+	}
+	return rng, ref, start, end, nil
+}
+
+// matchesCategory reports whether entry passes the optional, case-insensitive category filter.
+func matchesCategory(entry ProductivityEntry, category string) bool {
+	return category == "" || strings.EqualFold(entry.Category, category)
+}
+
 func (s *service) summaryBounds(rng SummaryRange, ref time.Time) (time.Time, time.Time, error) {
 	refDay := truncateToDay(ref)
 	switch rng {
@@ -750,14 +813,9 @@ func (s *service) calculateMostProductiveHour(entries []ProductivityEntry, refer
 	}
 	totals := make(map[time.Time]int)
 	for _, entry := range entries {
-		start := entry.StartTime.In(loc)
-		end := entry.EndTime.In(loc)
-		if end.IsZero() || !end.After(start) {
-			if entry.TimeElapsed > 0 {
-				end = start.Add(time.Duration(entry.TimeElapsed) * time.Second)
-			} else {
-				continue
-			}
+		start, end, ok := entrySpan(entry, loc)
+		if !ok {
+			continue
 		}
 		current := start
 		for current.Before(end) {
@@ -797,6 +855,40 @@ func (s *service) calculateMostProductiveHour(entries []ProductivityEntry, refer
 	return &startUTC, &endUTC
 }
 
+// entrySpan returns the wall-clock interval of a session in loc. Entries without
+// a usable end time fall back to start + TimeElapsed.
+func entrySpan(entry ProductivityEntry, loc *time.Location) (time.Time, time.Time, bool) {
+	start := entry.StartTime.In(loc)
+	end := entry.EndTime.In(loc)
+	if entry.EndTime.IsZero() || !end.After(start) {
+		if entry.TimeElapsed <= 0 {
+			return time.Time{}, time.Time{}, false
+		}
+		end = start.Add(time.Duration(entry.TimeElapsed) * time.Second)
+	}
+	return start, end, true
+}
+
+// entryMinutes converts a session's elapsed seconds to whole minutes, counting
+// any non-zero session as at least one minute.
+func entryMinutes(entry ProductivityEntry) int {
+	mins := entry.TimeElapsed / 60
+	if mins <= 0 && entry.TimeElapsed > 0 {
+		mins = 1
+	}
+	return mins
+}
+
+// entryLocation returns the timezone a session was recorded in, or fallback.
+// Streaks bucket sessions this way so that changing timezone later does not
+// move past sessions onto different days.
+func entryLocation(entry ProductivityEntry, fallback *time.Location) *time.Location {
+	if timezone.Valid(entry.Timezone) {
+		return timezone.Load(entry.Timezone)
+	}
+	return fallback
+}
+
 func truncateToDay(t time.Time) time.Time {
 	y, m, d := t.Date()
 	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
@@ -0,0 +1,179 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultShareDays = 30
+	maxShareDays     = 90
+	// SharePathPrefix is the public route serving snapshots, relative to the gateway.
+	SharePathPrefix = "/v1/public/shares/"
+)
+
+// CreateShareLink stores a new share link and returns its signed token. The
+// shared period is fixed at creation; totals are computed when the link is
+// opened.
+func (s *service) CreateShareLink(ctx context.Context, userID string, input ShareLinkInput) (*ShareLinkResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	if s.shares == nil {
+		return nil, ErrSharingDisabled
+	}
+	rng := input.Range
+	if rng == "" {
+		rng = SummaryRangeMonth
+	}
+	days := input.ExpiresInDays
+	if days == 0 {
+		days = defaultShareDays
+	}
+	if days < 0 || days > maxShareDays {
+		return nil, fmt.Errorf("%w: expires_in_days must be between 1 and %d", ErrInvalidShareLink, maxShareDays)
+	}
+	loc := s.resolveLocation(input.Timezone)
+	ref := time.Now().In(loc)
+	if !input.ReferenceDate.IsZero() {
+		y, m, d := input.ReferenceDate.Date()
+		ref = time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+	if _, _, err := s.summaryBounds(rng, ref); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	link := &ShareLink{
+		UserID:            userID,
+		Range:             rng,
+		ReferenceDate:     ref.Format(dateLayout),
+		IncludeCategories: input.IncludeCategories,
+		Timezone:          loc.String(),
+		CreatedAt:         now,
+		ExpiresAt:         now.AddDate(0, 0, days).Truncate(time.Second),
+	}
+	if err := s.repo.CreateShareLink(ctx, link); err != nil {
+		return nil, fmt.Errorf("create share link: %w", err)
+	}
+	token, err := s.shares.Sign(link.ID, link.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("sign share link: %w", err)
+	}
+	return &ShareLinkResponse{ShareLink: *link, Token: token, Path: SharePathPrefix + token}, nil
+}
+
+// ListShareLinks returns the user's share links, newest first, including
+// revoked and expired ones.
+func (s *service) ListShareLinks(ctx context.Context, userID string) ([]ShareLink, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	links, err := s.repo.ListShareLinks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list share links: %w", err)
+	}
+	sort.SliceStable(links, func(i, j int) bool { return links[i].CreatedAt.After(links[j].CreatedAt) })
+	if links == nil {
+		links = []ShareLink{}
+	}
+	return links, nil
+}
+
+// RevokeShareLink disables a link immediately. Revoking twice is a no-op.
+func (s *service) RevokeShareLink(ctx context.Context, userID, linkID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return ErrMissingUserID
+	}
+	link, err := s.repo.GetShareLink(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if link == nil || link.UserID != userID {
+		return ErrShareLinkNotFound
+	}
+	if link.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	link.RevokedAt = &now
+	if err := s.repo.UpdateShareLink(ctx, link); err != nil {
+		return fmt.Errorf("revoke share link: %w", err)
+	}
+	return nil
+}
+
+// GetSharedSnapshot resolves a public token to its snapshot. Forged, expired,
+// revoked and unknown links all return ErrShareLinkNotFound.
+func (s *service) GetSharedSnapshot(ctx context.Context, token string) (*ShareSnapshot, error) {
+	if s.shares == nil {
+		return nil, ErrSharingDisabled
+	}
+	now := time.Now()
+	claims, err := s.shares.Verify(token, now)
+	if err != nil {
+		return nil, ErrShareLinkNotFound
+	}
+	link, err := s.repo.GetShareLink(ctx, claims.LinkID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil || link.RevokedAt != nil || !now.Before(link.ExpiresAt) {
+		return nil, ErrShareLinkNotFound
+	}
+
+	loc := s.resolveLocation(link.Timezone)
+	ref, err := time.ParseInLocation(dateLayout, link.ReferenceDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("share link reference date: %w", err)
+	}
+	start, end, err := s.summaryBounds(link.Range, ref)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListProductivities(ctx, link.UserID, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+
+	snapshot := &ShareSnapshot{
+		Range:     link.Range,
+		From:      start.Format(dateLayout),
+		To:        end.AddDate(0, 0, -1).Format(dateLayout),
+		Timezone:  loc.String(),
+		ExpiresAt: link.ExpiresAt,
+	}
+	activeDays := make(map[string]bool)
+	categories := make(map[string]int)
+	for _, entry := range entries {
+		snapshot.TimeElapsed += entry.TimeElapsed
+		snapshot.Sessions++
+		if !entry.StartTime.IsZero() {
+			activeDays[entry.StartTime.In(entryLocation(entry, loc)).Format(dateLayout)] = true
+		}
+		if link.IncludeCategories && entry.Category != "" {
+			categories[entry.Category] += entry.TimeElapsed
+		}
+	}
+	snapshot.ActiveDays = len(activeDays)
+	for category, elapsed := range categories {
+		snapshot.Categories = append(snapshot.Categories, ShareCategory{Category: category, TimeElapsed: elapsed})
+	}
+	sort.Slice(snapshot.Categories, func(i, j int) bool {
+		a, b := snapshot.Categories[i], snapshot.Categories[j]
+		if a.TimeElapsed != b.TimeElapsed {
+			return a.TimeElapsed > b.TimeElapsed
+		}
+		return a.Category < b.Category
+	})
+
+	streak, err := s.GetCurrentStreak(ctx, link.UserID, link.Timezone, "")
+	if err != nil {
+		return nil, err
+	}
+	snapshot.CurrentStreak = streak.CurrentStreak
+	return snapshot, nil
+}
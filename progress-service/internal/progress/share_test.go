@@ -0,0 +1,96 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/sharelink"
+)
+
+func TestShareLinks(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	at := func(day, hour int) time.Time { return time.Date(2025, time.March, day, hour, 0, 0, 0, loc) }
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		{StartTime: at(1, 0), TimeElapsed: 1800, Category: "Study", Mood: "Capek"},
+		{StartTime: at(1, 20), TimeElapsed: 600, Category: "Work"},
+		{StartTime: at(31, 23), TimeElapsed: 3600, Category: "Work"},
+		{StartTime: at(0, 23), TimeElapsed: 900, Category: "Work"}, // 28 Feb, outside the month
+	}}
+	signer, err := sharelink.NewSigner(strings.Repeat("s", sharelink.MinSecretLength))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	svc := NewService(repo, WithShareSigner(signer))
+	ctx := context.Background()
+
+	link, err := svc.CreateShareLink(ctx, "user-1", ShareLinkInput{
+		ReferenceDate:     time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC),
+		IncludeCategories: true,
+		Timezone:          "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("CreateShareLink() error = %v", err)
+	}
+	if link.Range != SummaryRangeMonth || link.ReferenceDate != "2025-03-15" || link.Path != SharePathPrefix+link.Token {
+		t.Errorf("link = %+v", link)
+	}
+	if days := link.ExpiresAt.Sub(link.CreatedAt).Hours() / 24; days < 29.9 || days > 30 {
+		t.Errorf("link expires after %.1f days, want 30", days)
+	}
+
+	snapshot, err := svc.GetSharedSnapshot(ctx, link.Token)
+	if err != nil {
+		t.Fatalf("GetSharedSnapshot() error = %v", err)
+	}
+	if snapshot.From != "2025-03-01" || snapshot.To != "2025-03-31" || snapshot.TimeElapsed != 6000 || snapshot.Sessions != 3 || snapshot.ActiveDays != 2 {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+	if len(snapshot.Categories) != 2 || snapshot.Categories[0] != (ShareCategory{Category: "Work", TimeElapsed: 4200}) {
+		t.Errorf("categories = %+v", snapshot.Categories)
+	}
+
+	if _, err := svc.GetSharedSnapshot(ctx, link.Token+"x"); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("tampered token error = %v, want ErrShareLinkNotFound", err)
+	}
+	if err := svc.RevokeShareLink(ctx, "user-2", link.ID); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("foreign revoke error = %v, want ErrShareLinkNotFound", err)
+	}
+	if err := svc.RevokeShareLink(ctx, "user-1", link.ID); err != nil {
+		t.Fatalf("RevokeShareLink() error = %v", err)
+	}
+	if _, err := svc.GetSharedSnapshot(ctx, link.Token); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("revoked link error = %v, want ErrShareLinkNotFound", err)
+	}
+	links, err := svc.ListShareLinks(ctx, "user-1")
+	if err != nil || len(links) != 1 || links[0].RevokedAt == nil {
+		t.Errorf("ListShareLinks() = %+v, %v", links, err)
+	}
+
+	if _, err := svc.CreateShareLink(ctx, "user-1", ShareLinkInput{ExpiresInDays: 91}); !errors.Is(err, ErrInvalidShareLink) {
+		t.Errorf("long expiry error = %v, want ErrInvalidShareLink", err)
+	}
+	if _, err := NewService(repo).CreateShareLink(ctx, "user-1", ShareLinkInput{}); !errors.Is(err, ErrSharingDisabled) {
+		t.Errorf("unsigned service error = %v, want ErrSharingDisabled", err)
+	}
+}
+
+func TestShareLinkExpired(t *testing.T) {
+	signer, _ := sharelink.NewSigner(strings.Repeat("s", sharelink.MinSecretLength))
+	repo := &fakeRepository{links: map[string]ShareLink{
+		"share-1": {ID: "share-1", UserID: "user-1", Range: SummaryRangeMonth, ReferenceDate: "2025-03-15", ExpiresAt: time.Now().Add(-time.Hour)},
+	}}
+	svc := NewService(repo, WithShareSigner(signer))
+
+	// A token that outlives its link is still rejected by the stored expiry.
+	token, _ := signer.Sign("share-1", time.Now().Add(time.Hour))
+	if _, err := svc.GetSharedSnapshot(context.Background(), token); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("expired link error = %v, want ErrShareLinkNotFound", err)
+	}
+	token, _ = signer.Sign("share-1", time.Now().Add(-time.Minute))
+	if _, err := svc.GetSharedSnapshot(context.Background(), token); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("expired token error = %v, want ErrShareLinkNotFound", err)
+	}
+}
@@ -0,0 +1,149 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GetStreakHistory rebuilds every streak from the user's active days in the
+// requested timezone. A gap is bridged when a recovery event covers its first
+// missed day and activity resumed within the grace window, mirroring the rules
+// GetCurrentStreak applies to the live streak.
+func (s *service) GetStreakHistory(ctx context.Context, userID string, timezone string) (*StreakHistoryResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	loc := s.resolveLocation(timezone)
+	entries, err := s.listAllProductivities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.ListRecoveryEvents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery events: %w", err)
+	}
+
+	active := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.StartTime.IsZero() {
+			active[entry.StartTime.In(entryLocation(entry, loc)).Format(dateLayout)] = true
+		}
+	}
+	streaks := buildStreakHistory(sortedKeys(active), events, truncateToDay(time.Now().In(loc)), loc)
+
+	resp := &StreakHistoryResponse{Timezone: loc.String(), Streaks: streaks}
+	for _, run := range streaks {
+		if run.Length > resp.LongestStreak {
+			resp.LongestStreak = run.Length
+		}
+	}
+	return resp, nil
+}
+
+// streakAsOf returns the global streak as it stood at the end of day: the
+// run, bridged by recoveries as in GetStreakHistory, that was still active or
+// in grace on day. History is read a month at a time backward from day, until
+// the run starts far enough inside the loaded range that no earlier day could
+// bridge into it.
+func (s *service) streakAsOf(ctx context.Context, userID string, day time.Time, loc *time.Location) (int, error) {
+	events, err := s.repo.ListRecoveryEvents(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list recovery events: %w", err)
+	}
+	day = truncateToDay(day.In(loc))
+	dayKey := day.Format(dateLayout)
+	active := make(map[string]bool)
+	end := day.AddDate(0, 0, 1)
+	for start := end.AddDate(0, -1, 0); ; start = start.AddDate(0, -1, 0) {
+		entries, err := s.repo.ListProductivities(ctx, userID, start.UTC(), end.UTC())
+		if err != nil {
+			return 0, fmt.Errorf("failed to list productivities: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.StartTime.IsZero() {
+				continue
+			}
+			if key := entry.StartTime.In(entryLocation(entry, loc)).Format(dateLayout); key <= dayKey {
+				active[key] = true
+			}
+		}
+		end = start
+
+		runs := buildStreakHistory(sortedKeys(active), events, day, loc)
+		if len(runs) == 0 || runs[0].EndedBy != StreakEndedOngoing {
+			return 0, nil
+		}
+		first, err := time.ParseInLocation(dateLayout, runs[0].StartDate, loc)
+		if err != nil || !first.AddDate(0, 0, -(daysUntilExpiry+graceDays)).Before(start) {
+			return runs[0].Length, nil
+		}
+	}
+}
+
+// buildStreakHistory groups sorted active days (YYYY-MM-DD) into streaks, newest
+// first.
+func buildStreakHistory(days []string, events []RecoveryEvent, today time.Time, loc *time.Location) []StreakRun {
+	recoveries := make(map[string]RecoveryEvent, len(events))
+	for _, e := range events {
+		recoveries[e.ExpiredAt] = e
+	}
+
+	var (
+		runs    []StreakRun
+		current *StreakRun
+		last    time.Time
+	)
+	closeRun := func(endedBy string) {
+		current.EndDate = last.Format(dateLayout)
+		current.EndedBy = endedBy
+		runs = append(runs, *current)
+		current = nil
+	}
+	for _, key := range days {
+		day, err := time.ParseInLocation(dateLayout, key, loc)
+		if err != nil {
+			continue
+		}
+		if current != nil && !day.Equal(last.AddDate(0, 0, 1)) {
+			expiredAt := last.AddDate(0, 0, daysUntilExpiry)
+			event, recovered := recoveries[expiredAt.Format(dateLayout)]
+			switch {
+			case recovered && !day.After(expiredAt.AddDate(0, 0, graceDays)):
+				current.Recoveries = append(current.Recoveries, event)
+			case recovered:
+				closeRun(StreakEndedRecovered)
+			default:
+				closeRun(StreakEndedExpired)
+			}
+		}
+		if current == nil {
+			current = &StreakRun{StartDate: key, Recoveries: []RecoveryEvent{}}
+		}
+		current.Length++
+		last = day
+	}
+
+	if current != nil {
+		expiredAt := last.AddDate(0, 0, daysUntilExpiry)
+		_, recovered := recoveries[expiredAt.Format(dateLayout)]
+		switch {
+		// Still active, or inside the grace window where it can be recovered/resumed.
+		case !today.After(expiredAt.AddDate(0, 0, graceDays-1)),
+			recovered && !today.After(expiredAt.AddDate(0, 0, graceDays)):
+			closeRun(StreakEndedOngoing)
+		case recovered:
+			closeRun(StreakEndedRecovered)
+		default:
+			closeRun(StreakEndedExpired)
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartDate > runs[j].StartDate })
+	if runs == nil {
+		runs = []StreakRun{}
+	}
+	return runs
+}
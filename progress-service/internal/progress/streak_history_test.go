@@ -0,0 +1,81 @@
+package progress
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildStreakHistory(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation(dateLayout, s, loc)
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		days     []string
+		events   []RecoveryEvent
+		today    string
+		expected []StreakRun
+	}{
+		{
+			name:     "No activity",
+			today:    "2025-11-20",
+			expected: []StreakRun{},
+		},
+		{
+			name:  "Expired then ongoing",
+			days:  []string{"2025-11-01", "2025-11-02", "2025-11-03", "2025-11-10", "2025-11-11"},
+			today: "2025-11-12",
+			expected: []StreakRun{
+				{StartDate: "2025-11-10", EndDate: "2025-11-11", Length: 2, EndedBy: StreakEndedOngoing},
+				{StartDate: "2025-11-01", EndDate: "2025-11-03", Length: 3, EndedBy: StreakEndedExpired},
+			},
+		},
+		{
+			name:   "Recovery bridges the gap",
+			days:   []string{"2025-11-01", "2025-11-02", "2025-11-05", "2025-11-06"},
+			events: []RecoveryEvent{{ExpiredAt: "2025-11-03", StreakValue: 2}},
+			today:  "2025-11-20",
+			expected: []StreakRun{
+				{StartDate: "2025-11-01", EndDate: "2025-11-06", Length: 4, EndedBy: StreakEndedExpired,
+					Recoveries: []RecoveryEvent{{ExpiredAt: "2025-11-03", StreakValue: 2}}},
+			},
+		},
+		{
+			name:   "Recovered but not resumed in time",
+			days:   []string{"2025-11-01", "2025-11-02", "2025-11-10"},
+			events: []RecoveryEvent{{ExpiredAt: "2025-11-03", StreakValue: 2}},
+			today:  "2025-11-20",
+			expected: []StreakRun{
+				{StartDate: "2025-11-10", EndDate: "2025-11-10", Length: 1, EndedBy: StreakEndedExpired},
+				{StartDate: "2025-11-01", EndDate: "2025-11-02", Length: 2, EndedBy: StreakEndedRecovered},
+			},
+		},
+		{
+			name:  "In grace counts as ongoing",
+			days:  []string{"2025-11-17", "2025-11-18"},
+			today: "2025-11-21",
+			expected: []StreakRun{
+				{StartDate: "2025-11-17", EndDate: "2025-11-18", Length: 2, EndedBy: StreakEndedOngoing},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildStreakHistory(tt.days, tt.events, day(tt.today), loc)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("buildStreakHistory() = %+v, want %+v", got, tt.expected)
+			}
+			for i, run := range got {
+				want := tt.expected[i]
+				if run.StartDate != want.StartDate || run.EndDate != want.EndDate || run.Length != want.Length ||
+					run.EndedBy != want.EndedBy || len(run.Recoveries) != len(want.Recoveries) {
+					t.Errorf("run %d = %+v, want %+v", i, run, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// consistencyRatio is the fraction of a mode's median session length a session
+	// must reach to count as "completed" for consistency.
+	consistencyRatio = 0.8
+	// timeModeTopMoods caps the moods listed per time mode.
+	timeModeTopMoods = 3
+	// unspecifiedTimeMode groups legacy sessions saved without a time mode.
+	// It is lowercase so it cannot collide with a real mode such as "Other".
+	unspecifiedTimeMode = "unspecified"
+)
+
+// GetTimeModeReport compares the user's time modes (Pomodoro, Deep Work, …) over
+// the same windows as GetSummary, computed from raw productivities.
+func (s *service) GetTimeModeReport(ctx context.Context, userID string, input SummaryInput) (*TimeModeReport, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, ErrMissingUserID
+	}
+	rng, ref, startLocal, endLocal, err := s.summaryWindow(input)
+	if err != nil {
+		return nil, err
+	}
+	loc := ref.Location()
+	entries, err := s.repo.ListProductivities(ctx, userID, startLocal.UTC(), endLocal.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list productivities: %w", err)
+	}
+	category := strings.TrimSpace(input.Category)
+
+	byMode := make(map[string][]ProductivityEntry)
+	total := 0
+	for _, entry := range entries {
+		if !matchesCategory(entry, category) {
+			continue
+		}
+		mode := strings.TrimSpace(entry.TimeMode)
+		if mode == "" {
+			mode = unspecifiedTimeMode
+		}
+		byMode[mode] = append(byMode[mode], entry)
+		total++
+	}
+
+	report := &TimeModeReport{
+		Range:         rng,
+		StartDate:     startLocal.Format(dateLayout),
+		EndDate:       endLocal.AddDate(0, 0, -1).Format(dateLayout),
+		Timezone:      loc.String(),
+		Category:      category,
+		TotalSessions: total,
+		Modes:         make([]TimeModeStat, 0, len(byMode)),
+	}
+	for mode, list := range byMode {
+		stat := TimeModeStat{
+			TimeMode:   mode,
+			Sessions:   len(list),
+			TopMoods:   []MoodCount{},
+			Categories: []TimeModeCategoryStat{},
+		}
+
+		var (
+			seconds    int
+			cycles     int
+			durations  = make([]int, 0, len(list))
+			segments   = make(map[string]int)
+			hours      = make(map[int]int)
+			moods      = make(map[string]int)
+			categories = make(map[string]*TimeModeCategoryStat)
+			catSeconds = make(map[string]int)
+			catCycles  = make(map[string]int)
+		)
+		for _, entry := range list {
+			seconds += entry.TimeElapsed
+			cycles += entry.NumCycle
+			stat.TotalMinutes += entryMinutes(entry)
+			durations = append(durations, entry.TimeElapsed)
+
+			local := entry.StartTime.In(loc)
+			segments[timeOfDay(local.Hour())]++
+			hours[local.Hour()]++
+			if mood := strings.TrimSpace(entry.Mood); mood != "" {
+				moods[mood]++
+			}
+
+			cs, ok := categories[entry.Category]
+			if !ok {
+				cs = &TimeModeCategoryStat{Category: entry.Category}
+				categories[entry.Category] = cs
+			}
+			cs.Sessions++
+			cs.TotalMinutes += entryMinutes(entry)
+			catSeconds[entry.Category] += entry.TimeElapsed
+			catCycles[entry.Category] += entry.NumCycle
+		}
+
+		n := float64(len(list))
+		stat.AvgMinutes = roundMinutes(float64(seconds) / 60 / n)
+		stat.AvgCycles = roundTenth(float64(cycles) / n)
+		stat.Consistency = sessionConsistency(durations)
+		stat.TypicalTimeOfDay = topKey(segments)
+		stat.PeakHour = peakHour(hours)
+
+		for mood, count := range moods {
+			stat.TopMoods = append(stat.TopMoods, MoodCount{Mood: mood, Count: count})
+		}
+		sort.Slice(stat.TopMoods, func(i, j int) bool {
+			if stat.TopMoods[i].Count != stat.TopMoods[j].Count {
+				return stat.TopMoods[i].Count > stat.TopMoods[j].Count
+			}
+			return stat.TopMoods[i].Mood < stat.TopMoods[j].Mood
+		})
+		if len(stat.TopMoods) > timeModeTopMoods {
+			stat.TopMoods = stat.TopMoods[:timeModeTopMoods]
+		}
+
+		for cat, cs := range categories {
+			cs.AvgMinutes = roundMinutes(float64(catSeconds[cat]) / 60 / float64(cs.Sessions))
+			cs.AvgCycles = roundTenth(float64(catCycles[cat]) / float64(cs.Sessions))
+			stat.Categories = append(stat.Categories, *cs)
+		}
+		sort.Slice(stat.Categories, func(i, j int) bool {
+			if stat.Categories[i].TotalMinutes != stat.Categories[j].TotalMinutes {
+				return stat.Categories[i].TotalMinutes > stat.Categories[j].TotalMinutes
+			}
+			return stat.Categories[i].Category < stat.Categories[j].Category
+		})
+
+		report.Modes = append(report.Modes, stat)
+	}
+	sort.Slice(report.Modes, func(i, j int) bool {
+		if report.Modes[i].Sessions != report.Modes[j].Sessions {
+			return report.Modes[i].Sessions > report.Modes[j].Sessions
+		}
+		return report.Modes[i].TimeMode < report.Modes[j].TimeMode
+	})
+	return report, nil
+}
+
+// sessionConsistency returns the share of sessions (0–1, three decimals) whose
+// length reached consistencyRatio of the median length.
+func sessionConsistency(durations []int) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), durations...)
+	sort.Ints(sorted)
+	var median float64
+	if mid := len(sorted) / 2; len(sorted)%2 == 1 {
+		median = float64(sorted[mid])
+	} else {
+		median = float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	target := median * consistencyRatio
+	reached := 0
+	for _, d := range durations {
+		if float64(d) >= target {
+			reached++
+		}
+	}
+	return math.Round(float64(reached)/float64(len(durations))*1000) / 1000
+}
+
+// roundTenth rounds an average to one decimal place.
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// peakHour returns the local hour with the most session starts; ties go to the
+// earlier hour.
+func peakHour(hours map[int]int) int {
+	best, bestCount := 0, -1
+	for h := 0; h < 24; h++ {
+		if hours[h] > bestCount {
+			best, bestCount = h, hours[h]
+		}
+	}
+	return best
+}
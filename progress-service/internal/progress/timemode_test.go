@@ -0,0 +1,86 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionConsistency(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []int
+		expected  float64
+	}{
+		{name: "No sessions", durations: nil, expected: 0},
+		{name: "All equal", durations: []int{1500, 1500, 1500}, expected: 1},
+		{name: "Odd count", durations: []int{600, 1500, 1500, 1500, 300}, expected: 0.6},
+		{name: "Even count uses midpoint", durations: []int{1000, 2000, 3000, 4000}, expected: 0.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sessionConsistency(tt.durations); got != tt.expected {
+				t.Errorf("sessionConsistency() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetTimeModeReport(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+	at := func(day, hour int) time.Time { return time.Date(2025, 11, day, hour, 0, 0, 0, loc) }
+	repo := &fakeRepository{entries: []ProductivityEntry{
+		{StartTime: at(17, 8), TimeElapsed: 1500, Category: "Work", TimeMode: "Pomodoro", NumCycle: 1, Mood: "Fokus"},
+		{StartTime: at(18, 8), TimeElapsed: 3000, Category: "Work", TimeMode: "Pomodoro", NumCycle: 2, Mood: "Fokus"},
+		{StartTime: at(19, 9), TimeElapsed: 1500, Category: "Study", TimeMode: "Pomodoro", NumCycle: 1, Mood: "Capek"},
+		{StartTime: at(19, 21), TimeElapsed: 5400, Category: "Work", TimeMode: "Deep Work", NumCycle: 1},
+		{StartTime: at(20, 10), TimeElapsed: 600, Category: "Read"},
+		{StartTime: at(20, 11), TimeElapsed: 900, Category: "Read", TimeMode: "Other"},
+	}}
+	svc := NewService(repo)
+
+	report, err := svc.GetTimeModeReport(context.Background(), "user-1", SummaryInput{
+		Range:         SummaryRangeWeek,
+		ReferenceDate: at(20, 0),
+		Timezone:      "Asia/Jakarta",
+	})
+	if err != nil {
+		t.Fatalf("GetTimeModeReport() error = %v", err)
+	}
+
+	if report.TotalSessions != 6 || len(report.Modes) != 4 {
+		t.Fatalf("report = %d sessions / %d modes", report.TotalSessions, len(report.Modes))
+	}
+	pomodoro := report.Modes[0]
+	if pomodoro.TimeMode != "Pomodoro" || pomodoro.Sessions != 3 || pomodoro.TotalMinutes != 100 {
+		t.Errorf("pomodoro = %+v", pomodoro)
+	}
+	if pomodoro.AvgMinutes != 33.3 || pomodoro.AvgCycles != 1.3 || pomodoro.Consistency != 1 {
+		t.Errorf("pomodoro averages = %v min / %v cycles / %v consistency", pomodoro.AvgMinutes, pomodoro.AvgCycles, pomodoro.Consistency)
+	}
+	if pomodoro.TypicalTimeOfDay != SegmentMorning || pomodoro.PeakHour != 8 {
+		t.Errorf("pomodoro time of day = %q / %d", pomodoro.TypicalTimeOfDay, pomodoro.PeakHour)
+	}
+	if len(pomodoro.TopMoods) != 2 || pomodoro.TopMoods[0] != (MoodCount{Mood: "Fokus", Count: 2}) {
+		t.Errorf("pomodoro moods = %+v", pomodoro.TopMoods)
+	}
+	if len(pomodoro.Categories) != 2 || pomodoro.Categories[0].Category != "Work" || pomodoro.Categories[0].AvgCycles != 1.5 {
+		t.Errorf("pomodoro categories = %+v", pomodoro.Categories)
+	}
+
+	var other *TimeModeStat
+	for i := range report.Modes {
+		if report.Modes[i].TimeMode == unspecifiedTimeMode {
+			other = &report.Modes[i]
+		}
+	}
+	if other == nil || other.Sessions != 1 || other.TotalMinutes != 10 {
+		t.Errorf("sessions without time mode should be grouped under %q: %+v", unspecifiedTimeMode, report.Modes)
+	}
+	for _, mode := range report.Modes {
+		if mode.TimeMode == "Other" && mode.Sessions != 1 {
+			t.Errorf("sessions tagged Other should stay apart from untagged ones: %+v", mode)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package recap
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/focusnest/progress-service/internal/progress"
+)
+
+// The card is laid out once as a list of shapes; the PNG and SVG renderers only
+// differ in how they draw them, so both formats always match.
+
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+type rect struct {
+	x, y, w, h int
+	fill       color.RGBA
+}
+
+type text struct {
+	x, y   int // baseline position
+	size   float64
+	bold   bool
+	anchor anchor
+	fill   color.RGBA
+	value  string
+}
+
+type card struct {
+	rects []rect
+	texts []text
+}
+
+var (
+	colorBackground = color.RGBA{R: 0x1b, G: 0x1f, B: 0x3b, A: 0xff}
+	colorPanel      = color.RGBA{R: 0x27, G: 0x2d, B: 0x52, A: 0xff}
+	colorAccent     = color.RGBA{R: 0xff, G: 0xb5, B: 0x47, A: 0xff}
+	colorBar        = color.RGBA{R: 0x6c, G: 0x8c, B: 0xff, A: 0xff}
+	colorBarEmpty   = color.RGBA{R: 0x3a, G: 0x41, B: 0x6e, A: 0xff}
+	colorText       = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	colorMuted      = color.RGBA{R: 0xa9, G: 0xb0, B: 0xd6, A: 0xff}
+)
+
+const (
+	margin      = 80
+	chartTop    = 640
+	chartBottom = 940
+	barGap      = 28
+)
+
+func layout(r *progress.Recap, lang string) card {
+	l := labelsFor(lang)
+	c := card{rects: []rect{{x: 0, y: 0, w: Width, h: Height, fill: colorBackground}}}
+
+	c.texts = append(c.texts,
+		text{x: margin, y: 130, size: 56, bold: true, fill: colorText, value: l.title},
+		text{x: margin, y: 185, size: 30, fill: colorMuted, value: formatRange(r.WeekStart, r.WeekEnd, l)},
+	)
+
+	// Headline stats.
+	c.rects = append(c.rects, rect{x: margin, y: 230, w: Width - 2*margin, h: 200, fill: colorPanel})
+	c.texts = append(c.texts,
+		text{x: margin + 40, y: 290, size: 28, fill: colorMuted, value: l.totalFocus},
+		text{x: margin + 40, y: 370, size: 72, bold: true, fill: colorAccent, value: formatDuration(r.TotalMinutes, l)},
+		text{x: margin + 40, y: 410, size: 26, fill: colorMuted, value: fmt.Sprintf("%d %s", r.TotalSessions, l.sessions)},
+		text{x: Width - margin - 40, y: 290, size: 28, anchor: anchorEnd, fill: colorMuted, value: l.streak},
+		text{x: Width - margin - 40, y: 350, size: 48, bold: true, anchor: anchorEnd, fill: colorText, value: fmt.Sprintf("%d %s", r.CurrentStreak, l.days)},
+	)
+	if r.BestHour != nil {
+		c.texts = append(c.texts,
+			text{x: Width - margin - 40, y: 410, size: 26, anchor: anchorEnd, fill: colorMuted, value: l.bestHour + " " + formatHour(*r.BestHour)},
+		)
+	}
+
+	// Top categories.
+	c.texts = append(c.texts, text{x: margin, y: 500, size: 30, bold: true, fill: colorText, value: l.topCats})
+	if len(r.TopCategories) == 0 {
+		c.texts = append(c.texts, text{x: margin, y: 550, size: 28, fill: colorMuted, value: l.noActivity})
+	}
+	colWidth := (Width - 2*margin) / 3
+	for i, cat := range r.TopCategories {
+		x := margin + i*colWidth
+		c.texts = append(c.texts,
+			text{x: x, y: 550, size: 28, bold: true, fill: colorAccent, value: fmt.Sprintf("%d. %s", i+1, cat.Category)},
+			text{x: x, y: 590, size: 26, fill: colorMuted, value: formatDuration(cat.Minutes, l)},
+		)
+	}
+
+	// Daily bar chart, scaled to the best day.
+	maxMinutes := 0
+	for _, d := range r.Days {
+		if d.Minutes > maxMinutes {
+			maxMinutes = d.Minutes
+		}
+	}
+	n := len(r.Days)
+	if n == 0 {
+		return c
+	}
+	barWidth := (Width - 2*margin - (n-1)*barGap) / n
+	chartHeight := chartBottom - chartTop
+	for i, d := range r.Days {
+		x := margin + i*(barWidth+barGap)
+		h := 8
+		fill := colorBarEmpty
+		if maxMinutes > 0 && d.Minutes > 0 {
+			h = max(d.Minutes*chartHeight/maxMinutes, 8)
+			fill = colorBar
+		}
+		c.rects = append(c.rects, rect{x: x, y: chartBottom - h, w: barWidth, h: h, fill: fill})
+		label := ""
+		if d.Weekday >= 0 && d.Weekday < len(l.weekdays) {
+			label = l.weekdays[d.Weekday]
+		}
+		c.texts = append(c.texts, text{x: x + barWidth/2, y: chartBottom + 50, size: 26, anchor: anchorMiddle, fill: colorMuted, value: label})
+	}
+	return c
+}
@@ -0,0 +1,97 @@
+package recap
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"sync"
+
+	"github.com/focusnest/progress-service/internal/progress"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	fontsOnce sync.Once
+	fontsErr  error
+	regular   *opentype.Font
+	bold      *opentype.Font
+)
+
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		if regular, fontsErr = opentype.Parse(goregular.TTF); fontsErr != nil {
+			return
+		}
+		bold, fontsErr = opentype.Parse(gobold.TTF)
+	})
+	return fontsErr
+}
+
+// RenderPNG draws the recap card as a Width×Height PNG.
+func RenderPNG(r *progress.Recap, lang string) ([]byte, error) {
+	if err := loadFonts(); err != nil {
+		return nil, fmt.Errorf("load fonts: %w", err)
+	}
+	c := layout(r, lang)
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	for _, rc := range c.rects {
+		draw.Draw(img, image.Rect(rc.x, rc.y, rc.x+rc.w, rc.y+rc.h), image.NewUniform(rc.fill), image.Point{}, draw.Src)
+	}
+
+	faces := make(map[faceKey]font.Face)
+	defer func() {
+		for _, f := range faces {
+			_ = f.Close()
+		}
+	}()
+	for _, t := range c.texts {
+		face, err := faceFor(faces, t.size, t.bold)
+		if err != nil {
+			return nil, err
+		}
+		d := &font.Drawer{Dst: img, Src: image.NewUniform(t.fill), Face: face}
+		x := fixed.I(t.x)
+		switch t.anchor {
+		case anchorMiddle:
+			x -= d.MeasureString(t.value) / 2
+		case anchorEnd:
+			x -= d.MeasureString(t.value)
+		}
+		d.Dot = fixed.Point26_6{X: x, Y: fixed.I(t.y)}
+		d.DrawString(t.value)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type faceKey struct {
+	size float64
+	bold bool
+}
+
+func faceFor(cache map[faceKey]font.Face, size float64, isBold bool) (font.Face, error) {
+	key := faceKey{size: size, bold: isBold}
+	if f, ok := cache[key]; ok {
+		return f, nil
+	}
+	src := regular
+	if isBold {
+		src = bold
+	}
+	f, err := opentype.NewFace(src, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("new font face: %w", err)
+	}
+	cache[key] = f
+	return f, nil
+}
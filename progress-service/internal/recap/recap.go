@@ -0,0 +1,121 @@
+// Package recap renders the shareable weekly recap card as PNG or SVG. Rendering
+// is pure Go with the bundled Go fonts, so it needs no network or system fonts.
+package recap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Card dimensions, in pixels.
+const (
+	Width  = 1080
+	Height = 1080
+)
+
+// ErrUnsupportedLanguage indicates a language without recap translations.
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// Supported languages.
+const (
+	LangEN = "en"
+	LangID = "id"
+)
+
+type labels struct {
+	title      string
+	totalFocus string
+	sessions   string
+	streak     string
+	days       string
+	bestHour   string
+	topCats    string
+	noActivity string
+	hourUnit   string
+	minuteUnit string
+	weekdays   [7]string // Monday first
+	months     [12]string
+}
+
+var translations = map[string]labels{
+	LangEN: {
+		title:      "My focus week",
+		totalFocus: "Total focus",
+		sessions:   "sessions",
+		streak:     "Streak",
+		days:       "days",
+		bestHour:   "Best hour",
+		topCats:    "Top categories",
+		noActivity: "No sessions yet",
+		hourUnit:   "h",
+		minuteUnit: "m",
+		weekdays:   [7]string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		months:     [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	},
+	LangID: {
+		title:      "Minggu fokusku",
+		totalFocus: "Total fokus",
+		sessions:   "sesi",
+		streak:     "Streak",
+		days:       "hari",
+		bestHour:   "Jam terbaik",
+		topCats:    "Kategori teratas",
+		noActivity: "Belum ada sesi",
+		hourUnit:   "j",
+		minuteUnit: "m",
+		weekdays:   [7]string{"Sen", "Sel", "Rab", "Kam", "Jum", "Sab", "Min"},
+		months:     [12]string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"},
+	},
+}
+
+// NormalizeLang lowercases lang and defaults it to English. It returns
+// ErrUnsupportedLanguage for languages without translations.
+func NormalizeLang(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return LangEN, nil
+	}
+	if _, ok := translations[lang]; !ok {
+		return "", ErrUnsupportedLanguage
+	}
+	return lang, nil
+}
+
+func labelsFor(lang string) labels {
+	if l, ok := translations[lang]; ok {
+		return l
+	}
+	return translations[LangEN]
+}
+
+// formatDuration renders minutes as "2h 05m" (or "2j 05m" in Indonesian).
+func formatDuration(minutes int, l labels) string {
+	h, m := minutes/60, minutes%60
+	if h == 0 {
+		return fmt.Sprintf("%d%s", m, l.minuteUnit)
+	}
+	return fmt.Sprintf("%d%s %02d%s", h, l.hourUnit, m, l.minuteUnit)
+}
+
+// formatRange renders "17–23 Nov 2025", spanning months or years when needed.
+func formatRange(start, end string, l labels) string {
+	s, err1 := time.Parse("2006-01-02", start)
+	e, err2 := time.Parse("2006-01-02", end)
+	if err1 != nil || err2 != nil {
+		return start + " – " + end
+	}
+	switch {
+	case s.Year() != e.Year():
+		return fmt.Sprintf("%d %s %d – %d %s %d", s.Day(), l.months[s.Month()-1], s.Year(), e.Day(), l.months[e.Month()-1], e.Year())
+	case s.Month() != e.Month():
+		return fmt.Sprintf("%d %s – %d %s %d", s.Day(), l.months[s.Month()-1], e.Day(), l.months[e.Month()-1], e.Year())
+	default:
+		return fmt.Sprintf("%d–%d %s %d", s.Day(), e.Day(), l.months[e.Month()-1], e.Year())
+	}
+}
+
+func formatHour(hour int) string {
+	return fmt.Sprintf("%02d:00–%02d:00", hour, (hour+1)%24)
+}
@@ -0,0 +1,117 @@
+package recap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/focusnest/progress-service/internal/progress"
+)
+
+func sampleRecap() *progress.Recap {
+	hour := 9
+	days := make([]progress.RecapDay, 7)
+	for i := range days {
+		days[i] = progress.RecapDay{Weekday: i, Minutes: i * 15}
+	}
+	return &progress.Recap{
+		WeekStart:     "2025-11-17",
+		WeekEnd:       "2025-11-23",
+		TotalMinutes:  315,
+		TotalSessions: 9,
+		CurrentStreak: 4,
+		TopCategories: []progress.RecapCategory{{Category: "Work", Minutes: 200}, {Category: "Study & Read", Minutes: 115}},
+		Days:          days,
+		BestHour:      &hour,
+	}
+}
+
+func TestNormalizeLang(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: LangEN},
+		{in: "ID", want: LangID},
+		{in: " en ", want: LangEN},
+		{in: "fr", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeLang(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeLang(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := formatDuration(125, translations[LangEN]); got != "2h 05m" {
+		t.Errorf("formatDuration(en) = %q", got)
+	}
+	if got := formatDuration(125, translations[LangID]); got != "2j 05m" {
+		t.Errorf("formatDuration(id) = %q", got)
+	}
+	if got := formatDuration(45, translations[LangEN]); got != "45m" {
+		t.Errorf("formatDuration(<1h) = %q", got)
+	}
+}
+
+func TestFormatRange(t *testing.T) {
+	l := translations[LangID]
+	if got := formatRange("2025-11-17", "2025-11-23", l); got != "17–23 Nov 2025" {
+		t.Errorf("same month = %q", got)
+	}
+	if got := formatRange("2025-07-28", "2025-08-03", l); got != "28 Jul – 3 Agu 2025" {
+		t.Errorf("across months = %q", got)
+	}
+	if got := formatRange("2025-12-29", "2026-01-04", l); got != "29 Des 2025 – 4 Jan 2026" {
+		t.Errorf("across years = %q", got)
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	data, err := RenderPNG(sampleRecap(), LangEN)
+	if err != nil {
+		t.Fatalf("RenderPNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != Width || b.Dy() != Height {
+		t.Errorf("bounds = %v, want %dx%d", b, Width, Height)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	data, err := RenderSVG(sampleRecap(), LangID)
+	if err != nil {
+		t.Fatalf("RenderSVG() error = %v", err)
+	}
+	if err := xml.Unmarshal(data, new(struct{})); err != nil {
+		t.Fatalf("SVG is not well-formed XML: %v", err)
+	}
+	svg := string(data)
+	for _, want := range []string{"Minggu fokusku", "5j 15m", "4 hari", "Study &amp; Read", "Sen", "Min", "09:00–10:00"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG missing %q", want)
+		}
+	}
+}
+
+func TestRenderEmptyWeek(t *testing.T) {
+	empty := &progress.Recap{WeekStart: "2025-11-17", WeekEnd: "2025-11-23", Days: make([]progress.RecapDay, 7)}
+	if _, err := RenderPNG(empty, LangEN); err != nil {
+		t.Fatalf("RenderPNG() error = %v", err)
+	}
+	data, err := RenderSVG(empty, LangEN)
+	if err != nil {
+		t.Fatalf("RenderSVG() error = %v", err)
+	}
+	if !strings.Contains(string(data), "No sessions yet") {
+		t.Errorf("empty recap should say there are no sessions")
+	}
+}
@@ -0,0 +1,53 @@
+package recap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+
+	"github.com/focusnest/progress-service/internal/progress"
+)
+
+// svgFontFamily prefers the Go fonts used by the PNG renderer.
+const svgFontFamily = "Go, Helvetica, Arial, sans-serif"
+
+// RenderSVG draws the recap card as a standalone SVG document.
+func RenderSVG(r *progress.Recap, lang string) ([]byte, error) {
+	c := layout(r, lang)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s">`+"\n",
+		Width, Height, Width, Height, svgFontFamily)
+	for _, rc := range c.rects {
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", rc.x, rc.y, rc.w, rc.h, hexColor(rc.fill))
+	}
+	for _, t := range c.texts {
+		weight := "normal"
+		if t.bold {
+			weight = "bold"
+		}
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="%g" font-weight="%s" text-anchor="%s" fill="%s">`,
+			t.x, t.y, t.size, weight, svgAnchor(t.anchor), hexColor(t.fill))
+		if err := xml.EscapeText(&buf, []byte(t.value)); err != nil {
+			return nil, fmt.Errorf("escape text: %w", err)
+		}
+		buf.WriteString("</text>\n")
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), nil
+}
+
+func svgAnchor(a anchor) string {
+	switch a {
+	case anchorMiddle:
+		return "middle"
+	case anchorEnd:
+		return "end"
+	default:
+		return "start"
+	}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
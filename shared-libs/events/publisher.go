@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Event types published on pubsub.TopicUserEvents.
+const (
+	TypeUserSynced    = "user.synced"
+	TypeUserDeleted   = "user.deleted"
+	TypeUserLeveledUp = "user.leveled_up"
+)
+
+// Publisher delivers an event payload to a topic.
+type Publisher interface {
+	Publish(ctx context.Context, topic, eventType string, payload any) error
+}
+
+// LogPublisher records events in the structured log until a message broker
+// is wired in. Payloads can hold personal data, so only the topic and type
+// are logged. It never fails.
+type LogPublisher struct {
+	Logger *slog.Logger
+}
+
+// Publish logs the event.
+func (p LogPublisher) Publish(ctx context.Context, topic, eventType string, payload any) error {
+	p.Logger.InfoContext(ctx, "event published",
+		slog.String("topic", topic),
+		slog.String("eventType", eventType))
+	return nil
+}
@@ -16,3 +16,12 @@ type UserDeleted struct {
 	UserID    string    `json:"userId"`
 	DeletedAt time.Time `json:"deletedAt"`
 }
+
+// UserLeveledUp is emitted when a user's XP crosses into a higher level.
+type UserLeveledUp struct {
+	UserID        string    `json:"userId"`
+	PreviousLevel int       `json:"previousLevel"`
+	Level         int       `json:"level"`
+	XP            int       `json:"xp"`
+	LeveledUpAt   time.Time `json:"leveledUpAt"`
+}
@@ -0,0 +1,50 @@
+// Package imageupload holds the checks applied to user-uploaded images:
+// session photos in focus-service and avatars in user-service.
+package imageupload
+
+import (
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	allowedExtensions = map[string]struct{}{
+		".jpg":  {},
+		".jpeg": {},
+		".png":  {},
+		".webp": {},
+		".heic": {},
+		".heif": {},
+	}
+	allowedMIMEs = map[string]struct{}{
+		"image/jpeg": {},
+		"image/png":  {},
+		"image/webp": {},
+		"image/heic": {},
+		"image/heif": {},
+	}
+)
+
+// ValidateFile checks the extension and, when sent, the Content-Type of a
+// multipart image upload.
+func ValidateFile(header *multipart.FileHeader) error {
+	if header == nil {
+		return fmt.Errorf("invalid image upload")
+	}
+	if _, ok := allowedExtensions[Extension(header.Filename)]; !ok {
+		return fmt.Errorf("unsupported image type; allowed formats: jpg, jpeg, png, webp, heic, heif")
+	}
+	if ct := strings.ToLower(header.Header.Get("Content-Type")); ct != "" {
+		if _, ok := allowedMIMEs[ct]; !ok {
+			return fmt.Errorf("unsupported image content type; allowed: image/jpeg, image/png, image/webp, image/heic, image/heif")
+		}
+	}
+	return nil
+}
+
+// Extension returns the lowercased extension of filename, including the dot.
+func Extension(filename string) string {
+	return strings.ToLower(filepath.Ext(filename))
+}
@@ -0,0 +1,149 @@
+// Package insights turns a user's recent focus sessions into short, ranked,
+// human-readable observations. Each observation comes from a Rule; rules are
+// plain values so services can run the defaults, drop some, or add their own.
+package insights
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Supported languages.
+const (
+	LangEN = "en"
+	LangID = "id"
+)
+
+// ErrUnsupportedLang is returned by NormalizeLang for languages without messages.
+var ErrUnsupportedLang = errors.New("unsupported language")
+
+// Session is the part of a focus session the rules look at. Start must already
+// be in the user's location.
+type Session struct {
+	Start    time.Time
+	Minutes  int
+	Category string
+	Mood     string
+	TimeMode string
+}
+
+// Data is the input to every rule. Sessions cover [Since, Now]; rules that
+// compare windows must check Since before trusting an empty window.
+type Data struct {
+	Sessions []Session
+	Since    time.Time
+	Now      time.Time
+}
+
+// Insight is a single observation. Key selects the localized message and Args
+// fill it; Term arguments are translated as well.
+type Insight struct {
+	Rule       string  `json:"rule"`
+	Key        string  `json:"key"`
+	Confidence float64 `json:"confidence"` // 0–1, mostly driven by sample size
+	Score      float64 `json:"score"`      // confidence weighted by effect size; used for ranking
+	Message    string  `json:"message"`
+	Args       []any   `json:"-"`
+}
+
+// Rule produces zero or more insights from data.
+type Rule interface {
+	ID() string
+	Evaluate(data *Data) []Insight
+}
+
+// Engine runs a fixed set of rules.
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine returns an engine running rules in order.
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// DefaultRules returns the built-in rules.
+func DefaultRules() []Rule {
+	return []Rule{TimeOfDayRule{}, WeeklyTrendRule{}, LateNightMoodRule{}}
+}
+
+// Evaluate runs every rule and returns the insights ranked by score, then
+// confidence. Messages are left empty; see Localize.
+func (e *Engine) Evaluate(data *Data) []Insight {
+	var out []Insight
+	for _, rule := range e.rules {
+		for _, in := range rule.Evaluate(data) {
+			if in.Rule == "" {
+				in.Rule = rule.ID()
+			}
+			in.Confidence = round2(clamp01(in.Confidence))
+			in.Score = round2(clamp01(in.Score))
+			out = append(out, in)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Confidence > out[j].Confidence
+	})
+	return out
+}
+
+// Generate evaluates data and returns at most limit localized insights
+// (all of them when limit <= 0).
+func (e *Engine) Generate(data *Data, lang string, limit int) []Insight {
+	out := e.Evaluate(data)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	for i := range out {
+		out[i].Message = Localize(out[i], lang)
+	}
+	return out
+}
+
+// NormalizeLang lower-cases lang and defaults it to English.
+func NormalizeLang(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	switch lang {
+	case "":
+		return LangEN, nil
+	case LangEN, LangID:
+		return lang, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedLang, lang)
+	}
+}
+
+// Localize renders the insight's message in lang, falling back to English.
+func Localize(in Insight, lang string) string {
+	table, ok := messages[lang]
+	if !ok {
+		lang, table = LangEN, messages[LangEN]
+	}
+	format, ok := table[in.Key]
+	if !ok {
+		format = messages[LangEN][in.Key]
+	}
+	args := make([]any, len(in.Args))
+	for i, arg := range in.Args {
+		if term, ok := arg.(Term); ok {
+			arg = term.In(lang)
+		}
+		args[i] = arg
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,64 @@
+package insights
+
+// Term is an insight argument that is itself translated, such as a time of day.
+type Term string
+
+// Terms used by the built-in rules.
+const (
+	TermMorning   Term = "morning"
+	TermAfternoon Term = "afternoon"
+	TermEvening   Term = "evening"
+	TermNight     Term = "night"
+)
+
+// In returns the term in lang, or the term itself when it has no translation.
+func (t Term) In(lang string) string {
+	if v, ok := terms[lang][t]; ok {
+		return v
+	}
+	return string(t)
+}
+
+// Message keys used by the built-in rules.
+const (
+	KeyTimeOfDayLonger = "time_of_day_longer"
+	KeyTotalUp         = "total_up"
+	KeyTotalDown       = "total_down"
+	KeyCategoryUp      = "category_up"
+	KeyCategoryDown    = "category_down"
+	KeyLateNightMood   = "late_night_mood"
+)
+
+var messages = map[string]map[string]string{
+	LangEN: {
+		KeyTimeOfDayLonger: "You focus %d%% longer %s than at other times of day",
+		KeyTotalUp:         "Your focus time rose %d%% vs last week",
+		KeyTotalDown:       "Your focus time dropped %d%% vs last week",
+		KeyCategoryUp:      "%s time rose %d%% vs last week",
+		KeyCategoryDown:    "%s time dropped %d%% vs last week",
+		KeyLateNightMood:   "Sessions after 22:00 are mostly tagged %s (%d%%)",
+	},
+	LangID: {
+		KeyTimeOfDayLonger: "Kamu fokus %d%% lebih lama %s dibanding waktu lain",
+		KeyTotalUp:         "Waktu fokusmu naik %d%% dibanding minggu lalu",
+		KeyTotalDown:       "Waktu fokusmu turun %d%% dibanding minggu lalu",
+		KeyCategoryUp:      "Waktu %s naik %d%% dibanding minggu lalu",
+		KeyCategoryDown:    "Waktu %s turun %d%% dibanding minggu lalu",
+		KeyLateNightMood:   "Sesi setelah pukul 22:00 kebanyakan bertanda %s (%d%%)",
+	},
+}
+
+var terms = map[string]map[Term]string{
+	LangEN: {
+		TermMorning:   "in the morning",
+		TermAfternoon: "in the afternoon",
+		TermEvening:   "in the evening",
+		TermNight:     "at night",
+	},
+	LangID: {
+		TermMorning:   "di pagi hari",
+		TermAfternoon: "di siang hari",
+		TermEvening:   "di sore hari",
+		TermNight:     "di malam hari",
+	},
+}
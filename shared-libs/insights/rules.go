@@ -0,0 +1,226 @@
+package insights
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	minSegmentSessions = 3  // per side of a time-of-day comparison
+	minTimeOfDayGain   = 20 // percent
+	minTrendMinutes    = 30 // previous-week minutes needed to report a trend
+	minTotalTrend      = 20 // percent
+	minCategoryTrend   = 25 // percent
+	minLateSessions    = 3
+	minLateMoodShare   = 0.5
+)
+
+// TimeOfDayRule compares the average session length of the user's best time of
+// day with every other time of day.
+type TimeOfDayRule struct{}
+
+func (TimeOfDayRule) ID() string { return "time_of_day" }
+
+func (TimeOfDayRule) Evaluate(data *Data) []Insight {
+	type stat struct{ n, minutes int }
+	segments := []Term{TermMorning, TermAfternoon, TermEvening, TermNight}
+	stats := make(map[Term]*stat, len(segments))
+	for _, seg := range segments {
+		stats[seg] = &stat{}
+	}
+	var total stat
+	for _, s := range data.Sessions {
+		if s.Minutes <= 0 {
+			continue
+		}
+		st := stats[segmentOf(s.Start.Hour())]
+		st.n++
+		st.minutes += s.Minutes
+		total.n++
+		total.minutes += s.Minutes
+	}
+
+	var (
+		best    Term
+		bestAvg float64
+	)
+	for _, seg := range segments {
+		st := stats[seg]
+		if st.n < minSegmentSessions {
+			continue
+		}
+		if avg := float64(st.minutes) / float64(st.n); avg > bestAvg {
+			best, bestAvg = seg, avg
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	restN := total.n - stats[best].n
+	restMinutes := total.minutes - stats[best].minutes
+	if restN < minSegmentSessions || restMinutes == 0 {
+		return nil
+	}
+	pct := int(math.Round((bestAvg/(float64(restMinutes)/float64(restN)) - 1) * 100))
+	if pct < minTimeOfDayGain {
+		return nil
+	}
+	confidence := math.Min(1, float64(total.n)/30)
+	return []Insight{{
+		Key:        KeyTimeOfDayLonger,
+		Args:       []any{pct, best},
+		Confidence: confidence,
+		Score:      confidence * math.Min(1, float64(pct)/100),
+	}}
+}
+
+// WeeklyTrendRule compares the last 7 days with the 7 days before, overall and
+// per category.
+type WeeklyTrendRule struct{}
+
+func (WeeklyTrendRule) ID() string { return "weekly_trend" }
+
+func (WeeklyTrendRule) Evaluate(data *Data) []Insight {
+	today := startOfDay(data.Now)
+	curStart := today.AddDate(0, 0, -6)
+	prevStart := today.AddDate(0, 0, -13)
+	end := today.AddDate(0, 0, 1)
+	if data.Since.After(prevStart) {
+		return nil
+	}
+
+	type window struct{ n, minutes int }
+	var cur, prev window
+	curCat := make(map[string]int)
+	prevCat := make(map[string]int)
+	for _, s := range data.Sessions {
+		switch {
+		case s.Start.Before(prevStart) || !s.Start.Before(end):
+			continue
+		case s.Start.Before(curStart):
+			prev.n++
+			prev.minutes += s.Minutes
+			if s.Category != "" {
+				prevCat[s.Category] += s.Minutes
+			}
+		default:
+			cur.n++
+			cur.minutes += s.Minutes
+			if s.Category != "" {
+				curCat[s.Category] += s.Minutes
+			}
+		}
+	}
+
+	confidence := math.Min(1, float64(cur.n+prev.n)/10)
+	trend := func(current, previous, threshold int) (int, bool) {
+		if previous < minTrendMinutes {
+			return 0, false
+		}
+		pct := int(math.Round(float64(current-previous) * 100 / float64(previous)))
+		return pct, abs(pct) >= threshold
+	}
+	insight := func(up, down string, pct int, args ...any) Insight {
+		key := up
+		if pct < 0 {
+			key = down
+		}
+		return Insight{
+			Key:        key,
+			Args:       append(args, abs(pct)),
+			Confidence: confidence,
+			Score:      confidence * math.Min(1, float64(abs(pct))/100),
+		}
+	}
+
+	var out []Insight
+	if pct, ok := trend(cur.minutes, prev.minutes, minTotalTrend); ok {
+		out = append(out, insight(KeyTotalUp, KeyTotalDown, pct))
+	}
+	categories := make(map[string]bool, len(prevCat))
+	for cat := range prevCat {
+		categories[cat] = true
+	}
+	for cat := range curCat {
+		categories[cat] = true
+	}
+	if len(categories) < 2 {
+		return out // a single category would just repeat the total
+	}
+	names := make([]string, 0, len(categories))
+	for cat := range categories {
+		names = append(names, cat)
+	}
+	sort.Strings(names)
+	for _, cat := range names {
+		if pct, ok := trend(curCat[cat], prevCat[cat], minCategoryTrend); ok {
+			out = append(out, insight(KeyCategoryUp, KeyCategoryDown, pct, cat))
+		}
+	}
+	return out
+}
+
+// LateNightMoodRule reports the dominant mood of sessions started between
+// 22:00 and 04:00.
+type LateNightMoodRule struct{}
+
+func (LateNightMoodRule) ID() string { return "late_night_mood" }
+
+func (LateNightMoodRule) Evaluate(data *Data) []Insight {
+	moods := make(map[string]int)
+	n := 0
+	for _, s := range data.Sessions {
+		if h := s.Start.Hour(); (h >= 22 || h < 4) && s.Mood != "" {
+			moods[s.Mood]++
+			n++
+		}
+	}
+	if n < minLateSessions {
+		return nil
+	}
+	var top string
+	for mood, count := range moods {
+		if count > moods[top] || (count == moods[top] && mood < top) {
+			top = mood
+		}
+	}
+	share := float64(moods[top]) / float64(n)
+	if share < minLateMoodShare {
+		return nil
+	}
+	confidence := math.Min(1, float64(n)/8)
+	return []Insight{{
+		Key:        KeyLateNightMood,
+		Args:       []any{top, int(math.Round(share * 100))},
+		Confidence: confidence,
+		Score:      confidence * share,
+	}}
+}
+
+// segmentOf maps a local hour to the same time-of-day segments progress-service
+// uses for mood analytics.
+func segmentOf(hour int) Term {
+	switch {
+	case hour >= 5 && hour < 12:
+		return TermMorning
+	case hour >= 12 && hour < 17:
+		return TermAfternoon
+	case hour >= 17 && hour < 21:
+		return TermEvening
+	default:
+		return TermNight
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
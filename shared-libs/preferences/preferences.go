@@ -0,0 +1,225 @@
+// Package preferences defines the user preferences schema shared by every
+// service. user-service owns writes (/v1/users/me/preferences); other
+// services read the same profiles/{uid} document and decode it with
+// Document, so they see the same defaults and migrations:
+//
+//	var doc preferences.Document
+//	if err := snap.DataTo(&doc); err != nil { ... }
+//	prefs := doc.Resolve()
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/focusnest/shared-libs/timezone"
+)
+
+const (
+	// SchemaVersion is the current layout of Preferences. Bump it when a
+	// field is added or its meaning changes, and teach migrate the upgrade.
+	SchemaVersion = 1
+	// ProfileField is the profiles/{uid} field holding Preferences. The
+	// timezone stays in timezone.ProfileField, where it already lived.
+	ProfileField = "preferences"
+)
+
+// Supported languages, matching the app's translations.
+const (
+	LangEN = "en"
+	LangID = "id"
+)
+
+// Week start days.
+const (
+	WeekStartMonday   = "monday"
+	WeekStartSunday   = "sunday"
+	WeekStartSaturday = "saturday"
+)
+
+// Bounds for the numeric fields, in minutes.
+const (
+	MinFocusMinutes     = 5
+	MaxFocusMinutes     = 180
+	MinBreakMinutes     = 1
+	MaxBreakMinutes     = 60
+	MaxDailyGoalMinutes = 24 * 60
+)
+
+// ErrInvalid indicates a value outside the schema.
+var ErrInvalid = errors.New("invalid preferences")
+
+// Notifications are opt-ins; all are off until the user turns them on.
+type Notifications struct {
+	DailyReminder  bool `json:"daily_reminder" firestore:"daily_reminder"`
+	StreakReminder bool `json:"streak_reminder" firestore:"streak_reminder"`
+	FriendActivity bool `json:"friend_activity" firestore:"friend_activity"`
+	WeeklySummary  bool `json:"weekly_summary" firestore:"weekly_summary"`
+}
+
+// Preferences are the user's app settings.
+type Preferences struct {
+	// Version is the schema version the document was written with.
+	Version int `json:"version" firestore:"version"`
+	// Revision counts writes; 0 means the user never saved preferences.
+	Revision          int           `json:"revision" firestore:"revision"`
+	Language          string        `json:"language" firestore:"language"`
+	Timezone          string        `json:"timezone" firestore:"-"` // "" means unset; see timezone.Load
+	FocusMinutes      int           `json:"focus_minutes" firestore:"focus_minutes"`
+	ShortBreakMinutes int           `json:"short_break_minutes" firestore:"short_break_minutes"`
+	LongBreakMinutes  int           `json:"long_break_minutes" firestore:"long_break_minutes"`
+	WeekStart         string        `json:"week_start" firestore:"week_start"`
+	DailyGoalMinutes  int           `json:"daily_goal_minutes" firestore:"daily_goal_minutes"` // 0 means no goal
+	Notifications     Notifications `json:"notifications" firestore:"notifications"`
+	UpdatedAt         *time.Time    `json:"updated_at" firestore:"updated_at"`
+}
+
+// Defaults returns the preferences of a user who never saved any.
+func Defaults() Preferences {
+	return Preferences{
+		Version:           SchemaVersion,
+		Language:          LangEN,
+		FocusMinutes:      25,
+		ShortBreakMinutes: 5,
+		LongBreakMinutes:  15,
+		WeekStart:         WeekStartMonday,
+		DailyGoalMinutes:  120,
+	}
+}
+
+// Document is the part of a profiles/{uid} snapshot holding preferences.
+type Document struct {
+	Timezone    string       `firestore:"timezone"`
+	Preferences *Preferences `firestore:"preferences"`
+}
+
+// Resolve returns the stored preferences upgraded to SchemaVersion, or the
+// defaults when none were saved.
+func (d Document) Resolve() Preferences {
+	p := Defaults()
+	if d.Preferences != nil {
+		p = migrate(*d.Preferences)
+	}
+	p.Timezone = strings.TrimSpace(d.Timezone)
+	return p
+}
+
+// migrate upgrades a stored document. Version 1 is the first layout, so
+// this only fills fields that are missing or no longer valid.
+func migrate(p Preferences) Preferences {
+	def := Defaults()
+	if _, ok := languages[p.Language]; !ok {
+		p.Language = def.Language
+	}
+	if _, ok := weekStarts[p.WeekStart]; !ok {
+		p.WeekStart = def.WeekStart
+	}
+	if p.FocusMinutes < MinFocusMinutes || p.FocusMinutes > MaxFocusMinutes {
+		p.FocusMinutes = def.FocusMinutes
+	}
+	if p.ShortBreakMinutes < MinBreakMinutes || p.ShortBreakMinutes > MaxBreakMinutes {
+		p.ShortBreakMinutes = def.ShortBreakMinutes
+	}
+	if p.LongBreakMinutes < MinBreakMinutes || p.LongBreakMinutes > MaxBreakMinutes {
+		p.LongBreakMinutes = def.LongBreakMinutes
+	}
+	if p.DailyGoalMinutes < 0 || p.DailyGoalMinutes > MaxDailyGoalMinutes {
+		p.DailyGoalMinutes = def.DailyGoalMinutes
+	}
+	p.Version = SchemaVersion
+	return p
+}
+
+var (
+	languages  = map[string]struct{}{LangEN: {}, LangID: {}}
+	weekStarts = map[string]struct{}{WeekStartMonday: {}, WeekStartSunday: {}, WeekStartSaturday: {}}
+)
+
+// NotificationsPatch updates notification opt-ins; nil fields are left
+// unchanged.
+type NotificationsPatch struct {
+	DailyReminder  *bool `json:"daily_reminder"`
+	StreakReminder *bool `json:"streak_reminder"`
+	FriendActivity *bool `json:"friend_activity"`
+	WeeklySummary  *bool `json:"weekly_summary"`
+}
+
+// Patch updates preferences; nil fields are left unchanged. Timezone ""
+// clears it, as on PATCH /v1/users/me.
+type Patch struct {
+	Language          *string             `json:"language"`
+	Timezone          *string             `json:"timezone"`
+	FocusMinutes      *int                `json:"focus_minutes"`
+	ShortBreakMinutes *int                `json:"short_break_minutes"`
+	LongBreakMinutes  *int                `json:"long_break_minutes"`
+	WeekStart         *string             `json:"week_start"`
+	DailyGoalMinutes  *int                `json:"daily_goal_minutes"`
+	Notifications     *NotificationsPatch `json:"notifications"`
+}
+
+// IsEmpty reports whether the patch changes nothing.
+func (patch Patch) IsEmpty() bool {
+	return patch == Patch{}
+}
+
+// Apply validates the patch and returns p with it applied.
+func (patch Patch) Apply(p Preferences) (Preferences, error) {
+	if patch.Language != nil {
+		lang := strings.ToLower(strings.TrimSpace(*patch.Language))
+		if _, ok := languages[lang]; !ok {
+			return p, fmt.Errorf("%w: language must be %s or %s", ErrInvalid, LangEN, LangID)
+		}
+		p.Language = lang
+	}
+	if patch.Timezone != nil {
+		tz := strings.TrimSpace(*patch.Timezone)
+		if tz != "" && !timezone.Valid(tz) {
+			return p, fmt.Errorf("%w: timezone must be an IANA name such as %s", ErrInvalid, timezone.Default)
+		}
+		p.Timezone = tz
+	}
+	for _, field := range []struct {
+		name     string
+		dst      *int
+		value    *int
+		min, max int
+	}{
+		{"focus_minutes", &p.FocusMinutes, patch.FocusMinutes, MinFocusMinutes, MaxFocusMinutes},
+		{"short_break_minutes", &p.ShortBreakMinutes, patch.ShortBreakMinutes, MinBreakMinutes, MaxBreakMinutes},
+		{"long_break_minutes", &p.LongBreakMinutes, patch.LongBreakMinutes, MinBreakMinutes, MaxBreakMinutes},
+		{"daily_goal_minutes", &p.DailyGoalMinutes, patch.DailyGoalMinutes, 0, MaxDailyGoalMinutes},
+	} {
+		if field.value == nil {
+			continue
+		}
+		if *field.value < field.min || *field.value > field.max {
+			return p, fmt.Errorf("%w: %s must be between %d and %d", ErrInvalid, field.name, field.min, field.max)
+		}
+		*field.dst = *field.value
+	}
+	if patch.WeekStart != nil {
+		day := strings.ToLower(strings.TrimSpace(*patch.WeekStart))
+		if _, ok := weekStarts[day]; !ok {
+			return p, fmt.Errorf("%w: week_start must be %s, %s or %s", ErrInvalid, WeekStartMonday, WeekStartSunday, WeekStartSaturday)
+		}
+		p.WeekStart = day
+	}
+	if n := patch.Notifications; n != nil {
+		for _, field := range []struct {
+			dst   *bool
+			value *bool
+		}{
+			{&p.Notifications.DailyReminder, n.DailyReminder},
+			{&p.Notifications.StreakReminder, n.StreakReminder},
+			{&p.Notifications.FriendActivity, n.FriendActivity},
+			{&p.Notifications.WeeklySummary, n.WeeklySummary},
+		} {
+			if field.value != nil {
+				*field.dst = *field.value
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,71 @@
+package profilestats
+
+import "time"
+
+// Achievement IDs. They are stored as badge IDs and must stay stable.
+const (
+	AchievementFirstDeepWork = "first_deep_work"
+	AchievementPomodoro100   = "pomodoro_100"
+	AchievementStreak30      = "streak_30"
+	AchievementAllCategories = "all_categories"
+	AchievementNightOwl      = "night_owl"
+	AchievementEarlyBird     = "early_bird"
+)
+
+// Awards are stored once per badge in profiles/{uid}/badges/{id}; user-service
+// owns the catalog and awards point badges there too.
+const (
+	AwardCollection        = "badges"
+	AwardSourceAchievement = "achievement"
+)
+
+// Award is a badge award document.
+type Award struct {
+	Source    string    `firestore:"source"`
+	AwardedAt time.Time `firestore:"awarded_at"`
+}
+
+const (
+	timeModeDeepWork = "Deep Work"
+	timeModePomodoro = "Pomodoro"
+
+	// Session start hours, local to where the session was recorded.
+	nightOwlFromHour  = 22 // 22:00–03:59
+	earlyBirdFromHour = 4  // 04:00–06:59
+	earlyBirdToHour   = 7
+)
+
+// Categories mirrors the categories focus-service accepts.
+var Categories = []string{"Work", "Study", "Read", "Journal", "Cook", "Workout", "Music", "Other"}
+
+// Achievements lists the achievements the counted sessions unlock. Awards
+// are permanent, so callers only add to what is already held.
+func (c Counters) Achievements() []string {
+	var ids []string
+	if c.DeepWorkSessions > 0 {
+		ids = append(ids, AchievementFirstDeepWork)
+	}
+	if c.PomodoroCycles >= 100 {
+		ids = append(ids, AchievementPomodoro100)
+	}
+	if c.LongestStreak() >= 30 {
+		ids = append(ids, AchievementStreak30)
+	}
+	all := true
+	for _, category := range Categories {
+		if c.Categories[category] == 0 {
+			all = false
+			break
+		}
+	}
+	if all {
+		ids = append(ids, AchievementAllCategories)
+	}
+	if c.NightOwlSessions > 0 {
+		ids = append(ids, AchievementNightOwl)
+	}
+	if c.EarlyBirdSessions > 0 {
+		ids = append(ids, AchievementEarlyBird)
+	}
+	return ids
+}
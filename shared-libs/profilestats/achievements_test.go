@@ -0,0 +1,49 @@
+package profilestats
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAchievements(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	day := time.Date(2025, 11, 3, 0, 0, 0, 0, jakarta)
+	sessions := []Session{
+		{StartTime: day.Add(23 * time.Hour), TimeMode: "Pomodoro", NumCycle: 4, Category: "Work"},
+		{StartTime: day.AddDate(0, 0, 1).Add(9 * time.Hour), TimeMode: "Pomodoro", Category: "Study"},
+		{StartTime: day.AddDate(0, 0, 2).Add(10 * time.Hour), TimeMode: "Deep Work", Category: " Read "},
+		// Recorded in Tokyo at 05:30 local, which is 03:30 in Jakarta.
+		{StartTime: time.Date(2025, 11, 10, 5, 30, 0, 0, time.FixedZone("JST", 9*3600)), Timezone: "Asia/Tokyo", TimeMode: "Free Timer", Category: "Cook"},
+	}
+	c := Build(sessions, "Asia/Jakarta")
+
+	if c.DeepWorkSessions != 1 || c.PomodoroCycles != 5 || c.NightOwlSessions != 1 || c.EarlyBirdSessions != 1 {
+		t.Fatalf("counters = %+v, want one Deep Work session, 5 Pomodoro cycles, one night and one early session", c)
+	}
+	want := []string{AchievementFirstDeepWork, AchievementNightOwl, AchievementEarlyBird}
+	if got := c.Achievements(); !slices.Equal(got, want) {
+		t.Fatalf("Achievements() = %v, want %v", got, want)
+	}
+
+	// Deleting the Deep Work session locks its achievement again; awards
+	// already stored are kept by the caller.
+	c.Replace(&sessions[2], nil)
+	if slices.Contains(c.Achievements(), AchievementFirstDeepWork) {
+		t.Fatalf("Achievements() = %v after deleting the Deep Work session", c.Achievements())
+	}
+}
+
+func TestAllCategoriesAchievement(t *testing.T) {
+	var c Counters
+	for _, category := range Categories[1:] {
+		c.Replace(nil, &Session{StartTime: at(17, 9), Timezone: "UTC", Category: category})
+	}
+	if slices.Contains(c.Achievements(), AchievementAllCategories) {
+		t.Fatalf("all_categories unlocked without %s", Categories[0])
+	}
+	c.Replace(nil, &Session{StartTime: at(17, 9), Timezone: "UTC", Category: Categories[0]})
+	if !slices.Contains(c.Achievements(), AchievementAllCategories) {
+		t.Fatal("all_categories locked with every category tried")
+	}
+}
@@ -0,0 +1,254 @@
+// Package profilestats defines the per-user session counters behind the
+// profile metadata (sessions, cycles, focus time, categories, longest
+// streak), XP and achievements.
+// They live in users/{uid}/stats/profile. focus-service updates them in the
+// same transaction as every productivity write, so user-service can read one
+// document instead of scanning every session:
+//
+//	var c profilestats.Counters
+//	if err := snap.DataTo(&c); err != nil { ... }
+//	c.RefreshXP()
+//	c.Replace(before, after)
+//
+// A user without the document, or with one stored under another Version, has
+// not been counted yet. Nobody scans history while holding a session write:
+// focus-service commits the write and then rebuilds the counters in a
+// transaction of their own, user-service rebuilds and stores them on the
+// first read, and reconcile_profile_stats.go backfills everyone ahead of
+// both. Check the version with StoredVersion before decoding, as older
+// layouts may not decode.
+package profilestats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/focusnest/shared-libs/timezone"
+)
+
+const (
+	// Version is the current layout and counting rules. Documents stored
+	// under another version are rebuilt from history. The XP curve is not
+	// part of it: see RefreshXP.
+	Version = 4
+	// Collection and DocID locate the counters below users/{uid}.
+	Collection = "stats"
+	DocID      = "profile"
+
+	dayLayout = "2006-01-02"
+)
+
+// Session is the part of a productivity entry the counters depend on.
+type Session struct {
+	StartTime time.Time
+	Timezone  string // zone the session was recorded in
+	NumCycle  int
+	Category  string
+	// TimeElapsed is the focused time in seconds.
+	TimeElapsed int
+	TimeMode    string // "Pomodoro", "Deep Work", …
+}
+
+// DayTotals sums the sessions of one local day.
+type DayTotals struct {
+	Sessions int `firestore:"sessions"`
+	Seconds  int `firestore:"seconds"`
+	// Cycles are as recorded: unlike TotalCycle, a session without cycles
+	// adds none.
+	Cycles int `firestore:"cycles"`
+}
+
+// Counters are the running totals over a user's non-deleted sessions.
+// Categories and Days count sessions per key, so removing a session knows
+// when a category or an active day disappears. XP and Level follow from Days
+// and are refreshed on every change and after decoding.
+type Counters struct {
+	Version       int                  `firestore:"version"`
+	TotalSessions int                  `firestore:"total_sessions"`
+	TotalCycle    int                  `firestore:"total_cycle"`
+	TotalSeconds  int                  `firestore:"total_seconds"` // focused time
+	Categories    map[string]int       `firestore:"categories"`
+	Days          map[string]DayTotals `firestore:"days"` // local YYYY-MM-DD
+	XP            int                  `firestore:"xp"`
+	Level         int                  `firestore:"level"`
+	// Sessions behind the achievements; see Achievements.
+	DeepWorkSessions  int `firestore:"deep_work_sessions"`
+	PomodoroCycles    int `firestore:"pomodoro_cycles"`
+	NightOwlSessions  int `firestore:"night_owl_sessions"`
+	EarlyBirdSessions int `firestore:"early_bird_sessions"`
+	// Fallback places sessions recorded without a timezone. It is fixed when
+	// the counters are built, so a session always lands on the same day.
+	Fallback  string    `firestore:"fallback_timezone"`
+	UpdatedAt time.Time `firestore:"updated_at"`
+}
+
+// newCounters returns empty counters at the current Version.
+func newCounters(fallback string) Counters {
+	return Counters{
+		Version:    Version,
+		Categories: make(map[string]int),
+		Days:       make(map[string]DayTotals),
+		Level:      1,
+		Fallback:   fallback,
+	}
+}
+
+// StoredVersion reads the version field of a stored document's data, so
+// callers can skip documents in an older layout before decoding them.
+func StoredVersion(data map[string]any) int {
+	v, _ := data["version"].(int64)
+	return int(v)
+}
+
+// Build counts sessions from scratch.
+func Build(sessions []Session, fallback string) Counters {
+	c := newCounters(fallback)
+	for _, s := range sessions {
+		c.add(s, 1)
+	}
+	c.RefreshXP()
+	return c
+}
+
+// Replace swaps before for after; either may be nil for a created or
+// deleted session.
+func (c *Counters) Replace(before, after *Session) {
+	if before != nil {
+		c.add(*before, -1)
+	}
+	if after != nil {
+		c.add(*after, 1)
+	}
+	c.RefreshXP()
+}
+
+func (c *Counters) add(s Session, delta int) {
+	if c.Categories == nil {
+		c.Categories = make(map[string]int)
+	}
+	if c.Days == nil {
+		c.Days = make(map[string]DayTotals)
+	}
+	c.TotalSessions += delta
+	cycle := s.NumCycle
+	if cycle <= 0 {
+		cycle = 1
+	}
+	c.TotalCycle += delta * cycle
+	c.TotalSeconds += delta * max(s.TimeElapsed, 0)
+	switch s.TimeMode {
+	case timeModeDeepWork:
+		c.DeepWorkSessions += delta
+	case timeModePomodoro:
+		c.PomodoroCycles += delta * cycle
+	}
+	if category := strings.TrimSpace(s.Category); category != "" {
+		bump(c.Categories, category, delta)
+	}
+	if !s.StartTime.IsZero() {
+		switch hour := c.local(s).Hour(); {
+		case hour >= nightOwlFromHour || hour < earlyBirdFromHour:
+			c.NightOwlSessions += delta
+		case hour < earlyBirdToHour:
+			c.EarlyBirdSessions += delta
+		}
+		key := c.Day(s)
+		day := c.Days[key]
+		day.Sessions += delta
+		day.Seconds += delta * max(s.TimeElapsed, 0)
+		day.Cycles += delta * max(s.NumCycle, 0)
+		if day.Sessions > 0 {
+			c.Days[key] = day
+		} else {
+			delete(c.Days, key)
+		}
+	}
+}
+
+// Day is the local date of s in the zone it was recorded in, else Fallback.
+func (c Counters) Day(s Session) string {
+	return c.local(s).Format(dayLayout)
+}
+
+// local is the start of s in the zone it was recorded in, else Fallback.
+func (c Counters) local(s Session) time.Time {
+	tz := s.Timezone
+	if tz == "" {
+		tz = c.Fallback
+	}
+	return s.StartTime.In(timezone.Load(tz))
+}
+
+// bump keeps only positive counts, so keys vanish with their last session.
+func bump(m map[string]int, key string, delta int) {
+	if n := m[key] + delta; n > 0 {
+		m[key] = n
+	} else {
+		delete(m, key)
+	}
+}
+
+// DistinctCategories is the number of categories with at least one session.
+func (c Counters) DistinctCategories() int {
+	return len(c.Categories)
+}
+
+// LongestStreak is the longest run of consecutive active days.
+func (c Counters) LongestStreak() int {
+	longest := 0
+	c.eachDay(func(_ DayTotals, run int) {
+		longest = max(longest, run)
+	})
+	return longest
+}
+
+// eachDay calls fn for every active day in order, with run the number of
+// consecutive active days ending on it.
+func (c Counters) eachDay(fn func(day DayTotals, run int)) {
+	keys := make([]string, 0, len(c.Days))
+	for k := range c.Days {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	run := 0
+	var prev time.Time
+	for _, key := range keys {
+		day, err := time.Parse(dayLayout, key)
+		if err != nil {
+			continue
+		}
+		if run > 0 && prev.AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = day
+		fn(c.Days[key], run)
+	}
+}
+
+// Equal reports whether c and other count the same sessions.
+func (c Counters) Equal(other Counters) bool {
+	return c.TotalSessions == other.TotalSessions &&
+		c.TotalCycle == other.TotalCycle &&
+		c.TotalSeconds == other.TotalSeconds &&
+		c.DeepWorkSessions == other.DeepWorkSessions &&
+		c.PomodoroCycles == other.PomodoroCycles &&
+		c.NightOwlSessions == other.NightOwlSessions &&
+		c.EarlyBirdSessions == other.EarlyBirdSessions &&
+		sameCounts(c.Categories, other.Categories) &&
+		sameCounts(c.Days, other.Days)
+}
+
+func sameCounts[V comparable](a, b map[string]V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package profilestats
+
+import (
+	"testing"
+	"time"
+)
+
+func at(day, hour int) time.Time {
+	return time.Date(2025, 11, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestReplace(t *testing.T) {
+	work := Session{StartTime: at(17, 9), Timezone: "UTC", NumCycle: 2, Category: "Work", TimeElapsed: 1500}
+	study := Session{StartTime: at(17, 14), Timezone: "UTC", NumCycle: 3, Category: "Study", TimeElapsed: 2700}
+
+	tests := []struct {
+		name  string
+		apply func(c *Counters)
+		want  Counters
+	}{
+		{
+			name:  "create",
+			apply: func(c *Counters) { c.Replace(nil, &work) },
+			want:  Build([]Session{work}, "UTC"),
+		},
+		{
+			name: "delete then recreate on the same day",
+			apply: func(c *Counters) {
+				c.Replace(nil, &work)
+				c.Replace(&work, nil)
+				c.Replace(nil, &work)
+			},
+			want: Build([]Session{work}, "UTC"),
+		},
+		{
+			name: "category removed with its last session",
+			apply: func(c *Counters) {
+				c.Replace(nil, &work)
+				c.Replace(nil, &study)
+				c.Replace(&study, nil)
+			},
+			want: Build([]Session{work}, "UTC"),
+		},
+		{
+			name: "edit moves day and category",
+			apply: func(c *Counters) {
+				c.Replace(nil, &work)
+				moved := study
+				moved.StartTime = at(19, 9)
+				c.Replace(&work, &moved)
+			},
+			want: Build([]Session{{StartTime: at(19, 9), Timezone: "UTC", NumCycle: 3, Category: "Study", TimeElapsed: 2700}}, "UTC"),
+		},
+		{
+			name: "delete the only session",
+			apply: func(c *Counters) {
+				c.Replace(nil, &work)
+				c.Replace(&work, nil)
+			},
+			want: Build(nil, "UTC"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCounters("UTC")
+			tt.apply(&c)
+			if !c.Equal(tt.want) || c.XP != tt.want.XP {
+				t.Fatalf("counters = %+v, want %+v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceDropsEmptyKeys(t *testing.T) {
+	work := Session{StartTime: at(17, 9), Timezone: "UTC", NumCycle: 2, Category: "Work"}
+	study := Session{StartTime: at(18, 9), Timezone: "UTC", Category: "Study", TimeElapsed: 600}
+	c := Build([]Session{work, study}, "UTC")
+
+	c.Replace(&study, nil)
+	if _, ok := c.Categories["Study"]; ok {
+		t.Fatalf("categories = %v, want Study removed", c.Categories)
+	}
+	if _, ok := c.Days["2025-11-18"]; ok {
+		t.Fatalf("days = %v, want 2025-11-18 removed", c.Days)
+	}
+	if c.DistinctCategories() != 1 || c.TotalSessions != 1 || c.TotalCycle != 2 || c.TotalSeconds != 0 {
+		t.Fatalf("counters = %+v, want one Work session of 2 cycles", c)
+	}
+
+	// A session with no cycles recorded counts as one.
+	c.Replace(nil, &study)
+	c.Replace(&study, nil)
+	if c.TotalCycle != 2 {
+		t.Fatalf("total cycle = %d, want 2", c.TotalCycle)
+	}
+}
+
+func TestLongestStreak(t *testing.T) {
+	tests := []struct {
+		name string
+		days []int
+		want int
+	}{
+		{name: "no sessions", days: nil, want: 0},
+		{name: "single day", days: []int{17}, want: 1},
+		{name: "several sessions one day", days: []int{17, 17, 17}, want: 1},
+		{name: "consecutive", days: []int{17, 18, 19}, want: 3},
+		{name: "longest run wins", days: []int{1, 2, 5, 6, 7, 9}, want: 3},
+		{name: "unordered", days: []int{19, 17, 18, 25}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sessions []Session
+			for _, d := range tt.days {
+				sessions = append(sessions, Session{StartTime: at(d, 9), Timezone: "UTC"})
+			}
+			if got := Build(sessions, "UTC").LongestStreak(); got != tt.want {
+				t.Fatalf("LongestStreak() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLongestStreakAcrossMonthAndZones(t *testing.T) {
+	sessions := []Session{
+		{StartTime: time.Date(2025, 10, 31, 9, 0, 0, 0, time.UTC), Timezone: "UTC"},
+		{StartTime: time.Date(2025, 11, 1, 9, 0, 0, 0, time.UTC), Timezone: "UTC"},
+		// 20:00 UTC on Nov 1 is Nov 2 in Tokyo, where it was recorded.
+		{StartTime: time.Date(2025, 11, 1, 20, 0, 0, 0, time.UTC), Timezone: "Asia/Tokyo"},
+		// No timezone: placed by the fallback, New York, on Nov 2.
+		{StartTime: time.Date(2025, 11, 3, 3, 0, 0, 0, time.UTC)},
+	}
+	c := Build(sessions, "America/New_York")
+	if got := c.LongestStreak(); got != 3 {
+		t.Fatalf("LongestStreak() = %d, want 3 (days %v)", got, c.Days)
+	}
+
+	// Deleting the Tokyo session breaks the run only if Nov 2 empties.
+	c.Replace(&sessions[2], nil)
+	if got := c.LongestStreak(); got != 3 {
+		t.Fatalf("after delete LongestStreak() = %d, want 3 (days %v)", got, c.Days)
+	}
+	c.Replace(&sessions[3], nil)
+	if got := c.LongestStreak(); got != 2 {
+		t.Fatalf("after second delete LongestStreak() = %d, want 2 (days %v)", got, c.Days)
+	}
+}
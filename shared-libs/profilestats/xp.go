@@ -0,0 +1,81 @@
+package profilestats
+
+// XP comes from focus sessions and is separate from spendable points. Each
+// local day earns XP for its minutes and cycles plus a bonus for keeping a
+// streak. XP derives from the stored Days, so readers recompute it with
+// RefreshXP and changing the rules below needs no Version bump.
+
+// xpTier awards Rate tenths of XP per unit up to UpTo units a day; UpTo 0
+// means no limit.
+type xpTier struct {
+	UpTo int
+	Rate int
+}
+
+// Daily tiers give full XP for a normal day and little for piling on more,
+// so farming sessions does not pay.
+var (
+	minuteXPTiers = []xpTier{{UpTo: 120, Rate: 10}, {UpTo: 240, Rate: 5}, {Rate: 1}}
+	cycleXPTiers  = []xpTier{{UpTo: 8, Rate: 50}, {UpTo: 16, Rate: 20}}
+)
+
+const (
+	// streakDayXP is earned for each active day, plus streakDayBonusXP for
+	// every consecutive day before it, up to maxStreakDayBonus days.
+	streakDayXP       = 10
+	streakDayBonusXP  = 1
+	maxStreakDayBonus = 20
+	// maxLevel caps the curve so a corrupt total cannot loop for long.
+	maxLevel = 100
+)
+
+// XPForLevel is the total XP needed to reach level: 0, 100, 300, 600, 1000, …
+func XPForLevel(level int) int {
+	return 50 * level * (level - 1)
+}
+
+// LevelForXP returns the highest level xp reaches.
+func LevelForXP(xp int) int {
+	level := 1
+	for level < maxLevel && XPForLevel(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+func tieredXP(units int, tiers []xpTier) int {
+	tenths, from := 0, 0
+	for _, t := range tiers {
+		if units <= from {
+			break
+		}
+		n := units - from
+		if t.UpTo > 0 {
+			n = min(units, t.UpTo) - from
+		}
+		tenths += n * t.Rate
+		if t.UpTo == 0 {
+			break
+		}
+		from = t.UpTo
+	}
+	return tenths
+}
+
+// dayXP is the XP of d when it is the run-th consecutive active day.
+func dayXP(d DayTotals, run int) int {
+	return (tieredXP(d.Seconds/60, minuteXPTiers)+tieredXP(d.Cycles, cycleXPTiers))/10 +
+		streakDayXP + streakDayBonusXP*min(run-1, maxStreakDayBonus)
+}
+
+// RefreshXP recomputes XP and Level from Days. Editing a past day can change
+// the streak bonus of every day after it, so the total is summed afresh.
+// Call it on decoded counters so they read with the current XP curve.
+func (c *Counters) RefreshXP() {
+	xp := 0
+	c.eachDay(func(day DayTotals, run int) {
+		xp += dayXP(day, run)
+	})
+	c.XP = xp
+	c.Level = LevelForXP(xp)
+}
@@ -0,0 +1,81 @@
+package profilestats
+
+import "testing"
+
+func TestTieredXPDiminishes(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    int // tenths
+	}{
+		{0, 0},
+		{60, 600},
+		{120, 1200},
+		{180, 1500},
+		{240, 1800},
+		{600, 2160},
+	}
+	for _, tt := range tests {
+		if got := tieredXP(tt.minutes, minuteXPTiers); got != tt.want {
+			t.Errorf("tieredXP(%d minutes) = %d, want %d", tt.minutes, got, tt.want)
+		}
+	}
+	if got := tieredXP(30, cycleXPTiers); got != 8*50+8*20 {
+		t.Errorf("tieredXP(30 cycles) = %d, want cycles beyond 16 to earn nothing", got)
+	}
+}
+
+func TestLevelCurve(t *testing.T) {
+	tests := []struct{ xp, level int }{
+		{0, 1}, {99, 1}, {100, 2}, {299, 2}, {300, 3}, {1000, 5},
+	}
+	for _, tt := range tests {
+		if got := LevelForXP(tt.xp); got != tt.level {
+			t.Errorf("LevelForXP(%d) = %d, want %d", tt.xp, got, tt.level)
+		}
+	}
+}
+
+func TestXPFollowsPastEdits(t *testing.T) {
+	sessions := []Session{
+		{StartTime: at(17, 9), Timezone: "UTC", TimeElapsed: 3600, NumCycle: 2},
+		{StartTime: at(18, 9), Timezone: "UTC", TimeElapsed: 3600, NumCycle: 1},
+		{StartTime: at(19, 9), Timezone: "UTC", TimeElapsed: 1800, NumCycle: 1},
+		{StartTime: at(21, 9), Timezone: "UTC", TimeElapsed: 600, NumCycle: 1},
+	}
+	var c Counters
+	for i := range sessions {
+		c.Replace(nil, &sessions[i])
+	}
+	// 17th: 60 + 10 + 10; 18th: 60 + 5 + 11; 19th: 30 + 5 + 12; 21st: 10 + 5 + 10.
+	if want := 80 + 76 + 47 + 25; c.XP != want || c.Level != 2 {
+		t.Fatalf("xp = %d level %d, want %d at level 2", c.XP, c.Level, want)
+	}
+	if built := Build(sessions, "UTC"); built.XP != c.XP {
+		t.Fatalf("built xp = %d, want %d", built.XP, c.XP)
+	}
+
+	// Deleting the 18th also breaks the streak bonus of the 19th.
+	c.Replace(&sessions[1], nil)
+	if want := 80 + 45 + 25; c.XP != want || c.Level != 2 {
+		t.Fatalf("after delete xp = %d level %d, want %d at level 2", c.XP, c.Level, want)
+	}
+
+	// A second session on the 21st is summed with the first before tiering.
+	long := Session{StartTime: at(21, 14), Timezone: "UTC", TimeElapsed: 3 * 3600}
+	c.Replace(nil, &long)
+	// 21st: 190 minutes = 120 + 35, one cycle, a run of one.
+	if want := 80 + 45 + 155 + 5 + 10; c.XP != want || c.Level != 2 {
+		t.Fatalf("after long session xp = %d level %d, want %d at level 2", c.XP, c.Level, want)
+	}
+}
+
+func TestRefreshXPIgnoresStoredXP(t *testing.T) {
+	c := Build([]Session{{StartTime: at(17, 9), Timezone: "UTC", TimeElapsed: 3600}}, "UTC")
+	want := c.XP
+	// As decoded from a document written under another XP curve.
+	c.XP, c.Level = 5000, 9
+	c.RefreshXP()
+	if c.XP != want || c.Level != LevelForXP(want) {
+		t.Fatalf("xp = %d level %d, want %d at level %d", c.XP, c.Level, want, LevelForXP(want))
+	}
+}
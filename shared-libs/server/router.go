@@ -13,21 +13,35 @@ import (
 
 // NewRouter returns a chi router pre-configured with default middleware and a health endpoint.
 func NewRouter(service string, register func(r chi.Router)) *chi.Mux {
+	return NewStreamingRouter(service, register, nil)
+}
+
+// NewStreamingRouter is NewRouter plus routes registered by stream, which
+// skip the request timeout. Use it only for long-lived responses such as
+// Server-Sent Events; those handlers must bound themselves.
+func NewStreamingRouter(service string, register, stream func(r chi.Router)) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
-
-	r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
-		writeJSON(w, http.StatusOK, dto.HealthResponse{Status: "ok", Service: service, Version: "v0.0.1"})
-	})
 
-	if register != nil {
-		register(r)
+	if stream != nil {
+		r.Group(stream)
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(60 * time.Second))
+
+		r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, http.StatusOK, dto.HealthResponse{Status: "ok", Service: service, Version: "v0.0.1"})
+		})
+
+		if register != nil {
+			register(r)
+		}
+	})
+
 	return r
 }
 
@@ -0,0 +1,97 @@
+// Package sharelink signs and verifies the tokens behind public share links.
+//
+// A token is base64url(claims) + "." + base64url(HMAC-SHA256(claims)). Claims
+// only carry the link ID and expiry, so a token reveals nothing about its
+// owner; revocation is checked against the stored link by the issuing service.
+// The gateway can verify tokens with the same secret to reject forged or
+// expired links before proxying.
+package sharelink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MinSecretLength is the minimum accepted HMAC secret length in bytes.
+const MinSecretLength = 32
+
+var (
+	// ErrInvalidToken indicates a malformed token or a bad signature.
+	ErrInvalidToken = errors.New("invalid share token")
+	// ErrExpired indicates a correctly signed token past its expiry.
+	ErrExpired = errors.New("share token expired")
+	// ErrWeakSecret indicates a secret shorter than MinSecretLength.
+	ErrWeakSecret = errors.New("share link secret must be at least 32 bytes")
+)
+
+// Claims is the signed payload of a token.
+type Claims struct {
+	LinkID    string `json:"id"`
+	ExpiresAt int64  `json:"exp"` // unix seconds
+}
+
+// Expiry returns ExpiresAt as a time.
+func (c Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0).UTC()
+}
+
+// Signer issues and verifies tokens with a shared secret.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner returns a Signer for secret.
+func NewSigner(secret string) (*Signer, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrWeakSecret
+	}
+	return &Signer{key: []byte(secret)}, nil
+}
+
+// Sign returns the token for linkID, valid until expiresAt.
+func (s *Signer) Sign(linkID string, expiresAt time.Time) (string, error) {
+	if linkID == "" {
+		return "", ErrInvalidToken
+	}
+	payload, err := json.Marshal(Claims{LinkID: linkID, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+// Verify checks the signature and expiry of token at now.
+func (s *Signer) Verify(token string, now time.Time) (Claims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || encoded == "" || sig == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(encoded)) {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.LinkID == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	if !now.Before(claims.Expiry()) {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+func (s *Signer) mac(encoded string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(encoded))
+	return h.Sum(nil)
+}
@@ -0,0 +1,184 @@
+// Package timezone resolves the IANA timezone used to bucket a user's activity
+// into local days. Every service resolves it the same way:
+//
+//  1. the X-Timezone header (or ?timezone=) sent by the client, when valid;
+//  2. the timezone stored on the user's profile (profiles/{uid}.timezone);
+//  3. Default.
+package timezone
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/focusnest/shared-libs/auth"
+)
+
+const (
+	// Default is used when neither the request nor the profile has a timezone.
+	Default = "Asia/Jakarta"
+	// Header carries the resolved timezone to handlers.
+	Header = "X-Timezone"
+	// ProfileField is the profiles/{uid} field holding the user's preference.
+	ProfileField = "timezone"
+
+	cacheTTL = 5 * time.Minute
+)
+
+// locations caches loaded zones by name. Only valid names are stored, so the
+// map is bounded by the IANA database however many bad names clients send.
+var locations sync.Map // string -> *time.Location
+
+// Valid reports whether name is a loadable IANA timezone. "Local" and the
+// empty string are rejected so a server's own zone never leaks into user data.
+func Valid(name string) bool {
+	_, ok := location(name)
+	return ok
+}
+
+// Load returns the location for name, falling back to Default and then UTC.
+func Load(name string) *time.Location {
+	if loc, ok := location(name); ok {
+		return loc
+	}
+	if loc, ok := location(Default); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+func location(name string) (*time.Location, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return nil, false
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), true
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	locations.Store(name, loc)
+	return loc, true
+}
+
+// Lookup returns the timezone stored for a user, or "" when none is set.
+type Lookup interface {
+	UserTimezone(ctx context.Context, userID string) (string, error)
+}
+
+// LookupFunc adapts a function to Lookup.
+type LookupFunc func(ctx context.Context, userID string) (string, error)
+
+// UserTimezone calls f.
+func (f LookupFunc) UserTimezone(ctx context.Context, userID string) (string, error) {
+	return f(ctx, userID)
+}
+
+type cacheEntry struct {
+	timezone  string
+	expiresAt time.Time
+}
+
+// Resolver applies the resolution order above, caching profile lookups for a
+// few minutes. user-service calls Forget when the preference changes; other
+// services see the change within cacheTTL. Clients that send X-Timezone see
+// it immediately. Expired entries are pruned at most once per cacheTTL, so
+// the cache holds roughly the users seen in the last two TTLs.
+type Resolver struct {
+	lookup    Lookup
+	logger    *slog.Logger
+	mu        sync.RWMutex
+	cache     map[string]cacheEntry
+	nextPrune time.Time
+}
+
+// NewResolver returns a Resolver backed by lookup. A nil lookup skips step 2.
+func NewResolver(lookup Lookup, logger *slog.Logger) *Resolver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Resolver{lookup: lookup, logger: logger, cache: make(map[string]cacheEntry)}
+}
+
+// Resolve returns the timezone name to use for userID. Lookup failures are
+// logged and fall through to Default rather than failing the request.
+func (r *Resolver) Resolve(ctx context.Context, userID, requested string) string {
+	if requested = strings.TrimSpace(requested); Valid(requested) {
+		return requested
+	}
+	if stored := r.stored(ctx, userID); Valid(stored) {
+		return stored
+	}
+	return Default
+}
+
+// Forget drops a cached lookup, e.g. right after the user changes timezone.
+func (r *Resolver) Forget(userID string) {
+	r.mu.Lock()
+	delete(r.cache, userID)
+	r.mu.Unlock()
+}
+
+func (r *Resolver) stored(ctx context.Context, userID string) string {
+	if r.lookup == nil || userID == "" {
+		return ""
+	}
+	r.mu.RLock()
+	entry, ok := r.cache[userID]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.timezone
+	}
+
+	tz, err := r.lookup.UserTimezone(ctx, userID)
+	if err != nil {
+		r.logger.Warn("timezone lookup failed, using default", slog.String("user_id", userID), slog.Any("error", err))
+		return ""
+	}
+	now := time.Now()
+	r.mu.Lock()
+	if now.After(r.nextPrune) {
+		r.prune(now)
+	}
+	r.cache[userID] = cacheEntry{timezone: tz, expiresAt: now.Add(cacheTTL)}
+	r.mu.Unlock()
+	return tz
+}
+
+// prune drops expired entries. The caller holds r.mu.
+func (r *Resolver) prune(now time.Time) {
+	for userID, entry := range r.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(r.cache, userID)
+		}
+	}
+	r.nextPrune = now.Add(cacheTTL)
+}
+
+// Middleware sets the X-Timezone header on every request to the resolved
+// timezone, so handlers can keep reading the header. Mount it after
+// auth.Middleware.
+func Middleware(resolver *Resolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested := r.Header.Get(Header)
+			if !Valid(requested) {
+				requested = r.URL.Query().Get("timezone")
+			}
+			r.Header.Set(Header, resolver.Resolve(r.Context(), requestUserID(r), requested))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func requestUserID(r *http.Request) string {
+	if user, ok := auth.UserFromContext(r.Context()); ok && user.UserID != "" {
+		return user.UserID
+	}
+	return r.Header.Get("X-User-ID")
+}
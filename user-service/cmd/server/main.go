@@ -9,12 +9,17 @@ import (
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/storage"
 	"github.com/go-chi/chi/v5"
 
 	sharedauth "github.com/focusnest/shared-libs/auth"
+	"github.com/focusnest/shared-libs/events"
 	"github.com/focusnest/shared-libs/logging"
 	sharedserver "github.com/focusnest/shared-libs/server"
+	"github.com/focusnest/shared-libs/timezone"
 
+	"github.com/focusnest/user-service/internal/account"
+	"github.com/focusnest/user-service/internal/clerk"
 	"github.com/focusnest/user-service/internal/config"
 	"github.com/focusnest/user-service/internal/httpapi"
 	"github.com/focusnest/user-service/internal/user"
@@ -43,9 +48,46 @@ func main() {
 	}
 	defer client.Close()
 
+	// Avatars live in focus-service's image bucket, and account export and
+	// deletion cover both when it is configured.
+	var (
+		objects account.ObjectStore
+		avatars user.AvatarStore
+	)
+	if cfg.Storage.Bucket != "" {
+		storageClient, err := storage.NewClient(ctx)
+		if err != nil {
+			panic(fmt.Errorf("storage client: %w", err))
+		}
+		defer storageClient.Close()
+		objects = account.NewGCSStore(storageClient, cfg.Storage.Bucket)
+		avatars = user.NewGCSAvatarStore(storageClient, cfg.Storage.Bucket)
+	}
+	accounts := account.NewService(client, objects)
+
 	// Initialize user service
 	userRepo := user.NewFirestoreRepository(client)
-	userService := user.NewService(userRepo)
+	userService := user.NewService(userRepo, avatars)
+
+	// Fill X-Timezone from the profile preference when the client omits it.
+	tzResolver := timezone.NewResolver(timezone.LookupFunc(func(ctx context.Context, userID string) (string, error) {
+		profile, err := userRepo.GetProfile(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		return profile.Timezone, nil
+	}), logger)
+
+	// Clerk's user lifecycle webhook keeps profiles in sync with sign-ups,
+	// profile edits and account deletions.
+	var clerkWebhook http.Handler
+	if cfg.Webhooks.ClerkSecret != "" {
+		webhookVerifier, err := clerk.NewVerifier(cfg.Webhooks.ClerkSecret)
+		if err != nil {
+			panic(fmt.Errorf("clerk webhook: %w", err))
+		}
+		clerkWebhook = clerk.NewHandler(webhookVerifier, userService, accounts, events.LogPublisher{Logger: logger}, logger)
+	}
 
 	verifier, err := sharedauth.NewVerifier(sharedauth.Config{
 		Mode:     sharedauth.Mode(cfg.Auth.Mode),
@@ -58,11 +100,19 @@ func main() {
 	}
 
 	router := sharedserver.NewRouter("user-service", func(r chi.Router) {
+		if clerkWebhook != nil {
+			httpapi.RegisterWebhookRoutes(r, clerkWebhook)
+		}
+
+		// Service-to-service reads; Cloud Run IAM guards the service itself.
+		httpapi.RegisterInternalRoutes(r, userService, logger)
+
 		r.Group(func(r chi.Router) {
 			r.Use(sharedauth.Middleware(verifier))
+			r.Use(timezone.Middleware(tzResolver))
 
 			// Register user routes
-			httpapi.RegisterRoutes(r, userService, logger)
+			httpapi.RegisterRoutes(r, userService, accounts, tzResolver, logger)
 		})
 	})
 
@@ -0,0 +1,271 @@
+// Package account exports and deletes everything FocusNest stores about a
+// user. Every service writes to the same Firestore database, so the source
+// catalog below also covers data owned by focus-service (productivities,
+// images, co-focus rooms), progress-service (streaks, goals, share links),
+// chatbot-service (chat history) and the gateway (feedback).
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Service runs exports and deletions.
+type Service struct {
+	client  *firestore.Client
+	objects ObjectStore // nil when no image bucket is configured
+}
+
+// NewService creates an account service. objects may be nil, in which case
+// images are reported as skipped.
+func NewService(client *firestore.Client, objects ObjectStore) *Service {
+	return &Service{client: client, objects: objects}
+}
+
+// record is one document found for a user. file groups records in exports.
+type record struct {
+	file string
+	snap *firestore.DocumentSnapshot
+}
+
+// source finds one kind of user data.
+type source struct {
+	name string
+	// exported is false for data that belongs to other users but mentions
+	// this one, such as other people's block lists. It is deleted, not exported.
+	exported bool
+	find     func(ctx context.Context, client *firestore.Client, userID string) ([]record, error)
+	// remove, when set, takes the user out of a document shared with other
+	// users instead of deleting it.
+	remove func(ctx context.Context, client *firestore.Client, userID string, ref *firestore.DocumentRef) error
+}
+
+// sources lists every place user data lives, in deletion order: documents
+// that are only reachable through another (chat messages through their
+// sessions) come before it.
+var sources = []source{
+	{name: "profiles", exported: true, find: tree("profiles")},
+	{name: "users", exported: true, find: tree("users")},
+	{name: "streak_state", exported: true, find: docByID("streak_state")},
+	{name: "streak_recovery_quota", exported: true, find: idPrefix("streak_recovery_quota", "_")},
+	{name: "streak_recoveries", exported: true, find: field("streak_recoveries", "user_id")},
+	{name: "share_links", exported: true, find: field("share_links", "user_id")},
+	{name: "daily_summaries", exported: true, find: field("daily_summaries", "user_id")},
+	{name: "chat_messages", exported: true, find: chatMessages},
+	{name: "chat_sessions", exported: true, find: field("chat_sessions", "user_id")},
+	{name: "feedbacks", exported: true, find: field("feedbacks", "user_id")},
+	{name: "friend_codes", exported: true, find: field("friend_codes", "user_id")},
+	{name: "handles", exported: true, find: field("handles", "user_id")},
+	{name: "friendships", exported: true, find: arrayContains("friendships", "users")},
+	{name: "blocked_by", exported: false, find: blockedBy},
+	{name: "rooms", exported: false, find: arrayContains("rooms", "member_ids"), remove: leaveRoom},
+}
+
+// tree finds {collection}/{uid} and every document below it, deepest first
+// so a partial deletion still leaves the parent to rediscover the rest.
+func tree(collection string) func(context.Context, *firestore.Client, string) ([]record, error) {
+	return func(ctx context.Context, client *firestore.Client, userID string) ([]record, error) {
+		root := client.Collection(collection).Doc(userID)
+		records, err := descendants(ctx, root, collection)
+		if err != nil {
+			return nil, err
+		}
+		snap, err := root.Get(ctx)
+		if status.Code(err) == codes.NotFound {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return append(records, record{file: collection, snap: snap}), nil
+	}
+}
+
+// descendants walks the subcollections of ref. Subcollections are listed
+// even when ref itself no longer exists.
+func descendants(ctx context.Context, ref *firestore.DocumentRef, file string) ([]record, error) {
+	collections, err := ref.Collections(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("list collections of %s: %w", ref.Path, err)
+	}
+	var records []record
+	for _, coll := range collections {
+		name := file + "/" + coll.ID
+		// DocumentRefs includes documents that only hold subcollections,
+		// which Documents skips.
+		refs, err := coll.DocumentRefs(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", coll.Path, err)
+		}
+		for _, child := range refs {
+			nested, err := descendants(ctx, child, name)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, nested...)
+		}
+		found, err := collect(ctx, name, coll.Documents(ctx))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, found...)
+	}
+	return records, nil
+}
+
+func docByID(collection string) func(context.Context, *firestore.Client, string) ([]record, error) {
+	return func(ctx context.Context, client *firestore.Client, userID string) ([]record, error) {
+		snap, err := client.Collection(collection).Doc(userID).Get(ctx)
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []record{{file: collection, snap: snap}}, nil
+	}
+}
+
+// idPrefix finds documents whose ID starts with {uid}{sep}.
+func idPrefix(collection, sep string) func(context.Context, *firestore.Client, string) ([]record, error) {
+	return func(ctx context.Context, client *firestore.Client, userID string) ([]record, error) {
+		coll := client.Collection(collection)
+		prefix := userID + sep
+		return collect(ctx, collection, coll.
+			Where(firestore.DocumentID, ">=", coll.Doc(prefix)).
+			Where(firestore.DocumentID, "<", coll.Doc(prefix+"\uf8ff")).
+			Documents(ctx))
+	}
+}
+
+func field(collection, path string) func(context.Context, *firestore.Client, string) ([]record, error) {
+	return func(ctx context.Context, client *firestore.Client, userID string) ([]record, error) {
+		return collect(ctx, collection, client.Collection(collection).Where(path, "==", userID).Documents(ctx))
+	}
+}
+
+func arrayContains(collection, path string) func(context.Context, *firestore.Client, string) ([]record, error) {
+	return func(ctx context.Context, client *firestore.Client, userID string) ([]record, error) {
+		return collect(ctx, collection, client.Collection(collection).Where(path, "array-contains", userID).Documents(ctx))
+	}
+}
+
+// chatMessages finds the messages of the user's chat sessions, which only
+// carry a session ID.
+func chatMessages(ctx context.Context, client *firestore.Client, userID string) ([]record, error) {
+	sessions, err := client.Collection("chat_sessions").Where("user_id", "==", userID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	for _, session := range sessions {
+		found, err := collect(ctx, "chat_messages", client.Collection("chat_messages").
+			Where("session_id", "==", session.Ref.ID).Documents(ctx))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, found...)
+	}
+	return records, nil
+}
+
+// blockedBy finds other users' block entries for this user
+// (users/{other}/blocks/{uid}).
+func blockedBy(ctx context.Context, client *firestore.Client, userID string) ([]record, error) {
+	return collect(ctx, "blocked_by", client.CollectionGroup("blocks").Where("user_id", "==", userID).Documents(ctx))
+}
+
+// leaveRoom takes the user out of a co-focus room (rooms/{code}): their
+// member entry, Pomodoro presence and last-session result. Rooms they host,
+// or that nobody else is in, are deleted.
+func leaveRoom(ctx context.Context, client *firestore.Client, userID string, ref *firestore.DocumentRef) error {
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, keep := withoutRoomMember(snap.Data(), userID)
+		if !keep {
+			return tx.Delete(ref)
+		}
+		return tx.Set(ref, data)
+	})
+}
+
+// withoutRoomMember removes userID from a room document. keep is false when
+// the room should be deleted instead.
+func withoutRoomMember(data map[string]any, userID string) (_ map[string]any, keep bool) {
+	if data["host_id"] == userID {
+		return nil, false
+	}
+	members := without(data["members"], userID)
+	if len(members) == 0 {
+		return nil, false
+	}
+	data["members"] = members
+	ids := make([]any, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.(map[string]any)["user_id"])
+	}
+	data["member_ids"] = ids
+	if pomodoro, ok := data["pomodoro"].(map[string]any); ok {
+		if participants, ok := pomodoro["participants"].(map[string]any); ok {
+			delete(participants, userID)
+		}
+	}
+	if last, ok := data["last_session"].(map[string]any); ok {
+		last["results"] = without(last["results"], userID)
+	}
+	return data, true
+}
+
+// without drops the maps whose user_id is userID from a Firestore array.
+func without(value any, userID string) []any {
+	items, _ := value.([]any)
+	kept := make([]any, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok && m["user_id"] == userID {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+func collect(ctx context.Context, file string, iter *firestore.DocumentIterator) ([]record, error) {
+	defer iter.Stop()
+	var records []record
+	for {
+		snap, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+		records = append(records, record{file: file, snap: snap})
+	}
+}
+
+// imagePrefixes are where focus-service stores a user's session images and
+// user-service their uploaded avatars.
+func imagePrefixes(userID string) []string {
+	return []string{"original/" + userID + "/", "overview/" + userID + "/", "avatars/" + userID + "/"}
+}
+
+func validUserID(userID string) error {
+	if userID == "" || strings.Contains(userID, "/") {
+		return fmt.Errorf("invalid user id %q", userID)
+	}
+	return nil
+}
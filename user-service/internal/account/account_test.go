@@ -0,0 +1,51 @@
+package account
+
+import "testing"
+
+func TestWithoutRoomMember(t *testing.T) {
+	room := func(host string) map[string]any {
+		return map[string]any{
+			"host_id": host,
+			"members": []any{
+				map[string]any{"user_id": "bob", "timezone": "Asia/Jakarta"},
+				map[string]any{"user_id": "alice", "timezone": "Europe/Berlin"},
+			},
+			"member_ids": []any{"bob", "alice"},
+			"pomodoro": map[string]any{"participants": map[string]any{
+				"bob":   map[string]any{"timezone": "Asia/Jakarta"},
+				"alice": map[string]any{"timezone": "Europe/Berlin"},
+			}},
+			"last_session": map[string]any{"results": []any{
+				map[string]any{"user_id": "alice", "focus_seconds": int64(1500)},
+				map[string]any{"user_id": "bob", "focus_seconds": int64(1500)},
+			}},
+		}
+	}
+
+	if _, keep := withoutRoomMember(room("alice"), "alice"); keep {
+		t.Error("a room alice hosts should be deleted")
+	}
+
+	data, keep := withoutRoomMember(room("bob"), "alice")
+	if !keep {
+		t.Fatal("bob's room should be kept")
+	}
+	if members := data["members"].([]any); len(members) != 1 || members[0].(map[string]any)["user_id"] != "bob" {
+		t.Errorf("members = %v, want only bob", members)
+	}
+	if ids := data["member_ids"].([]any); len(ids) != 1 || ids[0] != "bob" {
+		t.Errorf("member_ids = %v, want [bob]", ids)
+	}
+	participants := data["pomodoro"].(map[string]any)["participants"].(map[string]any)
+	if _, ok := participants["alice"]; ok || len(participants) != 1 {
+		t.Errorf("participants = %v, want only bob", participants)
+	}
+	if results := data["last_session"].(map[string]any)["results"].([]any); len(results) != 1 {
+		t.Errorf("last session results = %v, want only bob's", results)
+	}
+
+	alone := map[string]any{"host_id": "bob", "members": []any{map[string]any{"user_id": "alice"}}, "pomodoro": nil}
+	if _, keep := withoutRoomMember(alone, "alice"); keep {
+		t.Error("a room left empty should be deleted")
+	}
+}
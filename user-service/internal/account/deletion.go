@@ -0,0 +1,318 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// deletionsCollection keeps one progress record per user. It holds only the
+// user ID and counts, and stays behind as proof the deletion ran.
+const deletionsCollection = "account_deletions"
+
+// staleAfter is how long a running deletion may go without progress before
+// a new request restarts it. Runs end with the request that started them, so
+// only a crashed instance leaves one running that long.
+const staleAfter = 2 * time.Minute
+
+// saveTimeout bounds recording a run that was cut short.
+const saveTimeout = 5 * time.Second
+
+const imagesStep = "images"
+
+// ErrDeletionNotFound indicates no deletion was ever requested.
+var ErrDeletionNotFound = errors.New("deletion not found")
+
+// ErrDeletionCutShort reports a run stopped by its time limit. The deletion
+// is saved as incomplete, so requesting it again resumes the purge.
+var ErrDeletionCutShort = errors.New("deletion did not finish in time")
+
+// DeletionStatus is the state of an account deletion.
+type DeletionStatus string
+
+const (
+	DeletionRunning DeletionStatus = "running"
+	// DeletionCompleted means verification found nothing left.
+	DeletionCompleted DeletionStatus = "completed"
+	// DeletionIncomplete means a step failed or verification found data;
+	// requesting the deletion again retries.
+	DeletionIncomplete DeletionStatus = "incomplete"
+)
+
+// Step states.
+const (
+	StepPending = "pending"
+	StepDone    = "done"
+	StepSkipped = "skipped"
+	StepFailed  = "failed"
+)
+
+// DeletionStep tracks one source.
+type DeletionStep struct {
+	Source  string `json:"source" firestore:"source"`
+	Status  string `json:"status" firestore:"status"`
+	Deleted int    `json:"deleted" firestore:"deleted"`
+	// Remaining is what verification still found afterwards.
+	Remaining int    `json:"remaining" firestore:"remaining"`
+	Error     string `json:"error,omitempty" firestore:"error,omitempty"`
+}
+
+// Deletion is the progress and verification report of an account deletion.
+type Deletion struct {
+	UserID      string         `json:"user_id" firestore:"user_id"`
+	Status      DeletionStatus `json:"status" firestore:"status"`
+	RequestedAt time.Time      `json:"requested_at" firestore:"requested_at"`
+	UpdatedAt   time.Time      `json:"updated_at" firestore:"updated_at"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty" firestore:"completed_at"`
+	Steps       []DeletionStep `json:"steps" firestore:"steps"`
+}
+
+func (s *Service) deletionRef(userID string) *firestore.DocumentRef {
+	return s.client.Collection(deletionsCollection).Doc(userID)
+}
+
+// StartDeletion records a new deletion with every step pending. started is
+// false when one is already running, which is returned instead.
+func (s *Service) StartDeletion(ctx context.Context, userID string) (d *Deletion, started bool, err error) {
+	if err := validUserID(userID); err != nil {
+		return nil, false, err
+	}
+	ref := s.deletionRef(userID)
+	err = s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		now := time.Now().UTC()
+		snap, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			var existing Deletion
+			if err := snap.DataTo(&existing); err != nil {
+				return fmt.Errorf("unmarshal deletion: %w", err)
+			}
+			if existing.Status == DeletionRunning && now.Sub(existing.UpdatedAt) < staleAfter {
+				d, started = &existing, false
+				return nil
+			}
+		}
+		d = newDeletion(userID, now)
+		started = true
+		return tx.Set(ref, d)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return d, started, nil
+}
+
+func newDeletion(userID string, now time.Time) *Deletion {
+	d := &Deletion{UserID: userID, Status: DeletionRunning, RequestedAt: now, UpdatedAt: now}
+	for _, src := range sources {
+		d.Steps = append(d.Steps, DeletionStep{Source: src.name, Status: StepPending})
+	}
+	d.Steps = append(d.Steps, DeletionStep{Source: imagesStep, Status: StepPending})
+	return d
+}
+
+// RunDeletion deletes each source in order, saving progress after every
+// step, then counts what is left. It is safe to run again after a failure.
+func (s *Service) RunDeletion(ctx context.Context, d *Deletion) (*Deletion, error) {
+	for i, src := range sources {
+		step := &d.Steps[i]
+		records, err := src.find(ctx, s.client, d.UserID)
+		switch {
+		case err != nil:
+		case src.remove != nil:
+			step.Deleted, err = s.removeFromRecords(ctx, src, d.UserID, records)
+		default:
+			step.Deleted, err = s.deleteRecords(ctx, records)
+		}
+		step.finish(err)
+		if err := s.save(ctx, d); err != nil {
+			return d, err
+		}
+	}
+
+	images := &d.Steps[len(sources)]
+	if s.objects == nil {
+		images.Status = StepSkipped
+	} else {
+		var err error
+		images.Deleted, err = s.deleteImages(ctx, d.UserID)
+		images.finish(err)
+	}
+	if err := s.save(ctx, d); err != nil {
+		return d, err
+	}
+
+	s.verify(ctx, d)
+	return d, s.save(ctx, d)
+}
+
+// RunDeletionWithin runs a started deletion inside the caller's request for
+// at most limit, so no work is left running after the response, when Cloud
+// Run may throttle the CPU. A run cut short returns ErrDeletionCutShort.
+func (s *Service) RunDeletionWithin(ctx context.Context, d *Deletion, limit time.Duration) (*Deletion, error) {
+	runCtx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+	result, err := s.RunDeletion(runCtx, d)
+	if err == nil || runCtx.Err() == nil {
+		return result, err
+	}
+
+	// runCtx is done; record the partial run with a context of its own.
+	saveCtx, cancelSave := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+	defer cancelSave()
+	d.Status = DeletionIncomplete
+	if err := s.save(saveCtx, d); err != nil {
+		return d, err
+	}
+	return d, ErrDeletionCutShort
+}
+
+// Delete starts and runs a deletion in one go, as the operator script does.
+func (s *Service) Delete(ctx context.Context, userID string) (*Deletion, error) {
+	d, started, err := s.StartDeletion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return d, fmt.Errorf("deletion of %s is already running", userID)
+	}
+	return s.RunDeletion(ctx, d)
+}
+
+// GetDeletion returns the latest deletion record.
+func (s *Service) GetDeletion(ctx context.Context, userID string) (*Deletion, error) {
+	if err := validUserID(userID); err != nil {
+		return nil, err
+	}
+	snap, err := s.deletionRef(userID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var d Deletion
+	if err := snap.DataTo(&d); err != nil {
+		return nil, fmt.Errorf("unmarshal deletion: %w", err)
+	}
+	return &d, nil
+}
+
+// verify recounts every source and sets the final status.
+func (s *Service) verify(ctx context.Context, d *Deletion) {
+	clean := true
+	for i, src := range sources {
+		step := &d.Steps[i]
+		records, err := src.find(ctx, s.client, d.UserID)
+		if err != nil {
+			step.Status, step.Error = StepFailed, "verify: "+err.Error()
+		}
+		step.Remaining = len(records)
+		clean = clean && step.Status == StepDone && step.Remaining == 0
+	}
+	images := &d.Steps[len(sources)]
+	if s.objects != nil {
+		remaining, err := s.listImages(ctx, d.UserID)
+		if err != nil {
+			images.Status, images.Error = StepFailed, "verify: "+err.Error()
+		}
+		images.Remaining = len(remaining)
+		clean = clean && images.Status == StepDone && images.Remaining == 0
+	}
+
+	d.Status = DeletionIncomplete
+	if clean {
+		now := time.Now().UTC()
+		d.Status, d.CompletedAt = DeletionCompleted, &now
+	}
+}
+
+func (step *DeletionStep) finish(err error) {
+	step.Status, step.Error = StepDone, ""
+	if err != nil {
+		step.Status, step.Error = StepFailed, err.Error()
+	}
+}
+
+func (s *Service) save(ctx context.Context, d *Deletion) error {
+	d.UpdatedAt = time.Now().UTC()
+	_, err := s.deletionRef(d.UserID).Set(ctx, d)
+	return err
+}
+
+func (s *Service) deleteRecords(ctx context.Context, records []record) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	bw := s.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(records))
+	for _, r := range records {
+		job, err := bw.Delete(r.snap.Ref)
+		if err != nil {
+			bw.End()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+
+	deleted := 0
+	var firstErr error
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		deleted++
+	}
+	return deleted, firstErr
+}
+
+// removeFromRecords runs src.remove on each record, which takes the user
+// out of documents other users still need.
+func (s *Service) removeFromRecords(ctx context.Context, src source, userID string, records []record) (int, error) {
+	removed := 0
+	for _, r := range records {
+		if err := src.remove(ctx, s.client, userID, r.snap.Ref); err != nil {
+			return removed, fmt.Errorf("remove from %s: %w", r.snap.Ref.Path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (s *Service) listImages(ctx context.Context, userID string) ([]string, error) {
+	var names []string
+	for _, prefix := range imagePrefixes(userID) {
+		found, err := s.objects.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, found...)
+	}
+	return names, nil
+}
+
+func (s *Service) deleteImages(ctx context.Context, userID string) (int, error) {
+	names, err := s.listImages(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, name := range names {
+		if err := s.objects.Delete(ctx, name); err != nil {
+			return deleted, fmt.Errorf("delete %s: %w", name, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,171 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memStore is an in-memory ObjectStore.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memStore) List(_ context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *memStore) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return io.NopCloser(bytes.NewReader(m.objects[name])), nil
+}
+
+func (m *memStore) Delete(_ context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, name)
+	return nil
+}
+
+// emulatorClient connects to FIRESTORE_EMULATOR_HOST, e.g. the
+// firebase-emulator from docker-compose.yml, and skips otherwise.
+func emulatorClient(t *testing.T) *firestore.Client {
+	t.Helper()
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST not set")
+	}
+	client, err := firestore.NewClient(context.Background(), "focusnest-test")
+	if err != nil {
+		t.Fatalf("firestore client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestExportAndDeleteAgainstEmulator(t *testing.T) {
+	client := emulatorClient(t)
+	ctx := context.Background()
+	suffix := time.Now().Format("150405.000000")
+	userID, otherID := "alice-"+suffix, "bob-"+suffix
+	now := time.Now().UTC()
+
+	seed := map[string]map[string]any{
+		"profiles/" + userID:                             {"user_id": userID, "points_total": 50},
+		"profiles/" + userID + "/points_ledger/00000001": {"amount": 50, "created_at": now},
+		"users/" + userID + "/productivities/p1":         {"category": "Work", "start_time": now},
+		"users/" + userID + "/goals/g1":                  {"target": 600},
+		"streak_state/" + userID:                         {"current_streak": 3},
+		"streak_recovery_quota/" + userID + "_2025-11":   {"count": 1},
+		"share_links/s-" + suffix:                        {"user_id": userID},
+		"chat_sessions/c-" + suffix:                      {"user_id": userID},
+		"chat_messages/m-" + suffix:                      {"session_id": "c-" + suffix, "content": "halo"},
+		"feedbacks/f-" + suffix:                          {"user_id": userID, "message": "mantap"},
+		"handles/alice" + suffix:                         {"user_id": userID},
+		"friendships/" + userID + "_" + otherID:          {"users": []string{userID, otherID}},
+		"users/" + otherID + "/blocks/" + userID:         {"user_id": userID},
+		"profiles/" + otherID:                            {"user_id": otherID},
+		"streak_recovery_quota/" + otherID + "_2025-11":  {"count": 2},
+		"users/" + otherID + "/productivities/p2":        {"category": "Study", "start_time": now},
+		"rooms/A" + suffix:                               {"host_id": userID, "members": []map[string]any{{"user_id": userID}}, "member_ids": []string{userID}},
+		"rooms/B" + suffix: {
+			"host_id":    otherID,
+			"members":    []map[string]any{{"user_id": otherID}, {"user_id": userID}},
+			"member_ids": []string{otherID, userID},
+		},
+	}
+	for path, data := range seed {
+		if _, err := client.Doc(path).Set(ctx, data); err != nil {
+			t.Fatalf("seed %s: %v", path, err)
+		}
+	}
+	objects := &memStore{objects: map[string][]byte{
+		"original/" + userID + "/a.jpg":  []byte("jpg"),
+		"overview/" + otherID + "/b.png": []byte("png"),
+	}}
+	svc := NewService(client, objects)
+
+	var buf bytes.Buffer
+	manifest, err := svc.Export(ctx, userID, &buf)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	for _, file := range []string{"profiles", "profiles/points_ledger", "users/productivities", "users/goals", "chat_messages", "friendships"} {
+		if manifest.Files[file] != 1 {
+			t.Errorf("manifest.Files[%q] = %d, want 1", file, manifest.Files[file])
+		}
+	}
+	for _, file := range []string{"blocked_by", "rooms"} {
+		if _, ok := manifest.Files[file]; ok {
+			t.Errorf("export includes shared %s documents", file)
+		}
+	}
+	if len(manifest.Images) != 1 {
+		t.Errorf("manifest.Images = %v, want alice's image", manifest.Images)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, name := range []string{"manifest.json", "json/users/productivities.json", "csv/users/productivities.csv", "images/original/" + userID + "/a.jpg"} {
+		if !names[name] {
+			t.Errorf("archive is missing %s", name)
+		}
+	}
+
+	deletion, err := svc.Delete(ctx, userID)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deletion.Status != DeletionCompleted {
+		t.Fatalf("Delete() status = %s, steps %+v", deletion.Status, deletion.Steps)
+	}
+	stored, err := svc.GetDeletion(ctx, userID)
+	if err != nil || stored.Status != DeletionCompleted {
+		t.Fatalf("GetDeletion() = %+v, %v", stored, err)
+	}
+
+	for _, path := range []string{"profiles/" + otherID, "streak_recovery_quota/" + otherID + "_2025-11", "users/" + otherID + "/productivities/p2"} {
+		if _, err := client.Doc(path).Get(ctx); err != nil {
+			t.Errorf("other user's %s: %v", path, err)
+		}
+	}
+	if _, err := client.Doc("rooms/A" + suffix).Get(ctx); status.Code(err) != codes.NotFound {
+		t.Errorf("room hosted by the deleted user: %v, want it deleted", err)
+	}
+	shared, err := client.Doc("rooms/B" + suffix).Get(ctx)
+	if err != nil {
+		t.Fatalf("room hosted by the other user: %v", err)
+	}
+	if ids, _ := shared.DataAt("member_ids"); len(ids.([]any)) != 1 || ids.([]any)[0] != otherID {
+		t.Errorf("shared room member_ids = %v, want only %s", ids, otherID)
+	}
+	if _, ok := objects.objects["overview/"+otherID+"/b.png"]; !ok {
+		t.Error("other user's image was deleted")
+	}
+}
@@ -0,0 +1,215 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Manifest describes an export archive.
+type Manifest struct {
+	UserID     string         `json:"user_id"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Files      map[string]int `json:"files"` // documents per file, keyed without extension
+	Images     []string       `json:"images"`
+	// ImagesSkipped is true when no image bucket is configured.
+	ImagesSkipped bool `json:"images_skipped,omitempty"`
+}
+
+type exportedDoc struct {
+	Path string         `json:"path"`
+	Data map[string]any `json:"data"`
+}
+
+// Export writes a zip archive of everything stored about the user:
+//
+//	manifest.json     what the archive holds
+//	json/<file>.json  documents as {"path", "data"}
+//	csv/<file>.csv    the same documents, one row each
+//	images/<object>   session images
+func (s *Service) Export(ctx context.Context, userID string, w io.Writer) (*Manifest, error) {
+	if err := validUserID(userID); err != nil {
+		return nil, err
+	}
+	grouped := map[string][]record{}
+	for _, src := range sources {
+		if !src.exported {
+			continue
+		}
+		records, err := src.find(ctx, s.client, userID)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", src.name, err)
+		}
+		for _, r := range records {
+			grouped[r.file] = append(grouped[r.file], r)
+		}
+	}
+
+	manifest := &Manifest{UserID: userID, ExportedAt: time.Now().UTC(), Files: map[string]int{}, Images: []string{}}
+	zw := zip.NewWriter(w)
+	files := make([]string, 0, len(grouped))
+	for file := range grouped {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		docs := make([]exportedDoc, 0, len(grouped[file]))
+		for _, r := range grouped[file] {
+			docs = append(docs, exportedDoc{Path: relativePath(r.snap.Ref), Data: normalize(r.snap.Data()).(map[string]any)})
+		}
+		sort.Slice(docs, func(i, j int) bool { return docs[i].Path < docs[j].Path })
+		if err := writeJSONFile(zw, "json/"+file+".json", docs); err != nil {
+			return nil, err
+		}
+		if err := writeCSVFile(zw, "csv/"+file+".csv", docs); err != nil {
+			return nil, err
+		}
+		manifest.Files[file] = len(docs)
+	}
+
+	if s.objects == nil {
+		manifest.ImagesSkipped = true
+	} else {
+		for _, prefix := range imagePrefixes(userID) {
+			names, err := s.objects.List(ctx, prefix)
+			if err != nil {
+				return nil, fmt.Errorf("export images: %w", err)
+			}
+			for _, name := range names {
+				if err := s.copyObject(ctx, zw, name); err != nil {
+					return nil, err
+				}
+				manifest.Images = append(manifest.Images, name)
+			}
+		}
+	}
+
+	if err := writeJSONFile(zw, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	return manifest, zw.Close()
+}
+
+func (s *Service) copyObject(ctx context.Context, zw *zip.Writer, name string) error {
+	r, err := s.objects.Open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("open image %s: %w", name, err)
+	}
+	defer r.Close()
+	// Images are already compressed.
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: "images/" + name, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, r); err != nil {
+		return fmt.Errorf("copy image %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeJSONFile(zw *zip.Writer, name string, v any) error {
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(dst)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeCSVFile writes one row per document with a column per field seen in
+// any of them. Nested values are JSON encoded.
+func writeCSVFile(zw *zip.Writer, name string, docs []exportedDoc) error {
+	fields := map[string]bool{}
+	for _, d := range docs {
+		for k := range d.Data {
+			fields[k] = true
+		}
+	}
+	columns := make([]string, 0, len(fields))
+	for k := range fields {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(dst)
+	if err := cw.Write(append([]string{"path"}, columns...)); err != nil {
+		return err
+	}
+	for _, d := range docs {
+		row := make([]string, 0, len(columns)+1)
+		row = append(row, d.Path)
+		for _, c := range columns {
+			row = append(row, csvCell(d.Data[c]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case bool, int64, float64:
+		return fmt.Sprint(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+// normalize replaces document references, which do not marshal, with
+// their paths.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case *firestore.DocumentRef:
+		if v == nil {
+			return nil
+		}
+		return relativePath(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = normalize(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	return v
+}
+
+// relativePath strips projects/{p}/databases/{d}/documents/ from a path.
+func relativePath(ref *firestore.DocumentRef) string {
+	if _, rest, ok := strings.Cut(ref.Path, "/documents/"); ok {
+		return rest
+	}
+	return ref.Path
+}
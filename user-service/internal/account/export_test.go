@@ -0,0 +1,67 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+)
+
+func TestWriteCSVFileUnionsFields(t *testing.T) {
+	at := time.Date(2025, 11, 19, 8, 30, 0, 0, time.FixedZone("WIB", 7*3600))
+	docs := []exportedDoc{
+		{Path: "users/u1/productivities/a", Data: map[string]any{"category": "Work", "num_cycle": int64(2), "start_time": at}},
+		{Path: "users/u1/productivities/b", Data: map[string]any{"category": "Study", "tags": []any{"x", "y"}, "deleted": true}},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := writeCSVFile(zw, "csv/users/productivities.csv", docs); err != nil {
+		t.Fatalf("writeCSVFile() error = %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+
+	want := [][]string{
+		{"path", "category", "deleted", "num_cycle", "start_time", "tags"},
+		{"users/u1/productivities/a", "Work", "", "2", "2025-11-19T01:30:00Z", ""},
+		{"users/u1/productivities/b", "Study", "true", "", "", `["x","y"]`},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %v, want %v", rows, want)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Fatalf("row %d = %v, want %v", i, rows[i], want[i])
+			}
+		}
+	}
+}
+
+func TestValidUserID(t *testing.T) {
+	for _, id := range []string{"", "a/b"} {
+		if validUserID(id) == nil {
+			t.Errorf("validUserID(%q) = nil, want error", id)
+		}
+	}
+	if err := validUserID("user_2abc"); err != nil {
+		t.Errorf("validUserID() error = %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// ObjectStore is the part of Cloud Storage exports and deletions use.
+type ObjectStore interface {
+	List(ctx context.Context, prefix string) ([]string, error)
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+type gcsStore struct {
+	bucket *storage.BucketHandle
+}
+
+// NewGCSStore wraps the bucket focus-service uploads session images to.
+// STORAGE_EMULATOR_HOST is honoured by the client.
+func NewGCSStore(client *storage.Client, bucket string) ObjectStore {
+	return &gcsStore{bucket: client.Bucket(bucket)}
+}
+
+func (s *gcsStore) List(ctx context.Context, prefix string) ([]string, error) {
+	iter := s.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	var names []string
+	for {
+		attrs, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			return names, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		names = append(names, attrs.Name)
+	}
+}
+
+func (s *gcsStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return s.bucket.Object(name).NewReader(ctx)
+}
+
+func (s *gcsStore) Delete(ctx context.Context, name string) error {
+	err := s.bucket.Object(name).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
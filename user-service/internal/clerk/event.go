@@ -0,0 +1,127 @@
+package clerk
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// Clerk event types handled by the webhook.
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
+
+// ErrInvalidPayload indicates a body that is not a Clerk user event.
+var ErrInvalidPayload = errors.New("invalid webhook payload")
+
+// Event is a parsed Clerk user event.
+type Event struct {
+	Type string
+	// User is set for user.created and user.updated.
+	User *user.ClerkUser
+	// UserID is set for every user event.
+	UserID string
+	// Timestamp is when Clerk emitted the event.
+	Timestamp time.Time
+}
+
+type envelope struct {
+	Type      string          `json:"type"`
+	Timestamp int64           `json:"timestamp"` // Unix milliseconds
+	Data      json.RawMessage `json:"data"`
+}
+
+// userData is the subset of Clerk's User object the profile uses.
+type userData struct {
+	ID                    string  `json:"id"`
+	Deleted               bool    `json:"deleted"`
+	FirstName             *string `json:"first_name"`
+	LastName              *string `json:"last_name"`
+	Username              *string `json:"username"`
+	ImageURL              string  `json:"image_url"`
+	PrimaryEmailAddressID *string `json:"primary_email_address_id"`
+	EmailAddresses        []struct {
+		ID           string `json:"id"`
+		EmailAddress string `json:"email_address"`
+	} `json:"email_addresses"`
+	PublicMetadata struct {
+		Roles []string `json:"roles"`
+	} `json:"public_metadata"`
+	UpdatedAt int64 `json:"updated_at"` // Unix milliseconds
+}
+
+// ParseEvent decodes a webhook body. Event types other than user events are
+// returned with only Type set.
+func ParseEvent(body []byte) (*Event, error) {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil || env.Type == "" {
+		return nil, ErrInvalidPayload
+	}
+	event := &Event{Type: env.Type, Timestamp: time.UnixMilli(env.Timestamp).UTC()}
+	switch env.Type {
+	case EventUserCreated, EventUserUpdated, EventUserDeleted:
+	default:
+		return event, nil
+	}
+
+	var data userData
+	if err := json.Unmarshal(env.Data, &data); err != nil || data.ID == "" {
+		return nil, ErrInvalidPayload
+	}
+	event.UserID = data.ID
+	if env.Type == EventUserDeleted {
+		return event, nil
+	}
+
+	updatedAt := event.Timestamp
+	if data.UpdatedAt != 0 {
+		updatedAt = time.UnixMilli(data.UpdatedAt).UTC()
+	}
+	event.User = &user.ClerkUser{
+		UserID:      data.ID,
+		Email:       data.primaryEmail(),
+		DisplayName: data.displayName(),
+		AvatarURL:   data.ImageURL,
+		Roles:       data.PublicMetadata.Roles,
+		UpdatedAt:   updatedAt,
+	}
+	return event, nil
+}
+
+// primaryEmail returns the primary address, or the first one when none is
+// marked primary.
+func (d userData) primaryEmail() string {
+	if len(d.EmailAddresses) == 0 {
+		return ""
+	}
+	if d.PrimaryEmailAddressID != nil {
+		for _, e := range d.EmailAddresses {
+			if e.ID == *d.PrimaryEmailAddressID {
+				return e.EmailAddress
+			}
+		}
+	}
+	return d.EmailAddresses[0].EmailAddress
+}
+
+// displayName joins first and last name, falling back to the username.
+func (d userData) displayName() string {
+	var parts []string
+	for _, p := range []*string{d.FirstName, d.LastName} {
+		if p != nil && strings.TrimSpace(*p) != "" {
+			parts = append(parts, strings.TrimSpace(*p))
+		}
+	}
+	if len(parts) > 0 {
+		return strings.Join(parts, " ")
+	}
+	if d.Username != nil {
+		return strings.TrimSpace(*d.Username)
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+// Package clerk receives Clerk's user lifecycle webhooks and mirrors them
+// into the profile store.
+package clerk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/focusnest/shared-libs/events"
+	"github.com/focusnest/shared-libs/pubsub"
+	"github.com/focusnest/user-service/internal/account"
+	"github.com/focusnest/user-service/internal/user"
+)
+
+const (
+	maxBodyBytes   = 1 << 20 // Clerk user payloads are a few KB
+	handlerTimeout = 8 * time.Second
+	// Svix gives up on an attempt after 15 seconds. A deletion that needs
+	// longer is cut short, answered with 5xx and resumed on redelivery.
+	deleteTimeout    = 14 * time.Second
+	deletionRunLimit = 10 * time.Second
+)
+
+// Users applies events to profiles; user.Service implements it.
+type Users interface {
+	SyncClerkUser(ctx context.Context, eventID, eventType string, u user.ClerkUser) (user.SyncOutcome, error)
+	RecordWebhookEvent(ctx context.Context, eventID, eventType string) (bool, error)
+}
+
+// Accounts deletes a user's data; *account.Service implements it.
+type Accounts interface {
+	StartDeletion(ctx context.Context, userID string) (*account.Deletion, bool, error)
+	RunDeletionWithin(ctx context.Context, d *account.Deletion, limit time.Duration) (*account.Deletion, error)
+}
+
+// Handler serves POST /v1/webhooks/clerk.
+//
+// Svix redelivers until it gets a 2xx, so the handler answers 5xx only for
+// failures worth retrying. Every event is applied at most once per Svix
+// message ID, and user events are published only when applied.
+type Handler struct {
+	verifier  *Verifier
+	users     Users
+	accounts  Accounts
+	publisher events.Publisher
+	logger    *slog.Logger
+}
+
+// NewHandler creates the webhook handler.
+func NewHandler(verifier *Verifier, users Users, accounts Accounts, publisher events.Publisher, logger *slog.Logger) *Handler {
+	return &Handler{verifier: verifier, users: users, accounts: accounts, publisher: publisher, logger: logger}
+}
+
+// result is the response body, useful when replaying from the Svix dashboard.
+type result struct {
+	EventID string `json:"event_id"`
+	Type    string `json:"type"`
+	Outcome string `json:"outcome"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "payload too large")
+		return
+	}
+	eventID, err := h.verifier.Verify(r.Header, body)
+	if err != nil {
+		h.logger.Warn("rejected clerk webhook", slog.Any("error", err))
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	event, err := ParseEvent(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	timeout := handlerTimeout
+	if event.Type == EventUserDeleted {
+		timeout = deleteTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	res := result{EventID: eventID, Type: event.Type}
+	switch event.Type {
+	case EventUserCreated, EventUserUpdated:
+		res.Outcome, err = h.sync(ctx, eventID, event)
+	case EventUserDeleted:
+		res.Outcome, err = h.delete(ctx, eventID, event)
+	default:
+		res.Outcome = "ignored"
+	}
+	if errors.Is(err, user.ErrInvalidWebhookEvent) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to process clerk webhook",
+			slog.String("eventId", eventID),
+			slog.String("type", event.Type),
+			slog.String("userId", event.UserID),
+			slog.Any("error", err))
+		writeError(w, http.StatusInternalServerError, "failed to process event")
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) sync(ctx context.Context, eventID string, event *Event) (string, error) {
+	outcome, err := h.users.SyncClerkUser(ctx, eventID, event.Type, *event.User)
+	if err != nil {
+		return "", err
+	}
+	if outcome == user.SyncApplied {
+		h.publish(ctx, events.TypeUserSynced, events.UserSynced{
+			UserID:      event.User.UserID,
+			Email:       event.User.Email,
+			DisplayName: event.User.DisplayName,
+			Roles:       event.User.Roles,
+			SyncedAt:    time.Now().UTC(),
+		})
+	}
+	return string(outcome), nil
+}
+
+// delete runs the account deletion before recording the event, so a
+// failure or a run cut short is retried; a redelivery finds the deletion
+// running or reruns a finished one, which is harmless.
+func (h *Handler) delete(ctx context.Context, eventID string, event *Event) (string, error) {
+	deletion, started, err := h.accounts.StartDeletion(ctx, event.UserID)
+	if err != nil {
+		return "", err
+	}
+	if started {
+		if _, err := h.accounts.RunDeletionWithin(ctx, deletion, deletionRunLimit); err != nil {
+			return "", err
+		}
+	}
+	first, err := h.users.RecordWebhookEvent(ctx, eventID, event.Type)
+	if err != nil {
+		return "", err
+	}
+	if !first {
+		return string(user.SyncDuplicate), nil
+	}
+	h.publish(ctx, events.TypeUserDeleted, events.UserDeleted{
+		UserID:    event.UserID,
+		DeletedAt: event.Timestamp,
+	})
+	return string(user.SyncApplied), nil
+}
+
+// publish reports failures without failing the delivery: the event is
+// already recorded, so a retry would not publish again.
+func (h *Handler) publish(ctx context.Context, eventType string, payload any) {
+	if err := h.publisher.Publish(ctx, pubsub.TopicUserEvents, eventType, payload); err != nil {
+		h.logger.Error("failed to publish user event",
+			slog.String("eventType", eventType),
+			slog.Any("error", err))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
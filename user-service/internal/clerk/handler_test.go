@@ -0,0 +1,357 @@
+package clerk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/events"
+	"github.com/focusnest/user-service/internal/account"
+	"github.com/focusnest/user-service/internal/user"
+)
+
+const fixtureUserID = "user_2vQm8HqZ3nW6tYbR1kPcX9dEfGh"
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return body
+}
+
+func TestParseEvent_Fixtures(t *testing.T) {
+	created, err := ParseEvent(loadFixture(t, "user_created.json"))
+	if err != nil {
+		t.Fatalf("parse user.created: %v", err)
+	}
+	wantCreated := &user.ClerkUser{
+		UserID:      fixtureUserID,
+		Email:       "ayu.lestari@example.org",
+		DisplayName: "Ayu Lestari",
+		AvatarURL:   "https://img.clerk.com/eyJ0eXBlIjoiZGVmYXVsdCJ9",
+		UpdatedAt:   time.UnixMilli(1760780100561).UTC(),
+	}
+	if created.Type != EventUserCreated || !reflect.DeepEqual(created.User, wantCreated) {
+		t.Fatalf("user.created = %+v %+v", created, created.User)
+	}
+
+	updated, err := ParseEvent(loadFixture(t, "user_updated.json"))
+	if err != nil {
+		t.Fatalf("parse user.updated: %v", err)
+	}
+	// The primary address moved to the second entry.
+	if got := updated.User.Email; got != "ayu@focus.example.com" {
+		t.Fatalf("email = %q", got)
+	}
+	if got := updated.User.DisplayName; got != "Ayu Lestari-Putri" {
+		t.Fatalf("display name = %q", got)
+	}
+	if !reflect.DeepEqual(updated.User.Roles, []string{"member", "beta"}) {
+		t.Fatalf("roles = %v", updated.User.Roles)
+	}
+
+	deleted, err := ParseEvent(loadFixture(t, "user_deleted.json"))
+	if err != nil {
+		t.Fatalf("parse user.deleted: %v", err)
+	}
+	if deleted.UserID != fixtureUserID || deleted.User != nil || !deleted.Timestamp.Equal(time.UnixMilli(1760781020447)) {
+		t.Fatalf("user.deleted = %+v", deleted)
+	}
+
+	other, err := ParseEvent(loadFixture(t, "session_created.json"))
+	if err != nil {
+		t.Fatalf("parse session.created: %v", err)
+	}
+	if other.Type != "session.created" || other.UserID != "" {
+		t.Fatalf("session.created = %+v", other)
+	}
+}
+
+func TestParseEvent_DisplayNameFallsBackToUsername(t *testing.T) {
+	body := `{"type":"user.created","timestamp":1,"data":{"id":"user_1","first_name":null,"last_name":"  ","username":"deepwork"}}`
+	event, err := ParseEvent([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if event.User.DisplayName != "deepwork" || event.User.Email != "" {
+		t.Fatalf("user = %+v", event.User)
+	}
+}
+
+func TestParseEvent_RejectsMalformed(t *testing.T) {
+	for _, body := range []string{`not json`, `{}`, `{"type":"user.updated","data":{}}`} {
+		if _, err := ParseEvent([]byte(body)); !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("ParseEvent(%s) err = %v", body, err)
+		}
+	}
+}
+
+// memUsers mirrors the Firestore repository: one marker per event ID and
+// identities ordered by UpdatedAt.
+type memUsers struct {
+	mu       sync.Mutex
+	seen     map[string]bool
+	profiles map[string]user.ClerkUser
+	err      error
+}
+
+func newMemUsers() *memUsers {
+	return &memUsers{seen: map[string]bool{}, profiles: map[string]user.ClerkUser{}}
+}
+
+func (m *memUsers) SyncClerkUser(_ context.Context, eventID, _ string, u user.ClerkUser) (user.SyncOutcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return "", m.err
+	}
+	if m.seen[eventID] {
+		return user.SyncDuplicate, nil
+	}
+	m.seen[eventID] = true
+	if current, ok := m.profiles[u.UserID]; ok && u.UpdatedAt.Before(current.UpdatedAt) {
+		return user.SyncStale, nil
+	}
+	m.profiles[u.UserID] = u
+	return user.SyncApplied, nil
+}
+
+func (m *memUsers) RecordWebhookEvent(_ context.Context, eventID, _ string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.seen[eventID] {
+		return false, nil
+	}
+	m.seen[eventID] = true
+	return true, nil
+}
+
+type fakeAccounts struct {
+	mu       sync.Mutex
+	starts   int
+	running  bool
+	cutShort bool // runs stop at their limit and are saved incomplete
+	ran      chan string
+}
+
+func (f *fakeAccounts) StartDeletion(_ context.Context, userID string) (*account.Deletion, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.starts++
+	d := &account.Deletion{UserID: userID, Status: account.DeletionRunning}
+	if f.running {
+		return d, false, nil
+	}
+	f.running = true
+	return d, true, nil
+}
+
+func (f *fakeAccounts) RunDeletionWithin(_ context.Context, d *account.Deletion, _ time.Duration) (*account.Deletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cutShort {
+		f.running = false
+		d.Status = account.DeletionIncomplete
+		return d, account.ErrDeletionCutShort
+	}
+	d.Status = account.DeletionCompleted
+	f.ran <- d.UserID
+	return d, nil
+}
+
+type published struct {
+	topic, eventType string
+	payload          any
+}
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []published
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, topic, eventType string, payload any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, published{topic, eventType, payload})
+	return nil
+}
+
+type webhookHarness struct {
+	handler   *Handler
+	users     *memUsers
+	accounts  *fakeAccounts
+	publisher *recordingPublisher
+	now       time.Time
+}
+
+func newWebhookHarness(t *testing.T) *webhookHarness {
+	now := time.Unix(1760781000, 0)
+	h := &webhookHarness{
+		users:     newMemUsers(),
+		accounts:  &fakeAccounts{ran: make(chan string, 1)},
+		publisher: &recordingPublisher{},
+		now:       now,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h.handler = NewHandler(newTestVerifier(t, now), h.users, h.accounts, h.publisher, logger)
+	return h
+}
+
+// deliver posts a fixture signed under svixID, as Svix does on every attempt.
+func (h *webhookHarness) deliver(t *testing.T, svixID, fixture string) (int, result) {
+	t.Helper()
+	body := loadFixture(t, fixture)
+	req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/clerk", strings.NewReader(string(body)))
+	req.Header = signedHeader(t, svixID, h.now, body)
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, req)
+
+	var res result
+	_ = json.Unmarshal(rec.Body.Bytes(), &res)
+	return rec.Code, res
+}
+
+func (h *webhookHarness) eventTypes() []string {
+	h.publisher.mu.Lock()
+	defer h.publisher.mu.Unlock()
+	var types []string
+	for _, e := range h.publisher.events {
+		if e.topic != "user.events" {
+			panic("unexpected topic " + e.topic)
+		}
+		types = append(types, e.eventType)
+	}
+	return types
+}
+
+func TestHandler_LifecycleIsIdempotentUnderRedelivery(t *testing.T) {
+	h := newWebhookHarness(t)
+
+	steps := []struct {
+		svixID, fixture string
+		outcome         string
+	}{
+		{"msg_created", "user_created.json", "applied"},
+		{"msg_created", "user_created.json", "duplicate"},
+		{"msg_updated", "user_updated.json", "applied"},
+		{"msg_updated", "user_updated.json", "duplicate"},
+		{"msg_deleted", "user_deleted.json", "applied"},
+		{"msg_deleted", "user_deleted.json", "duplicate"},
+		{"msg_session", "session_created.json", "ignored"},
+	}
+	for _, step := range steps {
+		code, res := h.deliver(t, step.svixID, step.fixture)
+		if code != http.StatusOK || res.Outcome != step.outcome || res.EventID != step.svixID {
+			t.Fatalf("%s (%s): code=%d result=%+v, want outcome %s", step.fixture, step.svixID, code, res, step.outcome)
+		}
+	}
+
+	select {
+	case id := <-h.accounts.ran:
+		if id != fixtureUserID {
+			t.Fatalf("deleted %q", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("deletion did not run")
+	}
+	if h.accounts.starts != 2 {
+		t.Fatalf("StartDeletion calls = %d, want 2", h.accounts.starts)
+	}
+
+	want := []string{events.TypeUserSynced, events.TypeUserSynced, events.TypeUserDeleted}
+	if got := h.eventTypes(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+	synced := h.publisher.events[1].payload.(events.UserSynced)
+	if synced.UserID != fixtureUserID || synced.Email != "ayu@focus.example.com" || synced.DisplayName != "Ayu Lestari-Putri" {
+		t.Fatalf("user synced payload = %+v", synced)
+	}
+	deleted := h.publisher.events[2].payload.(events.UserDeleted)
+	if !deleted.DeletedAt.Equal(time.UnixMilli(1760781020447)) {
+		t.Fatalf("user deleted payload = %+v", deleted)
+	}
+}
+
+func TestHandler_OutOfOrderUpdateIsStale(t *testing.T) {
+	h := newWebhookHarness(t)
+
+	if _, res := h.deliver(t, "msg_updated", "user_updated.json"); res.Outcome != "applied" {
+		t.Fatalf("user.updated outcome = %q", res.Outcome)
+	}
+	if _, res := h.deliver(t, "msg_created", "user_created.json"); res.Outcome != "stale" {
+		t.Fatalf("late user.created outcome = %q", res.Outcome)
+	}
+	if got := h.users.profiles[fixtureUserID].DisplayName; got != "Ayu Lestari-Putri" {
+		t.Fatalf("display name = %q", got)
+	}
+	if got := h.eventTypes(); len(got) != 1 {
+		t.Fatalf("published %v", got)
+	}
+}
+
+func TestHandler_RejectsBadSignature(t *testing.T) {
+	h := newWebhookHarness(t)
+	body := loadFixture(t, "user_created.json")
+	req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/clerk", strings.NewReader(string(body)))
+	req.Header = signedHeader(t, "msg_created", h.now, []byte(`{}`))
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("code = %d", rec.Code)
+	}
+	if len(h.users.seen) != 0 || len(h.eventTypes()) != 0 {
+		t.Fatal("unverified event was processed")
+	}
+}
+
+func TestHandler_StoreFailureAsksForRetry(t *testing.T) {
+	h := newWebhookHarness(t)
+	h.users.err = errors.New("firestore unavailable")
+
+	if code, _ := h.deliver(t, "msg_created", "user_created.json"); code != http.StatusInternalServerError {
+		t.Fatalf("code = %d, want 500", code)
+	}
+
+	h.users.err = nil
+	if _, res := h.deliver(t, "msg_created", "user_created.json"); res.Outcome != "applied" {
+		t.Fatalf("retry outcome = %q", res.Outcome)
+	}
+}
+
+func TestHandler_DeletionCutShortIsRedelivered(t *testing.T) {
+	h := newWebhookHarness(t)
+	h.accounts.cutShort = true
+
+	if code, _ := h.deliver(t, "msg_deleted", "user_deleted.json"); code != http.StatusInternalServerError {
+		t.Fatalf("cut-short deletion code = %d, want 500 so Svix redelivers", code)
+	}
+	if got := h.eventTypes(); len(got) != 0 {
+		t.Fatalf("published %v before the deletion finished", got)
+	}
+
+	h.accounts.cutShort = false
+	code, res := h.deliver(t, "msg_deleted", "user_deleted.json")
+	if code != http.StatusOK || res.Outcome != "applied" {
+		t.Fatalf("redelivery code=%d result=%+v, want applied", code, res)
+	}
+	if h.accounts.starts != 2 {
+		t.Fatalf("StartDeletion calls = %d, want the redelivery to start over", h.accounts.starts)
+	}
+	if got := h.eventTypes(); !reflect.DeepEqual(got, []string{events.TypeUserDeleted}) {
+		t.Fatalf("published %v, want user.deleted once", got)
+	}
+}
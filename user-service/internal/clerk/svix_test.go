@@ -0,0 +1,143 @@
+package clerk
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testSecret signs the fixtures in tests; it is not a real Clerk secret.
+const testSecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+func newTestVerifier(t *testing.T, now time.Time) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(testSecret)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	v.now = func() time.Time { return now }
+	return v
+}
+
+// signedHeader signs body the way Svix does.
+func signedHeader(t *testing.T, id string, at time.Time, body []byte) http.Header {
+	t.Helper()
+	v := newTestVerifier(t, at)
+	ts := strconv.FormatInt(at.Unix(), 10)
+	h := http.Header{}
+	h.Set("svix-id", id)
+	h.Set("svix-timestamp", ts)
+	h.Set("svix-signature", "v1,"+base64.StdEncoding.EncodeToString(v.sign(id, ts, body)))
+	return h
+}
+
+func TestVerify_SvixReferenceVector(t *testing.T) {
+	// Example delivery from the Svix verification docs.
+	v := newTestVerifier(t, time.Unix(1614265330, 0))
+	h := http.Header{}
+	h.Set("svix-id", "msg_p5jXN8AQM9LWM0D4loKWxJek")
+	h.Set("svix-timestamp", "1614265330")
+	h.Set("svix-signature", "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=")
+
+	id, err := v.Verify(h, []byte(`{"test": 2432232314}`))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if id != "msg_p5jXN8AQM9LWM0D4loKWxJek" {
+		t.Fatalf("id = %q", id)
+	}
+}
+
+func TestVerify_Rejections(t *testing.T) {
+	now := time.Unix(1760780100, 0)
+	body := []byte(`{"type":"user.created"}`)
+
+	tests := []struct {
+		name   string
+		header func() http.Header
+		body   []byte
+		want   error
+	}{
+		{
+			name:   "tampered body",
+			header: func() http.Header { return signedHeader(t, "msg_1", now, body) },
+			body:   []byte(`{"type":"user.deleted"}`),
+			want:   ErrInvalidSignature,
+		},
+		{
+			name: "other id",
+			header: func() http.Header {
+				h := signedHeader(t, "msg_1", now, body)
+				h.Set("svix-id", "msg_2")
+				return h
+			},
+			want: ErrInvalidSignature,
+		},
+		{
+			name:   "too old",
+			header: func() http.Header { return signedHeader(t, "msg_1", now.Add(-6*time.Minute), body) },
+			want:   ErrInvalidTimestamp,
+		},
+		{
+			name:   "too far ahead",
+			header: func() http.Header { return signedHeader(t, "msg_1", now.Add(6*time.Minute), body) },
+			want:   ErrInvalidTimestamp,
+		},
+		{
+			name: "unsupported version",
+			header: func() http.Header {
+				h := signedHeader(t, "msg_1", now, body)
+				h.Set("svix-signature", "v2"+h.Get("svix-signature")[2:])
+				return h
+			},
+			want: ErrInvalidSignature,
+		},
+		{
+			name: "missing signature",
+			header: func() http.Header {
+				h := signedHeader(t, "msg_1", now, body)
+				h.Del("svix-signature")
+				return h
+			},
+			want: ErrMissingHeaders,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := body
+			if tt.body != nil {
+				b = tt.body
+			}
+			_, err := newTestVerifier(t, now).Verify(tt.header(), b)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify_AcceptsRotatedSecretAndWebhookHeaders(t *testing.T) {
+	now := time.Unix(1760780100, 0)
+	body := []byte(`{"type":"user.created"}`)
+	signed := signedHeader(t, "msg_1", now, body)
+
+	h := http.Header{}
+	h.Set("webhook-id", "msg_1")
+	h.Set("webhook-timestamp", signed.Get("svix-timestamp"))
+	h.Set("webhook-signature", "v1,b2xkLXNlY3JldC1zaWduYXR1cmU= "+signed.Get("svix-signature"))
+
+	if _, err := newTestVerifier(t, now).Verify(h, body); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
+
+func TestNewVerifier_RejectsMalformedSecret(t *testing.T) {
+	for _, secret := range []string{"", "whsec_", "whsec_not base64!"} {
+		if _, err := NewVerifier(secret); err == nil {
+			t.Errorf("NewVerifier(%q) succeeded", secret)
+		}
+	}
+}
@@ -10,6 +10,8 @@ type Config struct {
 	DataStore    string `validate:"required"`
 	Auth         AuthConfig
 	Firestore    FirestoreConfig
+	Storage      StorageConfig
+	Webhooks     WebhookConfig
 }
 
 type AuthConfig struct {
@@ -23,6 +25,19 @@ type FirestoreConfig struct {
 	EmulatorHost string
 }
 
+// StorageConfig points at focus-service's image bucket, which holds avatar
+// uploads and which account export and deletion read. Empty disables avatar
+// uploads and skips images.
+type StorageConfig struct {
+	Bucket string
+}
+
+// WebhookConfig holds the Clerk webhook signing secret ("whsec_..."). Empty
+// leaves the webhook unmounted.
+type WebhookConfig struct {
+	ClerkSecret string
+}
+
 func Load() (Config, error) {
 	cfg := Config{
 		Port:         envconfig.Get("PORT", "8080"),
@@ -37,6 +52,12 @@ func Load() (Config, error) {
 		Firestore: FirestoreConfig{
 			EmulatorHost: envconfig.Get("FIRESTORE_EMULATOR_HOST", ""),
 		},
+		Storage: StorageConfig{
+			Bucket: envconfig.Get("FOCUS_STORAGE_BUCKET", ""),
+		},
+		Webhooks: WebhookConfig{
+			ClerkSecret: envconfig.Get("CLERK_WEBHOOK_SECRET", ""),
+		},
 	}
 	return cfg, envconfig.Validate(cfg)
 }
@@ -0,0 +1,152 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/focusnest/user-service/internal/account"
+)
+
+// exportTimeout stays under the server's 60s write timeout.
+const exportTimeout = 50 * time.Second
+
+// deletionRunTimeout leaves room under the 60s timeouts to save a run that
+// was cut short and answer.
+const deletionRunTimeout = 45 * time.Second
+
+// registerAccountRoutes mounts data export and deletion under /v1/users.
+func registerAccountRoutes(r chi.Router, accounts *account.Service, logger *slog.Logger) {
+	r.Get("/me/export", exportAccount(accounts, logger))
+	r.Post("/me/deletion", requestDeletion(accounts, logger))
+	r.Get("/me/deletion", getDeletion(accounts, logger))
+}
+
+// GET /v1/users/me/export
+func exportAccount(accounts *account.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), exportTimeout)
+		defer cancel()
+
+		// Build the archive first so a failure can still return a 500.
+		tmp, err := os.CreateTemp("", "export-*.zip")
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to create export file", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to export data")
+			return
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		manifest, err := accounts.Export(ctx, userID, tmp)
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to export data", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to export data")
+			return
+		}
+		size, err := tmp.Seek(0, io.SeekCurrent)
+		if err == nil {
+			_, err = tmp.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to read export file", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to export data")
+			return
+		}
+
+		filename := fmt.Sprintf("focusnest-export-%s.zip", manifest.ExportedAt.Format("20060102-150405"))
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.Copy(w, tmp)
+	}
+}
+
+// POST /v1/users/me/deletion with {"confirm": true}
+func requestDeletion(accounts *account.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var body struct {
+			Confirm bool `json:"confirm"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+		if !body.Confirm {
+			writeError(w, http.StatusBadRequest, "confirm must be true")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		deletion, started, err := accounts.StartDeletion(ctx, userID)
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to start deletion", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to start deletion")
+			return
+		}
+		if !started {
+			writeJSON(w, http.StatusAccepted, deletion)
+			return
+		}
+
+		deletion, err = accounts.RunDeletionWithin(r.Context(), deletion, deletionRunTimeout)
+		if errors.Is(err, account.ErrDeletionCutShort) {
+			writeJSON(w, http.StatusAccepted, deletion)
+			return
+		}
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to run deletion", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to run deletion")
+			return
+		}
+		writeJSON(w, http.StatusOK, deletion)
+	}
+}
+
+// GET /v1/users/me/deletion
+func getDeletion(accounts *account.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		deletion, err := accounts.GetDeletion(ctx, userID)
+		if errors.Is(err, account.ErrDeletionNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to load deletion", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to load deletion")
+			return
+		}
+		writeJSON(w, http.StatusOK, deletion)
+	}
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// registerBadgeRoutes mounts the badge endpoints.
+func registerBadgeRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
+	r.Route("/v1/badges", func(r chi.Router) {
+		r.Use(middleware.Recoverer)
+
+		r.Get("/", getBadges(service, logger))
+		r.Post("/seen", markBadgesSeen(service, logger))
+	})
+}
+
+// GET /v1/badges
+func getBadges(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		badges, err := service.GetBadges(ctx, userID)
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to load badges", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to load badges")
+			return
+		}
+		writeJSON(w, http.StatusOK, badges)
+	}
+}
+
+// POST /v1/badges/seen
+func markBadgesSeen(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		if err := service.MarkBadgesSeen(ctx, userID); err != nil {
+			logRequestError(r.Context(), logger, "failed to mark badges seen", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to mark badges seen")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,382 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// registerFriendRoutes mounts the friend graph, block list, leaderboard and
+// privacy endpoints.
+func registerFriendRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
+	r.Route("/v1/friends", func(r chi.Router) {
+		r.Use(middleware.Recoverer)
+
+		r.Get("/", listFriends(service, logger))
+		r.Get("/code", getFriendCode(service, logger))
+		r.Get("/leaderboard", getFriendLeaderboard(service, logger))
+		r.Get("/requests", listFriendRequests(service, logger))
+		r.Post("/requests", sendFriendRequest(service, logger))
+		r.Post("/requests/{userID}/accept", acceptFriendRequest(service, logger))
+		r.Post("/requests/{userID}/decline", declineFriendRequest(service, logger))
+		r.Delete("/{userID}", removeFriend(service, logger))
+		r.Get("/blocks", listBlockedUsers(service, logger))
+		r.Put("/blocks/{userID}", blockUser(service, logger))
+		r.Delete("/blocks/{userID}", unblockUser(service, logger))
+	})
+}
+
+// GET /v1/friends
+func listFriends(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		friends, err := service.ListFriends(ctx, userID)
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to list friends", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"friends": friends})
+	}
+}
+
+// GET /v1/friends/code
+func getFriendCode(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		code, err := service.GetFriendCode(ctx, userID)
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to load friend code", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"code": code})
+	}
+}
+
+// GET /v1/friends/leaderboard?period=week|month&metric=minutes|streak|points
+func getFriendLeaderboard(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		timezone := r.Header.Get("X-Timezone")
+		if timezone == "" {
+			timezone = r.URL.Query().Get("timezone")
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		board, err := service.GetFriendLeaderboard(ctx, userID, user.LeaderboardInput{
+			Period:   user.LeaderboardPeriod(r.URL.Query().Get("period")),
+			Metric:   user.LeaderboardMetric(r.URL.Query().Get("metric")),
+			Timezone: timezone,
+		})
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to load leaderboard", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, board)
+	}
+}
+
+// GET /v1/friends/requests
+func listFriendRequests(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.ListFriendRequests(ctx, userID)
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to list friend requests", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// POST /v1/friends/requests {"handle": "..."} or {"code": "..."}
+func sendFriendRequest(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var body struct {
+			Handle string `json:"handle"`
+			Code   string `json:"code"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		friendship, err := service.SendFriendRequest(ctx, userID, user.FriendRequestInput{Handle: body.Handle, Code: body.Code})
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to send friend request", err, userID)
+			return
+		}
+		status := http.StatusCreated
+		if friendship.Status == user.FriendshipAccepted {
+			status = http.StatusOK
+		}
+		writeJSON(w, status, friendship)
+	}
+}
+
+// POST /v1/friends/requests/{userID}/accept
+func acceptFriendRequest(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		friendship, err := service.AcceptFriendRequest(ctx, userID, chi.URLParam(r, "userID"))
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to accept friend request", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, friendship)
+	}
+}
+
+// POST /v1/friends/requests/{userID}/decline
+func declineFriendRequest(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		if err := service.DeclineFriendRequest(ctx, userID, chi.URLParam(r, "userID")); err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to decline friend request", err, userID)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// DELETE /v1/friends/{userID}
+func removeFriend(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		if err := service.RemoveFriend(ctx, userID, chi.URLParam(r, "userID")); err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to remove friend", err, userID)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GET /v1/friends/blocks
+func listBlockedUsers(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		blocks, err := service.ListBlockedUsers(ctx, userID)
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to list blocked users", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"blocks": blocks})
+	}
+}
+
+// PUT /v1/friends/blocks/{userID}
+func blockUser(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		if err := service.BlockUser(ctx, userID, chi.URLParam(r, "userID")); err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to block user", err, userID)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// DELETE /v1/friends/blocks/{userID}
+func unblockUser(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		if err := service.UnblockUser(ctx, userID, chi.URLParam(r, "userID")); err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to unblock user", err, userID)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GET /v1/users/me/privacy
+func getPrivacy(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		privacy, err := service.GetPrivacy(ctx, userID)
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to load privacy settings", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, privacy)
+	}
+}
+
+// PATCH /v1/users/me/privacy
+func updatePrivacy(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var body struct {
+			FocusMinutes   *user.Visibility `json:"focus_minutes"`
+			Streak         *user.Visibility `json:"streak"`
+			Points         *user.Visibility `json:"points"`
+			Badges         *user.Visibility `json:"badges"`
+			FriendRequests *bool            `json:"friend_requests"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		privacy, err := service.UpdatePrivacy(ctx, userID, user.PrivacyPatch{
+			FocusMinutes:   body.FocusMinutes,
+			Streak:         body.Streak,
+			Points:         body.Points,
+			Badges:         body.Badges,
+			FriendRequests: body.FriendRequests,
+		})
+		if err != nil {
+			writeFriendError(r.Context(), w, logger, "failed to update privacy settings", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, privacy)
+	}
+}
+
+// decodeBody decodes a single strict JSON object into dst, writing a 400 or
+// 413 and returning false on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPatchBodyBytes)
+	defer r.Body.Close()
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(dst)
+	if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = errInvalidPayload
+	}
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "payload too large")
+		} else {
+			writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+		}
+		return false
+	}
+	return true
+}
+
+func writeFriendError(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, message string, err error, userID string) {
+	switch {
+	case errors.Is(err, user.ErrInvalidFriendRequest), errors.Is(err, user.ErrInvalidPrivacy), errors.Is(err, user.ErrInvalidLeaderboard):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, user.ErrFriendRequestsClosed):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, user.ErrUserNotFound), errors.Is(err, user.ErrFriendRequestNotFound), errors.Is(err, user.ErrNotFriends):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, user.ErrAlreadyFriends), errors.Is(err, user.ErrFriendLimitReached):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		logRequestError(ctx, logger, message, err, userID)
+		writeError(w, http.StatusInternalServerError, message)
+	}
+}
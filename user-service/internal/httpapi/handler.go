@@ -13,6 +13,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/focusnest/shared-libs/timezone"
+	"github.com/focusnest/user-service/internal/account"
 	"github.com/focusnest/user-service/internal/user"
 )
 
@@ -22,15 +24,32 @@ const (
 	maxPatchBodyBytes = 64 * 1024 // 64KB of JSON is more than enough for profile updates
 )
 
-// RegisterRoutes registers all user routes
-func RegisterRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
+// RegisterRoutes registers all user routes. accounts may be nil to leave out
+// data export and deletion. tz is the resolver behind timezone.Middleware;
+// profile and preference updates drop its cached lookup so the next request
+// sees the new timezone.
+func RegisterRoutes(r chi.Router, service user.Service, accounts *account.Service, tz *timezone.Resolver, logger *slog.Logger) {
 	r.Route("/v1/users", func(r chi.Router) {
 		r.Use(middleware.Recoverer)
 
 		r.Get("/me", getProfile(service, logger))
-		r.Patch("/me", updateProfile(service, logger))
+		r.Patch("/me", updateProfile(service, tz, logger))
+		r.Get("/me/privacy", getPrivacy(service, logger))
+		r.Patch("/me/privacy", updatePrivacy(service, logger))
+		r.Put("/me/handle", setHandle(service, logger))
+		r.Put("/me/avatar", uploadAvatar(service, logger))
+		r.Delete("/me/avatar", deleteAvatar(service, logger))
+		registerPreferencesRoutes(r, service, tz, logger)
+		if accounts != nil {
+			registerAccountRoutes(r, accounts, logger)
+		}
 	})
 
+	registerFriendRoutes(r, service, logger)
+	registerPublicProfileRoutes(r, service, logger)
+	registerPointsRoutes(r, service, logger)
+	registerBadgeRoutes(r, service, logger)
+
 	r.Route("/v1/challenges", func(r chi.Router) {
 		r.Use(middleware.Recoverer)
 
@@ -45,10 +64,7 @@ func RegisterRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
 		r.Post("/", recordShare(service, logger))
 	})
 
-	r.Route("/v1/mindfulness", func(r chi.Router) {
-		r.Use(middleware.Recoverer)
-		r.Post("/", recordMindfulness(service, logger))
-	})
+	registerMindfulnessRoutes(r, service, logger)
 }
 
 func listChallenges(service user.Service, logger *slog.Logger) http.HandlerFunc {
@@ -233,7 +249,7 @@ func getProfile(service user.Service, logger *slog.Logger) http.HandlerFunc {
 	}
 }
 
-func updateProfile(service user.Service, logger *slog.Logger) http.HandlerFunc {
+func updateProfile(service user.Service, tz *timezone.Resolver, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := headerUserID(r)
 		if userID == "" {
@@ -248,8 +264,8 @@ func updateProfile(service user.Service, logger *slog.Logger) http.HandlerFunc {
 		if err != nil {
 			var maxErr *http.MaxBytesError
 			switch {
-			case errors.Is(err, errInvalidPayload):
-				writeError(w, http.StatusBadRequest, errInvalidPayload.Error())
+			case errors.Is(err, errInvalidPayload), errors.Is(err, errInvalidTimezone):
+				writeError(w, http.StatusBadRequest, err.Error())
 			case errors.As(err, &maxErr):
 				writeError(w, http.StatusRequestEntityTooLarge, "payload too large")
 			default:
@@ -275,12 +291,16 @@ func updateProfile(service user.Service, logger *slog.Logger) http.HandlerFunc {
 			writeError(w, http.StatusInternalServerError, "failed to update profile")
 			return
 		}
+		forgetTimezone(tz, userID)
 
 		writeJSON(w, http.StatusOK, profile)
 	}
 }
 
-var errInvalidPayload = errors.New("invalid request body")
+var (
+	errInvalidPayload  = errors.New("invalid request body")
+	errInvalidTimezone = errors.New("invalid timezone, use an IANA name such as Asia/Jakarta")
+)
 
 func decodePatchPayload(r *http.Request) (user.ProfileUpdateInput, error) {
 	var (
@@ -288,6 +308,7 @@ func decodePatchPayload(r *http.Request) (user.ProfileUpdateInput, error) {
 		body  struct {
 			Bio       *string          `json:"bio"`
 			Birthdate *json.RawMessage `json:"birthdate"`
+			Timezone  *string          `json:"timezone"`
 		}
 	)
 
@@ -303,12 +324,20 @@ func decodePatchPayload(r *http.Request) (user.ProfileUpdateInput, error) {
 	if err := decoder.Decode(&struct{}{}); err != io.EOF {
 		return input, errInvalidPayload
 	}
-	if body.Bio == nil && body.Birthdate == nil {
+	if body.Bio == nil && body.Birthdate == nil && body.Timezone == nil {
 		return input, errInvalidPayload
 	}
 
 	input.Bio = body.Bio
 
+	if body.Timezone != nil {
+		tz := strings.TrimSpace(*body.Timezone)
+		if tz != "" && !timezone.Valid(tz) {
+			return input, errInvalidTimezone
+		}
+		input.Timezone = &tz
+	}
+
 	if body.Birthdate != nil {
 		patch := &user.BirthdatePatch{IsSet: true}
 		if string(*body.Birthdate) != "null" {
@@ -340,6 +369,15 @@ func headerUserID(r *http.Request) string {
 	return r.Header.Get("x-user-id")
 }
 
+// RegisterWebhookRoutes mounts webhooks that authenticate by signature
+// rather than a user token, so r must sit outside the auth middleware.
+func RegisterWebhookRoutes(r chi.Router, clerkWebhook http.Handler) {
+	r.Route("/v1/webhooks", func(r chi.Router) {
+		r.Use(middleware.Recoverer)
+		r.Method(http.MethodPost, "/clerk", clerkWebhook)
+	})
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
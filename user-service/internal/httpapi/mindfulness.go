@@ -0,0 +1,143 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// registerMindfulnessRoutes mounts the guided exercise catalog and session
+// endpoints. POST /v1/mindfulness still takes a bare minute count for older
+// clients.
+func registerMindfulnessRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
+	r.Route("/v1/mindfulness", func(r chi.Router) {
+		r.Use(middleware.Recoverer)
+
+		r.Post("/", recordMindfulness(service, logger))
+		r.Get("/exercises", listMindfulnessExercises(service))
+		r.Get("/sessions", getMindfulnessHistory(service, logger))
+		r.Post("/sessions", recordMindfulnessSession(service, logger))
+		r.Get("/stats", getMindfulnessStats(service, logger))
+	})
+}
+
+// GET /v1/mindfulness/exercises
+func listMindfulnessExercises(service user.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if headerUserID(r) == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"exercises": service.ListMindfulnessExercises(r.Context())})
+	}
+}
+
+// POST /v1/mindfulness/sessions
+func recordMindfulnessSession(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var input user.MindfulnessSessionInput
+		if !decodeBody(w, r, &input) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		session, err := service.RecordMindfulnessSession(ctx, userID, input)
+		if err != nil {
+			writeMindfulnessError(r.Context(), w, logger, "failed to record mindfulness session", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusCreated, session)
+	}
+}
+
+// GET /v1/mindfulness/sessions?before=<RFC3339>&before_id=<id>&limit=<n>
+func getMindfulnessHistory(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		before := user.MindfulnessCursor{ID: r.URL.Query().Get("before_id")}
+		if raw := r.URL.Query().Get("before"); raw != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid before")
+				return
+			}
+			before.CompletedAt = parsed
+		}
+		limit, err := queryInt(r, "limit")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		history, err := service.GetMindfulnessHistory(ctx, userID, before, limit)
+		if err != nil {
+			writeMindfulnessError(r.Context(), w, logger, "failed to load mindfulness history", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, history)
+	}
+}
+
+// GET /v1/mindfulness/stats?days=<n>
+func getMindfulnessStats(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		days, err := queryInt(r, "days")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid days")
+			return
+		}
+		timezone := r.Header.Get("X-Timezone")
+		if timezone == "" {
+			timezone = r.URL.Query().Get("timezone")
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		stats, err := service.GetMindfulnessStats(ctx, userID, days, timezone)
+		if err != nil {
+			writeMindfulnessError(r.Context(), w, logger, "failed to load mindfulness stats", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
+func writeMindfulnessError(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, message string, err error, userID string) {
+	switch {
+	case errors.Is(err, user.ErrInvalidMindfulness):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		logRequestError(ctx, logger, message, err, userID)
+		writeError(w, http.StatusInternalServerError, message)
+	}
+}
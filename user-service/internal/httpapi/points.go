@@ -0,0 +1,143 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// registerPointsRoutes mounts the points ledger and reward redemption
+// endpoints. Adjustments and reversals are operator tasks and are not
+// exposed here; see scripts/adjust_points.go.
+func registerPointsRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
+	r.Route("/v1/points", func(r chi.Router) {
+		r.Use(middleware.Recoverer)
+
+		r.Get("/", getPoints(service, logger))
+		r.Get("/rewards", listRewards(service, logger))
+		r.Post("/redeem", redeemReward(service, logger))
+	})
+}
+
+// GET /v1/points?before=<seq>&limit=<n>
+func getPoints(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		before, err := queryInt(r, "before")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid before")
+			return
+		}
+		limit, err := queryInt(r, "limit")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		points, err := service.GetPoints(ctx, userID, before, limit)
+		if err != nil {
+			writePointsError(r.Context(), w, logger, "failed to load points", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, points)
+	}
+}
+
+// GET /v1/points/rewards
+func listRewards(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		rewards, err := service.ListRewards(ctx, userID)
+		if err != nil {
+			writePointsError(r.Context(), w, logger, "failed to list rewards", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"rewards": rewards})
+	}
+}
+
+// POST /v1/points/redeem with an Idempotency-Key header
+func redeemReward(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var input user.RedeemInput
+		if !decodeBody(w, r, &input) {
+			return
+		}
+		input.IdempotencyKey = r.Header.Get("Idempotency-Key")
+		if input.IdempotencyKey == "" {
+			writeError(w, http.StatusBadRequest, "missing Idempotency-Key header")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.Redeem(ctx, userID, input)
+		if err != nil {
+			writePointsError(r.Context(), w, logger, "failed to redeem reward", err, userID)
+			return
+		}
+		status := http.StatusCreated
+		if resp.Replayed {
+			status = http.StatusOK
+		}
+		writeJSON(w, status, resp)
+	}
+}
+
+// queryInt parses an optional non-negative integer query parameter.
+func queryInt(r *http.Request, name string) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, errInvalidPayload
+	}
+	return n, nil
+}
+
+func writePointsError(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, message string, err error, userID string) {
+	switch {
+	case errors.Is(err, user.ErrInvalidPoints):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, user.ErrRewardNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, user.ErrInsufficientPoints), errors.Is(err, user.ErrRewardLimitReached),
+		errors.Is(err, user.ErrIdempotencyKeyReused):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		logRequestError(ctx, logger, message, err, userID)
+		writeError(w, http.StatusInternalServerError, message)
+	}
+}
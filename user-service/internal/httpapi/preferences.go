@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/focusnest/shared-libs/preferences"
+	"github.com/focusnest/shared-libs/timezone"
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// registerPreferencesRoutes mounts the preferences endpoints under /v1/users.
+func registerPreferencesRoutes(r chi.Router, service user.Service, tz *timezone.Resolver, logger *slog.Logger) {
+	r.Get("/me/preferences", getPreferences(service, logger))
+	r.Patch("/me/preferences", updatePreferences(service, tz, logger))
+}
+
+// RegisterInternalRoutes mounts GET /internal/users/{userID}/preferences for
+// other services. The gateway does not proxy /internal, so only callers
+// allowed to invoke user-service directly reach it.
+func RegisterInternalRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
+	r.Get("/internal/users/{userID}/preferences", getUserPreferences(service, logger))
+}
+
+// GET /internal/users/{userID}/preferences
+func getUserPreferences(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := chi.URLParam(r, "userID")
+		if userID == "" {
+			writeError(w, http.StatusBadRequest, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		prefs, err := service.GetPreferences(ctx, userID)
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to load preferences", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to load preferences")
+			return
+		}
+		writePreferences(w, prefs)
+	}
+}
+
+// GET /v1/users/me/preferences
+func getPreferences(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		prefs, err := service.GetPreferences(ctx, userID)
+		if err != nil {
+			logRequestError(r.Context(), logger, "failed to load preferences", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to load preferences")
+			return
+		}
+		writePreferences(w, prefs)
+	}
+}
+
+// PATCH /v1/users/me/preferences, optionally with If-Match: "<revision>"
+func updatePreferences(service user.Service, tz *timezone.Resolver, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var ifRevision *int
+		if header := r.Header.Get("If-Match"); header != "" {
+			revision, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+			if err != nil || revision < 0 {
+				writeError(w, http.StatusBadRequest, "If-Match must be a preferences revision")
+				return
+			}
+			ifRevision = &revision
+		}
+
+		var patch preferences.Patch
+		if !decodeBody(w, r, &patch) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		prefs, err := service.UpdatePreferences(ctx, userID, patch, ifRevision)
+		switch {
+		case errors.Is(err, preferences.ErrInvalid):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, user.ErrPreferencesConflict):
+			writeError(w, http.StatusPreconditionFailed, err.Error())
+		case err != nil:
+			logRequestError(r.Context(), logger, "failed to update preferences", err, userID)
+			writeError(w, http.StatusInternalServerError, "failed to update preferences")
+		default:
+			if patch.Timezone != nil {
+				forgetTimezone(tz, userID)
+			}
+			writePreferences(w, prefs)
+		}
+	}
+}
+
+// writePreferences sends prefs with its revision as the ETag.
+func writePreferences(w http.ResponseWriter, prefs *preferences.Preferences) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(prefs.Revision)))
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// forgetTimezone drops the cached profile timezone after userID changed it.
+// Other services keep their own cache and catch up within its TTL.
+func forgetTimezone(tz *timezone.Resolver, userID string) {
+	if tz != nil {
+		tz.Forget(userID)
+	}
+}
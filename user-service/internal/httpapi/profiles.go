@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/focusnest/shared-libs/imageupload"
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// maxAvatarBytes caps avatar uploads, multipart overhead included.
+const maxAvatarBytes = 5 << 20
+
+// registerPublicProfileRoutes mounts profile lookup by handle.
+func registerPublicProfileRoutes(r chi.Router, service user.Service, logger *slog.Logger) {
+	r.Route("/v1/profiles", func(r chi.Router) {
+		r.Use(middleware.Recoverer)
+
+		r.Get("/{handle}", getPublicProfile(service, logger))
+	})
+}
+
+// GET /v1/profiles/{handle}
+func getPublicProfile(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		timezone := r.Header.Get("X-Timezone")
+		if timezone == "" {
+			timezone = r.URL.Query().Get("timezone")
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		profile, err := service.GetPublicProfile(ctx, userID, chi.URLParam(r, "handle"), timezone)
+		if err != nil {
+			writeProfileError(r.Context(), w, logger, "failed to load profile", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, profile)
+	}
+}
+
+// PUT /v1/users/me/handle {"handle": "sam"}
+func setHandle(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		var body struct {
+			Handle string `json:"handle"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		resp, err := service.SetHandle(ctx, userID, body.Handle)
+		if err != nil {
+			writeProfileError(r.Context(), w, logger, "failed to set handle", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// PUT /v1/users/me/avatar (multipart, field "avatar")
+func uploadAvatar(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxAvatarBytes)
+		if err := r.ParseMultipartForm(maxAvatarBytes); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(w, http.StatusRequestEntityTooLarge, "avatar too large")
+				return
+			}
+			writeError(w, http.StatusBadRequest, "expected multipart form with an avatar file")
+			return
+		}
+		file, header, err := r.FormFile("avatar")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "missing avatar file")
+			return
+		}
+		defer file.Close()
+		if err := imageupload.ValidateFile(header); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		url, err := service.UploadAvatar(ctx, userID, user.AvatarUpload{
+			Data:        file,
+			Filename:    header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+		})
+		if err != nil {
+			writeProfileError(r.Context(), w, logger, "failed to upload avatar", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"avatar_url": url})
+	}
+}
+
+// DELETE /v1/users/me/avatar
+func deleteAvatar(service user.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := headerUserID(r)
+		if userID == "" {
+			writeError(w, http.StatusUnauthorized, "missing user ID")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), serviceTimeout)
+		defer cancel()
+
+		url, err := service.DeleteAvatar(ctx, userID)
+		if err != nil {
+			writeProfileError(r.Context(), w, logger, "failed to delete avatar", err, userID)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"avatar_url": url})
+	}
+}
+
+func writeProfileError(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, message string, err error, userID string) {
+	switch {
+	case errors.Is(err, user.ErrInvalidHandle):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, user.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, user.ErrHandleTaken):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, user.ErrHandleCooldown):
+		writeError(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, user.ErrAvatarsDisabled):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		logRequestError(ctx, logger, message, err, userID)
+		writeError(w, http.StatusInternalServerError, message)
+	}
+}
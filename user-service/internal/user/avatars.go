@@ -0,0 +1,122 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/focusnest/shared-libs/imageupload"
+)
+
+// avatarURLExpiry is how long a signed avatar URL stays valid. Clients
+// refetch the profile well within it.
+const avatarURLExpiry = 24 * time.Hour
+
+// avatarPrefix returns where a user's avatars live in the image bucket.
+// Account export and deletion cover the same prefix.
+func avatarPrefix(userID string) string {
+	return "avatars/" + userID + "/"
+}
+
+// UploadAvatar stores a new avatar, removes the previous upload and returns
+// the avatar URL. The image must already have passed imageupload.ValidateFile.
+func (s *service) UploadAvatar(ctx context.Context, userID string, upload AvatarUpload) (string, error) {
+	if userID == "" {
+		return "", fmt.Errorf("missing user id")
+	}
+	if s.avatars == nil {
+		return "", ErrAvatarsDisabled
+	}
+	now := time.Now().UTC()
+	// A fresh name per upload keeps cached copies of the old avatar from
+	// showing after a change.
+	name := avatarPrefix(userID) + strconv.FormatInt(now.UnixNano(), 36) + imageupload.Extension(upload.Filename)
+	if err := s.avatars.Put(ctx, name, upload.ContentType, upload.Data); err != nil {
+		return "", fmt.Errorf("upload avatar: %w", err)
+	}
+	return s.replaceAvatar(ctx, userID, name, now)
+}
+
+// DeleteAvatar removes the uploaded avatar and returns the Clerk avatar URL
+// that shows again.
+func (s *service) DeleteAvatar(ctx context.Context, userID string) (string, error) {
+	if userID == "" {
+		return "", fmt.Errorf("missing user id")
+	}
+	return s.replaceAvatar(ctx, userID, "", time.Now().UTC())
+}
+
+func (s *service) replaceAvatar(ctx context.Context, userID, path string, at time.Time) (string, error) {
+	profile, previous, err := s.repo.SetAvatarPath(ctx, userID, path, at)
+	if err != nil {
+		return "", err
+	}
+	if previous != "" && previous != path && s.avatars != nil {
+		// An orphaned object is harmless and goes with account deletion.
+		_ = s.avatars.Delete(ctx, previous)
+	}
+	return s.avatarURL(ctx, profile), nil
+}
+
+// profileResponse builds the response for profile, with the uploaded
+// avatar, if any, in place of the Clerk one.
+func (s *service) profileResponse(ctx context.Context, profile *Profile, metadata ProfileMetadata) *ProfileResponse {
+	resp := buildProfileResponse(profile, metadata)
+	resp.AvatarURL = s.avatarURL(ctx, profile)
+	return resp
+}
+
+// avatarURL signs the uploaded avatar, falling back to the Clerk avatar when
+// there is none or signing fails.
+func (s *service) avatarURL(ctx context.Context, profile *Profile) string {
+	if profile.AvatarPath == "" || s.avatars == nil {
+		return profile.AvatarURL
+	}
+	url, err := s.avatars.SignedURL(ctx, profile.AvatarPath)
+	if err != nil {
+		return profile.AvatarURL
+	}
+	return url
+}
+
+type gcsAvatarStore struct {
+	bucket *storage.BucketHandle
+}
+
+// NewGCSAvatarStore stores avatars in bucket, next to focus-service's
+// session images.
+func NewGCSAvatarStore(client *storage.Client, bucket string) AvatarStore {
+	return &gcsAvatarStore{bucket: client.Bucket(bucket)}
+}
+
+func (s *gcsAvatarStore) Put(ctx context.Context, name, contentType string, data io.Reader) error {
+	writer := s.bucket.Object(name).NewWriter(ctx)
+	writer.ContentType = contentType
+	writer.CacheControl = "private, max-age=3600"
+	if _, err := io.Copy(writer, data); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return writer.Close()
+}
+
+func (s *gcsAvatarStore) Delete(ctx context.Context, name string) error {
+	err := s.bucket.Object(name).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *gcsAvatarStore) SignedURL(_ context.Context, name string) (string, error) {
+	return s.bucket.SignedURL(name, &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  "GET",
+		Expires: time.Now().Add(avatarURLExpiry),
+	})
+}
@@ -0,0 +1,141 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/focusnest/shared-libs/profilestats"
+)
+
+// badgeCatalog lists every badge. IDs are stored with awards and must stay
+// stable; point thresholds can be tweaked. Achievements are unlocked by the
+// profile counters (profilestats.Counters.Achievements) and awarded by
+// focus-service as sessions are written.
+var badgeCatalog = []Badge{
+	pointsBadge("bronze", "Bronze", 100),
+	pointsBadge("silver", "Silver", 250),
+	pointsBadge("gold", "Gold", 500),
+	pointsBadge("diamond", "Diamond", 1000),
+	achievement(profilestats.AchievementFirstDeepWork, "Deep Diver", "Selesaikan sesi Deep Work pertamamu"),
+	achievement(profilestats.AchievementPomodoro100, "Tomat Seratus", "Selesaikan 100 cycle Pomodoro"),
+	achievement(profilestats.AchievementStreak30, "Sebulan Penuh", "Raih streak 30 hari"),
+	achievement(profilestats.AchievementAllCategories, "Serba Bisa", "Coba fokus di setiap kategori"),
+	achievement(profilestats.AchievementNightOwl, "Burung Hantu", "Mulai sesi fokus antara jam 22.00 dan 04.00"),
+	achievement(profilestats.AchievementEarlyBird, "Burung Pagi", "Mulai sesi fokus antara jam 04.00 dan 07.00"),
+}
+
+func pointsBadge(id, label string, minPts int) Badge {
+	return Badge{ID: id, Label: label, Kind: BadgeKindPoints, MinPts: minPts}
+}
+
+func achievement(id, label, description string) Badge {
+	return Badge{ID: id, Label: label, Description: description, Kind: BadgeKindAchievement}
+}
+
+// unlockedBadges returns the catalog badges points and achievements unlock.
+func unlockedBadges(points int, achievements []string) map[string]bool {
+	unlocked := make(map[string]bool)
+	for _, b := range badgeCatalog {
+		if b.Kind == BadgeKindPoints && points >= b.MinPts {
+			unlocked[b.ID] = true
+		}
+	}
+	for _, id := range achievements {
+		unlocked[id] = true
+	}
+	return unlocked
+}
+
+// GetBadges lists the catalog with the user's stored awards. Awards are made
+// when points or sessions are written, never here.
+func (s *service) GetBadges(ctx context.Context, userID string) (*BadgesResponse, error) {
+	profile, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	awards, err := s.repo.ListBadgeAwards(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	held := awardsByBadge(awards)
+
+	resp := &BadgesResponse{Badges: make([]BadgeStatus, 0, len(badgeCatalog))}
+	for _, b := range badgeCatalog {
+		status := BadgeStatus{Badge: b}
+		if a, ok := held[b.ID]; ok {
+			awardedAt := a.AwardedAt
+			status.Unlocked = true
+			status.AwardedAt = &awardedAt
+			status.New = profile.BadgesSeenAt == nil || awardedAt.After(*profile.BadgesSeenAt)
+		}
+		if status.New {
+			resp.NewCount++
+		}
+		resp.Badges = append(resp.Badges, status)
+	}
+	return resp, nil
+}
+
+// awardBadges stores the unlocked badges that are not in held.
+func (s *service) awardBadges(ctx context.Context, userID string, held map[string]BadgeAward, unlocked map[string]bool, at time.Time) ([]BadgeAward, error) {
+	var fresh []BadgeAward
+	for _, b := range badgeCatalog {
+		if _, ok := held[b.ID]; ok || !unlocked[b.ID] {
+			continue
+		}
+		fresh = append(fresh, BadgeAward{BadgeID: b.ID, Source: b.Kind, AwardedAt: at})
+	}
+	if len(fresh) == 0 {
+		return nil, nil
+	}
+	if err := s.repo.AwardBadges(ctx, userID, fresh); err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// awardEarnedBadges records the badges points and achievements unlock but
+// the user does not hold yet.
+func (s *service) awardEarnedBadges(ctx context.Context, userID string, points int, achievements []string) error {
+	awards, err := s.repo.ListBadgeAwards(ctx, userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.awardBadges(ctx, userID, awardsByBadge(awards), unlockedBadges(points, achievements), time.Now().UTC())
+	return err
+}
+
+// awardPointBadges records point badges after the balance grows.
+func (s *service) awardPointBadges(ctx context.Context, userID string, points int) error {
+	return s.awardEarnedBadges(ctx, userID, points, nil)
+}
+
+// heldBadges returns the catalog badges of kind the user holds, in catalog
+// order.
+func (s *service) heldBadges(ctx context.Context, userID string, kind BadgeKind) ([]Badge, error) {
+	awards, err := s.repo.ListBadgeAwards(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	held := awardsByBadge(awards)
+	badges := []Badge{}
+	for _, b := range badgeCatalog {
+		if _, ok := held[b.ID]; ok && (kind == "" || b.Kind == kind) {
+			badges = append(badges, b)
+		}
+	}
+	return badges, nil
+}
+
+func awardsByBadge(awards []BadgeAward) map[string]BadgeAward {
+	held := make(map[string]BadgeAward, len(awards))
+	for _, a := range awards {
+		held[a.BadgeID] = a
+	}
+	return held
+}
+
+// MarkBadgesSeen clears the new flag on every badge awarded so far.
+func (s *service) MarkBadgesSeen(ctx context.Context, userID string) error {
+	return s.repo.MarkBadgesSeen(ctx, userID, time.Now().UTC())
+}
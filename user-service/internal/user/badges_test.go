@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/profilestats"
+)
+
+func TestGetBadgesListsStoredAwards(t *testing.T) {
+	seenAt := time.Now().UTC().Add(-time.Hour)
+	repo := &fakeRepo{
+		getProfileFn: func(_ context.Context, userID string) (*Profile, error) {
+			// Enough points for silver, which is not stored: reads never award.
+			return &Profile{UserID: userID, PointsTotal: 300, BadgesSeenAt: &seenAt}, nil
+		},
+		listBadgeAwardsFn: func(context.Context, string) ([]BadgeAward, error) {
+			return []BadgeAward{
+				{BadgeID: "bronze", Source: BadgeKindPoints, AwardedAt: seenAt.Add(-time.Hour)},
+				{BadgeID: "night_owl", Source: BadgeKindAchievement, AwardedAt: seenAt.Add(time.Minute)},
+			}, nil
+		},
+		awardBadgesFn: func(context.Context, string, []BadgeAward) error {
+			t.Error("GetBadges awarded badges")
+			return nil
+		},
+	}
+
+	resp, err := NewService(repo, nil).GetBadges(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("GetBadges() error = %v", err)
+	}
+	got := map[string]BadgeStatus{}
+	for _, b := range resp.Badges {
+		got[b.ID] = b
+	}
+	if len(resp.Badges) != len(badgeCatalog) {
+		t.Fatalf("len(badges) = %d, want the whole catalog", len(resp.Badges))
+	}
+	if b := got["bronze"]; !b.Unlocked || b.New {
+		t.Fatalf("bronze = %+v, want unlocked and already seen", b)
+	}
+	if b := got["night_owl"]; !b.Unlocked || !b.New {
+		t.Fatalf("night_owl = %+v, want unlocked and new", b)
+	}
+	if got["silver"].Unlocked {
+		t.Fatal("silver unlocked without an award")
+	}
+	if resp.NewCount != 1 {
+		t.Fatalf("NewCount = %d, want 1", resp.NewCount)
+	}
+}
+
+func TestReconcileAwardsEarnedBadgesOnce(t *testing.T) {
+	held := map[string]BadgeAward{
+		"bronze": {BadgeID: "bronze", Source: BadgeKindPoints},
+	}
+	var counted profilestats.Counters
+	counted.Replace(nil, &profilestats.Session{
+		StartTime: time.Date(2025, 11, 17, 9, 0, 0, 0, time.UTC), Timezone: "UTC", TimeMode: "Deep Work", Category: "Work",
+	})
+	repo := &fakeRepo{
+		getProfileFn: func(_ context.Context, userID string) (*Profile, error) {
+			return &Profile{UserID: userID, PointsTotal: 300, Timezone: "UTC"}, nil
+		},
+		rebuildProfileMetaFn: func(context.Context, string, *time.Location) (*profilestats.Counters, profilestats.Counters, error) {
+			return &counted, counted, nil
+		},
+		listBadgeAwardsFn: func(context.Context, string) ([]BadgeAward, error) {
+			var out []BadgeAward
+			for _, a := range held {
+				out = append(out, a)
+			}
+			return out, nil
+		},
+		awardBadgesFn: func(_ context.Context, _ string, awards []BadgeAward) error {
+			for _, a := range awards {
+				if _, ok := held[a.BadgeID]; ok {
+					t.Errorf("badge %s awarded twice", a.BadgeID)
+				}
+				held[a.BadgeID] = a
+			}
+			return nil
+		},
+	}
+	svc := NewService(repo, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.ReconcileProfileMetadata(context.Background(), "u1", ""); err != nil {
+			t.Fatalf("ReconcileProfileMetadata() error = %v", err)
+		}
+	}
+	if len(held) != 3 || held["silver"].Source != BadgeKindPoints || held["first_deep_work"].Source != BadgeKindAchievement {
+		t.Fatalf("held = %+v, want bronze plus silver from points and first_deep_work", held)
+	}
+}
+
+func TestGetChallengesMeListsHeldPointBadges(t *testing.T) {
+	repo := &fakeRepo{
+		getProfileFn: func(_ context.Context, userID string) (*Profile, error) {
+			// Points spent since silver was awarded; it stays.
+			return &Profile{UserID: userID, PointsTotal: 40}, nil
+		},
+		listChallengesFn: func(context.Context) ([]ChallengeDefinition, error) {
+			return nil, nil
+		},
+		listBadgeAwardsFn: func(context.Context, string) ([]BadgeAward, error) {
+			return []BadgeAward{
+				{BadgeID: "silver", Source: BadgeKindPoints},
+				{BadgeID: "bronze", Source: BadgeKindPoints},
+				{BadgeID: "early_bird", Source: BadgeKindAchievement},
+			}, nil
+		},
+	}
+	resp, err := NewService(repo, nil).GetChallengesMe(context.Background(), "u1", "UTC")
+	if err != nil {
+		t.Fatalf("GetChallengesMe() error = %v", err)
+	}
+	if len(resp.Badges) != 2 || resp.Badges[0].ID != "bronze" || resp.Badges[1].ID != "silver" {
+		t.Fatalf("badges = %+v, want bronze and silver in catalog order", resp.Badges)
+	}
+}
@@ -0,0 +1,179 @@
+package user
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxChallengeHistory caps the past claims returned with a challenge.
+const maxChallengeHistory = 10
+
+// onceKey is the period key of challenges that do not recur.
+const onceKey = "once"
+
+// recurrence resolves ChallengeRecurrenceDefault against the rule window.
+func (d ChallengeDefinition) recurrence(rule ChallengeRule) ChallengeRecurrence {
+	if d.Recurrence != ChallengeRecurrenceDefault {
+		return d.Recurrence
+	}
+	switch rule.Window {
+	case ChallengeWindowDay:
+		return ChallengeRecurrenceDaily
+	case ChallengeWindowWeek:
+		return ChallengeRecurrenceWeekly
+	case ChallengeWindowMonth:
+		return ChallengeRecurrenceMonthly
+	}
+	return ChallengeRecurrenceOnce
+}
+
+func (d ChallengeDefinition) validateSchedule() error {
+	switch d.Recurrence {
+	case ChallengeRecurrenceDefault, ChallengeRecurrenceOnce, ChallengeRecurrenceDaily,
+		ChallengeRecurrenceWeekly, ChallengeRecurrenceMonthly:
+	default:
+		return fmt.Errorf("unknown recurrence %q", d.Recurrence)
+	}
+	if d.StartsAt != nil && d.EndsAt != nil && !d.EndsAt.After(*d.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// periodAt returns the period containing t, clipped to StartsAt and EndsAt.
+// Calendar periods follow t's location. ok is false outside the run.
+func (d ChallengeDefinition) periodAt(rule ChallengeRule, t time.Time) (ChallengePeriod, bool) {
+	if (d.StartsAt != nil && t.Before(*d.StartsAt)) || (d.EndsAt != nil && !t.Before(*d.EndsAt)) {
+		return ChallengePeriod{}, false
+	}
+
+	day := truncateToDay(t)
+	var (
+		key        string
+		start, end time.Time
+	)
+	switch d.recurrence(rule) {
+	case ChallengeRecurrenceDaily:
+		start, end = day, day.AddDate(0, 0, 1)
+		key = start.Format(dayKeyLayout)
+	case ChallengeRecurrenceWeekly:
+		start = getWeekStart(day)
+		end = start.AddDate(0, 0, 7)
+		year, week := start.ISOWeek()
+		key = fmt.Sprintf("%d-W%02d", year, week)
+	case ChallengeRecurrenceMonthly:
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		end = start.AddDate(0, 1, 0)
+		key = start.Format("2006-01")
+	default:
+		key = onceKey
+	}
+
+	if d.StartsAt != nil && d.StartsAt.After(start) {
+		start = *d.StartsAt
+	}
+	if d.EndsAt != nil && (end.IsZero() || d.EndsAt.Before(end)) {
+		end = *d.EndsAt
+	}
+	period := ChallengePeriod{Key: key}
+	if !start.IsZero() {
+		period.StartsAt = &start
+	}
+	if !end.IsZero() {
+		period.EndsAt = &end
+	}
+	return period, true
+}
+
+// previousPeriod returns the recurring period before p, if the challenge
+// was running then.
+func (d ChallengeDefinition) previousPeriod(rule ChallengeRule, p ChallengePeriod) (ChallengePeriod, bool) {
+	if d.recurrence(rule) == ChallengeRecurrenceOnce || p.StartsAt == nil {
+		return ChallengePeriod{}, false
+	}
+	return d.periodAt(rule, p.StartsAt.Add(-time.Nanosecond))
+}
+
+// remaining returns the whole seconds left in p at now, 0 when open-ended.
+func (p ChallengePeriod) remaining(now time.Time) int {
+	if p.EndsAt == nil || !p.EndsAt.After(now) {
+		return 0
+	}
+	return int(p.EndsAt.Sub(now) / time.Second)
+}
+
+// claimHistory keys claims by period, newest first. Claims stored before
+// periods existed are assigned the period they were made in.
+func (d ChallengeDefinition) claimHistory(rule ChallengeRule, claims []ChallengeClaim, loc *time.Location) ([]ChallengeClaim, map[string]bool) {
+	history := make([]ChallengeClaim, 0, len(claims))
+	claimed := make(map[string]bool, len(claims))
+	for _, c := range claims {
+		if c.PeriodKey == "" {
+			period, ok := d.periodAt(rule, c.ClaimedAt.In(loc))
+			if !ok {
+				continue
+			}
+			c.PeriodKey, c.PeriodStart, c.PeriodEnd = period.Key, period.StartsAt, period.EndsAt
+		}
+		claimed[c.PeriodKey] = true
+		history = append(history, c)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].ClaimedAt.After(history[j].ClaimedAt) })
+	if len(history) > maxChallengeHistory {
+		history = history[:maxChallengeHistory]
+	}
+	return history, claimed
+}
+
+// challengeEval is a rule evaluated within one period as of a given day.
+type challengeEval struct {
+	rule   ChallengeRule
+	asOf   time.Time
+	period ChallengePeriod
+}
+
+// bounded reports whether the period limits which activity counts.
+func (e challengeEval) bounded() bool {
+	return e.period.StartsAt != nil || e.period.EndsAt != nil
+}
+
+// from returns the oldest activity the evaluation reads.
+func (e challengeEval) from() time.Time {
+	start, _ := e.rule.bounds(e.asOf)
+	if e.period.StartsAt != nil && e.period.StartsAt.After(start) {
+		start = *e.period.StartsAt
+	}
+	return start
+}
+
+func (e challengeEval) evaluate(act *challengeActivity) (ChallengeProgress, bool) {
+	if e.bounded() {
+		act = act.within(e.period.StartsAt, e.period.EndsAt)
+	}
+	return evaluateChallenge(e.rule, act, e.asOf)
+}
+
+// within returns the activity in [start, end); nil bounds are open.
+func (a *challengeActivity) within(start, end *time.Time) *challengeActivity {
+	in := func(t time.Time) bool {
+		return (start == nil || !t.Before(*start)) && (end == nil || t.Before(*end))
+	}
+	clipped := &challengeActivity{loc: a.loc, clipped: true}
+	for _, e := range a.entries {
+		if in(e.StartTime) {
+			clipped.entries = append(clipped.entries, e)
+		}
+	}
+	for _, t := range a.shares {
+		if in(t) {
+			clipped.shares = append(clipped.shares, t)
+		}
+	}
+	for _, m := range a.mindfulness {
+		if in(m.CompletedAt) {
+			clipped.mindfulness = append(clipped.mindfulness, m)
+		}
+	}
+	return clipped
+}
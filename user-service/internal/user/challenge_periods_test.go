@@ -0,0 +1,246 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChallengePeriodAt(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	// Wednesday 2025-11-19 01:00 in Jakarta is still Tuesday in UTC.
+	now := time.Date(2025, 11, 19, 1, 0, 0, 0, jakarta)
+	day := ChallengeRule{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{{Metric: ChallengeMetricSessions, Target: 1}}}
+	rolling := ChallengeRule{Window: ChallengeWindowRollingDays, Days: 7, Conditions: day.Conditions}
+
+	cases := []struct {
+		name      string
+		def       ChallengeDefinition
+		rule      ChallengeRule
+		key       string
+		start     time.Time
+		end       time.Time
+		remaining int
+	}{
+		{"daily from window", ChallengeDefinition{}, day, "2025-11-19",
+			time.Date(2025, 11, 19, 0, 0, 0, 0, jakarta), time.Date(2025, 11, 20, 0, 0, 0, 0, jakarta), 23 * 3600},
+		{"weekly", ChallengeDefinition{Recurrence: ChallengeRecurrenceWeekly}, rolling, "2025-W47",
+			time.Date(2025, 11, 17, 0, 0, 0, 0, jakarta), time.Date(2025, 11, 24, 0, 0, 0, 0, jakarta), (4*24 + 23) * 3600},
+		{"monthly", ChallengeDefinition{Recurrence: ChallengeRecurrenceMonthly}, day, "2025-11",
+			time.Date(2025, 11, 1, 0, 0, 0, 0, jakarta), time.Date(2025, 12, 1, 0, 0, 0, 0, jakarta), (11*24 + 23) * 3600},
+		{"once overrides window", ChallengeDefinition{Recurrence: ChallengeRecurrenceOnce}, day, "once", time.Time{}, time.Time{}, 0},
+		{"rolling defaults to once", ChallengeDefinition{}, rolling, "once", time.Time{}, time.Time{}, 0},
+		{"clipped to the run", ChallengeDefinition{
+			Recurrence: ChallengeRecurrenceWeekly,
+			StartsAt:   ptrTime(time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC)),
+			EndsAt:     ptrTime(time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC)),
+		}, day, "2025-W47",
+			time.Date(2025, 11, 18, 12, 0, 0, 0, time.UTC), time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC), (1*24 + 6) * 3600},
+	}
+	for _, tc := range cases {
+		period, ok := tc.def.periodAt(tc.rule, now)
+		if !ok {
+			t.Fatalf("%s: expected a running period", tc.name)
+		}
+		if period.Key != tc.key {
+			t.Errorf("%s: expected key %s, got %s", tc.name, tc.key, period.Key)
+		}
+		if got := derefTime(period.StartsAt); !got.Equal(tc.start) {
+			t.Errorf("%s: expected start %s, got %s", tc.name, tc.start, got)
+		}
+		if got := derefTime(period.EndsAt); !got.Equal(tc.end) {
+			t.Errorf("%s: expected end %s, got %s", tc.name, tc.end, got)
+		}
+		if got := period.remaining(now); got != tc.remaining {
+			t.Errorf("%s: expected %ds remaining, got %d", tc.name, tc.remaining, got)
+		}
+	}
+
+	upcoming := ChallengeDefinition{StartsAt: ptrTime(now.Add(time.Hour))}
+	if _, ok := upcoming.periodAt(day, now); ok {
+		t.Error("expected a challenge that has not started to have no period")
+	}
+	ended := ChallengeDefinition{EndsAt: ptrTime(now)}
+	if _, ok := ended.periodAt(day, now); ok {
+		t.Error("expected a challenge that ended to have no period")
+	}
+	bad := ChallengeDefinition{ID: "bad", Rule: &day, StartsAt: ptrTime(now), EndsAt: ptrTime(now)}
+	if _, err := bad.effectiveRule(); err == nil {
+		t.Error("expected ends_at before starts_at to be rejected")
+	}
+}
+
+func TestServiceGetChallengesMe_PreviousPeriodStaysClaimable(t *testing.T) {
+	now := time.Now().UTC()
+	weekStart := getWeekStart(now)
+	def := ChallengeDefinition{
+		ID:           "share_recap_3x_weekly",
+		RewardPoints: 50,
+		Rule: &ChallengeRule{
+			Window:          ChallengeWindowWeek,
+			IncludePrevious: true,
+			Conditions:      []ChallengeCondition{{Metric: ChallengeMetricShares, Target: 3}},
+		},
+	}
+	lastWeek, _ := def.periodAt(*def.Rule, weekStart.AddDate(0, 0, -1))
+	thisWeek, _ := def.periodAt(*def.Rule, now)
+
+	var claims []ChallengeClaim
+	var sharesFrom time.Time
+	repo := &fakeRepo{
+		getProfileFn: func(ctx context.Context, userID string) (*Profile, error) {
+			return &Profile{UserID: userID, PointsTotal: 10}, nil
+		},
+		listChallengesFn: func(ctx context.Context) ([]ChallengeDefinition, error) {
+			return []ChallengeDefinition{def}, nil
+		},
+		listShareTimesFn: func(ctx context.Context, userID string, start, end time.Time) ([]time.Time, error) {
+			sharesFrom = start
+			day := weekStart.AddDate(0, 0, -2)
+			return []time.Time{day, day, day, weekStart}, nil
+		},
+		listChallengeClaimsFn: func(ctx context.Context, userID, challengeID string) ([]ChallengeClaim, error) {
+			return claims, nil
+		},
+		claimChallengeFn: func(ctx context.Context, userID string, claim ChallengeClaim) (int, bool, error) {
+			claims = append(claims, claim)
+			return 60, false, nil
+		},
+	}
+	svc := NewService(repo, nil)
+
+	resp, err := svc.GetChallengesMe(context.Background(), "user-1", "UTC")
+	if err != nil {
+		t.Fatalf("GetChallengesMe failed: %v", err)
+	}
+	status := resp.Challenges[0]
+	if status.Period.Key != lastWeek.Key || !status.Completed || status.Claimed {
+		t.Fatalf("expected last week's completed period to be offered, got %s completed=%v claimed=%v",
+			status.Period.Key, status.Completed, status.Claimed)
+	}
+	if !sharesFrom.Equal(*lastWeek.StartsAt) {
+		t.Fatalf("expected shares read from %s, got %s", lastWeek.StartsAt, sharesFrom)
+	}
+
+	claim, err := svc.ClaimChallenge(context.Background(), "user-1", def.ID, "UTC")
+	if err != nil {
+		t.Fatalf("ClaimChallenge failed: %v", err)
+	}
+	if !claim.Claimed || claim.PeriodKey != lastWeek.Key || claim.PointsAwarded != 50 || claim.PointsTotal != 60 {
+		t.Fatalf("unexpected claim response %+v", claim)
+	}
+	if len(claims) != 1 || !claims[0].PeriodStart.Equal(*lastWeek.StartsAt) || claims[0].ChallengeID != def.ID {
+		t.Fatalf("expected the claim stored for last week, got %+v", claims)
+	}
+
+	resp, err = svc.GetChallengesMe(context.Background(), "user-1", "UTC")
+	if err != nil {
+		t.Fatalf("GetChallengesMe failed: %v", err)
+	}
+	status = resp.Challenges[0]
+	if status.Period.Key != thisWeek.Key || status.Completed || status.Claimed {
+		t.Fatalf("expected this week's fresh period after claiming, got %+v", status)
+	}
+	if status.Progress.CurrentCount != 1 || status.Period.RemainingSeconds <= 0 {
+		t.Fatalf("expected this week's progress and time left, got %+v / %+v", status.Progress, status.Period)
+	}
+	if len(status.History) != 1 || status.History[0].PeriodKey != lastWeek.Key {
+		t.Fatalf("expected last week's claim in history, got %+v", status.History)
+	}
+
+	again, err := svc.ClaimChallenge(context.Background(), "user-1", def.ID, "UTC")
+	if err != nil {
+		t.Fatalf("ClaimChallenge failed: %v", err)
+	}
+	if again.Claimed || again.AlreadyClaimed || again.PeriodKey != thisWeek.Key {
+		t.Fatalf("expected this week to be not yet eligible, got %+v", again)
+	}
+}
+
+func TestServiceGetChallengesMe_LegacyClaimCountsForItsPeriod(t *testing.T) {
+	now := time.Now().UTC()
+	def := ChallengeDefinition{ID: "share_recap_3x_weekly", RuleType: ChallengeRuleWeeklyShares, TargetCount: 1, RewardPoints: 50}
+	repo := &fakeRepo{
+		getProfileFn: func(ctx context.Context, userID string) (*Profile, error) {
+			return &Profile{UserID: userID}, nil
+		},
+		listChallengesFn: func(ctx context.Context) ([]ChallengeDefinition, error) {
+			return []ChallengeDefinition{def}, nil
+		},
+		listShareTimesFn: func(ctx context.Context, userID string, start, end time.Time) ([]time.Time, error) {
+			return []time.Time{now}, nil
+		},
+		listChallengeClaimsFn: func(ctx context.Context, userID, challengeID string) ([]ChallengeClaim, error) {
+			// Claimed once, ever, under the old single-document scheme.
+			return []ChallengeClaim{{ChallengeID: challengeID, PointsAwarded: 50, ClaimedAt: getWeekStart(now).AddDate(0, 0, -10)}}, nil
+		},
+	}
+
+	resp, err := NewService(repo, nil).GetChallengesMe(context.Background(), "user-1", "UTC")
+	if err != nil {
+		t.Fatalf("GetChallengesMe failed: %v", err)
+	}
+	status := resp.Challenges[0]
+	if !status.Completed || status.Claimed {
+		t.Fatalf("expected an old claim not to block this week, got completed=%v claimed=%v", status.Completed, status.Claimed)
+	}
+	if len(status.History) != 1 || status.History[0].PeriodKey == "" || status.History[0].PeriodStart == nil {
+		t.Fatalf("expected the old claim mapped to its week, got %+v", status.History)
+	}
+}
+
+func TestServiceChallenges_TimeBoxed(t *testing.T) {
+	now := time.Now().UTC()
+	rule := &ChallengeRule{Window: ChallengeWindowAllTime, Conditions: []ChallengeCondition{{Metric: ChallengeMetricSessions, Target: 2}}}
+	repo := &fakeRepo{
+		getProfileFn: func(ctx context.Context, userID string) (*Profile, error) {
+			return &Profile{UserID: userID, PointsTotal: 5}, nil
+		},
+		listChallengesFn: func(ctx context.Context) ([]ChallengeDefinition, error) {
+			return []ChallengeDefinition{
+				{ID: "upcoming", RewardPoints: 10, Rule: rule, StartsAt: ptrTime(now.Add(time.Hour))},
+				{ID: "ended", RewardPoints: 10, Rule: rule, EndsAt: ptrTime(now.Add(-time.Hour))},
+				{ID: "sprint", RewardPoints: 10, Rule: rule, StartsAt: ptrTime(now.Add(-2 * time.Hour)), EndsAt: ptrTime(now.Add(time.Hour))},
+			}, nil
+		},
+		listProductivityEntriesFn: func(ctx context.Context, userID string, start, end time.Time) ([]ProductivityEntry, error) {
+			return []ProductivityEntry{
+				{StartTime: now.Add(-3 * time.Hour)}, // before the sprint
+				{StartTime: now.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	svc := NewService(repo, nil)
+
+	resp, err := svc.GetChallengesMe(context.Background(), "user-1", "UTC")
+	if err != nil {
+		t.Fatalf("GetChallengesMe failed: %v", err)
+	}
+	if len(resp.Challenges) != 1 || resp.Challenges[0].Challenge.ID != "sprint" {
+		t.Fatalf("expected only the running challenge, got %+v", resp.Challenges)
+	}
+	sprint := resp.Challenges[0]
+	if sprint.Completed || sprint.Progress.CurrentCount != 1 {
+		t.Fatalf("expected sessions before starts_at not to count, got %+v", sprint.Progress)
+	}
+	if r := sprint.Period.RemainingSeconds; r <= 0 || r > 3600 {
+		t.Fatalf("expected under an hour remaining, got %d", r)
+	}
+
+	claim, err := svc.ClaimChallenge(context.Background(), "user-1", "ended", "UTC")
+	if err != nil {
+		t.Fatalf("ClaimChallenge failed: %v", err)
+	}
+	if claim.Claimed || claim.PointsTotal != 5 {
+		t.Fatalf("expected an ended challenge not to be claimable, got %+v", claim)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
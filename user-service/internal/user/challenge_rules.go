@@ -0,0 +1,471 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// challengeLookbackDays is how far back consecutive_days rules look for a run.
+const challengeLookbackDays = 45
+
+const dayKeyLayout = "2006-01-02"
+
+// effectiveRule returns the rule the definition is evaluated with, translating
+// legacy RuleType definitions, and validates it.
+func (d ChallengeDefinition) effectiveRule() (ChallengeRule, error) {
+	var rule ChallengeRule
+	switch {
+	case d.Rule != nil:
+		rule = *d.Rule
+	case d.RuleType == ChallengeRuleDailyMinutesStreak:
+		rule = ChallengeRule{
+			Window:     ChallengeWindowConsecutiveDays,
+			Days:       d.ConsecutiveDays,
+			Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, Target: d.MinMinutesPerDay}},
+		}
+	case d.RuleType == ChallengeRuleWeeklyShares:
+		rule = ChallengeRule{
+			Window:          ChallengeWindowWeek,
+			IncludePrevious: true,
+			Conditions:      []ChallengeCondition{{Metric: ChallengeMetricShares, Target: d.TargetCount}},
+		}
+	case d.RuleType == ChallengeRuleStreakMilestone:
+		rule = ChallengeRule{
+			Window:     ChallengeWindowAllTime,
+			Conditions: []ChallengeCondition{{Metric: ChallengeMetricStreak, Target: d.TargetStreak}},
+		}
+	case d.RuleType == ChallengeRuleCyclesAndMindfulness:
+		rule = ChallengeRule{
+			Window:          ChallengeWindowDay,
+			IncludePrevious: true,
+			Conditions: []ChallengeCondition{
+				{Metric: ChallengeMetricCycles, Target: d.TargetCycles},
+				{Metric: ChallengeMetricMindfulnessMinutes, Target: d.TargetMindfulnessMinutes},
+			},
+		}
+	default:
+		return ChallengeRule{}, fmt.Errorf("%w: challenge %q has no rule", ErrInvalidChallengeRule, d.ID)
+	}
+	if err := rule.validate(); err != nil {
+		return ChallengeRule{}, fmt.Errorf("%w: challenge %q: %v", ErrInvalidChallengeRule, d.ID, err)
+	}
+	if err := d.validateSchedule(); err != nil {
+		return ChallengeRule{}, fmt.Errorf("%w: challenge %q: %v", ErrInvalidChallengeRule, d.ID, err)
+	}
+	return rule, nil
+}
+
+func (r ChallengeRule) validate() error {
+	switch r.Window {
+	case ChallengeWindowDay, ChallengeWindowWeek, ChallengeWindowMonth, ChallengeWindowAllTime:
+	case ChallengeWindowRollingDays:
+		if r.Days < 1 {
+			return fmt.Errorf("rolling_days window needs days >= 1")
+		}
+	case ChallengeWindowConsecutiveDays:
+		if r.Days < 1 || r.Days > challengeLookbackDays {
+			return fmt.Errorf("consecutive_days window needs days between 1 and %d", challengeLookbackDays)
+		}
+	default:
+		return fmt.Errorf("unknown window %q", r.Window)
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("no conditions")
+	}
+	for _, c := range r.Conditions {
+		switch c.Metric {
+		case ChallengeMetricMinutes, ChallengeMetricSessions, ChallengeMetricCycles:
+		case ChallengeMetricStreak:
+			if r.Window == ChallengeWindowConsecutiveDays {
+				return fmt.Errorf("streak cannot be used with a consecutive_days window")
+			}
+		case ChallengeMetricShares, ChallengeMetricMindfulnessMinutes, ChallengeMetricMindfulnessSessions:
+			if c.Category != "" || c.TimeMode != "" {
+				return fmt.Errorf("%s does not support category or time_mode filters", c.Metric)
+			}
+		default:
+			return fmt.Errorf("unknown metric %q", c.Metric)
+		}
+		if c.ExerciseID != "" {
+			if !c.Metric.mindfulness() {
+				return fmt.Errorf("%s does not support an exercise_id filter", c.Metric)
+			}
+			if _, ok := findExercise(c.ExerciseID); !ok {
+				return fmt.Errorf("unknown exercise %q", c.ExerciseID)
+			}
+		}
+		switch c.Comparator {
+		case "", ChallengeComparatorGTE, ChallengeComparatorLTE, ChallengeComparatorEQ:
+		default:
+			return fmt.Errorf("unknown comparator %q", c.Comparator)
+		}
+		if c.Target < 0 {
+			return fmt.Errorf("negative target for %s", c.Metric)
+		}
+	}
+	return nil
+}
+
+// bounds returns the window [start, end) that ends with the day asOf.
+func (r ChallengeRule) bounds(asOf time.Time) (time.Time, time.Time) {
+	tomorrow := asOf.AddDate(0, 0, 1)
+	switch r.Window {
+	case ChallengeWindowDay:
+		return asOf, tomorrow
+	case ChallengeWindowWeek:
+		start := getWeekStart(asOf)
+		return start, start.AddDate(0, 0, 7)
+	case ChallengeWindowMonth:
+		start := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, asOf.Location())
+		return start, start.AddDate(0, 1, 0)
+	case ChallengeWindowRollingDays:
+		return asOf.AddDate(0, 0, -(r.Days - 1)), tomorrow
+	case ChallengeWindowConsecutiveDays:
+		return asOf.AddDate(0, 0, -challengeLookbackDays), tomorrow
+	default: // all_time
+		return time.Time{}, tomorrow
+	}
+}
+
+// mindfulness reports whether the metric counts mindfulness sessions.
+func (m ChallengeMetric) mindfulness() bool {
+	return m == ChallengeMetricMindfulnessMinutes || m == ChallengeMetricMindfulnessSessions
+}
+
+// usesProfileStreak reports whether the condition reads the longest streak
+// from profile metadata instead of scanning every session. That only holds
+// while the challenge period does not clip activity.
+func usesProfileStreak(r ChallengeRule, c ChallengeCondition) bool {
+	return c.Metric == ChallengeMetricStreak && r.Window == ChallengeWindowAllTime &&
+		c.Category == "" && c.TimeMode == ""
+}
+
+// challengeActivity holds everything the evaluator reads for one user.
+type challengeActivity struct {
+	loc           *time.Location
+	clipped       bool // limited to a challenge period; longestStreak is unset
+	entries       []ProductivityEntry
+	shares        []time.Time
+	mindfulness   []MindfulnessSession
+	longestStreak int
+}
+
+// fetchSpan tracks the oldest start time a data source is needed from.
+type fetchSpan struct {
+	needed bool
+	from   time.Time
+}
+
+func (s *fetchSpan) include(start time.Time) {
+	if !s.needed || start.Before(s.from) {
+		s.from = start
+	}
+	s.needed = true
+}
+
+// loadChallengeActivity fetches, once, the data every evaluation reads.
+func (s *service) loadChallengeActivity(ctx context.Context, userID string, evals []challengeEval, today time.Time) (*challengeActivity, error) {
+	loc := today.Location()
+	act := &challengeActivity{loc: loc}
+
+	var entries, shares, mindfulness fetchSpan
+	needStreak := false
+	for _, e := range evals {
+		start := e.from()
+		for _, c := range e.rule.Conditions {
+			switch {
+			case usesProfileStreak(e.rule, c) && !e.bounded():
+				needStreak = true
+			case c.Metric == ChallengeMetricShares:
+				shares.include(start)
+			case c.Metric.mindfulness():
+				mindfulness.include(start)
+			default:
+				entries.include(start)
+			}
+		}
+	}
+
+	end := today.AddDate(0, 0, 1)
+	g, gctx := errgroup.WithContext(ctx)
+	if entries.needed {
+		g.Go(func() error {
+			list, err := s.repo.ListProductivityEntries(gctx, userID, entries.from, end)
+			act.entries = list
+			return err
+		})
+	}
+	if shares.needed {
+		g.Go(func() error {
+			list, err := s.repo.ListShareTimes(gctx, userID, shares.from, end)
+			act.shares = list
+			return err
+		})
+	}
+	if mindfulness.needed {
+		g.Go(func() error {
+			list, err := s.repo.ListMindfulnessSessions(gctx, userID, mindfulness.from, end)
+			act.mindfulness = list
+			return err
+		})
+	}
+	if needStreak {
+		g.Go(func() error {
+			stats, err := s.repo.GetProfileStats(gctx, userID, loc)
+			act.longestStreak = stats.LongestStreak()
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return act, nil
+}
+
+// daily returns the condition's metric per local day. Streaks are built
+// from session counts.
+func (a *challengeActivity) daily(c ChallengeCondition) map[string]int {
+	byDay := make(map[string]int)
+	switch c.Metric {
+	case ChallengeMetricShares:
+		for _, t := range a.shares {
+			byDay[t.In(a.loc).Format(dayKeyLayout)]++
+		}
+	case ChallengeMetricMindfulnessMinutes, ChallengeMetricMindfulnessSessions:
+		for _, m := range a.mindfulness {
+			if c.ExerciseID != "" && m.ExerciseID != c.ExerciseID {
+				continue
+			}
+			key := m.CompletedAt.In(a.loc).Format(dayKeyLayout)
+			if c.Metric == ChallengeMetricMindfulnessSessions {
+				byDay[key]++
+			} else {
+				byDay[key] += m.Minutes
+			}
+		}
+	default:
+		for _, e := range a.entries {
+			if e.StartTime.IsZero() {
+				continue
+			}
+			if c.Category != "" && e.Category != c.Category {
+				continue
+			}
+			if c.TimeMode != "" && e.TimeMode != c.TimeMode {
+				continue
+			}
+			key := localDay(e.StartTime, e.Timezone, a.loc).Format(dayKeyLayout)
+			switch c.Metric {
+			case ChallengeMetricMinutes:
+				mins := e.TimeElapsed / 60
+				if mins <= 0 && e.TimeElapsed > 0 {
+					mins = 1
+				}
+				byDay[key] += mins
+			case ChallengeMetricCycles:
+				byDay[key] += e.NumCycle
+			default: // sessions, streak
+				byDay[key]++
+			}
+		}
+	}
+	return byDay
+}
+
+// value aggregates the condition over the days in [start, end).
+func (a *challengeActivity) value(rule ChallengeRule, c ChallengeCondition, start, end time.Time) int {
+	if usesProfileStreak(rule, c) && !a.clipped {
+		return a.longestStreak
+	}
+	from, to := start.Format(dayKeyLayout), end.Format(dayKeyLayout)
+	if start.IsZero() {
+		from = ""
+	}
+	var active []string
+	total := 0
+	for key, v := range a.daily(c) {
+		if key < from || key >= to {
+			continue
+		}
+		total += v
+		if v > 0 {
+			active = append(active, key)
+		}
+	}
+	if c.Metric != ChallengeMetricStreak {
+		return total
+	}
+	return longestRun(active, a.loc)
+}
+
+// longestRun returns the longest run of consecutive days among keys.
+func longestRun(keys []string, loc *time.Location) int {
+	sort.Strings(keys)
+	longest, run := 0, 0
+	var prev time.Time
+	for i, key := range keys {
+		day, err := time.ParseInLocation(dayKeyLayout, key, loc)
+		if err != nil {
+			continue
+		}
+		if i > 0 && prev.AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		prev = day
+	}
+	return longest
+}
+
+func (c ChallengeCondition) met(value int) bool {
+	switch c.Comparator {
+	case ChallengeComparatorLTE:
+		return value <= c.Target
+	case ChallengeComparatorEQ:
+		return value == c.Target
+	default:
+		return value >= c.Target
+	}
+}
+
+// percent maps a value to 0-100 for the UI: how close value is to target
+// from the side the comparator approaches it.
+func (c ChallengeCondition) percent(value int) int {
+	if c.met(value) {
+		return 100
+	}
+	var p int
+	switch {
+	case value > c.Target: // lte or eq, overshot
+		p = c.Target * 100 / value
+	case c.Target > 0:
+		p = value * 100 / c.Target
+	}
+	if p > 100 {
+		p = 100
+	}
+	return p
+}
+
+// evaluateChallenge computes progress and completion for a rule as of the
+// day asOf.
+func evaluateChallenge(rule ChallengeRule, act *challengeActivity, asOf time.Time) (ChallengeProgress, bool) {
+	if rule.Window == ChallengeWindowConsecutiveDays {
+		return evaluateConsecutiveDays(rule, act, asOf)
+	}
+	start, end := rule.bounds(asOf)
+	var progress ChallengeProgress
+	completed := true
+	sum := 0
+	for _, c := range rule.Conditions {
+		v := act.value(rule, c, start, end)
+		met := c.met(v)
+		completed = completed && met
+		sum += c.percent(v)
+		progress.Conditions = append(progress.Conditions, ConditionProgress{
+			Metric: c.Metric, Current: v, Target: c.Target, Met: met,
+		})
+	}
+	progress.ProgressPercent = sum / len(rule.Conditions)
+	fillLegacyProgress(&progress)
+	return progress, completed
+}
+
+// evaluateConsecutiveDays looks for Days consecutive days on which every
+// condition holds. A finished run keeps the challenge complete; otherwise
+// the active run is shown, which a not-yet-met today does not break.
+func evaluateConsecutiveDays(rule ChallengeRule, act *challengeActivity, asOf time.Time) (ChallengeProgress, bool) {
+	series := make([]map[string]int, len(rule.Conditions))
+	for i, c := range rule.Conditions {
+		series[i] = act.daily(c)
+	}
+	dayMet := func(key string) bool {
+		for i, c := range rule.Conditions {
+			if !c.met(series[i][key]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	maxRun, run := 0, 0
+	for i := challengeLookbackDays; i >= 0; i-- {
+		if dayMet(asOf.AddDate(0, 0, -i).Format(dayKeyLayout)) {
+			run++
+			if run > maxRun {
+				maxRun = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	active := 0
+	for i := 0; i <= challengeLookbackDays; i++ {
+		if dayMet(asOf.AddDate(0, 0, -i).Format(dayKeyLayout)) {
+			active++
+		} else if i > 0 {
+			break
+		}
+	}
+
+	completed := maxRun >= rule.Days
+	displayed := active
+	if completed {
+		displayed = maxRun
+	}
+	percent := displayed * 100 / rule.Days
+	if percent > 100 {
+		percent = 100
+	}
+
+	progress := ChallengeProgress{
+		CurrentStreakDays: displayed,
+		TargetStreakDays:  rule.Days,
+		ProgressPercent:   percent,
+	}
+	todayKey := asOf.Format(dayKeyLayout)
+	for i, c := range rule.Conditions {
+		v := series[i][todayKey]
+		progress.Conditions = append(progress.Conditions, ConditionProgress{
+			Metric: c.Metric, Current: v, Target: c.Target, Met: c.met(v),
+		})
+		if c.Metric == ChallengeMetricMinutes && progress.MinMinutesPerDay == 0 {
+			progress.MinMinutesPerDay = c.Target
+			progress.MinutesToday = v
+		}
+	}
+	return progress, completed
+}
+
+// fillLegacyProgress mirrors condition progress into the per-metric fields
+// older clients read. The first condition of each metric wins.
+func fillLegacyProgress(p *ChallengeProgress) {
+	seen := make(map[ChallengeMetric]bool)
+	for _, c := range p.Conditions {
+		if seen[c.Metric] {
+			continue
+		}
+		seen[c.Metric] = true
+		switch c.Metric {
+		case ChallengeMetricShares, ChallengeMetricSessions, ChallengeMetricMindfulnessSessions:
+			if p.TargetCount == 0 {
+				p.CurrentCount, p.TargetCount = c.Current, c.Target
+			}
+		case ChallengeMetricStreak:
+			p.CurrentStreak, p.TargetStreak = c.Current, c.Target
+		case ChallengeMetricCycles:
+			p.CurrentCycles, p.TargetCycles = c.Current, c.Target
+		case ChallengeMetricMindfulnessMinutes:
+			p.CurrentMindfulnessMinutes, p.TargetMindfulnessMinutes = c.Current, c.Target
+		}
+	}
+}
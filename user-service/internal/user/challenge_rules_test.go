@@ -0,0 +1,248 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// wednesday is a fixed "today" so week and month windows are predictable.
+var wednesday = time.Date(2025, 11, 19, 0, 0, 0, 0, time.UTC)
+
+func TestEvaluateChallenge_CategoryAndTimeModeFilters(t *testing.T) {
+	act := &challengeActivity{loc: time.UTC, entries: []ProductivityEntry{
+		{StartTime: wednesday.Add(9 * time.Hour), TimeElapsed: 40 * 60, Category: "Work", TimeMode: "Pomodoro"},
+		{StartTime: wednesday.Add(11 * time.Hour), TimeElapsed: 30 * 60, Category: "Work", TimeMode: "Stopwatch"},
+		{StartTime: wednesday.Add(13 * time.Hour), TimeElapsed: 90 * 60, Category: "Study", TimeMode: "Pomodoro"},
+		// Monday of the same week
+		{StartTime: wednesday.AddDate(0, 0, -2).Add(9 * time.Hour), TimeElapsed: 50 * 60, Category: "Work", TimeMode: "Pomodoro"},
+	}}
+	rule := ChallengeRule{
+		Window: ChallengeWindowWeek,
+		Conditions: []ChallengeCondition{
+			{Metric: ChallengeMetricMinutes, Category: "Work", TimeMode: "Pomodoro", Target: 100},
+			{Metric: ChallengeMetricSessions, Category: "Work", Target: 3},
+		},
+	}
+	if err := rule.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	progress, completed := evaluateChallenge(rule, act, wednesday)
+	if completed {
+		t.Fatal("expected 90 filtered minutes to miss the 100 minute target")
+	}
+	if got := progress.Conditions[0].Current; got != 90 {
+		t.Fatalf("expected 90 Work/Pomodoro minutes, got %d", got)
+	}
+	if got := progress.Conditions[1]; got.Current != 3 || !got.Met {
+		t.Fatalf("expected 3 Work sessions to meet the target, got %+v", got)
+	}
+	if progress.ProgressPercent != (90+100)/2 {
+		t.Fatalf("expected averaged percent 95, got %d", progress.ProgressPercent)
+	}
+	if progress.CurrentCount != 3 || progress.TargetCount != 3 {
+		t.Fatalf("expected session count mirrored into legacy fields, got %d/%d", progress.CurrentCount, progress.TargetCount)
+	}
+}
+
+func TestEvaluateChallenge_Windows(t *testing.T) {
+	act := &challengeActivity{loc: time.UTC, entries: []ProductivityEntry{
+		{StartTime: wednesday.Add(time.Hour), NumCycle: 2},
+		{StartTime: wednesday.AddDate(0, 0, -3), NumCycle: 3},  // previous Sunday
+		{StartTime: wednesday.AddDate(0, 0, -20), NumCycle: 5}, // October 30
+	}}
+	cycles := []ChallengeCondition{{Metric: ChallengeMetricCycles, Target: 1}}
+
+	cases := []struct {
+		window ChallengeWindow
+		days   int
+		want   int
+	}{
+		{ChallengeWindowDay, 0, 2},
+		{ChallengeWindowWeek, 0, 2},
+		{ChallengeWindowMonth, 0, 5},
+		{ChallengeWindowRollingDays, 4, 5},
+		{ChallengeWindowRollingDays, 3, 2},
+		{ChallengeWindowAllTime, 0, 10},
+	}
+	for _, tc := range cases {
+		rule := ChallengeRule{Window: tc.window, Days: tc.days, Conditions: cycles}
+		progress, _ := evaluateChallenge(rule, act, wednesday)
+		if progress.CurrentCycles != tc.want {
+			t.Errorf("%s/%d: expected %d cycles, got %d", tc.window, tc.days, tc.want, progress.CurrentCycles)
+		}
+	}
+}
+
+func TestEvaluateChallenge_Comparators(t *testing.T) {
+	act := &challengeActivity{loc: time.UTC, mindfulness: []MindfulnessSession{
+		{Minutes: 5, CompletedAt: wednesday.Add(time.Hour)},
+	}}
+	cases := []struct {
+		comparator ChallengeComparator
+		target     int
+		met        bool
+		percent    int
+	}{
+		{ChallengeComparatorGTE, 10, false, 50},
+		{ChallengeComparatorLTE, 10, true, 100},
+		{ChallengeComparatorLTE, 4, false, 80},
+		{ChallengeComparatorEQ, 5, true, 100},
+		{ChallengeComparatorEQ, 10, false, 50},
+	}
+	for _, tc := range cases {
+		rule := ChallengeRule{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{
+			{Metric: ChallengeMetricMindfulnessMinutes, Comparator: tc.comparator, Target: tc.target},
+		}}
+		progress, completed := evaluateChallenge(rule, act, wednesday)
+		if completed != tc.met || progress.ProgressPercent != tc.percent {
+			t.Errorf("%s %d: expected met=%v percent=%d, got met=%v percent=%d",
+				tc.comparator, tc.target, tc.met, tc.percent, completed, progress.ProgressPercent)
+		}
+	}
+}
+
+func TestEvaluateChallenge_StreakWithinWindow(t *testing.T) {
+	var entries []ProductivityEntry
+	// Active Nov 1-4 and Nov 16-19 (today); only Study sessions on Nov 17.
+	for _, day := range []int{1, 2, 3, 4, 16, 18, 19} {
+		entries = append(entries, ProductivityEntry{
+			StartTime: time.Date(2025, 11, day, 8, 0, 0, 0, time.UTC), Category: "Work",
+		})
+	}
+	entries = append(entries, ProductivityEntry{StartTime: time.Date(2025, 11, 17, 8, 0, 0, 0, time.UTC), Category: "Study"})
+	act := &challengeActivity{loc: time.UTC, entries: entries, longestStreak: 30}
+
+	month := ChallengeRule{Window: ChallengeWindowMonth, Conditions: []ChallengeCondition{{Metric: ChallengeMetricStreak, Target: 4}}}
+	if progress, completed := evaluateChallenge(month, act, wednesday); !completed || progress.CurrentStreak != 4 {
+		t.Fatalf("expected a 4 day streak this month, got %d", progress.CurrentStreak)
+	}
+	month.Conditions[0].Category = "Work"
+	month.Conditions[0].Target = 3
+	progress, _ := evaluateChallenge(month, act, wednesday)
+	if progress.CurrentStreak != 4 {
+		t.Fatalf("expected the Nov 1-4 Work streak to be longest, got %d", progress.CurrentStreak)
+	}
+
+	allTime := ChallengeRule{Window: ChallengeWindowAllTime, Conditions: []ChallengeCondition{{Metric: ChallengeMetricStreak, Target: 10}}}
+	if progress, completed := evaluateChallenge(allTime, act, wednesday); !completed || progress.CurrentStreak != 30 {
+		t.Fatalf("expected the profile's longest streak for all_time, got %d", progress.CurrentStreak)
+	}
+}
+
+func TestEvaluateChallenge_ConsecutiveDaysNeedsEveryCondition(t *testing.T) {
+	act := &challengeActivity{loc: time.UTC}
+	for i := 0; i < 3; i++ {
+		day := wednesday.AddDate(0, 0, -i).Add(10 * time.Hour)
+		act.entries = append(act.entries, ProductivityEntry{StartTime: day, TimeElapsed: 30 * 60})
+		if i != 1 { // no mindfulness yesterday
+			act.mindfulness = append(act.mindfulness, MindfulnessSession{Minutes: 3, CompletedAt: day})
+		}
+	}
+	rule := ChallengeRule{
+		Window: ChallengeWindowConsecutiveDays,
+		Days:   2,
+		Conditions: []ChallengeCondition{
+			{Metric: ChallengeMetricMinutes, Target: 25},
+			{Metric: ChallengeMetricMindfulnessMinutes, Target: 2},
+		},
+	}
+	progress, completed := evaluateChallenge(rule, act, wednesday)
+	if completed {
+		t.Fatal("expected the missed mindfulness day to break the run")
+	}
+	if progress.CurrentStreakDays != 1 || progress.TargetStreakDays != 2 || progress.ProgressPercent != 50 {
+		t.Fatalf("unexpected progress %+v", progress)
+	}
+	if progress.MinutesToday != 30 || progress.MinMinutesPerDay != 25 {
+		t.Fatalf("expected today's minutes in legacy fields, got %d/%d", progress.MinutesToday, progress.MinMinutesPerDay)
+	}
+}
+
+func TestChallengeRuleValidate(t *testing.T) {
+	bad := []ChallengeRule{
+		{Window: "fortnight", Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, Target: 1}}},
+		{Window: ChallengeWindowDay},
+		{Window: ChallengeWindowRollingDays, Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, Target: 1}}},
+		{Window: ChallengeWindowConsecutiveDays, Days: challengeLookbackDays + 1, Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, Target: 1}}},
+		{Window: ChallengeWindowConsecutiveDays, Days: 3, Conditions: []ChallengeCondition{{Metric: ChallengeMetricStreak, Target: 1}}},
+		{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{{Metric: "steps", Target: 1}}},
+		{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{{Metric: ChallengeMetricShares, Category: "Work", Target: 1}}},
+		{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, Comparator: "gt", Target: 1}}},
+		{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, Target: -1}}},
+		{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, ExerciseID: "body_scan", Target: 1}}},
+		{Window: ChallengeWindowDay, Conditions: []ChallengeCondition{{Metric: ChallengeMetricMindfulnessSessions, ExerciseID: "yoga", Target: 1}}},
+	}
+	for i, rule := range bad {
+		if err := rule.validate(); err == nil {
+			t.Errorf("case %d: expected %+v to be rejected", i, rule)
+		}
+	}
+	for _, def := range challengeDefinitions() {
+		if _, err := def.effectiveRule(); err != nil {
+			t.Errorf("seed challenge %s: %v", def.ID, err)
+		}
+	}
+}
+
+func TestServiceGetChallengesMe_DataDrivenDefinitions(t *testing.T) {
+	now := time.Now().UTC()
+	var entriesFrom time.Time
+	repo := &fakeRepo{
+		getProfileFn: func(ctx context.Context, userID string) (*Profile, error) {
+			return &Profile{UserID: userID}, nil
+		},
+		listChallengesFn: func(ctx context.Context) ([]ChallengeDefinition, error) {
+			return []ChallengeDefinition{
+				{ID: "broken", Rule: &ChallengeRule{Window: "fortnight"}},
+				{ID: "legacy_unknown", RuleType: "mystery"},
+				{
+					ID:           "study_sessions_7d",
+					RewardPoints: 30,
+					Rule: &ChallengeRule{
+						Window:     ChallengeWindowRollingDays,
+						Days:       7,
+						Conditions: []ChallengeCondition{{Metric: ChallengeMetricSessions, Category: "Study", Target: 2}},
+					},
+				},
+			}, nil
+		},
+		listProductivityEntriesFn: func(ctx context.Context, userID string, start, end time.Time) ([]ProductivityEntry, error) {
+			entriesFrom = start
+			return []ProductivityEntry{
+				{StartTime: now, Category: "Study"},
+				{StartTime: now, Category: "Work"},
+				{StartTime: now.AddDate(0, 0, -2), Category: "Study"},
+			}, nil
+		},
+		listChallengeClaimsFn: func(ctx context.Context, userID, challengeID string) ([]ChallengeClaim, error) {
+			// A claim stored before periods existed.
+			return []ChallengeClaim{{ChallengeID: challengeID, PointsAwarded: 30, ClaimedAt: now.AddDate(0, 0, -30)}}, nil
+		},
+	}
+
+	resp, err := NewService(repo, nil).GetChallengesMe(context.Background(), "user-1", "UTC")
+	if err != nil {
+		t.Fatalf("GetChallengesMe failed: %v", err)
+	}
+	if len(resp.Challenges) != 1 {
+		t.Fatalf("expected invalid definitions to be skipped, got %d challenges", len(resp.Challenges))
+	}
+	c := resp.Challenges[0]
+	if !c.Completed || c.Progress.CurrentCount != 2 {
+		t.Fatalf("expected 2 Study sessions to complete the challenge, got %+v", c.Progress)
+	}
+	if !c.Claimed || c.Period.Key != "once" || len(c.History) != 1 || c.History[0].PeriodKey != "once" {
+		t.Fatalf("expected the earlier claim to cover the one-off period, got %+v", c)
+	}
+	if want := truncateToDay(now).AddDate(0, 0, -6); !entriesFrom.Equal(want) {
+		t.Fatalf("expected entries from %s, got %s", want, entriesFrom)
+	}
+
+	_, err = NewService(repo, nil).ClaimChallenge(context.Background(), "user-1", "broken", "UTC")
+	if !errors.Is(err, ErrInvalidChallengeRule) {
+		t.Fatalf("expected ErrInvalidChallengeRule, got %v", err)
+	}
+}
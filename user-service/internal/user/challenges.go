@@ -1,62 +1,55 @@
 package user
 
-// challengeDefinitions is the canonical list of available challenges.
-// Keep this stable because clients may store challenge IDs.
+// challengeDefinitions seeds the challenges collection with the launch set.
+// Challenges live in Firestore as data; keep these IDs stable because
+// clients may store them.
 func challengeDefinitions() []ChallengeDefinition {
 	return []ChallengeDefinition{
 		{
-			ID:               "focus_2h_3days",
-			Title:            "Fokus 2 jam selama 3 hari",
-			Description:      "Fokus minimal 2 jam tanpa distraksi selama 3 hari berturut-turut",
-			RewardPoints:     50,
-			RuleType:         ChallengeRuleDailyMinutesStreak,
-			MinMinutesPerDay: 120,
-			ConsecutiveDays:  3,
+			ID:           "focus_2h_3days",
+			Title:        "Fokus 2 jam selama 3 hari",
+			Description:  "Fokus minimal 2 jam tanpa distraksi selama 3 hari berturut-turut",
+			RewardPoints: 50,
+			Rule: &ChallengeRule{
+				Window:     ChallengeWindowConsecutiveDays,
+				Days:       3,
+				Conditions: []ChallengeCondition{{Metric: ChallengeMetricMinutes, Target: 120}},
+			},
 		},
 		{
 			ID:           "share_recap_3x_weekly",
 			Title:        "Bagikan Recap Mingguan",
 			Description:  "Bagikan recap hasil fokusmu 3 kali dalam seminggu",
 			RewardPoints: 50,
-			RuleType:     ChallengeRuleWeeklyShares,
-			TargetCount:  3,
+			Rule: &ChallengeRule{
+				Window:          ChallengeWindowWeek,
+				IncludePrevious: true,
+				Conditions:      []ChallengeCondition{{Metric: ChallengeMetricShares, Target: 3}},
+			},
 		},
 		{
 			ID:           "streak_10_days",
 			Title:        "Raih 10 Hari Streak",
 			Description:  "Raih 10 hari streak dan unggah recap ke media sosial",
 			RewardPoints: 50,
-			RuleType:     ChallengeRuleStreakMilestone,
-			TargetStreak: 10,
+			Rule: &ChallengeRule{
+				Window:     ChallengeWindowAllTime,
+				Conditions: []ChallengeCondition{{Metric: ChallengeMetricStreak, Target: 10}},
+			},
 		},
 		{
-			ID:                       "cycles_and_mindfulness",
-			Title:                    "Fokus & Mindfulness",
-			Description:              "Selesaikan 4 cycle kerja dan lakukan 2 menit mindfulness breathing",
-			RewardPoints:             50,
-			RuleType:                 ChallengeRuleCyclesAndMindfulness,
-			TargetCycles:             4,
-			TargetMindfulnessMinutes: 2,
+			ID:           "cycles_and_mindfulness",
+			Title:        "Fokus & Mindfulness",
+			Description:  "Selesaikan 4 cycle kerja dan lakukan 2 menit mindfulness breathing",
+			RewardPoints: 50,
+			Rule: &ChallengeRule{
+				Window:          ChallengeWindowDay,
+				IncludePrevious: true,
+				Conditions: []ChallengeCondition{
+					{Metric: ChallengeMetricCycles, Target: 4},
+					{Metric: ChallengeMetricMindfulnessMinutes, Target: 2},
+				},
+			},
 		},
 	}
 }
-
-// badgesForPoints returns every badge unlocked for the given points.
-func badgesForPoints(points int) []Badge {
-	// Feel free to tweak thresholds later; IDs should remain stable.
-	thresholds := []Badge{
-		{ID: "bronze", Label: "Bronze", MinPts: 100},
-		{ID: "silver", Label: "Silver", MinPts: 250},
-		{ID: "gold", Label: "Gold", MinPts: 500},
-		{ID: "diamond", Label: "Diamond", MinPts: 1000},
-	}
-
-	var unlocked []Badge
-	for _, b := range thresholds {
-		if points >= b.MinPts {
-			unlocked = append(unlocked, b)
-		}
-	}
-	return unlocked
-}
-
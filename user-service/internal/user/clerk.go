@@ -0,0 +1,48 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDisplayName caps the display name copied from Clerk, in runes.
+const maxDisplayName = 100
+
+// SyncClerkUser applies a Clerk user.created or user.updated event. Events
+// are deduplicated by eventID and ordered by UpdatedAt, so redelivery and
+// out-of-order delivery leave the newest identity in place.
+func (s *service) SyncClerkUser(ctx context.Context, eventID, eventType string, u ClerkUser) (SyncOutcome, error) {
+	eventID = strings.TrimSpace(eventID)
+	u.UserID = strings.TrimSpace(u.UserID)
+	if !validEventID(eventID) || u.UserID == "" || strings.Contains(u.UserID, "/") {
+		return "", ErrInvalidWebhookEvent
+	}
+	u.Email = strings.TrimSpace(u.Email)
+	u.DisplayName = truncateRunes(strings.TrimSpace(u.DisplayName), maxDisplayName)
+	u.AvatarURL = strings.TrimSpace(u.AvatarURL)
+	u.UpdatedAt = u.UpdatedAt.UTC()
+	return s.repo.SyncClerkUser(ctx, eventID, eventType, u)
+}
+
+// RecordWebhookEvent marks an event without a profile change, such as
+// user.deleted, as processed.
+func (s *service) RecordWebhookEvent(ctx context.Context, eventID, eventType string) (bool, error) {
+	eventID = strings.TrimSpace(eventID)
+	if !validEventID(eventID) {
+		return false, ErrInvalidWebhookEvent
+	}
+	return s.repo.RecordWebhookEvent(ctx, eventID, eventType)
+}
+
+// validEventID reports whether id can be used as a document ID.
+func validEventID(id string) bool {
+	return id != "" && len(id) <= 256 && !strings.Contains(id, "/")
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
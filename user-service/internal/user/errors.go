@@ -0,0 +1,59 @@
+package user
+
+import "errors"
+
+var (
+	// ErrUserNotFound indicates no user matches a handle or friend code, or the
+	// user is hidden from the caller by a block.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidFriendRequest indicates a malformed request, e.g. befriending yourself.
+	ErrInvalidFriendRequest = errors.New("invalid friend request")
+	// ErrFriendRequestNotFound indicates there is no pending request to act on.
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	// ErrFriendRequestsClosed indicates the addressee does not accept requests.
+	ErrFriendRequestsClosed = errors.New("user is not accepting friend requests")
+	// ErrAlreadyFriends indicates the users are already friends.
+	ErrAlreadyFriends = errors.New("already friends")
+	// ErrFriendLimitReached indicates one of the users has maxFriends friends.
+	ErrFriendLimitReached = errors.New("friend limit reached")
+	// ErrNotFriends indicates the users are not friends.
+	ErrNotFriends = errors.New("not friends")
+	// ErrFriendshipExists is returned by Repository.CreateFriendship when the pair already has a document.
+	ErrFriendshipExists = errors.New("friendship already exists")
+	// ErrFriendCodeTaken is returned by Repository.ReserveFriendCode on a collision.
+	ErrFriendCodeTaken = errors.New("friend code taken")
+	// ErrInvalidHandle indicates a handle that breaks the format rules or is reserved.
+	ErrInvalidHandle = errors.New("invalid handle")
+	// ErrHandleTaken indicates another user holds the handle, or released it too recently.
+	ErrHandleTaken = errors.New("handle taken")
+	// ErrHandleCooldown indicates the user changed their handle too recently.
+	ErrHandleCooldown = errors.New("handle was changed too recently")
+	// ErrAvatarsDisabled indicates no avatar bucket is configured.
+	ErrAvatarsDisabled = errors.New("avatar uploads are not configured")
+	// ErrInvalidPrivacy indicates an unsupported privacy value.
+	ErrInvalidPrivacy = errors.New("invalid privacy settings")
+	// ErrInvalidLeaderboard indicates an unsupported leaderboard period or metric.
+	ErrInvalidLeaderboard = errors.New("invalid leaderboard")
+	// ErrInvalidChallengeRule indicates a challenge definition that cannot be evaluated.
+	ErrInvalidChallengeRule = errors.New("invalid challenge rule")
+	// ErrInvalidPoints indicates a malformed ledger entry or redemption.
+	ErrInvalidPoints = errors.New("invalid points request")
+	// ErrInsufficientPoints indicates the balance does not cover a redemption.
+	ErrInsufficientPoints = errors.New("insufficient points")
+	// ErrRewardNotFound indicates an unknown reward item.
+	ErrRewardNotFound = errors.New("reward not found")
+	// ErrRewardLimitReached indicates the user already holds the most allowed of an item.
+	ErrRewardLimitReached = errors.New("reward limit reached")
+	// ErrIdempotencyKeyReused indicates a key replayed with a different request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	// ErrPointsEntryNotFound indicates an unknown ledger entry.
+	ErrPointsEntryNotFound = errors.New("points entry not found")
+	// ErrPointsEntryExists indicates a ledger entry ID, or a reversal, already exists.
+	ErrPointsEntryExists = errors.New("points entry already exists")
+	// ErrInvalidWebhookEvent indicates a webhook event missing its ID or user.
+	ErrInvalidWebhookEvent = errors.New("invalid webhook event")
+	// ErrInvalidMindfulness indicates an unknown exercise or an out-of-range duration or mood.
+	ErrInvalidMindfulness = errors.New("invalid mindfulness session")
+	// ErrPreferencesConflict indicates preferences changed since the revision the client read.
+	ErrPreferencesConflict = errors.New("preferences were changed by another request")
+)
@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"github.com/focusnest/shared-libs/preferences"
+	"github.com/focusnest/shared-libs/profilestats"
+	"github.com/focusnest/shared-libs/timezone"
 	"google.golang.org/api/iterator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -54,6 +57,9 @@ func (r *firestoreRepository) UpsertProfile(ctx context.Context, userID string,
 		if updates.Birthdate != nil && updates.Birthdate.IsSet {
 			data["birthdate"] = updates.Birthdate.Value
 		}
+		if updates.Timezone != nil {
+			data["timezone"] = strings.TrimSpace(*updates.Timezone)
+		}
 
 		if _, err := tx.Get(docRef); status.Code(err) == codes.NotFound {
 			data["created_at"] = now
@@ -72,86 +78,153 @@ func (r *firestoreRepository) UpsertProfile(ctx context.Context, userID string,
 	return r.GetProfile(ctx, userID)
 }
 
-func (r *firestoreRepository) GetProfileMetadata(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-	metrics := ProfileMetadata{}
-	query := r.productivitiesQuery(userID).
-		Select("start_time", "deleted", "num_cycle", "category").
-		OrderBy("start_time", firestore.Asc)
-	iter := query.Documents(ctx)
-	defer iter.Stop()
+func (r *firestoreRepository) profileStatsRef(userID string) *firestore.DocumentRef {
+	return r.client.Collection("users").Doc(userID).Collection(profilestats.Collection).Doc(profilestats.DocID)
+}
+
+// GetProfileStats reads the counters focus-service keeps up to date as
+// sessions are written. Users not counted yet are counted from history once
+// and stored, with loc placing sessions recorded without a timezone, so
+// later reads are a single document.
+func (r *firestoreRepository) GetProfileStats(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+	doc, err := r.profileStatsRef(userID).Get(ctx)
+	if err == nil && profilestats.StoredVersion(doc.Data()) == profilestats.Version {
+		var stats profilestats.Counters
+		if err := doc.DataTo(&stats); err != nil {
+			return profilestats.Counters{}, fmt.Errorf("decode profile stats: %w", err)
+		}
+		stats.RefreshXP()
+		return stats, nil
+	}
+	if err != nil && status.Code(err) != codes.NotFound {
+		return profilestats.Counters{}, err
+	}
+	_, current, err := r.rebuildProfileStats(ctx, userID, loc, false)
+	return current, err
+}
+
+// RebuildProfileMetadata counts the user's sessions from scratch and stores
+// the counters, returning the ones it replaced (nil if none were current).
+func (r *firestoreRepository) RebuildProfileMetadata(ctx context.Context, userID string, loc *time.Location) (*profilestats.Counters, profilestats.Counters, error) {
+	return r.rebuildProfileStats(ctx, userID, loc, true)
+}
+
+// rebuildProfileStats counts the user's sessions and stores the counters,
+// returning the current ones it found (nil if none). Unless force is set,
+// current counters, such as a session write stored since the caller looked,
+// are kept and nothing is scanned. Reading the counters in
+// the transaction orders the rebuild with focus-service's writes, so none is
+// lost in between.
+func (r *firestoreRepository) rebuildProfileStats(ctx context.Context, userID string, loc *time.Location, force bool) (*profilestats.Counters, profilestats.Counters, error) {
+	ref := r.profileStatsRef(userID)
+	var (
+		previous *profilestats.Counters
+		current  profilestats.Counters
+	)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		previous = nil
+		fallback := ""
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			// Keep legacy sessions on the days they were counted on.
+			fallback, _ = doc.Data()["fallback_timezone"].(string)
+			if profilestats.StoredVersion(doc.Data()) == profilestats.Version {
+				var stored profilestats.Counters
+				if err := doc.DataTo(&stored); err != nil {
+					return fmt.Errorf("decode profile stats: %w", err)
+				}
+				stored.RefreshXP()
+				previous = &stored
+				if !force {
+					current = stored
+					return nil
+				}
+			}
+		}
+		if fallback == "" {
+			// The owner's zone, as focus-service uses, whoever is reading.
+			profile, err := tx.Get(r.client.Collection("profiles").Doc(userID))
+			if err != nil && status.Code(err) != codes.NotFound {
+				return err
+			}
+			if err == nil {
+				fallback, _ = profile.Data()[timezone.ProfileField].(string)
+			}
+		}
+		if fallback == "" {
+			fallback = loc.String()
+		}
+
+		sessions, err := r.profileSessions(tx.Documents(r.productivitiesQuery(userID).
+			Select(profileSessionFields...)))
+		if err != nil {
+			return err
+		}
+		current = profilestats.Build(sessions, timezone.Load(fallback).String())
+		current.UpdatedAt = time.Now().UTC()
+		return tx.Set(ref, current)
+	})
+	if err != nil {
+		return nil, profilestats.Counters{}, err
+	}
+	return previous, current, nil
+}
 
-	var prevDay time.Time
-	var lastProcessedDay time.Time
-	current := 0
-	longest := 0
-	
-	// Track unique categories for TotalProductivities
-	uniqueCategories := make(map[string]bool)
+// profileSessionFields are the productivity fields the counters depend on.
+var profileSessionFields = []string{"start_time", "deleted", "num_cycle", "category", "timezone", "time_elapsed", "time_mode"}
 
+// profileSessions decodes the counted fields of every non-deleted session.
+func (r *firestoreRepository) profileSessions(iter *firestore.DocumentIterator) ([]profilestats.Session, error) {
+	defer iter.Stop()
+	var sessions []profilestats.Session
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return metrics, err
+			return nil, err
 		}
 
 		var snapshot struct {
-			StartTime time.Time `firestore:"start_time"`
-			Deleted   bool      `firestore:"deleted"`
-			NumCycle  int       `firestore:"num_cycle"`
-			Category  string    `firestore:"category"`
+			StartTime   time.Time `firestore:"start_time"`
+			Deleted     bool      `firestore:"deleted"`
+			NumCycle    int       `firestore:"num_cycle"`
+			Category    string    `firestore:"category"`
+			Timezone    string    `firestore:"timezone"`
+			TimeElapsed int       `firestore:"time_elapsed"`
+			TimeMode    string    `firestore:"time_mode"`
 		}
 		if err := doc.DataTo(&snapshot); err != nil {
-			return metrics, fmt.Errorf("decode productivity snapshot: %w", err)
+			return nil, fmt.Errorf("decode productivity snapshot: %w", err)
 		}
 		if snapshot.Deleted {
 			continue
 		}
-		
-		// Track unique categories (only count non-empty categories)
-		// Trim and check category - empty string means field doesn't exist or is empty
-		category := strings.TrimSpace(snapshot.Category)
-		if category != "" {
-			uniqueCategories[category] = true
-		}
-		
-		metrics.TotalSessions++
-		cycle := snapshot.NumCycle
-		if cycle <= 0 {
-			cycle = 1
-		}
-		metrics.TotalCycle += cycle
-
-		if snapshot.StartTime.IsZero() {
-			continue
-		}
-		localStart := snapshot.StartTime.In(loc)
-		day := time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, loc)
-		if !lastProcessedDay.IsZero() && day.Equal(lastProcessedDay) {
-			continue
-		}
-
-		if prevDay.IsZero() {
-			current = 1
-		} else if day.Equal(prevDay.AddDate(0, 0, 1)) {
-			current++
-		} else if day.After(prevDay) {
-			current = 1
-		}
-
-		if current > longest {
-			longest = current
-		}
-		prevDay = day
-		lastProcessedDay = day
+		sessions = append(sessions, profilestats.Session{
+			StartTime:   snapshot.StartTime,
+			Timezone:    snapshot.Timezone,
+			NumCycle:    snapshot.NumCycle,
+			Category:    snapshot.Category,
+			TimeElapsed: snapshot.TimeElapsed,
+			TimeMode:    snapshot.TimeMode,
+		})
 	}
+	return sessions, nil
+}
 
-	// Set TotalProductivities to count of unique categories
-	metrics.TotalProductivities = len(uniqueCategories)
-	metrics.LongestStreak = longest
-	return metrics, nil
+// metadataFromStats maps the stored counters to the profile's metadata.
+// TotalProductivities counts distinct categories.
+func metadataFromStats(stats profilestats.Counters) ProfileMetadata {
+	return ProfileMetadata{
+		LongestStreak:       stats.LongestStreak(),
+		TotalProductivities: stats.DistinctCategories(),
+		TotalSessions:       stats.TotalSessions,
+		TotalCycle:          stats.TotalCycle,
+	}
 }
 
 func (r *firestoreRepository) productivitiesQuery(userID string) firestore.Query {
@@ -175,7 +248,7 @@ func (r *firestoreRepository) GetDailyMinutesByDate(ctx context.Context, userID
 		if mins <= 0 && e.TimeElapsed > 0 {
 			mins = 1
 		}
-		key := e.StartTime.In(loc).Format("2006-01-02")
+		key := localDay(e.StartTime, e.Timezone, loc).Format("2006-01-02")
 		minsByDate[key] += mins
 	}
 
@@ -202,7 +275,11 @@ func (r *firestoreRepository) fetchProductivitiesForWindow(ctx context.Context,
 		var payload struct {
 			StartTime   time.Time `firestore:"start_time"`
 			TimeElapsed int       `firestore:"time_elapsed"`
+			NumCycle    int       `firestore:"num_cycle"`
+			Category    string    `firestore:"category"`
+			TimeMode    string    `firestore:"time_mode"`
 			Deleted     bool      `firestore:"deleted"`
+			Timezone    string    `firestore:"timezone"`
 		}
 		if err := doc.DataTo(&payload); err != nil {
 			continue
@@ -213,14 +290,45 @@ func (r *firestoreRepository) fetchProductivitiesForWindow(ctx context.Context,
 		entries = append(entries, ProductivityEntry{
 			StartTime:   payload.StartTime,
 			TimeElapsed: payload.TimeElapsed,
+			NumCycle:    payload.NumCycle,
+			Category:    payload.Category,
+			TimeMode:    payload.TimeMode,
+			Timezone:    payload.Timezone,
 		})
 	}
 	return entries, nil
 }
 
+func (r *firestoreRepository) ListProductivityEntries(ctx context.Context, userID string, start, end time.Time) ([]ProductivityEntry, error) {
+	if userID == "" {
+		return nil, nil
+	}
+	return r.fetchProductivitiesForWindow(ctx, userID, start, end)
+}
+
 type ProductivityEntry struct {
 	StartTime   time.Time
 	TimeElapsed int
+	NumCycle    int
+	Category    string
+	TimeMode    string
+	// Timezone is the zone the session was recorded in, empty for older entries.
+	Timezone string
+}
+
+// localDay returns the midnight, in loc, of the day a session counts towards.
+// Sessions that recorded their own timezone keep that day after the user moves.
+func localDay(start time.Time, tz string, loc *time.Location) time.Time {
+	local := localTime(start, tz, loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// localTime returns start on the clock of the zone it was recorded in, or loc.
+func localTime(start time.Time, tz string, loc *time.Location) time.Time {
+	if tz != "" {
+		return start.In(timezone.Load(tz))
+	}
+	return start.In(loc)
 }
 
 func (r *firestoreRepository) ListChallenges(ctx context.Context) ([]ChallengeDefinition, error) {
@@ -240,6 +348,10 @@ func (r *firestoreRepository) ListChallenges(ctx context.Context) ([]ChallengeDe
 		if err := doc.DataTo(&def); err != nil {
 			continue
 		}
+		if def.ID == "" {
+			// Hand-written documents may rely on the document ID alone.
+			def.ID = doc.Ref.ID
+		}
 		challenges = append(challenges, def)
 	}
 	return challenges, nil
@@ -250,123 +362,131 @@ func (r *firestoreRepository) CreateChallenge(ctx context.Context, def Challenge
 	return err
 }
 
-func (r *firestoreRepository) GetChallengeClaimedAt(ctx context.Context, userID, challengeID string) (time.Time, error) {
+// ListChallengeClaims reads profiles/{uid}/challenge_claims. Claims are
+// stored as {challengeID}@{periodKey}; older ones are keyed by challenge ID
+// and carry no period.
+func (r *firestoreRepository) ListChallengeClaims(ctx context.Context, userID, challengeID string) ([]ChallengeClaim, error) {
 	if userID == "" || challengeID == "" {
-		return time.Time{}, nil
-	}
-	ref := r.client.Collection("profiles").Doc(userID).Collection("challenge_claims").Doc(challengeID)
-	doc, err := ref.Get(ctx)
-	if status.Code(err) == codes.NotFound {
-		return time.Time{}, nil
-	}
-	if err != nil {
-		return time.Time{}, err
-	}
-	var claim struct {
-		ClaimedAt time.Time `firestore:"claimed_at"`
+		return nil, nil
 	}
-	if err := doc.DataTo(&claim); err != nil {
-		return time.Time{}, nil // fallback
+	iter := r.client.Collection("profiles").Doc(userID).Collection("challenge_claims").
+		Where("challenge_id", "==", challengeID).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var claims []ChallengeClaim
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var claim ChallengeClaim
+		if err := doc.DataTo(&claim); err != nil {
+			continue
+		}
+		claims = append(claims, claim)
 	}
-	return claim.ClaimedAt, nil
+	return claims, nil
 }
 
-func (r *firestoreRepository) ClaimChallenge(ctx context.Context, userID, challengeID string, points int) (newTotal int, claimedAt time.Time, alreadyClaimed bool, err error) {
-	if userID == "" || challengeID == "" {
-		return 0, time.Time{}, false, fmt.Errorf("missing identifiers")
+func challengeClaimDocID(challengeID, periodKey string) string {
+	return challengeID + "@" + periodKey
+}
+
+func (r *firestoreRepository) ClaimChallenge(ctx context.Context, userID string, claim ChallengeClaim) (newTotal int, alreadyClaimed bool, err error) {
+	if userID == "" || claim.ChallengeID == "" || claim.PeriodKey == "" {
+		return 0, false, fmt.Errorf("missing identifiers")
 	}
-	if points <= 0 {
-		return 0, time.Time{}, false, fmt.Errorf("invalid points")
+	if claim.PointsAwarded <= 0 {
+		return 0, false, fmt.Errorf("invalid points")
 	}
 
 	profileRef := r.client.Collection("profiles").Doc(userID)
-	claimRef := profileRef.Collection("challenge_claims").Doc(challengeID)
-	now := time.Now().UTC()
+	claimRef := profileRef.Collection("challenge_claims").Doc(challengeClaimDocID(claim.ChallengeID, claim.PeriodKey))
+	now := claim.ClaimedAt
 
 	err = r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		// We allow overwriting the claim document because service.go handles period checking.
-		// Just ensure profile exists.
-
-		// Ensure profile exists; if missing, create baseline.
-		if _, err := tx.Get(profileRef); status.Code(err) == codes.NotFound {
-			if err := tx.Set(profileRef, map[string]any{
-				"user_id":       userID,
-				"points_total":  0,
-				"created_at":    now,
-				"updated_at":    now,
-				"bio":           "",
-				"birthdate":     nil,
-			}, firestore.MergeAll); err != nil {
-				return err
-			}
-		} else if err != nil {
+		alreadyClaimed = false
+
+		// One claim document per period makes a repeated claim a no-op.
+		if _, err := tx.Get(claimRef); err == nil {
+			alreadyClaimed = true
+			return nil
+		} else if status.Code(err) != codes.NotFound {
 			return err
 		}
 
-		// Increment points atomically.
-		if err := tx.Update(profileRef, []firestore.Update{
-			{Path: "points_total", Value: firestore.Increment(int64(points))},
-			{Path: "updated_at", Value: now},
+		ledger, err := r.readLedger(tx, userID)
+		if err != nil {
+			return err
+		}
+		if _, err := ledger.append(tx, PointsEntry{
+			ID:         "challenge-" + claimRef.ID,
+			Type:       PointsEarn,
+			Amount:     claim.PointsAwarded,
+			Reason:     fmt.Sprintf("challenge %s (%s)", claim.ChallengeID, claim.PeriodKey),
+			SourceType: PointsSourceChallenge,
+			SourceID:   claimRef.ID,
+			CreatedAt:  now,
 		}); err != nil {
 			return err
 		}
-
-		// Set claim doc.
-		if err := tx.Set(claimRef, map[string]any{
-			"challenge_id":   challengeID,
-			"points_awarded": points,
-			"claimed_at":     now,
-		}, firestore.MergeAll); err != nil {
+		if err := ledger.commit(tx); err != nil {
 			return err
 		}
+		newTotal = ledger.profile.PointsTotal
 
-		claimedAt = now
-		return nil
+		return tx.Create(claimRef, claim)
 	})
 	if err != nil {
-		return 0, time.Time{}, false, err
+		return 0, false, err
 	}
-
-	// If we awarded, fetch the updated total. (We could read in-tx, but Increment is easier to resolve after.)
 	if !alreadyClaimed {
-		p, err2 := r.GetProfile(ctx, userID)
-		if err2 != nil {
-			return 0, time.Time{}, false, err2
-		}
-		newTotal = p.PointsTotal
+		return newTotal, false, nil
 	}
 
-	return newTotal, claimedAt, alreadyClaimed, nil
+	p, err := r.GetProfile(ctx, userID)
+	if err != nil {
+		return 0, false, err
+	}
+	return p.PointsTotal, alreadyClaimed, nil
 }
 
-// GetWeeklyShareCount returns the number of shares for a user in the given week.
-// weekStart should be the Monday of the week (truncated to day).
-func (r *firestoreRepository) GetWeeklyShareCount(ctx context.Context, userID string, weekStart time.Time) (int, error) {
+// ListShareTimes returns when the user shared anything in [start, end).
+func (r *firestoreRepository) ListShareTimes(ctx context.Context, userID string, start, end time.Time) ([]time.Time, error) {
 	if userID == "" {
-		return 0, nil
+		return nil, nil
 	}
 
-	weekEnd := weekStart.AddDate(0, 0, 7) // Monday + 7 days
-
 	iter := r.client.Collection("profiles").Doc(userID).Collection("shares").
-		Where("shared_at", ">=", weekStart).
-		Where("shared_at", "<", weekEnd).
+		Where("shared_at", ">=", start).
+		Where("shared_at", "<", end).
 		Documents(ctx)
 	defer iter.Stop()
 
-	count := 0
+	var times []time.Time
 	for {
-		_, err := iter.Next()
+		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return 0, err
+			return nil, err
+		}
+
+		var snapshot struct {
+			SharedAt time.Time `firestore:"shared_at"`
+		}
+		if err := doc.DataTo(&snapshot); err != nil {
+			continue
 		}
-		count++
+		times = append(times, snapshot.SharedAt)
 	}
 
-	return count, nil
+	return times, nil
 }
 
 // RecordShare records a share event for the user.
@@ -451,85 +571,97 @@ func (r *firestoreRepository) GetCurrentStreak(ctx context.Context, userID strin
 	return streak, nil
 }
 
-// GetCyclesByDate returns the total number of work cycles completed by the user on a specific date.
-func (r *firestoreRepository) GetCyclesByDate(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error) {
+// ListMindfulnessSessions returns the user's mindfulness sessions completed in [start, end).
+func (r *firestoreRepository) ListMindfulnessSessions(ctx context.Context, userID string, start, end time.Time) ([]MindfulnessSession, error) {
 	if userID == "" {
-		return 0, nil
+		return nil, nil
 	}
 
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	query := r.productivitiesQuery(userID).
-		Where("start_time", ">=", startOfDay).
-		Where("start_time", "<", endOfDay).
-		Select("num_cycle", "deleted", "start_time")
-	iter := query.Documents(ctx)
+	iter := r.mindfulnessCollection(userID).
+		Where("completed_at", ">=", start).
+		Where("completed_at", "<", end).
+		Documents(ctx)
 	defer iter.Stop()
 
-	totalCycles := 0
+	var sessions []MindfulnessSession
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 
-		var snapshot struct {
-			NumCycle  int       `firestore:"num_cycle"`
-			Deleted   bool      `firestore:"deleted"`
-			StartTime time.Time `firestore:"start_time"`
-		}
-		if err := doc.DataTo(&snapshot); err != nil {
-			continue
-		}
-		if snapshot.Deleted {
+		session, err := mindfulnessFromSnapshot(doc)
+		if err != nil {
 			continue
 		}
-		totalCycles += snapshot.NumCycle
+		sessions = append(sessions, session)
 	}
 
-	return totalCycles, nil
+	return sessions, nil
 }
 
-// GetMindfulnessMinutesByDate returns the total mindfulness minutes for the user on a specific date.
-func (r *firestoreRepository) GetMindfulnessMinutesByDate(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error) {
-	if userID == "" {
-		return 0, nil
+func (r *firestoreRepository) mindfulnessCollection(userID string) *firestore.CollectionRef {
+	return r.client.Collection("profiles").Doc(userID).Collection("mindfulness")
+}
+
+func mindfulnessFromSnapshot(doc *firestore.DocumentSnapshot) (MindfulnessSession, error) {
+	var session MindfulnessSession
+	if err := doc.DataTo(&session); err != nil {
+		return session, err
+	}
+	session.ID = doc.Ref.ID
+	if session.DurationSeconds == 0 {
+		// Minute-only records from before the exercise catalog.
+		session.DurationSeconds = session.Minutes * 60
 	}
+	return session, nil
+}
 
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
-	endOfDay := startOfDay.Add(24 * time.Hour)
+// CreateMindfulnessSession stores a guided session next to the minute-only
+// records, so challenges read both.
+func (r *firestoreRepository) CreateMindfulnessSession(ctx context.Context, userID string, session *MindfulnessSession) error {
+	ref := r.mindfulnessCollection(userID).NewDoc()
+	if _, err := ref.Create(ctx, session); err != nil {
+		return err
+	}
+	session.ID = ref.ID
+	return nil
+}
 
-	iter := r.client.Collection("profiles").Doc(userID).Collection("mindfulness").
-		Where("completed_at", ">=", startOfDay).
-		Where("completed_at", "<", endOfDay).
-		Documents(ctx)
+func (r *firestoreRepository) ListMindfulnessHistory(ctx context.Context, userID string, after MindfulnessCursor, limit int) ([]MindfulnessSession, error) {
+	query := r.mindfulnessCollection(userID).
+		OrderBy("completed_at", firestore.Desc).
+		OrderBy(firestore.DocumentID, firestore.Desc).
+		Limit(limit)
+	switch {
+	case after.CompletedAt.IsZero():
+	case after.ID != "":
+		query = query.StartAfter(after.CompletedAt, after.ID)
+	default:
+		query = query.StartAfter(after.CompletedAt)
+	}
+	iter := query.Documents(ctx)
 	defer iter.Stop()
 
-	totalMinutes := 0
+	var sessions []MindfulnessSession
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return 0, err
-		}
-
-		var snapshot struct {
-			Minutes     int       `firestore:"minutes"`
-			CompletedAt time.Time `firestore:"completed_at"`
+			return nil, err
 		}
-		if err := doc.DataTo(&snapshot); err != nil {
-			continue
+		session, err := mindfulnessFromSnapshot(doc)
+		if err != nil {
+			return nil, fmt.Errorf("decode mindfulness session: %w", err)
 		}
-		totalMinutes += snapshot.Minutes
+		sessions = append(sessions, session)
 	}
-
-	return totalMinutes, nil
+	return sessions, nil
 }
 
 // RecordMindfulness records a mindfulness session for the user.
@@ -542,12 +674,892 @@ func (r *firestoreRepository) RecordMindfulness(ctx context.Context, userID stri
 	}
 
 	now := time.Now().UTC()
-	mindfulnessRef := r.client.Collection("profiles").Doc(userID).Collection("mindfulness")
-
-	_, _, err := mindfulnessRef.Add(ctx, map[string]any{
+	_, _, err := r.mindfulnessCollection(userID).Add(ctx, map[string]any{
 		"minutes":      minutes,
 		"completed_at": now,
 	})
 
 	return err
 }
+
+func (r *firestoreRepository) ReserveFriendCode(ctx context.Context, userID, code string) error {
+	codeRef := r.client.Collection("friend_codes").Doc(code)
+	profileRef := r.client.Collection("profiles").Doc(userID)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(codeRef); err == nil {
+			return ErrFriendCodeTaken
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err := tx.Create(codeRef, map[string]interface{}{"user_id": userID, "created_at": time.Now().UTC()}); err != nil {
+			return err
+		}
+		return tx.Set(profileRef, map[string]interface{}{"user_id": userID, "friend_code": code}, firestore.MergeAll)
+	})
+}
+
+func (r *firestoreRepository) ResolveFriendCode(ctx context.Context, code string) (string, error) {
+	return r.lookupUserID(ctx, r.client.Collection("friend_codes").Doc(code))
+}
+
+func (r *firestoreRepository) handleRef(handle string) *firestore.DocumentRef {
+	return r.client.Collection("handles").Doc(strings.ToLower(handle))
+}
+
+// handleReservation is the handles/{lowercase handle} document. ReleasedAt
+// is set once its owner moves to another handle.
+type handleReservation struct {
+	UserID     string     `firestore:"user_id"`
+	Handle     string     `firestore:"handle"`
+	CreatedAt  time.Time  `firestore:"created_at"`
+	ReleasedAt *time.Time `firestore:"released_at"`
+}
+
+// ResolveHandle reads the handles/{lowercase handle} reservation document.
+// Released handles resolve to nobody.
+func (r *firestoreRepository) ResolveHandle(ctx context.Context, handle string) (string, error) {
+	doc, err := r.handleRef(handle).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var res handleReservation
+	if err := doc.DataTo(&res); err != nil {
+		return "", fmt.Errorf("unmarshal handle: %w", err)
+	}
+	if res.ReleasedAt != nil {
+		return "", nil
+	}
+	return res.UserID, nil
+}
+
+// ChangeHandle enforces uniqueness through the reservation document: reads
+// and writes share one transaction, so two users racing for a handle cannot
+// both win.
+func (r *firestoreRepository) ChangeHandle(ctx context.Context, userID, handle string, at time.Time, allow func(*Profile) error) (*Profile, error) {
+	profileRef := r.client.Collection("profiles").Doc(userID)
+	newRef := r.handleRef(handle)
+	var saved Profile
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		profile := defaultProfile(userID)
+		snap, err := tx.Get(profileRef)
+		exists := err == nil
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if exists {
+			if err := snap.DataTo(profile); err != nil {
+				return fmt.Errorf("unmarshal profile: %w", err)
+			}
+			profile.UserID = userID
+		}
+		if err := allow(profile); err != nil {
+			return err
+		}
+
+		resSnap, err := tx.Get(newRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			var res handleReservation
+			if err := resSnap.DataTo(&res); err != nil {
+				return fmt.Errorf("unmarshal handle: %w", err)
+			}
+			held := res.ReleasedAt == nil || at.Before(res.ReleasedAt.Add(handleReleaseHold))
+			if res.UserID != userID && held {
+				return ErrHandleTaken
+			}
+		}
+
+		data := map[string]interface{}{
+			"user_id":    userID,
+			"handle":     handle,
+			"updated_at": at,
+		}
+		if !strings.EqualFold(profile.Handle, handle) {
+			data["handle_changed_at"] = at
+			profile.HandleChangedAt = &at
+			if profile.Handle != "" {
+				if err := tx.Set(r.handleRef(profile.Handle), map[string]interface{}{"released_at": at}, firestore.MergeAll); err != nil {
+					return err
+				}
+			}
+		}
+		if !exists {
+			data["created_at"] = at
+			data["points_total"] = 0
+		}
+		if err := tx.Set(newRef, handleReservation{UserID: userID, Handle: handle, CreatedAt: at}); err != nil {
+			return err
+		}
+		profile.Handle = handle
+		saved = *profile
+		return tx.Set(profileRef, data, firestore.MergeAll)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (r *firestoreRepository) SetAvatarPath(ctx context.Context, userID, path string, at time.Time) (*Profile, string, error) {
+	ref := r.client.Collection("profiles").Doc(userID)
+	var (
+		saved    Profile
+		previous string
+	)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		profile := defaultProfile(userID)
+		snap, err := tx.Get(ref)
+		exists := err == nil
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if exists {
+			if err := snap.DataTo(profile); err != nil {
+				return fmt.Errorf("unmarshal profile: %w", err)
+			}
+			profile.UserID = userID
+		}
+		previous = profile.AvatarPath
+		profile.AvatarPath = path
+		saved = *profile
+
+		data := map[string]interface{}{
+			"user_id":     userID,
+			"avatar_path": path,
+			"updated_at":  at,
+		}
+		if !exists {
+			data["created_at"] = at
+			data["points_total"] = 0
+		}
+		return tx.Set(ref, data, firestore.MergeAll)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &saved, previous, nil
+}
+
+func (r *firestoreRepository) lookupUserID(ctx context.Context, ref *firestore.DocumentRef) (string, error) {
+	doc, err := ref.Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var payload struct {
+		UserID string `firestore:"user_id"`
+	}
+	if err := doc.DataTo(&payload); err != nil {
+		return "", fmt.Errorf("unmarshal %s: %w", ref.Path, err)
+	}
+	return payload.UserID, nil
+}
+
+func (r *firestoreRepository) friendshipRef(userA, userB string) *firestore.DocumentRef {
+	pair := friendPair(userA, userB)
+	return r.client.Collection("friendships").Doc(pair[0] + "_" + pair[1])
+}
+
+func (r *firestoreRepository) GetFriendship(ctx context.Context, userA, userB string) (*Friendship, error) {
+	doc, err := r.friendshipRef(userA, userB).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f Friendship
+	if err := doc.DataTo(&f); err != nil {
+		return nil, fmt.Errorf("unmarshal friendship: %w", err)
+	}
+	return &f, nil
+}
+
+func (r *firestoreRepository) CreateFriendship(ctx context.Context, f *Friendship) error {
+	_, err := r.friendshipRef(f.RequesterID, f.AddresseeID).Create(ctx, f)
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrFriendshipExists
+	}
+	return err
+}
+
+// AcceptFriendship reads both users' friendships in the accepting
+// transaction. Every friendship of a user is in that user's query, so two
+// accepts racing for the same user each read the document the other writes,
+// and Firestore retries one of them against the new count.
+func (r *firestoreRepository) AcceptFriendship(ctx context.Context, addresseeID, requesterID string, at time.Time, limit int) (*Friendship, error) {
+	ref := r.friendshipRef(addresseeID, requesterID)
+	var accepted Friendship
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrFriendRequestNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var f Friendship
+		if err := doc.DataTo(&f); err != nil {
+			return fmt.Errorf("unmarshal friendship: %w", err)
+		}
+		if f.Status != FriendshipPending || f.AddresseeID != addresseeID {
+			return ErrFriendRequestNotFound
+		}
+		for _, id := range []string{addresseeID, requesterID} {
+			docs, err := tx.Documents(r.client.Collection("friendships").Where("users", "array-contains", id)).GetAll()
+			if err != nil {
+				return err
+			}
+			count := 0
+			for _, doc := range docs {
+				if value, _ := doc.DataAt("status"); value == string(FriendshipAccepted) {
+					count++
+				}
+			}
+			if count >= limit {
+				return ErrFriendLimitReached
+			}
+		}
+		f.Status = FriendshipAccepted
+		f.AcceptedAt = &at
+		accepted = f
+		return tx.Set(ref, &f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &accepted, nil
+}
+
+func (r *firestoreRepository) DeleteFriendship(ctx context.Context, userA, userB string) error {
+	_, err := r.friendshipRef(userA, userB).Delete(ctx)
+	return err
+}
+
+func (r *firestoreRepository) ListFriendships(ctx context.Context, userID string) ([]Friendship, error) {
+	iter := r.client.Collection("friendships").Where("users", "array-contains", userID).Documents(ctx)
+	defer iter.Stop()
+
+	var out []Friendship
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var f Friendship
+		if err := doc.DataTo(&f); err != nil {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func (r *firestoreRepository) blocks(userID string) *firestore.CollectionRef {
+	return r.client.Collection("users").Doc(userID).Collection("blocks")
+}
+
+func (r *firestoreRepository) IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	_, err := r.blocks(blockerID).Doc(blockedID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *firestoreRepository) CreateBlock(ctx context.Context, block Block) error {
+	_, err := r.blocks(block.BlockerID).Doc(block.BlockedID).Create(ctx, block)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+func (r *firestoreRepository) DeleteBlock(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.blocks(blockerID).Doc(blockedID).Delete(ctx)
+	return err
+}
+
+func (r *firestoreRepository) ListBlocks(ctx context.Context, userID string) ([]Block, error) {
+	iter := r.blocks(userID).Documents(ctx)
+	defer iter.Stop()
+
+	var out []Block
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var b Block
+		if err := doc.DataTo(&b); err != nil {
+			continue
+		}
+		b.BlockerID = userID
+		b.BlockedID = doc.Ref.ID
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func (r *firestoreRepository) UpdatePrivacy(ctx context.Context, userID string, privacy PrivacySettings) error {
+	_, err := r.client.Collection("profiles").Doc(userID).Set(ctx, map[string]interface{}{
+		"user_id":    userID,
+		"privacy":    privacy,
+		"updated_at": time.Now().UTC(),
+	}, firestore.MergeAll)
+	return err
+}
+
+func (r *firestoreRepository) pointsLedgerRef(userID string) *firestore.CollectionRef {
+	return r.client.Collection("profiles").Doc(userID).Collection("points_ledger")
+}
+
+// pointsLedger appends to profiles/{uid}/points_ledger inside a transaction
+// and moves the profile balance with each entry. readLedger does its only
+// read, so callers finish their own reads first, append, then commit.
+type pointsLedger struct {
+	ref       *firestore.DocumentRef
+	profile   Profile
+	exists    bool
+	updatedAt time.Time
+}
+
+func (r *firestoreRepository) readLedger(tx *firestore.Transaction, userID string) (*pointsLedger, error) {
+	ref := r.client.Collection("profiles").Doc(userID)
+	ledger := &pointsLedger{ref: ref, profile: Profile{UserID: userID}}
+	doc, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.DataTo(&ledger.profile); err != nil {
+		return nil, fmt.Errorf("unmarshal profile: %w", err)
+	}
+	ledger.exists = true
+	return ledger, nil
+}
+
+// append writes entry after the current last one. A balance earned before
+// the ledger existed is first recorded as an opening adjustment so the
+// entries always sum to PointsTotal.
+func (l *pointsLedger) append(tx *firestore.Transaction, entry PointsEntry) (PointsEntry, error) {
+	if l.profile.PointsEntries == 0 && l.profile.PointsTotal != 0 {
+		opening := PointsEntry{
+			ID:         "opening-balance",
+			Type:       PointsAdjust,
+			Amount:     l.profile.PointsTotal,
+			Reason:     "balance before the points ledger",
+			SourceType: PointsSourceLedger,
+			CreatedAt:  entry.CreatedAt,
+		}
+		l.profile.PointsTotal = 0
+		if _, err := l.write(tx, opening); err != nil {
+			return PointsEntry{}, err
+		}
+	}
+	return l.write(tx, entry)
+}
+
+func (l *pointsLedger) write(tx *firestore.Transaction, entry PointsEntry) (PointsEntry, error) {
+	l.profile.PointsEntries++
+	l.profile.PointsTotal += entry.Amount
+	entry.Seq = l.profile.PointsEntries
+	entry.BalanceAfter = l.profile.PointsTotal
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("%08d", entry.Seq)
+	}
+	l.updatedAt = entry.CreatedAt
+	return entry, tx.Create(l.ref.Collection("points_ledger").Doc(entry.ID), entry)
+}
+
+// commit stores the balance and ledger length on the profile.
+func (l *pointsLedger) commit(tx *firestore.Transaction) error {
+	data := map[string]any{
+		"user_id":        l.profile.UserID,
+		"points_total":   l.profile.PointsTotal,
+		"points_entries": l.profile.PointsEntries,
+		"updated_at":     l.updatedAt,
+	}
+	if !l.exists {
+		data["created_at"] = l.updatedAt
+		data["bio"] = ""
+		data["birthdate"] = nil
+	}
+	return tx.Set(l.ref, data, firestore.MergeAll)
+}
+
+func (r *firestoreRepository) AppendPointsEntry(ctx context.Context, userID string, entry PointsEntry) (PointsEntry, error) {
+	var out PointsEntry
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if entry.ID != "" {
+			if _, err := tx.Get(r.pointsLedgerRef(userID).Doc(entry.ID)); err == nil {
+				return ErrPointsEntryExists
+			} else if status.Code(err) != codes.NotFound {
+				return err
+			}
+		}
+		ledger, err := r.readLedger(tx, userID)
+		if err != nil {
+			return err
+		}
+		if out, err = ledger.append(tx, entry); err != nil {
+			return err
+		}
+		return ledger.commit(tx)
+	})
+	return out, err
+}
+
+// ReversePointsEntry stores the reversal as reverse-{entryID}, so an entry
+// can be reversed once.
+func (r *firestoreRepository) ReversePointsEntry(ctx context.Context, userID, entryID, reason string, at time.Time) (PointsEntry, error) {
+	profileRef := r.client.Collection("profiles").Doc(userID)
+	origRef := r.pointsLedgerRef(userID).Doc(entryID)
+	reverseRef := r.pointsLedgerRef(userID).Doc("reverse-" + entryID)
+
+	var out PointsEntry
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(origRef)
+		if status.Code(err) == codes.NotFound {
+			return ErrPointsEntryNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var orig PointsEntry
+		if err := doc.DataTo(&orig); err != nil {
+			return fmt.Errorf("unmarshal points entry: %w", err)
+		}
+		if orig.Type == PointsReverse {
+			return fmt.Errorf("%w: a reversal cannot be reversed", ErrInvalidPoints)
+		}
+		if _, err := tx.Get(reverseRef); err == nil {
+			return ErrPointsEntryExists
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		// Reversing a spend on a reward takes the items back.
+		var (
+			redemption *Redemption
+			invRef     *firestore.DocumentRef
+			owned      int
+		)
+		if orig.SourceType == PointsSourceRedemption && orig.SourceID != "" {
+			doc, err := tx.Get(profileRef.Collection("redemptions").Doc(orig.SourceID))
+			if err != nil && status.Code(err) != codes.NotFound {
+				return err
+			}
+			if err == nil {
+				redemption = &Redemption{}
+				if err := doc.DataTo(redemption); err != nil {
+					return fmt.Errorf("unmarshal redemption: %w", err)
+				}
+				invRef = profileRef.Collection("inventory").Doc(redemption.ItemID)
+				if owned, err = readOwned(tx, invRef); err != nil {
+					return err
+				}
+			}
+		}
+
+		ledger, err := r.readLedger(tx, userID)
+		if err != nil {
+			return err
+		}
+		out, err = ledger.append(tx, PointsEntry{
+			ID:         reverseRef.ID,
+			Type:       PointsReverse,
+			Amount:     -orig.Amount,
+			Reason:     reason,
+			SourceType: PointsSourceLedger,
+			SourceID:   entryID,
+			CreatedAt:  at,
+		})
+		if err != nil {
+			return err
+		}
+		if redemption != nil {
+			if err := tx.Set(invRef, map[string]any{
+				"item_id":    redemption.ItemID,
+				"quantity":   max(owned-redemption.Quantity, 0),
+				"updated_at": at,
+			}); err != nil {
+				return err
+			}
+		}
+		return ledger.commit(tx)
+	})
+	return out, err
+}
+
+func (r *firestoreRepository) ListPointsEntries(ctx context.Context, userID string, before, limit int) ([]PointsEntry, error) {
+	query := r.pointsLedgerRef(userID).OrderBy("seq", firestore.Desc)
+	if before > 0 {
+		query = query.Where("seq", "<", before)
+	}
+	iter := query.Limit(limit).Documents(ctx)
+	defer iter.Stop()
+
+	var entries []PointsEntry
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry PointsEntry
+		if err := doc.DataTo(&entry); err != nil {
+			continue
+		}
+		entry.ID = doc.Ref.ID
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (r *firestoreRepository) ListPointsEntriesBetween(ctx context.Context, userID string, from, to time.Time) ([]PointsEntry, error) {
+	iter := r.pointsLedgerRef(userID).
+		Where("created_at", ">=", from).
+		Where("created_at", "<", to).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var entries []PointsEntry
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry PointsEntry
+		if err := doc.DataTo(&entry); err != nil {
+			continue
+		}
+		entry.ID = doc.Ref.ID
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Redeem keeps one profiles/{uid}/redemptions/{idempotencyKey} document per
+// request and holds the items in profiles/{uid}/inventory/{itemID}.
+func (r *firestoreRepository) Redeem(ctx context.Context, userID string, redemption Redemption, maxOwned int) (*RedeemResponse, error) {
+	profileRef := r.client.Collection("profiles").Doc(userID)
+	redemptionRef := profileRef.Collection("redemptions").Doc(redemption.IdempotencyKey)
+	invRef := profileRef.Collection("inventory").Doc(redemption.ItemID)
+
+	var resp *RedeemResponse
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var stored *Redemption
+		if doc, err := tx.Get(redemptionRef); err == nil {
+			stored = &Redemption{}
+			if err := doc.DataTo(stored); err != nil {
+				return fmt.Errorf("unmarshal redemption: %w", err)
+			}
+			if stored.ItemID != redemption.ItemID || stored.Quantity != redemption.Quantity {
+				return ErrIdempotencyKeyReused
+			}
+			stored.IdempotencyKey = redemption.IdempotencyKey
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		owned, err := readOwned(tx, invRef)
+		if err != nil {
+			return err
+		}
+		ledger, err := r.readLedger(tx, userID)
+		if err != nil {
+			return err
+		}
+		if stored != nil {
+			resp = &RedeemResponse{Redemption: *stored, PointsTotal: ledger.profile.PointsTotal, Owned: owned, Replayed: true}
+			return nil
+		}
+
+		if maxOwned > 0 && owned+redemption.Quantity > maxOwned {
+			return ErrRewardLimitReached
+		}
+		if ledger.profile.PointsTotal < redemption.Cost {
+			return ErrInsufficientPoints
+		}
+		entry, err := ledger.append(tx, PointsEntry{
+			Type:       PointsSpend,
+			Amount:     -redemption.Cost,
+			Reason:     fmt.Sprintf("redeem %d x %s", redemption.Quantity, redemption.ItemID),
+			SourceType: PointsSourceRedemption,
+			SourceID:   redemption.IdempotencyKey,
+			CreatedAt:  redemption.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		redemption.EntryID = entry.ID
+		if err := tx.Create(redemptionRef, redemption); err != nil {
+			return err
+		}
+		if err := tx.Set(invRef, map[string]any{
+			"item_id":    redemption.ItemID,
+			"quantity":   owned + redemption.Quantity,
+			"updated_at": redemption.CreatedAt,
+		}); err != nil {
+			return err
+		}
+		if err := ledger.commit(tx); err != nil {
+			return err
+		}
+		resp = &RedeemResponse{Redemption: redemption, PointsTotal: ledger.profile.PointsTotal, Owned: owned + redemption.Quantity}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func readOwned(tx *firestore.Transaction, ref *firestore.DocumentRef) (int, error) {
+	doc, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var item struct {
+		Quantity int `firestore:"quantity"`
+	}
+	if err := doc.DataTo(&item); err != nil {
+		return 0, fmt.Errorf("unmarshal inventory: %w", err)
+	}
+	return item.Quantity, nil
+}
+
+func (r *firestoreRepository) GetInventory(ctx context.Context, userID string) (map[string]int, error) {
+	iter := r.client.Collection("profiles").Doc(userID).Collection("inventory").Documents(ctx)
+	defer iter.Stop()
+
+	owned := map[string]int{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var item struct {
+			Quantity int `firestore:"quantity"`
+		}
+		if err := doc.DataTo(&item); err != nil {
+			continue
+		}
+		if item.Quantity > 0 {
+			owned[doc.Ref.ID] = item.Quantity
+		}
+	}
+	return owned, nil
+}
+
+func (r *firestoreRepository) badgesRef(userID string) *firestore.CollectionRef {
+	return r.client.Collection("profiles").Doc(userID).Collection("badges")
+}
+
+func (r *firestoreRepository) ListBadgeAwards(ctx context.Context, userID string) ([]BadgeAward, error) {
+	iter := r.badgesRef(userID).Documents(ctx)
+	defer iter.Stop()
+
+	var awards []BadgeAward
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var award BadgeAward
+		if err := doc.DataTo(&award); err != nil {
+			continue
+		}
+		award.BadgeID = doc.Ref.ID
+		awards = append(awards, award)
+	}
+	return awards, nil
+}
+
+// AwardBadges creates one document per badge, so concurrent evaluations
+// cannot overwrite the first award time.
+func (r *firestoreRepository) AwardBadges(ctx context.Context, userID string, awards []BadgeAward) error {
+	for _, award := range awards {
+		_, err := r.badgesRef(userID).Doc(award.BadgeID).Create(ctx, award)
+		if err != nil && status.Code(err) != codes.AlreadyExists {
+			return fmt.Errorf("award badge %s: %w", award.BadgeID, err)
+		}
+	}
+	return nil
+}
+
+func (r *firestoreRepository) MarkBadgesSeen(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.client.Collection("profiles").Doc(userID).Set(ctx, map[string]interface{}{
+		"user_id":        userID,
+		"badges_seen_at": at,
+		"updated_at":     at,
+	}, firestore.MergeAll)
+	return err
+}
+
+// webhookEventsCollection holds one marker per processed webhook delivery.
+// Markers carry no user data, so account deletion leaves them in place.
+const webhookEventsCollection = "webhook_events"
+
+func (r *firestoreRepository) webhookEventRef(eventID string) *firestore.DocumentRef {
+	return r.client.Collection(webhookEventsCollection).Doc(eventID)
+}
+
+func (r *firestoreRepository) SyncClerkUser(ctx context.Context, eventID, eventType string, u ClerkUser) (SyncOutcome, error) {
+	markerRef := r.webhookEventRef(eventID)
+	profileRef := r.client.Collection("profiles").Doc(u.UserID)
+	deletionRef := r.client.Collection("account_deletions").Doc(u.UserID)
+
+	var outcome SyncOutcome
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		now := time.Now().UTC()
+		if _, err := tx.Get(markerRef); err == nil {
+			outcome = SyncDuplicate
+			return nil
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		profileSnap, err := tx.Get(profileRef)
+		exists := err == nil
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		var profile Profile
+		if exists {
+			if err := profileSnap.DataTo(&profile); err != nil {
+				return fmt.Errorf("unmarshal profile: %w", err)
+			}
+		}
+		// A user deleted after this event was sent stays deleted.
+		var deletedAt time.Time
+		if snap, err := tx.Get(deletionRef); err == nil {
+			if v, err := snap.DataAt("requested_at"); err == nil {
+				deletedAt, _ = v.(time.Time)
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		outcome = SyncApplied
+		if (profile.ClerkUpdatedAt != nil && u.UpdatedAt.Before(*profile.ClerkUpdatedAt)) ||
+			(!deletedAt.IsZero() && u.UpdatedAt.Before(deletedAt)) {
+			outcome = SyncStale
+		}
+
+		if outcome == SyncApplied {
+			data := map[string]interface{}{
+				"user_id":          u.UserID,
+				"email":            u.Email,
+				"display_name":     u.DisplayName,
+				"avatar_url":       u.AvatarURL,
+				"clerk_updated_at": u.UpdatedAt,
+				"updated_at":       now,
+			}
+			if !exists {
+				data["created_at"] = now
+				data["points_total"] = 0
+			}
+			if err := tx.Set(profileRef, data, firestore.MergeAll); err != nil {
+				return err
+			}
+		}
+		return tx.Set(markerRef, webhookEvent(eventType, outcome, now))
+	})
+	if err != nil {
+		return "", err
+	}
+	return outcome, nil
+}
+
+func (r *firestoreRepository) RecordWebhookEvent(ctx context.Context, eventID, eventType string) (bool, error) {
+	_, err := r.webhookEventRef(eventID).Create(ctx, webhookEvent(eventType, SyncApplied, time.Now().UTC()))
+	if status.Code(err) == codes.AlreadyExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func webhookEvent(eventType string, outcome SyncOutcome, at time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"type":         eventType,
+		"outcome":      string(outcome),
+		"processed_at": at,
+	}
+}
+
+func (r *firestoreRepository) UpdatePreferences(ctx context.Context, userID string, update func(preferences.Preferences) (preferences.Preferences, error)) (*preferences.Preferences, error) {
+	ref := r.client.Collection("profiles").Doc(userID)
+	var saved preferences.Preferences
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		profile := defaultProfile(userID)
+		snap, err := tx.Get(ref)
+		exists := err == nil
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if exists {
+			if err := snap.DataTo(profile); err != nil {
+				return fmt.Errorf("unmarshal profile: %w", err)
+			}
+		}
+
+		current := resolvePreferences(profile)
+		next, err := update(current)
+		if err != nil {
+			return err
+		}
+		saved = next
+		if next.Revision == current.Revision {
+			return nil // nothing to write
+		}
+
+		data := map[string]interface{}{
+			"user_id":                userID,
+			preferences.ProfileField: next, // Timezone is firestore:"-"
+			timezone.ProfileField:    next.Timezone,
+			"updated_at":             *next.UpdatedAt,
+		}
+		if !exists {
+			data["created_at"] = *next.UpdatedAt
+			data["points_total"] = 0
+		}
+		return tx.Set(ref, data, firestore.MergeAll)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
@@ -0,0 +1,512 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// maxFriends caps accepted friends per user so leaderboards stay cheap.
+	maxFriends = 100
+	// friendCodeAlphabet omits look-alike characters (0/O, 1/I).
+	friendCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	friendCodeLength   = 8
+	// leaderboardConcurrency bounds parallel per-friend reads.
+	leaderboardConcurrency = 8
+)
+
+var defaultPrivacy = PrivacySettings{
+	FocusMinutes:   VisibilityFriends,
+	Streak:         VisibilityFriends,
+	Points:         VisibilityFriends,
+	Badges:         VisibilityFriends,
+	FriendRequests: true,
+}
+
+// GetFriendCode returns the user's friend code, creating one on first use.
+func (s *service) GetFriendCode(ctx context.Context, userID string) (string, error) {
+	profile, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if profile.FriendCode != "" {
+		return profile.FriendCode, nil
+	}
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := newFriendCode()
+		if err != nil {
+			return "", err
+		}
+		err = s.repo.ReserveFriendCode(ctx, userID, code)
+		if errors.Is(err, ErrFriendCodeTaken) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("reserve friend code: %w", err)
+		}
+		return code, nil
+	}
+	return "", errors.New("could not allocate a friend code")
+}
+
+// SendFriendRequest asks the user behind a handle or code to be friends. A
+// request to someone who already asked us accepts theirs instead, and
+// repeating an outgoing request is a no-op.
+func (s *service) SendFriendRequest(ctx context.Context, userID string, input FriendRequestInput) (*Friendship, error) {
+	targetID, err := s.resolveFriendTarget(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if targetID == userID {
+		return nil, fmt.Errorf("%w: cannot befriend yourself", ErrInvalidFriendRequest)
+	}
+	if hidden, err := s.blockedEitherWay(ctx, userID, targetID); err != nil {
+		return nil, err
+	} else if hidden {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := s.repo.GetFriendship(ctx, userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		switch {
+		case existing.Status == FriendshipAccepted:
+			return nil, ErrAlreadyFriends
+		case existing.RequesterID == userID:
+			return existing, nil
+		default:
+			return s.AcceptFriendRequest(ctx, userID, targetID)
+		}
+	}
+
+	target, err := s.repo.GetProfile(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !effectivePrivacy(target.Privacy).FriendRequests {
+		return nil, ErrFriendRequestsClosed
+	}
+	friendship := &Friendship{
+		Users:       friendPair(userID, targetID),
+		RequesterID: userID,
+		AddresseeID: targetID,
+		Status:      FriendshipPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.CreateFriendship(ctx, friendship); err != nil {
+		if errors.Is(err, ErrFriendshipExists) {
+			// Lost a race with the other user's request; resolve against theirs.
+			return s.SendFriendRequest(ctx, userID, input)
+		}
+		return nil, fmt.Errorf("create friendship: %w", err)
+	}
+	return friendship, nil
+}
+
+// AcceptFriendRequest accepts a pending request from requesterID. The
+// repository checks the friend limit in the transaction that accepts it.
+func (s *service) AcceptFriendRequest(ctx context.Context, userID, requesterID string) (*Friendship, error) {
+	friendship, err := s.repo.AcceptFriendship(ctx, userID, requesterID, time.Now().UTC(), maxFriends)
+	switch {
+	case errors.Is(err, ErrFriendRequestNotFound), errors.Is(err, ErrFriendLimitReached):
+		return nil, err
+	case err != nil:
+		return nil, fmt.Errorf("accept friendship: %w", err)
+	}
+	return friendship, nil
+}
+
+// DeclineFriendRequest drops a pending request in either direction, so the
+// requester can also use it to cancel.
+func (s *service) DeclineFriendRequest(ctx context.Context, userID, otherID string) error {
+	friendship, err := s.repo.GetFriendship(ctx, userID, otherID)
+	if err != nil {
+		return err
+	}
+	if friendship == nil || friendship.Status != FriendshipPending {
+		return ErrFriendRequestNotFound
+	}
+	return s.repo.DeleteFriendship(ctx, userID, otherID)
+}
+
+// RemoveFriend ends an accepted friendship.
+func (s *service) RemoveFriend(ctx context.Context, userID, friendID string) error {
+	friendship, err := s.repo.GetFriendship(ctx, userID, friendID)
+	if err != nil {
+		return err
+	}
+	if friendship == nil || friendship.Status != FriendshipAccepted {
+		return ErrNotFriends
+	}
+	return s.repo.DeleteFriendship(ctx, userID, friendID)
+}
+
+// ListFriends returns accepted friends, most recent first.
+func (s *service) ListFriends(ctx context.Context, userID string) ([]Friend, error) {
+	friendships, err := s.repo.ListFriendships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	friends := []Friend{}
+	for _, f := range friendships {
+		if f.Status != FriendshipAccepted {
+			continue
+		}
+		since := f.CreatedAt
+		if f.AcceptedAt != nil {
+			since = *f.AcceptedAt
+		}
+		friends = append(friends, Friend{UserID: f.Other(userID), Since: since})
+	}
+	sort.SliceStable(friends, func(i, j int) bool { return friends[i].Since.After(friends[j].Since) })
+	return friends, nil
+}
+
+// ListFriendRequests returns pending requests, newest first.
+func (s *service) ListFriendRequests(ctx context.Context, userID string) (*FriendRequestsResponse, error) {
+	friendships, err := s.repo.ListFriendships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &FriendRequestsResponse{Incoming: []FriendRequest{}, Outgoing: []FriendRequest{}}
+	for _, f := range friendships {
+		if f.Status != FriendshipPending {
+			continue
+		}
+		req := FriendRequest{UserID: f.Other(userID), CreatedAt: f.CreatedAt}
+		if f.AddresseeID == userID {
+			resp.Incoming = append(resp.Incoming, req)
+		} else {
+			resp.Outgoing = append(resp.Outgoing, req)
+		}
+	}
+	for _, list := range [][]FriendRequest{resp.Incoming, resp.Outgoing} {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	}
+	return resp, nil
+}
+
+// BlockUser blocks targetID and removes any friendship or pending request
+// between the two. Blocking twice is a no-op.
+func (s *service) BlockUser(ctx context.Context, userID, targetID string) error {
+	if targetID == "" || targetID == userID {
+		return fmt.Errorf("%w: cannot block yourself", ErrInvalidFriendRequest)
+	}
+	if err := s.repo.CreateBlock(ctx, Block{BlockerID: userID, BlockedID: targetID, CreatedAt: time.Now().UTC()}); err != nil {
+		return fmt.Errorf("create block: %w", err)
+	}
+	if err := s.repo.DeleteFriendship(ctx, userID, targetID); err != nil {
+		return fmt.Errorf("delete friendship: %w", err)
+	}
+	return nil
+}
+
+// UnblockUser lifts a block. It does not restore the previous friendship.
+func (s *service) UnblockUser(ctx context.Context, userID, targetID string) error {
+	return s.repo.DeleteBlock(ctx, userID, targetID)
+}
+
+// ListBlockedUsers returns the users blocked by userID, newest first.
+func (s *service) ListBlockedUsers(ctx context.Context, userID string) ([]Block, error) {
+	blocks, err := s.repo.ListBlocks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].CreatedAt.After(blocks[j].CreatedAt) })
+	if blocks == nil {
+		blocks = []Block{}
+	}
+	return blocks, nil
+}
+
+// GetPrivacy returns the user's privacy settings with defaults applied.
+func (s *service) GetPrivacy(ctx context.Context, userID string) (*PrivacySettings, error) {
+	profile, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	privacy := effectivePrivacy(profile.Privacy)
+	return &privacy, nil
+}
+
+// UpdatePrivacy applies patch and stores the full settings.
+func (s *service) UpdatePrivacy(ctx context.Context, userID string, patch PrivacyPatch) (*PrivacySettings, error) {
+	privacy, err := s.GetPrivacy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []struct {
+		dst   *Visibility
+		value *Visibility
+	}{
+		{&privacy.FocusMinutes, patch.FocusMinutes},
+		{&privacy.Streak, patch.Streak},
+		{&privacy.Points, patch.Points},
+		{&privacy.Badges, patch.Badges},
+	} {
+		if field.value == nil {
+			continue
+		}
+		switch *field.value {
+		case VisibilityPrivate, VisibilityFriends, VisibilityPublic:
+		default:
+			return nil, fmt.Errorf("%w: visibility must be private, friends or public", ErrInvalidPrivacy)
+		}
+		*field.dst = *field.value
+	}
+	if patch.FriendRequests != nil {
+		privacy.FriendRequests = *patch.FriendRequests
+	}
+	if err := s.repo.UpdatePrivacy(ctx, userID, *privacy); err != nil {
+		return nil, fmt.Errorf("update privacy: %w", err)
+	}
+	return privacy, nil
+}
+
+// GetFriendLeaderboard ranks the user and their friends. Friends who keep the
+// metric private are left out; the user always appears. Every metric is
+// measured over the current week (Monday-based) or month in the caller's
+// timezone: minutes summed, the longest run of active days, or points earned.
+func (s *service) GetFriendLeaderboard(ctx context.Context, userID string, input LeaderboardInput) (*Leaderboard, error) {
+	period := input.Period
+	if period == "" {
+		period = LeaderboardWeek
+	}
+	metric := input.Metric
+	if metric == "" {
+		metric = LeaderboardMinutes
+	}
+	loc := resolveLocation(input.Timezone)
+	today := truncateToDay(time.Now().In(loc))
+	var start, end time.Time
+	switch period {
+	case LeaderboardWeek:
+		start = getWeekStart(today)
+		end = start.AddDate(0, 0, 7)
+	case LeaderboardMonth:
+		start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	default:
+		return nil, fmt.Errorf("%w: period must be week or month", ErrInvalidLeaderboard)
+	}
+	switch metric {
+	case LeaderboardMinutes, LeaderboardStreak, LeaderboardPoints:
+	default:
+		return nil, fmt.Errorf("%w: metric must be minutes, streak or points", ErrInvalidLeaderboard)
+	}
+
+	friends, err := s.ListFriends(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	participants := make([]string, 0, len(friends)+1)
+	participants = append(participants, userID)
+	for _, f := range friends {
+		participants = append(participants, f.UserID)
+	}
+
+	entries := make([]*LeaderboardEntry, len(participants))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(leaderboardConcurrency)
+	for i, id := range participants {
+		g.Go(func() error {
+			profile, err := s.repo.GetProfile(gctx, id)
+			if err != nil {
+				return err
+			}
+			if id != userID && !metricVisible(effectivePrivacy(profile.Privacy), metric) {
+				return nil
+			}
+			entry := &LeaderboardEntry{UserID: id, IsMe: id == userID}
+			switch metric {
+			case LeaderboardMinutes, LeaderboardStreak:
+				minsByDate, err := s.repo.GetDailyMinutesByDate(gctx, id, start, end, loc)
+				if err != nil {
+					return err
+				}
+				if metric == LeaderboardStreak {
+					entry.Value = longestActiveRun(minsByDate, start, end)
+					break
+				}
+				for _, mins := range minsByDate {
+					entry.Value += mins
+				}
+			case LeaderboardPoints:
+				ledger, err := s.repo.ListPointsEntriesBetween(gctx, id, start, end)
+				if err != nil {
+					return err
+				}
+				entry.Value = pointsEarned(ledger)
+			}
+			entries[i] = entry
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return &Leaderboard{
+		Period:   period,
+		Metric:   metric,
+		From:     start.Format("2006-01-02"),
+		To:       end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone: loc.String(),
+		Entries:  rankEntries(entries),
+	}, nil
+}
+
+func (s *service) resolveFriendTarget(ctx context.Context, input FriendRequestInput) (string, error) {
+	handle := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(input.Handle), "@"))
+	code := normalizeFriendCode(input.Code)
+	var (
+		targetID string
+		err      error
+	)
+	switch {
+	case handle != "" && code != "":
+		return "", fmt.Errorf("%w: send either handle or code", ErrInvalidFriendRequest)
+	case handle != "":
+		targetID, err = s.repo.ResolveHandle(ctx, handle)
+	case code != "":
+		targetID, err = s.repo.ResolveFriendCode(ctx, code)
+	default:
+		return "", fmt.Errorf("%w: handle or code is required", ErrInvalidFriendRequest)
+	}
+	if err != nil {
+		return "", err
+	}
+	if targetID == "" {
+		return "", ErrUserNotFound
+	}
+	return targetID, nil
+}
+
+func (s *service) blockedEitherWay(ctx context.Context, a, b string) (bool, error) {
+	for _, pair := range [][2]string{{a, b}, {b, a}} {
+		blocked, err := s.repo.IsBlocked(ctx, pair[0], pair[1])
+		if err != nil || blocked {
+			return blocked, err
+		}
+	}
+	return false, nil
+}
+
+// longestActiveRun returns the longest run of consecutive days in
+// [start, end) that have a session in minsByDate.
+func longestActiveRun(minsByDate map[string]int, start, end time.Time) int {
+	longest, run := 0, 0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if _, ok := minsByDate[day.Format("2006-01-02")]; !ok {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// pointsEarned sums earn entries less the reversals among entries, so
+// spending points or an admin adjustment does not move a ranking.
+func pointsEarned(entries []PointsEntry) int {
+	earned := 0
+	for _, e := range entries {
+		if e.Type == PointsEarn || (e.Type == PointsReverse && e.Amount < 0) {
+			earned += e.Amount
+		}
+	}
+	return earned
+}
+
+// rankEntries drops nil entries, sorts by value (ties by user ID) and assigns
+// competition ranks (1, 1, 3).
+func rankEntries(entries []*LeaderboardEntry) []LeaderboardEntry {
+	ranked := make([]LeaderboardEntry, 0, len(entries))
+	for _, e := range entries {
+		if e != nil {
+			ranked = append(ranked, *e)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Value != ranked[j].Value {
+			return ranked[i].Value > ranked[j].Value
+		}
+		return ranked[i].UserID < ranked[j].UserID
+	})
+	for i := range ranked {
+		if i > 0 && ranked[i].Value == ranked[i-1].Value {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+	}
+	return ranked
+}
+
+func effectivePrivacy(p *PrivacySettings) PrivacySettings {
+	if p == nil {
+		return defaultPrivacy
+	}
+	out := *p
+	if out.FocusMinutes == "" {
+		out.FocusMinutes = defaultPrivacy.FocusMinutes
+	}
+	if out.Streak == "" {
+		out.Streak = defaultPrivacy.Streak
+	}
+	if out.Points == "" {
+		out.Points = defaultPrivacy.Points
+	}
+	if out.Badges == "" {
+		out.Badges = defaultPrivacy.Badges
+	}
+	return out
+}
+
+// metricVisible reports whether friends may see metric. Public fields are
+// visible to friends too.
+func metricVisible(p PrivacySettings, metric LeaderboardMetric) bool {
+	switch metric {
+	case LeaderboardMinutes:
+		return p.FocusMinutes != VisibilityPrivate
+	case LeaderboardStreak:
+		return p.Streak != VisibilityPrivate
+	default:
+		return p.Points != VisibilityPrivate
+	}
+}
+
+// friendPair returns the two IDs sorted, the canonical key of a friendship.
+func friendPair(a, b string) []string {
+	if b < a {
+		a, b = b, a
+	}
+	return []string{a, b}
+}
+
+func newFriendCode() (string, error) {
+	buf := make([]byte, friendCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = friendCodeAlphabet[int(b)%len(friendCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// normalizeFriendCode accepts codes typed in lower case or with separators.
+func normalizeFriendCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,385 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memFriends is an in-memory FriendRepository.
+type memFriends struct {
+	mu          sync.Mutex
+	codes       map[string]string
+	handles     map[string]string
+	friendships map[string]Friendship
+	blocks      map[[2]string]Block
+	privacy     map[string]PrivacySettings
+}
+
+func newMemFriends() *memFriends {
+	return &memFriends{
+		codes:       map[string]string{},
+		handles:     map[string]string{},
+		friendships: map[string]Friendship{},
+		blocks:      map[[2]string]Block{},
+		privacy:     map[string]PrivacySettings{},
+	}
+}
+
+func pairKey(a, b string) string {
+	pair := friendPair(a, b)
+	return pair[0] + "_" + pair[1]
+}
+
+func (m *memFriends) ReserveFriendCode(_ context.Context, userID, code string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.codes[code]; ok {
+		return ErrFriendCodeTaken
+	}
+	m.codes[code] = userID
+	return nil
+}
+
+func (m *memFriends) ResolveFriendCode(_ context.Context, code string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.codes[code], nil
+}
+
+func (m *memFriends) ResolveHandle(_ context.Context, handle string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.handles[handle], nil
+}
+
+func (m *memFriends) GetFriendship(_ context.Context, a, b string) (*Friendship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.friendships[pairKey(a, b)]
+	if !ok {
+		return nil, nil
+	}
+	return &f, nil
+}
+
+func (m *memFriends) CreateFriendship(_ context.Context, f *Friendship) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := pairKey(f.RequesterID, f.AddresseeID)
+	if _, ok := m.friendships[key]; ok {
+		return ErrFriendshipExists
+	}
+	m.friendships[key] = *f
+	return nil
+}
+
+func (m *memFriends) AcceptFriendship(_ context.Context, addresseeID, requesterID string, at time.Time, limit int) (*Friendship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := pairKey(addresseeID, requesterID)
+	f, ok := m.friendships[key]
+	if !ok || f.Status != FriendshipPending || f.AddresseeID != addresseeID {
+		return nil, ErrFriendRequestNotFound
+	}
+	for _, id := range []string{addresseeID, requesterID} {
+		count := 0
+		for _, other := range m.friendships {
+			if other.Status == FriendshipAccepted && (other.RequesterID == id || other.AddresseeID == id) {
+				count++
+			}
+		}
+		if count >= limit {
+			return nil, ErrFriendLimitReached
+		}
+	}
+	f.Status = FriendshipAccepted
+	f.AcceptedAt = &at
+	m.friendships[key] = f
+	return &f, nil
+}
+
+func (m *memFriends) DeleteFriendship(_ context.Context, a, b string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.friendships, pairKey(a, b))
+	return nil
+}
+
+func (m *memFriends) ListFriendships(_ context.Context, userID string) ([]Friendship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Friendship
+	for _, f := range m.friendships {
+		if f.RequesterID == userID || f.AddresseeID == userID {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func (m *memFriends) IsBlocked(_ context.Context, blockerID, blockedID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.blocks[[2]string{blockerID, blockedID}]
+	return ok, nil
+}
+
+func (m *memFriends) CreateBlock(_ context.Context, block Block) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocks[[2]string{block.BlockerID, block.BlockedID}] = block
+	return nil
+}
+
+func (m *memFriends) DeleteBlock(_ context.Context, blockerID, blockedID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blocks, [2]string{blockerID, blockedID})
+	return nil
+}
+
+func (m *memFriends) ListBlocks(_ context.Context, userID string) ([]Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Block
+	for key, b := range m.blocks {
+		if key[0] == userID {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (m *memFriends) UpdatePrivacy(_ context.Context, userID string, privacy PrivacySettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.privacy[userID] = privacy
+	return nil
+}
+
+// earnedPoints serves each user's points as one earn entry, plus a spend
+// that leaderboards must ignore; other PointsRepository methods are unset.
+type earnedPoints struct {
+	PointsRepository
+	points map[string]int
+}
+
+func (e earnedPoints) ListPointsEntriesBetween(_ context.Context, userID string, from, _ time.Time) ([]PointsEntry, error) {
+	return []PointsEntry{
+		{Type: PointsEarn, Amount: e.points[userID], CreatedAt: from},
+		{Type: PointsSpend, Amount: -10, CreatedAt: from},
+	}, nil
+}
+
+func newFriendsRepo(friends *memFriends, points map[string]int) *fakeRepo {
+	return &fakeRepo{
+		FriendRepository: friends,
+		PointsRepository: earnedPoints{points: points},
+		getProfileFn: func(_ context.Context, userID string) (*Profile, error) {
+			friends.mu.Lock()
+			defer friends.mu.Unlock()
+			profile := &Profile{UserID: userID, PointsTotal: points[userID]}
+			if p, ok := friends.privacy[userID]; ok {
+				profile.Privacy = &p
+			}
+			for code, id := range friends.codes {
+				if id == userID {
+					profile.FriendCode = code
+				}
+			}
+			return profile, nil
+		},
+	}
+}
+
+func TestFriendRequestFlow(t *testing.T) {
+	friends := newMemFriends()
+	friends.handles["bob"] = "bob-id"
+	svc := NewService(newFriendsRepo(friends, nil), nil)
+	ctx := context.Background()
+
+	code, err := svc.GetFriendCode(ctx, "alice-id")
+	if err != nil || len(code) != friendCodeLength {
+		t.Fatalf("GetFriendCode() = %q, %v", code, err)
+	}
+	if again, _ := svc.GetFriendCode(ctx, "alice-id"); again != code {
+		t.Errorf("GetFriendCode() second call = %q, want %q", again, code)
+	}
+
+	req, err := svc.SendFriendRequest(ctx, "alice-id", FriendRequestInput{Handle: "@Bob"})
+	if err != nil || req.Status != FriendshipPending || req.AddresseeID != "bob-id" {
+		t.Fatalf("SendFriendRequest() = %+v, %v", req, err)
+	}
+	if _, err := svc.AcceptFriendRequest(ctx, "alice-id", "bob-id"); !errors.Is(err, ErrFriendRequestNotFound) {
+		t.Errorf("requester accept error = %v, want ErrFriendRequestNotFound", err)
+	}
+	requests, _ := svc.ListFriendRequests(ctx, "bob-id")
+	if len(requests.Incoming) != 1 || requests.Incoming[0].UserID != "alice-id" || len(requests.Outgoing) != 0 {
+		t.Errorf("ListFriendRequests() = %+v", requests)
+	}
+
+	// Bob asking back by code accepts Alice's request.
+	accepted, err := svc.SendFriendRequest(ctx, "bob-id", FriendRequestInput{Code: " " + code[:4] + "-" + code[4:]})
+	if err != nil || accepted.Status != FriendshipAccepted || accepted.AcceptedAt == nil {
+		t.Fatalf("reverse SendFriendRequest() = %+v, %v", accepted, err)
+	}
+	if _, err := svc.SendFriendRequest(ctx, "alice-id", FriendRequestInput{Handle: "bob"}); !errors.Is(err, ErrAlreadyFriends) {
+		t.Errorf("repeat request error = %v, want ErrAlreadyFriends", err)
+	}
+	list, _ := svc.ListFriends(ctx, "alice-id")
+	if len(list) != 1 || list[0].UserID != "bob-id" {
+		t.Errorf("ListFriends() = %+v", list)
+	}
+
+	if err := svc.RemoveFriend(ctx, "bob-id", "alice-id"); err != nil {
+		t.Fatalf("RemoveFriend() error = %v", err)
+	}
+	if err := svc.RemoveFriend(ctx, "bob-id", "alice-id"); !errors.Is(err, ErrNotFriends) {
+		t.Errorf("second RemoveFriend() error = %v, want ErrNotFriends", err)
+	}
+	if _, err := svc.SendFriendRequest(ctx, "alice-id", FriendRequestInput{Handle: "nobody"}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("unknown handle error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := svc.SendFriendRequest(ctx, "alice-id", FriendRequestInput{Handle: "bob", Code: code}); !errors.Is(err, ErrInvalidFriendRequest) {
+		t.Errorf("handle and code error = %v, want ErrInvalidFriendRequest", err)
+	}
+}
+
+func TestFriendBlocksAndPrivacy(t *testing.T) {
+	friends := newMemFriends()
+	friends.handles["bob"] = "bob-id"
+	friends.handles["carol"] = "carol-id"
+	svc := NewService(newFriendsRepo(friends, nil), nil)
+	ctx := context.Background()
+
+	if _, err := svc.SendFriendRequest(ctx, "alice-id", FriendRequestInput{Handle: "bob"}); err != nil {
+		t.Fatalf("SendFriendRequest() error = %v", err)
+	}
+	if err := svc.BlockUser(ctx, "bob-id", "alice-id"); err != nil {
+		t.Fatalf("BlockUser() error = %v", err)
+	}
+	if f, _ := friends.GetFriendship(ctx, "alice-id", "bob-id"); f != nil {
+		t.Errorf("pending request survived block: %+v", f)
+	}
+	// A blocked user cannot tell the blocker apart from a missing user.
+	if _, err := svc.SendFriendRequest(ctx, "alice-id", FriendRequestInput{Handle: "bob"}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("blocked request error = %v, want ErrUserNotFound", err)
+	}
+	if blocked, _ := svc.ListBlockedUsers(ctx, "bob-id"); len(blocked) != 1 || blocked[0].BlockedID != "alice-id" {
+		t.Errorf("ListBlockedUsers() = %+v", blocked)
+	}
+
+	closed := false
+	if _, err := svc.UpdatePrivacy(ctx, "carol-id", PrivacyPatch{FriendRequests: &closed}); err != nil {
+		t.Fatalf("UpdatePrivacy() error = %v", err)
+	}
+	if _, err := svc.SendFriendRequest(ctx, "alice-id", FriendRequestInput{Handle: "carol"}); !errors.Is(err, ErrFriendRequestsClosed) {
+		t.Errorf("closed requests error = %v, want ErrFriendRequestsClosed", err)
+	}
+	everyone := Visibility("everyone")
+	if _, err := svc.UpdatePrivacy(ctx, "carol-id", PrivacyPatch{Points: &everyone}); !errors.Is(err, ErrInvalidPrivacy) {
+		t.Errorf("invalid visibility error = %v, want ErrInvalidPrivacy", err)
+	}
+	privacy, _ := svc.GetPrivacy(ctx, "carol-id")
+	if privacy.FriendRequests || privacy.Points != VisibilityFriends {
+		t.Errorf("GetPrivacy() = %+v", privacy)
+	}
+}
+
+func TestAcceptFriendRequestLimit(t *testing.T) {
+	friends := newMemFriends()
+	now := time.Now().UTC()
+	for i := 0; i < maxFriends; i++ {
+		id := fmt.Sprintf("friend-%d", i)
+		friends.friendships[pairKey("alice-id", id)] = Friendship{
+			Users: friendPair("alice-id", id), RequesterID: id, AddresseeID: "alice-id",
+			Status: FriendshipAccepted, CreatedAt: now,
+		}
+	}
+	friends.friendships[pairKey("alice-id", "bob-id")] = Friendship{
+		Users: friendPair("alice-id", "bob-id"), RequesterID: "bob-id", AddresseeID: "alice-id",
+		Status: FriendshipPending, CreatedAt: now,
+	}
+	svc := NewService(newFriendsRepo(friends, nil), nil)
+
+	if _, err := svc.AcceptFriendRequest(context.Background(), "alice-id", "bob-id"); !errors.Is(err, ErrFriendLimitReached) {
+		t.Fatalf("AcceptFriendRequest() error = %v, want ErrFriendLimitReached", err)
+	}
+	if f, _ := friends.GetFriendship(context.Background(), "alice-id", "bob-id"); f.Status != FriendshipPending {
+		t.Errorf("request status = %s, want pending", f.Status)
+	}
+}
+
+func TestGetFriendLeaderboard(t *testing.T) {
+	friends := newMemFriends()
+	now := time.Now().UTC()
+	for _, id := range []string{"bob-id", "carol-id", "dave-id"} {
+		friends.friendships[pairKey("alice-id", id)] = Friendship{
+			Users:       friendPair("alice-id", id),
+			RequesterID: "alice-id",
+			AddresseeID: id,
+			Status:      FriendshipAccepted,
+			CreatedAt:   now,
+		}
+	}
+	friends.privacy["dave-id"] = PrivacySettings{Points: VisibilityPrivate}
+	friends.privacy["alice-id"] = PrivacySettings{Points: VisibilityPrivate}
+	repo := newFriendsRepo(friends, map[string]int{"alice-id": 50, "bob-id": 80, "carol-id": 50, "dave-id": 999})
+	svc := NewService(repo, nil)
+
+	board, err := svc.GetFriendLeaderboard(context.Background(), "alice-id", LeaderboardInput{Metric: LeaderboardPoints, Timezone: "Asia/Jakarta"})
+	if err != nil {
+		t.Fatalf("GetFriendLeaderboard() error = %v", err)
+	}
+	want := []LeaderboardEntry{
+		{Rank: 1, UserID: "bob-id", Value: 80},
+		{Rank: 2, UserID: "alice-id", Value: 50, IsMe: true},
+		{Rank: 2, UserID: "carol-id", Value: 50},
+	}
+	if len(board.Entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", board.Entries, want)
+	}
+	for i := range want {
+		if board.Entries[i] != want[i] {
+			t.Errorf("entries[%d] = %+v, want %+v", i, board.Entries[i], want[i])
+		}
+	}
+	if board.Period != LeaderboardWeek || board.Timezone != "Asia/Jakarta" {
+		t.Errorf("board = %+v", board)
+	}
+
+	repo.getDailyMinutesByDateFn = func(_ context.Context, userID string, start, end time.Time, _ *time.Location) (map[string]int, error) {
+		if start.Day() != 1 || end.Sub(start) < 28*24*time.Hour {
+			t.Errorf("month window = %v – %v", start, end)
+		}
+		if userID == "carol-id" {
+			return map[string]int{"a": 30, "b": 45}, nil
+		}
+		return map[string]int{}, nil
+	}
+	board, err = svc.GetFriendLeaderboard(context.Background(), "alice-id", LeaderboardInput{Period: LeaderboardMonth})
+	if err != nil || len(board.Entries) != 4 || board.Entries[0].UserID != "carol-id" || board.Entries[0].Value != 75 {
+		t.Errorf("minutes board = %+v, %v", board, err)
+	}
+
+	// Streaks count runs inside the period only.
+	repo.getDailyMinutesByDateFn = func(_ context.Context, userID string, start, _ time.Time, _ *time.Location) (map[string]int, error) {
+		day := func(n int) string { return start.AddDate(0, 0, n).Format("2006-01-02") }
+		if userID == "bob-id" {
+			return map[string]int{day(0): 25, day(1): 0, day(3): 50}, nil
+		}
+		return map[string]int{day(5): 10}, nil
+	}
+	board, err = svc.GetFriendLeaderboard(context.Background(), "alice-id", LeaderboardInput{Metric: LeaderboardStreak})
+	if err != nil || len(board.Entries) != 4 || board.Entries[0].UserID != "bob-id" || board.Entries[0].Value != 2 || board.Entries[1].Value != 1 {
+		t.Errorf("streak board = %+v, %v", board, err)
+	}
+
+	if _, err := svc.GetFriendLeaderboard(context.Background(), "alice-id", LeaderboardInput{Metric: "xp"}); !errors.Is(err, ErrInvalidLeaderboard) {
+		t.Errorf("invalid metric error = %v, want ErrInvalidLeaderboard", err)
+	}
+}
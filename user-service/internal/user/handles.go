@@ -0,0 +1,171 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	handleMinLength = 3
+	handleMaxLength = 20
+	// handleChangeCooldown is how long a user waits between handle changes.
+	// Setting the first handle or changing only its case is exempt.
+	handleChangeCooldown = 30 * 24 * time.Hour
+	// handleReleaseHold keeps a released handle for its previous owner, so
+	// nobody can grab it the moment they rename.
+	handleReleaseHold = 14 * 24 * time.Hour
+)
+
+// reservedHandles cannot be claimed: they would read as staff accounts or
+// clash with routes such as /v1/users/me.
+var reservedHandles = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "focusnest": true,
+	"help": true, "me": true, "moderator": true, "null": true,
+	"official": true, "root": true, "settings": true, "staff": true,
+	"support": true, "system": true, "undefined": true,
+}
+
+// normalizeHandle trims whitespace and a leading "@".
+func normalizeHandle(handle string) string {
+	return strings.TrimPrefix(strings.TrimSpace(handle), "@")
+}
+
+// validateHandle checks a normalized handle: 3–20 letters, digits,
+// underscores or periods, starting with a letter, with no ".." and no
+// trailing period. Case is kept for display but ignored for uniqueness.
+func validateHandle(handle string) error {
+	if len(handle) < handleMinLength || len(handle) > handleMaxLength {
+		return fmt.Errorf("%w: must be %d to %d characters", ErrInvalidHandle, handleMinLength, handleMaxLength)
+	}
+	for i, c := range handle {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i == 0:
+			return fmt.Errorf("%w: must start with a letter", ErrInvalidHandle)
+		case c >= '0' && c <= '9', c == '_', c == '.':
+		default:
+			return fmt.Errorf("%w: use letters, digits, underscores or periods", ErrInvalidHandle)
+		}
+	}
+	if strings.Contains(handle, "..") || strings.HasSuffix(handle, ".") {
+		return fmt.Errorf("%w: periods cannot repeat or end the handle", ErrInvalidHandle)
+	}
+	if reservedHandles[strings.ToLower(handle)] {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidHandle, handle)
+	}
+	return nil
+}
+
+// SetHandle claims handle for the user, releasing their previous one.
+func (s *service) SetHandle(ctx context.Context, userID, handle string) (*HandleResponse, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("missing user id")
+	}
+	handle = normalizeHandle(handle)
+	if err := validateHandle(handle); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	profile, err := s.repo.ChangeHandle(ctx, userID, handle, now, func(current *Profile) error {
+		if current.Handle == "" || strings.EqualFold(current.Handle, handle) || current.HandleChangedAt == nil {
+			return nil
+		}
+		if next := current.HandleChangedAt.Add(handleChangeCooldown); now.Before(next) {
+			return fmt.Errorf("%w: next change allowed at %s", ErrHandleCooldown, next.Format(time.RFC3339))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &HandleResponse{Handle: profile.Handle, ChangedAt: profile.HandleChangedAt}
+	if profile.HandleChangedAt != nil {
+		next := profile.HandleChangedAt.Add(handleChangeCooldown)
+		resp.NextChangeAt = &next
+	}
+	return resp, nil
+}
+
+// GetPublicProfile returns the profile behind handle as viewerID sees it.
+// Stats follow the owner's privacy settings: public fields are shown to
+// everyone, friends fields to accepted friends, and everything to the owner.
+// Blocked users get ErrUserNotFound, as if the handle did not exist.
+func (s *service) GetPublicProfile(ctx context.Context, viewerID, handle string, timezone string) (*PublicProfile, error) {
+	handle = strings.ToLower(normalizeHandle(handle))
+	if handle == "" {
+		return nil, ErrUserNotFound
+	}
+	ownerID, err := s.repo.ResolveHandle(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == "" {
+		return nil, ErrUserNotFound
+	}
+
+	isMe := ownerID == viewerID
+	isFriend := false
+	if !isMe {
+		if hidden, err := s.blockedEitherWay(ctx, viewerID, ownerID); err != nil {
+			return nil, err
+		} else if hidden {
+			return nil, ErrUserNotFound
+		}
+		friendship, err := s.repo.GetFriendship(ctx, viewerID, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		isFriend = friendship != nil && friendship.Status == FriendshipAccepted
+	}
+
+	profile, err := s.repo.GetProfile(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	privacy := effectivePrivacy(profile.Privacy)
+	visible := func(v Visibility) bool {
+		return isMe || v == VisibilityPublic || (isFriend && v == VisibilityFriends)
+	}
+
+	resp := &PublicProfile{
+		Handle:      profile.Handle,
+		DisplayName: profile.DisplayName,
+		AvatarURL:   s.avatarURL(ctx, profile),
+		Bio:         profile.Bio,
+		IsMe:        isMe,
+		IsFriend:    isFriend,
+	}
+
+	showHours, showStreak := visible(privacy.FocusMinutes), visible(privacy.Streak)
+	if showHours || showStreak {
+		// Days are the owner's, not the viewer's.
+		tz := profile.Timezone
+		if tz == "" {
+			tz = timezone
+		}
+		stats, err := s.repo.GetProfileStats(ctx, ownerID, resolveLocation(tz))
+		if err != nil {
+			return nil, err
+		}
+		if showHours {
+			hours := math.Round(float64(stats.TotalSeconds)/360) / 10
+			resp.TotalHours = &hours
+		}
+		if showStreak {
+			longest := stats.LongestStreak()
+			resp.LongestStreak = &longest
+		}
+	}
+
+	if visible(privacy.Badges) {
+		badges, err := s.heldBadges(ctx, ownerID, "")
+		if err != nil {
+			return nil, err
+		}
+		resp.Badges = badges
+	}
+	return resp, nil
+}
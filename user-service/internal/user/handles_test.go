@@ -0,0 +1,153 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/profilestats"
+)
+
+func TestValidateHandle(t *testing.T) {
+	tests := []struct {
+		handle string
+		valid  bool
+	}{
+		{"sam", true},
+		{"Sam_Focus.99", true},
+		{"ab", false},
+		{"abcdefghijklmnopqrstu", false},
+		{"9lives", false},
+		{"_sam", false},
+		{"sam..focus", false},
+		{"sam.", false},
+		{"sam-focus", false},
+		{"sám", false},
+		{"Admin", false},
+		{"me", false},
+	}
+	for _, tt := range tests {
+		err := validateHandle(tt.handle)
+		if tt.valid && err != nil {
+			t.Errorf("validateHandle(%q) = %v, want nil", tt.handle, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidHandle) {
+			t.Errorf("validateHandle(%q) = %v, want ErrInvalidHandle", tt.handle, err)
+		}
+	}
+}
+
+// handleRepo hands ChangeHandle a fixed stored profile.
+type handleRepo struct {
+	IdentityRepository
+	stored *Profile
+}
+
+func (h *handleRepo) ChangeHandle(_ context.Context, _ string, handle string, at time.Time, allow func(*Profile) error) (*Profile, error) {
+	if err := allow(h.stored); err != nil {
+		return nil, err
+	}
+	out := *h.stored
+	out.Handle = handle
+	out.HandleChangedAt = &at
+	return &out, nil
+}
+
+func TestSetHandleCooldown(t *testing.T) {
+	recent := time.Now().UTC().Add(-24 * time.Hour)
+	old := time.Now().UTC().Add(-handleChangeCooldown - time.Hour)
+	tests := []struct {
+		name    string
+		stored  Profile
+		handle  string
+		wantErr error
+	}{
+		{name: "first handle", stored: Profile{}, handle: "sam"},
+		{name: "within cooldown", stored: Profile{Handle: "sam", HandleChangedAt: &recent}, handle: "samuel", wantErr: ErrHandleCooldown},
+		{name: "case only", stored: Profile{Handle: "sam", HandleChangedAt: &recent}, handle: "@Sam"},
+		{name: "after cooldown", stored: Profile{Handle: "sam", HandleChangedAt: &old}, handle: "samuel"},
+		{name: "invalid", stored: Profile{}, handle: "support", wantErr: ErrInvalidHandle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := tt.stored
+			svc := NewService(&fakeRepo{IdentityRepository: &handleRepo{stored: &stored}}, nil)
+			resp, err := svc.SetHandle(context.Background(), "u1", tt.handle)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SetHandle error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetHandle: %v", err)
+			}
+			if resp.NextChangeAt == nil || !resp.NextChangeAt.Equal(resp.ChangedAt.Add(handleChangeCooldown)) {
+				t.Fatalf("NextChangeAt = %v, want ChangedAt + cooldown", resp.NextChangeAt)
+			}
+		})
+	}
+}
+
+func TestGetPublicProfileVisibility(t *testing.T) {
+	friends := newMemFriends()
+	friends.handles["sam"] = "owner"
+	friends.privacy["owner"] = PrivacySettings{
+		FocusMinutes: VisibilityPublic,
+		Streak:       VisibilityFriends,
+		Badges:       VisibilityPrivate,
+	}
+	start := time.Date(2025, 11, 17, 9, 0, 0, 0, time.UTC)
+	repo := newFriendsRepo(friends, nil)
+	repo.getProfileStatsFn = func(context.Context, string, *time.Location) (profilestats.Counters, error) {
+		return profilestats.Build([]profilestats.Session{
+			{StartTime: start, Timezone: "UTC", TimeElapsed: 5400},
+			{StartTime: start.AddDate(0, 0, 1), Timezone: "UTC", TimeElapsed: 1800},
+		}, "UTC"), nil
+	}
+	repo.listBadgeAwardsFn = func(context.Context, string) ([]BadgeAward, error) {
+		return []BadgeAward{{BadgeID: "bronze"}}, nil
+	}
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	stranger, err := svc.GetPublicProfile(ctx, "viewer", "@Sam", "UTC")
+	if err != nil {
+		t.Fatalf("GetPublicProfile: %v", err)
+	}
+	if stranger.TotalHours == nil || *stranger.TotalHours != 2 {
+		t.Fatalf("TotalHours = %v, want 2", stranger.TotalHours)
+	}
+	if stranger.LongestStreak != nil || stranger.Badges != nil {
+		t.Fatalf("stranger sees streak %v and badges %v", stranger.LongestStreak, stranger.Badges)
+	}
+
+	friends.friendships[pairKey("viewer", "owner")] = Friendship{RequesterID: "viewer", AddresseeID: "owner", Status: FriendshipAccepted}
+	friend, err := svc.GetPublicProfile(ctx, "viewer", "sam", "UTC")
+	if err != nil {
+		t.Fatalf("GetPublicProfile: %v", err)
+	}
+	if !friend.IsFriend || friend.LongestStreak == nil || *friend.LongestStreak != 2 {
+		t.Fatalf("friend view = %+v, want streak 2", friend)
+	}
+	if friend.Badges != nil {
+		t.Fatalf("friend sees private badges %v", friend.Badges)
+	}
+
+	me, err := svc.GetPublicProfile(ctx, "owner", "sam", "UTC")
+	if err != nil {
+		t.Fatalf("GetPublicProfile: %v", err)
+	}
+	if !me.IsMe || len(me.Badges) != 1 || me.Badges[0].ID != "bronze" {
+		t.Fatalf("owner view = %+v, want every field", me)
+	}
+
+	friends.blocks[[2]string{"owner", "viewer"}] = Block{BlockerID: "owner", BlockedID: "viewer"}
+	if _, err := svc.GetPublicProfile(ctx, "viewer", "sam", "UTC"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("blocked viewer error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := svc.GetPublicProfile(ctx, "viewer", "nobody", "UTC"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("unknown handle error = %v, want ErrUserNotFound", err)
+	}
+}
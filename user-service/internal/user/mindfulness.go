@@ -0,0 +1,239 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// maxMindfulnessSeconds bounds one recorded session.
+	maxMindfulnessSeconds = 2 * 60 * 60
+	minCalm, maxCalm      = 1, 5
+
+	defaultMindfulnessPageSize = 20
+	maxMindfulnessPageSize     = 100
+	// maxMindfulnessStatsDays bounds the stats window; 0 means all time.
+	maxMindfulnessStatsDays = 365
+)
+
+// Step kinds the app animates.
+const (
+	stepInhale = "inhale"
+	stepHold   = "hold"
+	stepExhale = "exhale"
+	stepFocus  = "focus"
+)
+
+// mindfulnessCatalog lists the guided exercises. IDs are stored with
+// sessions and challenge rules and must stay stable; DurationSeconds is
+// filled in from the steps.
+var mindfulnessCatalog = withDurations([]MindfulnessExercise{
+	{
+		ID:   "box_breathing",
+		Kind: "breathing",
+		Title: LocalizedText{
+			EN: "Box Breathing",
+			ID: "Napas Kotak",
+		},
+		Description: LocalizedText{
+			EN: "Breathe in, hold, breathe out and hold for four counts each to settle your mind before focusing.",
+			ID: "Tarik napas, tahan, buang napas, dan tahan masing-masing empat hitungan untuk menenangkan pikiran sebelum fokus.",
+		},
+		Steps: []MindfulnessStep{
+			{Kind: stepInhale, Seconds: 4, Text: LocalizedText{EN: "Breathe in slowly through your nose", ID: "Tarik napas perlahan lewat hidung"}},
+			{Kind: stepHold, Seconds: 4, Text: LocalizedText{EN: "Hold your breath", ID: "Tahan napas"}},
+			{Kind: stepExhale, Seconds: 4, Text: LocalizedText{EN: "Breathe out through your mouth", ID: "Buang napas lewat mulut"}},
+			{Kind: stepHold, Seconds: 4, Text: LocalizedText{EN: "Hold with empty lungs", ID: "Tahan dengan paru-paru kosong"}},
+		},
+		Rounds: 8,
+	},
+	{
+		ID:   "breathing_4_7_8",
+		Kind: "breathing",
+		Title: LocalizedText{
+			EN: "4-7-8 Breathing",
+			ID: "Napas 4-7-8",
+		},
+		Description: LocalizedText{
+			EN: "A long hold and a slow exhale to calm down after a tiring session.",
+			ID: "Tahan napas panjang dan buang perlahan untuk menenangkan diri setelah sesi yang melelahkan.",
+		},
+		Steps: []MindfulnessStep{
+			{Kind: stepInhale, Seconds: 4, Text: LocalizedText{EN: "Breathe in quietly through your nose", ID: "Tarik napas pelan lewat hidung"}},
+			{Kind: stepHold, Seconds: 7, Text: LocalizedText{EN: "Hold your breath", ID: "Tahan napas"}},
+			{Kind: stepExhale, Seconds: 8, Text: LocalizedText{EN: "Breathe out fully through your mouth", ID: "Buang napas sepenuhnya lewat mulut"}},
+		},
+		Rounds: 4,
+	},
+	{
+		ID:   "body_scan",
+		Kind: "body_scan",
+		Title: LocalizedText{
+			EN: "Body Scan",
+			ID: "Pindai Tubuh",
+		},
+		Description: LocalizedText{
+			EN: "Move your attention slowly from your feet to your head and let go of tension.",
+			ID: "Arahkan perhatian perlahan dari kaki ke kepala dan lepaskan ketegangan.",
+		},
+		Steps: []MindfulnessStep{
+			{Kind: stepFocus, Seconds: 30, Text: LocalizedText{EN: "Sit comfortably and close your eyes", ID: "Duduk dengan nyaman dan pejamkan mata"}},
+			{Kind: stepFocus, Seconds: 45, Text: LocalizedText{EN: "Notice your feet and legs", ID: "Rasakan kaki dan tungkaimu"}},
+			{Kind: stepFocus, Seconds: 45, Text: LocalizedText{EN: "Notice your hips and belly", ID: "Rasakan pinggul dan perutmu"}},
+			{Kind: stepFocus, Seconds: 45, Text: LocalizedText{EN: "Notice your chest and back", ID: "Rasakan dada dan punggungmu"}},
+			{Kind: stepFocus, Seconds: 45, Text: LocalizedText{EN: "Notice your arms and hands", ID: "Rasakan lengan dan tanganmu"}},
+			{Kind: stepFocus, Seconds: 45, Text: LocalizedText{EN: "Relax your shoulders, neck and face", ID: "Lemaskan bahu, leher, dan wajahmu"}},
+			{Kind: stepFocus, Seconds: 45, Text: LocalizedText{EN: "Feel your whole body at once", ID: "Rasakan seluruh tubuhmu sekaligus"}},
+		},
+		Rounds: 1,
+	},
+})
+
+func withDurations(exercises []MindfulnessExercise) []MindfulnessExercise {
+	for i := range exercises {
+		seconds := 0
+		for _, step := range exercises[i].Steps {
+			seconds += step.Seconds
+		}
+		exercises[i].DurationSeconds = seconds * exercises[i].Rounds
+	}
+	return exercises
+}
+
+func findExercise(id string) (MindfulnessExercise, bool) {
+	for _, e := range mindfulnessCatalog {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return MindfulnessExercise{}, false
+}
+
+// ListMindfulnessExercises returns the guided exercise catalog.
+func (s *service) ListMindfulnessExercises(context.Context) []MindfulnessExercise {
+	return mindfulnessCatalog
+}
+
+// RecordMindfulnessSession stores a completed guided exercise. Minutes is
+// derived from the duration so minute-based challenges count it.
+func (s *service) RecordMindfulnessSession(ctx context.Context, userID string, input MindfulnessSessionInput) (*MindfulnessSession, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("missing user id")
+	}
+	exerciseID := strings.TrimSpace(input.ExerciseID)
+	if _, ok := findExercise(exerciseID); !ok {
+		return nil, fmt.Errorf("%w: unknown exercise %q", ErrInvalidMindfulness, exerciseID)
+	}
+	if input.DurationSeconds <= 0 || input.DurationSeconds > maxMindfulnessSeconds {
+		return nil, fmt.Errorf("%w: duration_seconds must be between 1 and %d", ErrInvalidMindfulness, maxMindfulnessSeconds)
+	}
+	for _, calm := range []*int{input.CalmBefore, input.CalmAfter} {
+		if calm != nil && (*calm < minCalm || *calm > maxCalm) {
+			return nil, fmt.Errorf("%w: calm ratings must be between %d and %d", ErrInvalidMindfulness, minCalm, maxCalm)
+		}
+	}
+
+	session := &MindfulnessSession{
+		ExerciseID:      exerciseID,
+		DurationSeconds: input.DurationSeconds,
+		Minutes:         max(input.DurationSeconds/60, 1),
+		CalmBefore:      input.CalmBefore,
+		CalmAfter:       input.CalmAfter,
+		CompletedAt:     time.Now().UTC(),
+	}
+	if err := s.repo.CreateMindfulnessSession(ctx, userID, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetMindfulnessHistory returns a page of sessions older than before, newest
+// first.
+func (s *service) GetMindfulnessHistory(ctx context.Context, userID string, before MindfulnessCursor, limit int) (*MindfulnessHistory, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("missing user id")
+	}
+	if limit <= 0 {
+		limit = defaultMindfulnessPageSize
+	}
+	limit = min(limit, maxMindfulnessPageSize)
+
+	sessions, err := s.repo.ListMindfulnessHistory(ctx, userID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	history := &MindfulnessHistory{Sessions: sessions}
+	if len(sessions) > limit {
+		history.Sessions = sessions[:limit]
+		last := sessions[limit-1]
+		history.NextBefore = &last.CompletedAt
+		history.NextBeforeID = last.ID
+	}
+	if history.Sessions == nil {
+		history.Sessions = []MindfulnessSession{}
+	}
+	return history, nil
+}
+
+// GetMindfulnessStats summarizes the last days local days in timezone, or
+// all time when days is 0. Minute-only records count toward the totals but
+// not toward any exercise.
+func (s *service) GetMindfulnessStats(ctx context.Context, userID string, days int, timezone string) (*MindfulnessStats, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("missing user id")
+	}
+	if days < 0 || days > maxMindfulnessStatsDays {
+		return nil, fmt.Errorf("%w: days must be between 0 (all time) and %d", ErrInvalidMindfulness, maxMindfulnessStatsDays)
+	}
+	loc := resolveLocation(timezone)
+	today := truncateToDay(time.Now().In(loc))
+	var start time.Time
+	if days > 0 {
+		start = today.AddDate(0, 0, -(days - 1))
+	}
+	sessions, err := s.repo.ListMindfulnessSessions(ctx, userID, start, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return mindfulnessStats(sessions, days, loc), nil
+}
+
+func mindfulnessStats(sessions []MindfulnessSession, days int, loc *time.Location) *MindfulnessStats {
+	stats := &MindfulnessStats{Days: days, ByExercise: make([]MindfulnessExerciseStats, len(mindfulnessCatalog))}
+	index := make(map[string]int, len(mindfulnessCatalog))
+	for i, e := range mindfulnessCatalog {
+		stats.ByExercise[i].ExerciseID = e.ID
+		index[e.ID] = i
+	}
+
+	active := make(map[string]bool)
+	var before, after int
+	for _, m := range sessions {
+		stats.TotalSessions++
+		stats.TotalMinutes += m.Minutes
+		active[m.CompletedAt.In(loc).Format(dayKeyLayout)] = true
+		if i, ok := index[m.ExerciseID]; ok {
+			stats.ByExercise[i].Sessions++
+			stats.ByExercise[i].Minutes += m.Minutes
+		}
+		if m.CalmBefore != nil && m.CalmAfter != nil {
+			stats.Calm.RatedSessions++
+			before += *m.CalmBefore
+			after += *m.CalmAfter
+		}
+	}
+	stats.ActiveDays = len(active)
+	if n := stats.Calm.RatedSessions; n > 0 {
+		stats.Calm.AverageBefore = roundTenth(float64(before) / float64(n))
+		stats.Calm.AverageAfter = roundTenth(float64(after) / float64(n))
+		stats.Calm.AverageChange = roundTenth(float64(after-before) / float64(n))
+	}
+	return stats
+}
+
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
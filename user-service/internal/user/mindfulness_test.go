@@ -0,0 +1,191 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMindfulnessCatalog(t *testing.T) {
+	want := map[string]int{"box_breathing": 128, "breathing_4_7_8": 76, "body_scan": 300}
+	if len(mindfulnessCatalog) != len(want) {
+		t.Fatalf("catalog has %d exercises, want %d", len(mindfulnessCatalog), len(want))
+	}
+	for _, e := range mindfulnessCatalog {
+		if e.DurationSeconds != want[e.ID] {
+			t.Errorf("%s lasts %ds, want %ds", e.ID, e.DurationSeconds, want[e.ID])
+		}
+		for _, text := range append([]LocalizedText{e.Title, e.Description}, stepTexts(e)...) {
+			if text.EN == "" || text.ID == "" {
+				t.Errorf("%s is missing a translation: %+v", e.ID, text)
+			}
+		}
+	}
+}
+
+func stepTexts(e MindfulnessExercise) []LocalizedText {
+	texts := make([]LocalizedText, len(e.Steps))
+	for i, step := range e.Steps {
+		texts[i] = step.Text
+	}
+	return texts
+}
+
+// mindfulnessRepo keeps sessions in memory and pages them like Firestore.
+type mindfulnessRepo struct {
+	MindfulnessRepository
+	sessions []MindfulnessSession
+}
+
+func (m *mindfulnessRepo) CreateMindfulnessSession(_ context.Context, _ string, session *MindfulnessSession) error {
+	session.ID = session.CompletedAt.Format(time.RFC3339Nano)
+	m.sessions = append(m.sessions, *session)
+	return nil
+}
+
+func (m *mindfulnessRepo) ListMindfulnessHistory(_ context.Context, _ string, after MindfulnessCursor, limit int) ([]MindfulnessSession, error) {
+	sorted := slices.Clone(m.sessions)
+	slices.SortFunc(sorted, func(a, b MindfulnessSession) int {
+		if c := b.CompletedAt.Compare(a.CompletedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	var out []MindfulnessSession
+	for _, s := range sorted {
+		older := s.CompletedAt.Before(after.CompletedAt) ||
+			s.CompletedAt.Equal(after.CompletedAt) && after.ID != "" && s.ID < after.ID
+		if (after.CompletedAt.IsZero() || older) && len(out) < limit {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func TestRecordMindfulnessSession(t *testing.T) {
+	calm := func(v int) *int { return &v }
+	repo := &mindfulnessRepo{}
+	svc := NewService(&fakeRepo{MindfulnessRepository: repo}, nil)
+	ctx := context.Background()
+
+	bad := []MindfulnessSessionInput{
+		{ExerciseID: "yoga", DurationSeconds: 60},
+		{ExerciseID: "body_scan", DurationSeconds: 0},
+		{ExerciseID: "body_scan", DurationSeconds: maxMindfulnessSeconds + 1},
+		{ExerciseID: "body_scan", DurationSeconds: 60, CalmBefore: calm(0)},
+		{ExerciseID: "body_scan", DurationSeconds: 60, CalmAfter: calm(6)},
+	}
+	for _, input := range bad {
+		if _, err := svc.RecordMindfulnessSession(ctx, "u1", input); !errors.Is(err, ErrInvalidMindfulness) {
+			t.Errorf("RecordMindfulnessSession(%+v) error = %v, want ErrInvalidMindfulness", input, err)
+		}
+	}
+
+	session, err := svc.RecordMindfulnessSession(ctx, "u1", MindfulnessSessionInput{
+		ExerciseID: " box_breathing ", DurationSeconds: 30, CalmBefore: calm(2), CalmAfter: calm(4),
+	})
+	if err != nil {
+		t.Fatalf("RecordMindfulnessSession: %v", err)
+	}
+	if session.ID == "" || session.ExerciseID != "box_breathing" || session.Minutes != 1 {
+		t.Fatalf("session = %+v, want an ID, a trimmed exercise and at least one minute", session)
+	}
+	if len(repo.sessions) != 1 {
+		t.Fatalf("stored %d sessions, want 1", len(repo.sessions))
+	}
+}
+
+func TestGetMindfulnessHistoryPages(t *testing.T) {
+	base := time.Date(2025, 11, 19, 8, 0, 0, 0, time.UTC)
+	repo := &mindfulnessRepo{}
+	// Sessions complete in pairs at the same instant, so a page boundary
+	// falls between two of them.
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		repo.sessions = append(repo.sessions, MindfulnessSession{ID: id, ExerciseID: "body_scan", CompletedAt: base.Add(time.Duration(i/2) * time.Minute)})
+	}
+	svc := NewService(&fakeRepo{MindfulnessRepository: repo}, nil)
+
+	page, err := svc.GetMindfulnessHistory(context.Background(), "u1", MindfulnessCursor{}, 2)
+	if err != nil {
+		t.Fatalf("GetMindfulnessHistory: %v", err)
+	}
+	if len(page.Sessions) != 2 || page.Sessions[0].ID != "e" || page.NextBefore == nil {
+		t.Fatalf("first page = %+v, want the two newest and a cursor", page)
+	}
+	var seen []string
+	for {
+		for _, s := range page.Sessions {
+			seen = append(seen, s.ID)
+		}
+		if page.NextBefore == nil {
+			break
+		}
+		cursor := MindfulnessCursor{CompletedAt: *page.NextBefore, ID: page.NextBeforeID}
+		if page, err = svc.GetMindfulnessHistory(context.Background(), "u1", cursor, 2); err != nil {
+			t.Fatalf("GetMindfulnessHistory: %v", err)
+		}
+	}
+	if want := []string{"e", "d", "c", "b", "a"}; !slices.Equal(seen, want) {
+		t.Fatalf("paged through %v, want %v", seen, want)
+	}
+}
+
+func TestMindfulnessStats(t *testing.T) {
+	calm := func(v int) *int { return &v }
+	day := time.Date(2025, 11, 19, 9, 0, 0, 0, time.UTC)
+	sessions := []MindfulnessSession{
+		{ExerciseID: "box_breathing", Minutes: 2, CalmBefore: calm(2), CalmAfter: calm(4), CompletedAt: day},
+		{ExerciseID: "box_breathing", Minutes: 3, CalmBefore: calm(3), CompletedAt: day.Add(time.Hour)},
+		{ExerciseID: "body_scan", Minutes: 5, CalmBefore: calm(1), CalmAfter: calm(4), CompletedAt: day.AddDate(0, 0, -1)},
+		{Minutes: 2, CompletedAt: day.AddDate(0, 0, -3)}, // minute-only record
+	}
+
+	stats := mindfulnessStats(sessions, 7, time.UTC)
+	if stats.TotalSessions != 4 || stats.TotalMinutes != 12 || stats.ActiveDays != 3 {
+		t.Fatalf("totals = %+v", stats)
+	}
+	byID := make(map[string]MindfulnessExerciseStats)
+	for _, e := range stats.ByExercise {
+		byID[e.ExerciseID] = e
+	}
+	if len(byID) != len(mindfulnessCatalog) || byID["box_breathing"].Sessions != 2 || byID["box_breathing"].Minutes != 5 ||
+		byID["body_scan"].Sessions != 1 || byID["breathing_4_7_8"].Sessions != 0 {
+		t.Fatalf("by exercise = %+v", stats.ByExercise)
+	}
+	if stats.Calm.RatedSessions != 2 || stats.Calm.AverageBefore != 1.5 || stats.Calm.AverageAfter != 4 || stats.Calm.AverageChange != 2.5 {
+		t.Fatalf("calm = %+v, want only sessions rated before and after", stats.Calm)
+	}
+}
+
+func TestEvaluateChallenge_MindfulnessExerciseFilter(t *testing.T) {
+	act := &challengeActivity{loc: time.UTC, mindfulness: []MindfulnessSession{
+		{ExerciseID: "box_breathing", Minutes: 2, CompletedAt: wednesday.Add(8 * time.Hour)},
+		{ExerciseID: "box_breathing", Minutes: 2, CompletedAt: wednesday.Add(20 * time.Hour)},
+		{ExerciseID: "body_scan", Minutes: 5, CompletedAt: wednesday.Add(12 * time.Hour)},
+		{Minutes: 3, CompletedAt: wednesday.Add(13 * time.Hour)},
+	}}
+	rule := ChallengeRule{
+		Window: ChallengeWindowDay,
+		Conditions: []ChallengeCondition{
+			{Metric: ChallengeMetricMindfulnessSessions, ExerciseID: "box_breathing", Target: 2},
+			{Metric: ChallengeMetricMindfulnessMinutes, Target: 10},
+		},
+	}
+	if err := rule.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	progress, completed := evaluateChallenge(rule, act, wednesday)
+	if !completed {
+		t.Fatalf("expected the challenge to complete, got %+v", progress.Conditions)
+	}
+	if got := progress.Conditions[0].Current; got != 2 {
+		t.Fatalf("box breathing sessions = %d, want 2", got)
+	}
+	if got := progress.Conditions[1].Current; got != 12 {
+		t.Fatalf("mindfulness minutes = %d, want 12 across every exercise", got)
+	}
+}
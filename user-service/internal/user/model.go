@@ -2,7 +2,11 @@ package user
 
 import (
 	"context"
+	"io"
 	"time"
+
+	"github.com/focusnest/shared-libs/preferences"
+	"github.com/focusnest/shared-libs/profilestats"
 )
 
 // Profile represents the persisted profile document stored in Firestore.
@@ -10,7 +14,21 @@ type Profile struct {
 	UserID    string     `json:"user_id" firestore:"user_id"`
 	Bio       string     `json:"bio" firestore:"bio"`
 	Birthdate *time.Time `json:"birthdate" firestore:"birthdate"`
-	PointsTotal int      `json:"points_total" firestore:"points_total"`
+	PointsTotal int      `json:"points_total" firestore:"points_total"` // sum of the points ledger
+	PointsEntries int    `json:"-" firestore:"points_entries"` // ledger length; the next entry's Seq
+	Timezone  string     `json:"timezone" firestore:"timezone"` // IANA name; "" means unset
+	FriendCode string           `json:"-" firestore:"friend_code"`
+	Handle     string           `json:"handle" firestore:"handle"` // as typed; reserved lowercased in handles/
+	HandleChangedAt *time.Time  `json:"-" firestore:"handle_changed_at"`
+	AvatarPath string           `json:"-" firestore:"avatar_path"` // uploaded avatar object; wins over AvatarURL
+	Privacy    *PrivacySettings `json:"-" firestore:"privacy"` // nil until the user changes a setting
+	Preferences *preferences.Preferences `json:"-" firestore:"preferences"` // nil until the user saves preferences
+	BadgesSeenAt *time.Time     `json:"-" firestore:"badges_seen_at"`
+	// Identity fields mirrored from Clerk by the webhook.
+	Email          string     `json:"email" firestore:"email"`
+	DisplayName    string     `json:"display_name" firestore:"display_name"`
+	AvatarURL      string     `json:"avatar_url" firestore:"avatar_url"`
+	ClerkUpdatedAt *time.Time `json:"-" firestore:"clerk_updated_at"` // updated_at of the last applied Clerk event
 	CreatedAt time.Time  `json:"created_at" firestore:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" firestore:"updated_at"`
 }
@@ -23,14 +41,68 @@ type ProfileMetadata struct {
 	TotalCycle          int `json:"total_cycle"`
 }
 
-// Badge represents a milestone earned from points.
+// XPProgress is the user's XP and where it sits on the level curve.
+type XPProgress struct {
+	XP          int `json:"xp"`
+	Level       int `json:"level"`
+	LevelXP     int `json:"level_xp"`      // XP earned within the current level
+	NextLevelXP int `json:"next_level_xp"` // XP the current level spans
+}
+
+// MetadataReconciliation compares the stored profile counters with a
+// recount from history. Stored is nil when the user had not been counted.
+// Drift compares the raw counters, so a shifted day or category shows even
+// when the derived metadata happens to match.
+type MetadataReconciliation struct {
+	UserID string           `json:"user_id"`
+	Stored *ProfileMetadata `json:"stored"`
+	Actual ProfileMetadata  `json:"actual"`
+	Drift  bool             `json:"drift"`
+}
+
+// Badge represents a milestone earned from points or activity.
 type Badge struct {
-	ID     string `json:"id"`
-	Label  string `json:"label"`
-	MinPts int    `json:"min_points"`
+	ID          string    `json:"id"`
+	Label       string    `json:"label"`
+	Description string    `json:"description,omitempty"`
+	Kind        BadgeKind `json:"kind,omitempty"`
+	MinPts      int       `json:"min_points,omitempty"`
+}
+
+// BadgeKind says what unlocks a badge.
+type BadgeKind string
+
+const (
+	BadgeKindPoints      BadgeKind = "points"      // PointsTotal reaching MinPts
+	BadgeKindAchievement BadgeKind = "achievement" // focus sessions; awarded by focus-service
+)
+
+// BadgeAward is stored at profiles/{uid}/badges/{badgeID} when a badge
+// unlocks. Awards are permanent: spending points does not revoke them.
+type BadgeAward struct {
+	BadgeID   string    `json:"badge_id" firestore:"-"`
+	Source    BadgeKind `json:"source" firestore:"source"`
+	AwardedAt time.Time `json:"awarded_at" firestore:"awarded_at"`
 }
 
-// ChallengeRuleType identifies how progress is computed.
+// BadgeStatus is a catalog badge with the caller's award, if any.
+type BadgeStatus struct {
+	Badge
+	Unlocked  bool       `json:"unlocked"`
+	AwardedAt *time.Time `json:"awarded_at,omitempty"`
+	// New is true for badges awarded after the user last marked badges seen.
+	New bool `json:"new"`
+}
+
+// BadgesResponse is returned by GET /v1/badges.
+type BadgesResponse struct {
+	Badges   []BadgeStatus `json:"badges"`
+	NewCount int           `json:"new_count"`
+}
+
+// ChallengeRuleType identifies one of the original hard-coded challenge
+// kinds. Definitions without a Rule are translated from it; new challenges
+// should set Rule instead.
 type ChallengeRuleType string
 
 const (
@@ -40,15 +112,103 @@ const (
 	ChallengeRuleCyclesAndMindfulness  ChallengeRuleType = "cycles_and_mindfulness"
 )
 
-// ChallengeDefinition is a static challenge template.
+// ChallengeMetric is the quantity a challenge condition measures.
+type ChallengeMetric string
+
+const (
+	ChallengeMetricMinutes             ChallengeMetric = "minutes"
+	ChallengeMetricSessions            ChallengeMetric = "sessions"
+	ChallengeMetricCycles              ChallengeMetric = "cycles"
+	ChallengeMetricShares              ChallengeMetric = "shares"
+	ChallengeMetricMindfulnessMinutes  ChallengeMetric = "mindfulness_minutes"
+	ChallengeMetricMindfulnessSessions ChallengeMetric = "mindfulness_sessions"
+	// ChallengeMetricStreak is the longest run of days with at least one
+	// matching session inside the window.
+	ChallengeMetricStreak ChallengeMetric = "streak"
+)
+
+// ChallengeWindow is the period a rule aggregates over, in the user's timezone.
+type ChallengeWindow string
+
+const (
+	ChallengeWindowDay         ChallengeWindow = "day"   // today
+	ChallengeWindowWeek        ChallengeWindow = "week"  // Monday-based week
+	ChallengeWindowMonth       ChallengeWindow = "month" // calendar month
+	ChallengeWindowRollingDays ChallengeWindow = "rolling_days"
+	// ChallengeWindowConsecutiveDays evaluates each day on its own and
+	// requires Days consecutive days that meet every condition.
+	ChallengeWindowConsecutiveDays ChallengeWindow = "consecutive_days"
+	ChallengeWindowAllTime         ChallengeWindow = "all_time"
+)
+
+// ChallengeComparator compares an aggregated value with a condition target.
+type ChallengeComparator string
+
+const (
+	ChallengeComparatorGTE ChallengeComparator = "gte" // default
+	ChallengeComparatorLTE ChallengeComparator = "lte"
+	ChallengeComparatorEQ  ChallengeComparator = "eq"
+)
+
+// ChallengeRule describes how a challenge is evaluated. All conditions are
+// aggregated over the same window and must hold together.
+type ChallengeRule struct {
+	Window ChallengeWindow `json:"window" firestore:"window"`
+	// Days is the length of a rolling_days window or the run length of a
+	// consecutive_days window.
+	Days int `json:"days,omitempty" firestore:"days,omitempty"`
+	// IncludePrevious keeps the previous period of a recurring challenge
+	// claimable while the current one runs, so a challenge finished just
+	// before midnight can still be claimed.
+	IncludePrevious bool                 `json:"include_previous,omitempty" firestore:"include_previous,omitempty"`
+	Conditions      []ChallengeCondition `json:"conditions" firestore:"conditions"`
+}
+
+// ChallengeCondition compares one metric with a target. Category and
+// TimeMode restrict the sessions counted by minutes, sessions, cycles and
+// streak; ExerciseID restricts the mindfulness metrics to one exercise.
+type ChallengeCondition struct {
+	Metric     ChallengeMetric     `json:"metric" firestore:"metric"`
+	Category   string              `json:"category,omitempty" firestore:"category,omitempty"`
+	TimeMode   string              `json:"time_mode,omitempty" firestore:"time_mode,omitempty"`
+	ExerciseID string              `json:"exercise_id,omitempty" firestore:"exercise_id,omitempty"`
+	Comparator ChallengeComparator `json:"comparator,omitempty" firestore:"comparator,omitempty"`
+	// Target applies per day in consecutive_days windows.
+	Target int `json:"target" firestore:"target"`
+}
+
+// ChallengeRecurrence controls how often a challenge resets and can be
+// claimed again.
+type ChallengeRecurrence string
+
+const (
+	// ChallengeRecurrenceDefault follows the rule window: day, week and
+	// month windows recur with it, the others are claimable once.
+	ChallengeRecurrenceDefault ChallengeRecurrence = ""
+	ChallengeRecurrenceOnce    ChallengeRecurrence = "once"
+	ChallengeRecurrenceDaily   ChallengeRecurrence = "daily"
+	ChallengeRecurrenceWeekly  ChallengeRecurrence = "weekly" // Monday-based
+	ChallengeRecurrenceMonthly ChallengeRecurrence = "monthly"
+)
+
+// ChallengeDefinition is a challenge template stored in the challenges
+// collection.
 type ChallengeDefinition struct {
 	ID           string            `json:"id"`
 	Title        string            `json:"title"`
 	Description  string            `json:"description"`
 	RewardPoints int               `json:"reward_points"`
-	RuleType     ChallengeRuleType `json:"rule_type"`
+	Rule         *ChallengeRule    `json:"rule,omitempty" firestore:"rule,omitempty"`
+
+	// StartsAt and EndsAt bound when the challenge runs; nil is open-ended.
+	// Recurring periods are clipped to them.
+	StartsAt   *time.Time          `json:"starts_at,omitempty" firestore:"starts_at,omitempty"`
+	EndsAt     *time.Time          `json:"ends_at,omitempty" firestore:"ends_at,omitempty"`
+	Recurrence ChallengeRecurrence `json:"recurrence,omitempty" firestore:"recurrence,omitempty"`
+
+	// Legacy rule type and params, read only when Rule is nil.
+	RuleType ChallengeRuleType `json:"rule_type,omitempty"`
 
-	// Rule params (interpreted based on RuleType)
 	MinMinutesPerDay int `json:"min_minutes_per_day,omitempty"`
 	ConsecutiveDays  int `json:"consecutive_days,omitempty"`
 
@@ -85,16 +245,145 @@ type ChallengeProgress struct {
 	CurrentMindfulnessMinutes int `json:"current_mindfulness_minutes,omitempty"`
 	TargetMindfulnessMinutes  int `json:"target_mindfulness_minutes,omitempty"`
 
+	// Conditions reports each rule condition in order.
+	Conditions []ConditionProgress `json:"conditions,omitempty"`
+
 	// Generic progress percentage (0-100) for UI
 	ProgressPercent int `json:"progress_percent"`
 }
 
-// ChallengeStatus is the per-user state for a challenge.
+// ConditionProgress is the evaluated value of one rule condition. For
+// consecutive_days windows Current and Target count days.
+type ConditionProgress struct {
+	Metric  ChallengeMetric `json:"metric"`
+	Current int             `json:"current"`
+	Target  int             `json:"target"`
+	Met     bool            `json:"met"`
+}
+
+// MindfulnessSession is one recorded mindfulness exercise. Records made
+// before the exercise catalog have only Minutes.
+//
+// CalmBefore and CalmAfter rate how calm the user felt, from 1 (tense) to 5
+// (calm). They are not focus-session moods ("Fokus", "Capek", …): those are
+// unordered labels, while an exercise is judged by how far it moves the
+// rating, which needs an ordered scale.
+type MindfulnessSession struct {
+	ID              string    `json:"id" firestore:"-"`
+	ExerciseID      string    `json:"exercise_id,omitempty" firestore:"exercise_id,omitempty"`
+	DurationSeconds int       `json:"duration_seconds" firestore:"duration_seconds"`
+	Minutes         int       `json:"minutes" firestore:"minutes"`
+	CalmBefore      *int      `json:"calm_before,omitempty" firestore:"calm_before,omitempty"`
+	CalmAfter       *int      `json:"calm_after,omitempty" firestore:"calm_after,omitempty"`
+	CompletedAt     time.Time `json:"completed_at" firestore:"completed_at"`
+}
+
+// LocalizedText is a string in each app language.
+type LocalizedText struct {
+	EN string `json:"en"`
+	ID string `json:"id"`
+}
+
+// MindfulnessStep is one timed phase of a guided exercise.
+type MindfulnessStep struct {
+	Kind    string        `json:"kind"` // inhale, hold, exhale, focus
+	Seconds int           `json:"seconds"`
+	Text    LocalizedText `json:"text"`
+}
+
+// MindfulnessExercise is a guided exercise from the catalog. Steps make one
+// round, played Rounds times.
+type MindfulnessExercise struct {
+	ID              string            `json:"id"`
+	Kind            string            `json:"kind"` // breathing, body_scan
+	Title           LocalizedText     `json:"title"`
+	Description     LocalizedText     `json:"description"`
+	Steps           []MindfulnessStep `json:"steps"`
+	Rounds          int               `json:"rounds"`
+	DurationSeconds int               `json:"duration_seconds"`
+}
+
+// MindfulnessSessionInput is the body of POST /v1/mindfulness/sessions.
+type MindfulnessSessionInput struct {
+	ExerciseID      string `json:"exercise_id"`
+	DurationSeconds int    `json:"duration_seconds"`
+	CalmBefore      *int   `json:"calm_before,omitempty"`
+	CalmAfter       *int   `json:"calm_after,omitempty"`
+}
+
+// MindfulnessHistory is a page of sessions, newest first.
+type MindfulnessHistory struct {
+	Sessions []MindfulnessSession `json:"sessions"`
+	// NextBefore and NextBeforeID page to older sessions; NextBefore is nil
+	// when there are none.
+	NextBefore   *time.Time `json:"next_before,omitempty"`
+	NextBeforeID string     `json:"next_before_id,omitempty"`
+}
+
+// MindfulnessCursor is the last session of a history page. ID breaks ties
+// between sessions completed at the same instant; without it every session
+// at CompletedAt is skipped.
+type MindfulnessCursor struct {
+	CompletedAt time.Time
+	ID          string
+}
+
+// MindfulnessStats summarizes sessions over the last Days local days, or all
+// time when Days is 0.
+type MindfulnessStats struct {
+	Days          int                        `json:"days,omitempty"`
+	TotalSessions int                        `json:"total_sessions"`
+	TotalMinutes  int                        `json:"total_minutes"`
+	ActiveDays    int                        `json:"active_days"`
+	ByExercise    []MindfulnessExerciseStats `json:"by_exercise"`
+	Calm          MindfulnessCalmStats       `json:"calm"`
+}
+
+// MindfulnessExerciseStats totals one catalog exercise.
+type MindfulnessExerciseStats struct {
+	ExerciseID string `json:"exercise_id"`
+	Sessions   int    `json:"sessions"`
+	Minutes    int    `json:"minutes"`
+}
+
+// MindfulnessCalmStats averages sessions rated both before and after.
+type MindfulnessCalmStats struct {
+	RatedSessions int     `json:"rated_sessions"`
+	AverageBefore float64 `json:"average_before"`
+	AverageAfter  float64 `json:"average_after"`
+	AverageChange float64 `json:"average_change"`
+}
+
+// ChallengePeriod is one claimable occurrence of a challenge.
+type ChallengePeriod struct {
+	// Key identifies the period: "once", "2025-11-19", "2025-W47" or "2025-11".
+	Key      string     `json:"key"`
+	StartsAt *time.Time `json:"starts_at,omitempty"` // nil when open-ended
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	// RemainingSeconds is the time left until EndsAt.
+	RemainingSeconds int `json:"remaining_seconds,omitempty"`
+}
+
+// ChallengeClaim records a reward paid out for one period of a challenge.
+type ChallengeClaim struct {
+	ChallengeID   string     `json:"-" firestore:"challenge_id"`
+	PeriodKey     string     `json:"period_key" firestore:"period_key"`
+	PeriodStart   *time.Time `json:"period_start,omitempty" firestore:"period_start,omitempty"`
+	PeriodEnd     *time.Time `json:"period_end,omitempty" firestore:"period_end,omitempty"`
+	PointsAwarded int        `json:"points_awarded" firestore:"points_awarded"`
+	ClaimedAt     time.Time  `json:"claimed_at" firestore:"claimed_at"`
+}
+
+// ChallengeStatus is the per-user state for a challenge in its current
+// period, or in the previous one while that is still claimable.
 type ChallengeStatus struct {
 	Challenge ChallengeDefinition `json:"challenge"`
+	Period    ChallengePeriod     `json:"period"`
 	Progress  ChallengeProgress   `json:"progress"`
 	Completed bool                `json:"completed"`
 	Claimed   bool                `json:"claimed"`
+	// History lists past claims, newest first.
+	History []ChallengeClaim `json:"history"`
 }
 
 // ChallengesMeResponse is returned by GET /v1/challenges/me.
@@ -107,6 +396,7 @@ type ChallengesMeResponse struct {
 // ClaimChallengeResponse is returned by POST /v1/challenges/{id}/claim.
 type ClaimChallengeResponse struct {
 	ChallengeID   string    `json:"challenge_id"`
+	PeriodKey     string    `json:"period_key,omitempty"`
 	Claimed       bool      `json:"claimed"`
 	AlreadyClaimed bool     `json:"already_claimed"`
 	PointsAwarded int       `json:"points_awarded"`
@@ -120,6 +410,12 @@ type ProfileResponse struct {
 	Bio       string     `json:"bio"`
 	Birthdate *time.Time `json:"birthdate"`
 	PointsTotal int      `json:"points_total"`
+	XPProgress
+	Timezone  string     `json:"timezone"`
+	Handle      string   `json:"handle"`
+	Email       string   `json:"email"`
+	DisplayName string   `json:"display_name"`
+	AvatarURL   string   `json:"avatar_url"`
 	ProfileMetadata
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
@@ -129,6 +425,7 @@ type ProfileResponse struct {
 type ProfileUpdateInput struct {
 	Bio       *string
 	Birthdate *BirthdatePatch
+	Timezone  *string // validated IANA name; "" clears the preference
 }
 
 // BirthdatePatch differentiates between omitted and explicit null updates.
@@ -137,38 +434,458 @@ type BirthdatePatch struct {
 	Value *time.Time
 }
 
+// Visibility controls who can see a profile field.
+type Visibility string
+
+const (
+	VisibilityPrivate Visibility = "private"
+	VisibilityFriends Visibility = "friends"
+	// VisibilityPublic also shows the field on the public profile.
+	VisibilityPublic Visibility = "public"
+)
+
+// PrivacySettings controls what friends and other users can see and who may
+// send requests. Empty fields fall back to defaultPrivacy.
+type PrivacySettings struct {
+	FocusMinutes   Visibility `json:"focus_minutes" firestore:"focus_minutes"`
+	Streak         Visibility `json:"streak" firestore:"streak"`
+	Points         Visibility `json:"points" firestore:"points"`
+	Badges         Visibility `json:"badges" firestore:"badges"`
+	FriendRequests bool       `json:"friend_requests" firestore:"friend_requests"` // accept new requests
+}
+
+// PrivacyPatch updates privacy settings; nil fields are left unchanged.
+type PrivacyPatch struct {
+	FocusMinutes   *Visibility
+	Streak         *Visibility
+	Points         *Visibility
+	Badges         *Visibility
+	FriendRequests *bool
+}
+
+// HandleResponse is returned by PUT /v1/users/me/handle.
+type HandleResponse struct {
+	Handle       string     `json:"handle"`
+	ChangedAt    *time.Time `json:"changed_at,omitempty"`
+	NextChangeAt *time.Time `json:"next_change_at,omitempty"` // end of the change cooldown
+}
+
+// PublicProfile is what other users see at GET /v1/profiles/{handle}. Stats
+// are omitted when the owner's privacy settings hide them from the viewer.
+type PublicProfile struct {
+	Handle        string   `json:"handle"`
+	DisplayName   string   `json:"display_name"`
+	AvatarURL     string   `json:"avatar_url"`
+	Bio           string   `json:"bio"`
+	IsMe          bool     `json:"is_me,omitempty"`
+	IsFriend      bool     `json:"is_friend,omitempty"`
+	TotalHours    *float64 `json:"total_hours,omitempty"`    // focus_minutes visibility
+	LongestStreak *int     `json:"longest_streak,omitempty"` // streak visibility
+	Badges        []Badge  `json:"badges,omitempty"`         // badges visibility
+}
+
+// AvatarUpload is an image sent to PUT /v1/users/me/avatar.
+type AvatarUpload struct {
+	Data        io.Reader
+	Filename    string
+	ContentType string
+}
+
+// AvatarStore keeps uploaded avatars in Cloud Storage.
+type AvatarStore interface {
+	Put(ctx context.Context, name, contentType string, data io.Reader) error
+	Delete(ctx context.Context, name string) error
+	// SignedURL returns a time-limited read URL for name.
+	SignedURL(ctx context.Context, name string) (string, error)
+}
+
+// FriendshipStatus is the state of a friendship document.
+type FriendshipStatus string
+
+const (
+	FriendshipPending  FriendshipStatus = "pending"
+	FriendshipAccepted FriendshipStatus = "accepted"
+)
+
+// Friendship links two users. There is one document per pair, keyed by the
+// sorted user IDs, so a request and its reverse can never both exist.
+type Friendship struct {
+	Users       []string         `json:"-" firestore:"users"` // sorted pair, for array-contains queries
+	RequesterID string           `json:"requester_id" firestore:"requester_id"`
+	AddresseeID string           `json:"addressee_id" firestore:"addressee_id"`
+	Status      FriendshipStatus `json:"status" firestore:"status"`
+	CreatedAt   time.Time        `json:"created_at" firestore:"created_at"`
+	AcceptedAt  *time.Time       `json:"accepted_at,omitempty" firestore:"accepted_at"`
+}
+
+// Other returns the user on the other side of the friendship.
+func (f Friendship) Other(userID string) string {
+	if f.RequesterID == userID {
+		return f.AddresseeID
+	}
+	return f.RequesterID
+}
+
+// FriendRequestInput identifies who to befriend, by handle or friend code.
+type FriendRequestInput struct {
+	Handle string
+	Code   string
+}
+
+// Friend is an accepted friend as listed to the user.
+type Friend struct {
+	UserID string    `json:"user_id"`
+	Since  time.Time `json:"since"`
+}
+
+// FriendRequest is a pending request as listed to one side.
+type FriendRequest struct {
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FriendRequestsResponse lists pending requests in both directions.
+type FriendRequestsResponse struct {
+	Incoming []FriendRequest `json:"incoming"`
+	Outgoing []FriendRequest `json:"outgoing"`
+}
+
+// Block hides BlockedID from BlockerID: no requests either way and no
+// leaderboard entries.
+type Block struct {
+	BlockerID string    `json:"-" firestore:"-"`
+	BlockedID string    `json:"user_id" firestore:"user_id"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// LeaderboardPeriod is the window every leaderboard metric is measured over.
+type LeaderboardPeriod string
+
+const (
+	LeaderboardWeek  LeaderboardPeriod = "week"
+	LeaderboardMonth LeaderboardPeriod = "month"
+)
+
+// LeaderboardMetric is what a leaderboard ranks by.
+type LeaderboardMetric string
+
+const (
+	LeaderboardMinutes LeaderboardMetric = "minutes" // focus minutes within the period
+	LeaderboardStreak  LeaderboardMetric = "streak"  // longest run of active days within the period
+	LeaderboardPoints  LeaderboardMetric = "points"  // points earned within the period
+)
+
+// LeaderboardInput selects a friend leaderboard.
+type LeaderboardInput struct {
+	Period   LeaderboardPeriod
+	Metric   LeaderboardMetric
+	Timezone string
+}
+
+// LeaderboardEntry is one ranked user. Equal values share a rank.
+type LeaderboardEntry struct {
+	Rank   int    `json:"rank"`
+	UserID string `json:"user_id"`
+	Value  int    `json:"value"`
+	IsMe   bool   `json:"is_me,omitempty"`
+}
+
+// Leaderboard ranks the user and the friends who share the metric.
+type Leaderboard struct {
+	Period   LeaderboardPeriod  `json:"period"`
+	Metric   LeaderboardMetric  `json:"metric"`
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Timezone string             `json:"timezone"`
+	Entries  []LeaderboardEntry `json:"entries"`
+}
+
+// FriendRepository stores the friend graph, blocks, friend codes and privacy.
+type FriendRepository interface {
+	// ReserveFriendCode claims code for userID, or returns ErrFriendCodeTaken.
+	ReserveFriendCode(ctx context.Context, userID, code string) error
+	// ResolveFriendCode and ResolveHandle return "" when nobody matches.
+	ResolveFriendCode(ctx context.Context, code string) (string, error)
+	ResolveHandle(ctx context.Context, handle string) (string, error)
+	// GetFriendship returns nil when the pair has no document.
+	GetFriendship(ctx context.Context, userA, userB string) (*Friendship, error)
+	// CreateFriendship returns ErrFriendshipExists when the pair already has a document.
+	CreateFriendship(ctx context.Context, f *Friendship) error
+	// AcceptFriendship accepts the pending request from requesterID to
+	// addresseeID at at, counting both users' accepted friendships in the
+	// same transaction. It returns ErrFriendRequestNotFound when no such
+	// request is pending and ErrFriendLimitReached when either user already
+	// has limit friends.
+	AcceptFriendship(ctx context.Context, addresseeID, requesterID string, at time.Time, limit int) (*Friendship, error)
+	DeleteFriendship(ctx context.Context, userA, userB string) error
+	ListFriendships(ctx context.Context, userID string) ([]Friendship, error)
+	IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error)
+	CreateBlock(ctx context.Context, block Block) error
+	DeleteBlock(ctx context.Context, blockerID, blockedID string) error
+	ListBlocks(ctx context.Context, userID string) ([]Block, error)
+	UpdatePrivacy(ctx context.Context, userID string, privacy PrivacySettings) error
+}
+
+// IdentityRepository stores handles and avatars on the profile document.
+type IdentityRepository interface {
+	// ChangeHandle reserves handles/{lowercase handle} for userID and
+	// releases the previous handle in one transaction. allow sees the stored
+	// profile and may veto the change. A handle held by someone else returns
+	// ErrHandleTaken.
+	ChangeHandle(ctx context.Context, userID, handle string, at time.Time, allow func(*Profile) error) (*Profile, error)
+	// SetAvatarPath stores path ("" clears it) and returns the updated
+	// profile with the path it replaced.
+	SetAvatarPath(ctx context.Context, userID, path string, at time.Time) (profile *Profile, previous string, err error)
+}
+
+// MindfulnessRepository stores guided mindfulness sessions.
+type MindfulnessRepository interface {
+	// CreateMindfulnessSession stores session and fills in its ID.
+	CreateMindfulnessSession(ctx context.Context, userID string, session *MindfulnessSession) error
+	// ListMindfulnessHistory returns up to limit sessions after the cursor
+	// (from the newest when zero), newest first with ties by ID descending.
+	ListMindfulnessHistory(ctx context.Context, userID string, after MindfulnessCursor, limit int) ([]MindfulnessSession, error)
+}
+
+// PointsEntryType classifies a points ledger entry.
+type PointsEntryType string
+
+const (
+	PointsEarn    PointsEntryType = "earn"
+	PointsSpend   PointsEntryType = "spend"
+	PointsAdjust  PointsEntryType = "adjust"
+	PointsReverse PointsEntryType = "reverse"
+)
+
+// Points sources name what a ledger entry refers to.
+const (
+	PointsSourceChallenge  = "challenge"  // SourceID is {challengeID}@{periodKey}
+	PointsSourceRedemption = "redemption" // SourceID is the idempotency key
+	PointsSourceManual     = "manual"
+	PointsSourceLedger     = "ledger" // SourceID is the reversed entry, if any
+)
+
+// PointsEntry is one line of the append-only points ledger. Amount is
+// signed and BalanceAfter is Profile.PointsTotal once the entry applied.
+type PointsEntry struct {
+	ID           string          `json:"id" firestore:"-"`
+	Seq          int             `json:"seq" firestore:"seq"`
+	Type         PointsEntryType `json:"type" firestore:"type"`
+	Amount       int             `json:"amount" firestore:"amount"`
+	BalanceAfter int             `json:"balance_after" firestore:"balance_after"`
+	Reason       string          `json:"reason" firestore:"reason"`
+	SourceType   string          `json:"source_type" firestore:"source_type"`
+	SourceID     string          `json:"source_id,omitempty" firestore:"source_id,omitempty"`
+	CreatedAt    time.Time       `json:"created_at" firestore:"created_at"`
+}
+
+// PointsResponse is returned by GET /v1/points.
+type PointsResponse struct {
+	PointsTotal int           `json:"points_total"`
+	Entries     []PointsEntry `json:"entries"`
+	// NextBefore pages to older entries; 0 when there are none.
+	NextBefore int `json:"next_before,omitempty"`
+}
+
+// RewardItem is something points can be spent on.
+type RewardItem struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Kind        string `json:"kind"` // streak_freeze, theme
+	Cost        int    `json:"cost"`
+	// MaxOwned caps how many a user can hold; 0 means no limit.
+	MaxOwned int `json:"max_owned,omitempty"`
+	// Owned is how many the caller holds.
+	Owned int `json:"owned"`
+}
+
+// RedeemInput is the body of POST /v1/points/redeem.
+type RedeemInput struct {
+	ItemID         string `json:"item_id"`
+	Quantity       int    `json:"quantity"`
+	IdempotencyKey string `json:"-"`
+}
+
+// Redemption records points spent on a reward item.
+type Redemption struct {
+	IdempotencyKey string    `json:"idempotency_key" firestore:"-"`
+	ItemID         string    `json:"item_id" firestore:"item_id"`
+	Quantity       int       `json:"quantity" firestore:"quantity"`
+	Cost           int       `json:"cost" firestore:"cost"` // total for Quantity
+	EntryID        string    `json:"entry_id" firestore:"entry_id"`
+	CreatedAt      time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// RedeemResponse is returned by POST /v1/points/redeem.
+type RedeemResponse struct {
+	Redemption  Redemption `json:"redemption"`
+	PointsTotal int        `json:"points_total"`
+	Owned       int        `json:"owned"`
+	// Replayed is true when the idempotency key was already used.
+	Replayed bool `json:"replayed"`
+}
+
+// PointsRepository stores the append-only points ledger. Every write moves
+// Profile.PointsTotal by the entry amount in the same transaction.
+type PointsRepository interface {
+	// AppendPointsEntry writes entry, assigning Seq, BalanceAfter and, when
+	// empty, ID. An existing ID returns ErrPointsEntryExists.
+	AppendPointsEntry(ctx context.Context, userID string, entry PointsEntry) (PointsEntry, error)
+	// ReversePointsEntry appends the opposite of entryID. Reversing a
+	// redemption also returns the items. A second reversal returns
+	// ErrPointsEntryExists.
+	ReversePointsEntry(ctx context.Context, userID, entryID, reason string, at time.Time) (PointsEntry, error)
+	// ListPointsEntries returns entries with Seq below before (all when 0), newest first.
+	ListPointsEntries(ctx context.Context, userID string, before, limit int) ([]PointsEntry, error)
+	// ListPointsEntriesBetween returns entries created in [from, to).
+	ListPointsEntriesBetween(ctx context.Context, userID string, from, to time.Time) ([]PointsEntry, error)
+	// Redeem spends redemption.Cost and adds the items unless the key was
+	// used before, in which case the stored redemption is replayed.
+	Redeem(ctx context.Context, userID string, redemption Redemption, maxOwned int) (*RedeemResponse, error)
+	GetInventory(ctx context.Context, userID string) (map[string]int, error)
+}
+
+// BadgeRepository stores badge awards.
+type BadgeRepository interface {
+	ListBadgeAwards(ctx context.Context, userID string) ([]BadgeAward, error)
+	// AwardBadges stores awards not already held; existing ones keep their
+	// original AwardedAt.
+	AwardBadges(ctx context.Context, userID string, awards []BadgeAward) error
+	MarkBadgesSeen(ctx context.Context, userID string, at time.Time) error
+}
+
+// ClerkUser is the identity carried by a Clerk user.created or user.updated
+// event.
+type ClerkUser struct {
+	UserID      string
+	Email       string
+	DisplayName string
+	AvatarURL   string
+	Roles       []string
+	UpdatedAt   time.Time // Clerk's updated_at; orders redeliveries
+}
+
+// SyncOutcome reports what SyncClerkUser did with an event.
+type SyncOutcome string
+
+const (
+	SyncApplied SyncOutcome = "applied"
+	// SyncDuplicate means the event ID was already processed.
+	SyncDuplicate SyncOutcome = "duplicate"
+	// SyncStale means a newer event, or the account's deletion, came first.
+	SyncStale SyncOutcome = "stale"
+)
+
+// WebhookRepository applies Clerk events exactly once per event ID.
+type WebhookRepository interface {
+	// SyncClerkUser upserts the identity fields and records eventID in the
+	// same transaction.
+	SyncClerkUser(ctx context.Context, eventID, eventType string, u ClerkUser) (SyncOutcome, error)
+	// RecordWebhookEvent marks eventID processed; first is false when it
+	// already was.
+	RecordWebhookEvent(ctx context.Context, eventID, eventType string) (first bool, err error)
+}
+
+// PreferencesRepository stores preferences on the profile document.
+type PreferencesRepository interface {
+	// UpdatePreferences resolves the stored preferences, passes them to
+	// update and saves the result, all in one transaction. update may run
+	// more than once if the transaction retries.
+	UpdatePreferences(ctx context.Context, userID string, update func(preferences.Preferences) (preferences.Preferences, error)) (*preferences.Preferences, error)
+}
+
 // Repository defines the interface for user data access.
 type Repository interface {
 	GetProfile(ctx context.Context, userID string) (*Profile, error)
 	UpsertProfile(ctx context.Context, userID string, updates ProfileUpdateInput) (*Profile, error)
-	GetProfileMetadata(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error)
+	// GetProfileStats returns the user's profile counters. Users not counted
+	// yet are counted and stored once, with loc placing sessions recorded
+	// without a timezone.
+	GetProfileStats(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error)
+	// RebuildProfileMetadata recounts the counters from every session and
+	// stores them, returning the stored ones they replaced (nil if none).
+	RebuildProfileMetadata(ctx context.Context, userID string, loc *time.Location) (*profilestats.Counters, profilestats.Counters, error)
 	GetDailyMinutesByDate(ctx context.Context, userID string, startDate, endDate time.Time, loc *time.Location) (map[string]int, error)
 	ListChallenges(ctx context.Context) ([]ChallengeDefinition, error)
 	CreateChallenge(ctx context.Context, def ChallengeDefinition) error
-	GetChallengeClaimedAt(ctx context.Context, userID, challengeID string) (time.Time, error)
-	ClaimChallenge(ctx context.Context, userID, challengeID string, points int) (newTotal int, claimedAt time.Time, alreadyClaimed bool, err error)
+	// ListChallengeClaims returns the user's claims for a challenge. Claims
+	// made before periods existed have an empty PeriodKey.
+	ListChallengeClaims(ctx context.Context, userID, challengeID string) ([]ChallengeClaim, error)
+	// ClaimChallenge stores the claim and awards its points once per
+	// challenge and period; a repeat reports alreadyClaimed.
+	ClaimChallenge(ctx context.Context, userID string, claim ChallengeClaim) (newTotal int, alreadyClaimed bool, err error)
+
+	// Activity read by the challenge evaluator, for start times in [start, end).
+	ListProductivityEntries(ctx context.Context, userID string, start, end time.Time) ([]ProductivityEntry, error)
+	ListShareTimes(ctx context.Context, userID string, start, end time.Time) ([]time.Time, error)
+	ListMindfulnessSessions(ctx context.Context, userID string, start, end time.Time) ([]MindfulnessSession, error)
 
-	// For weekly shares challenge
-	GetWeeklyShareCount(ctx context.Context, userID string, weekStart time.Time) (int, error)
 	RecordShare(ctx context.Context, userID string, shareType string) error
+	RecordMindfulness(ctx context.Context, userID string, minutes int) error
 
-	// For streak milestone challenge
 	GetCurrentStreak(ctx context.Context, userID string, loc *time.Location) (int, error)
 
-	// For cycles and mindfulness challenge
-	GetCyclesByDate(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error)
-	GetMindfulnessMinutesByDate(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error)
-	RecordMindfulness(ctx context.Context, userID string, minutes int) error
+	FriendRepository
+	IdentityRepository
+	MindfulnessRepository
+	PointsRepository
+	BadgeRepository
+	WebhookRepository
+	PreferencesRepository
 }
 
 // Service defines the user service interface.
 type Service interface {
 	GetProfile(ctx context.Context, userID string, timezone string) (*ProfileResponse, error)
 	UpdateProfile(ctx context.Context, userID string, updates ProfileUpdateInput, timezone string) (*ProfileResponse, error)
+	ReconcileProfileMetadata(ctx context.Context, userID string, timezone string) (*MetadataReconciliation, error)
 	ListChallenges(ctx context.Context) ([]ChallengeDefinition, error)
 	MigrateChallenges(ctx context.Context) error
 	GetChallengesMe(ctx context.Context, userID string, timezone string) (*ChallengesMeResponse, error)
 	ClaimChallenge(ctx context.Context, userID, challengeID string, timezone string) (*ClaimChallengeResponse, error)
 	RecordShare(ctx context.Context, userID string, shareType string) error
 	RecordMindfulness(ctx context.Context, userID string, minutes int) error
+
+	ListMindfulnessExercises(ctx context.Context) []MindfulnessExercise
+	RecordMindfulnessSession(ctx context.Context, userID string, input MindfulnessSessionInput) (*MindfulnessSession, error)
+	GetMindfulnessHistory(ctx context.Context, userID string, before MindfulnessCursor, limit int) (*MindfulnessHistory, error)
+	GetMindfulnessStats(ctx context.Context, userID string, days int, timezone string) (*MindfulnessStats, error)
+
+	GetFriendCode(ctx context.Context, userID string) (string, error)
+	SendFriendRequest(ctx context.Context, userID string, input FriendRequestInput) (*Friendship, error)
+	AcceptFriendRequest(ctx context.Context, userID, requesterID string) (*Friendship, error)
+	DeclineFriendRequest(ctx context.Context, userID, otherID string) error
+	RemoveFriend(ctx context.Context, userID, friendID string) error
+	ListFriends(ctx context.Context, userID string) ([]Friend, error)
+	ListFriendRequests(ctx context.Context, userID string) (*FriendRequestsResponse, error)
+	BlockUser(ctx context.Context, userID, targetID string) error
+	UnblockUser(ctx context.Context, userID, targetID string) error
+	ListBlockedUsers(ctx context.Context, userID string) ([]Block, error)
+	GetPrivacy(ctx context.Context, userID string) (*PrivacySettings, error)
+	UpdatePrivacy(ctx context.Context, userID string, patch PrivacyPatch) (*PrivacySettings, error)
+	GetFriendLeaderboard(ctx context.Context, userID string, input LeaderboardInput) (*Leaderboard, error)
+
+	SetHandle(ctx context.Context, userID, handle string) (*HandleResponse, error)
+	// UploadAvatar and DeleteAvatar return the avatar URL now shown.
+	UploadAvatar(ctx context.Context, userID string, upload AvatarUpload) (string, error)
+	DeleteAvatar(ctx context.Context, userID string) (string, error)
+	GetPublicProfile(ctx context.Context, viewerID, handle string, timezone string) (*PublicProfile, error)
+
+	GetPoints(ctx context.Context, userID string, before, limit int) (*PointsResponse, error)
+	ListRewards(ctx context.Context, userID string) ([]RewardItem, error)
+	Redeem(ctx context.Context, userID string, input RedeemInput) (*RedeemResponse, error)
+	AdjustPoints(ctx context.Context, userID string, amount int, reason string) (*PointsEntry, error)
+	ReversePoints(ctx context.Context, userID, entryID, reason string) (*PointsEntry, error)
+
+	GetBadges(ctx context.Context, userID string) (*BadgesResponse, error)
+	MarkBadgesSeen(ctx context.Context, userID string) error
+
+	SyncClerkUser(ctx context.Context, eventID, eventType string, u ClerkUser) (SyncOutcome, error)
+	RecordWebhookEvent(ctx context.Context, eventID, eventType string) (bool, error)
+
+	GetPreferences(ctx context.Context, userID string) (*preferences.Preferences, error)
+	// UpdatePreferences applies patch. A non-nil ifRevision must match the
+	// stored revision or ErrPreferencesConflict is returned.
+	UpdatePreferences(ctx context.Context, userID string, patch preferences.Patch, ifRevision *int) (*preferences.Preferences, error)
 }
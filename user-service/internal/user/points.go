@@ -0,0 +1,177 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPointsPageSize = 20
+	maxPointsPageSize     = 100
+	// maxRedeemQuantity bounds a single redemption.
+	maxRedeemQuantity = 10
+	// maxIdempotencyKeyLength keeps keys usable as Firestore document IDs.
+	maxIdempotencyKeyLength = 128
+)
+
+// rewardCatalog lists what points can be spent on. Owned is filled per user.
+var rewardCatalog = []RewardItem{
+	{
+		ID:          "streak_freeze",
+		Title:       "Streak Freeze",
+		Description: "Jaga streak tetap menyala saat kamu libur sehari",
+		Kind:        "streak_freeze",
+		Cost:        100,
+		MaxOwned:    3,
+	},
+	{
+		ID:          "theme_forest",
+		Title:       "Tema Hutan",
+		Description: "Tema hijau yang menenangkan untuk sesi fokus",
+		Kind:        "theme",
+		Cost:        250,
+		MaxOwned:    1,
+	},
+	{
+		ID:          "theme_ocean",
+		Title:       "Tema Laut",
+		Description: "Tema biru laut untuk sesi fokus",
+		Kind:        "theme",
+		Cost:        250,
+		MaxOwned:    1,
+	},
+	{
+		ID:          "theme_night",
+		Title:       "Tema Malam",
+		Description: "Tema gelap untuk fokus di malam hari",
+		Kind:        "theme",
+		Cost:        250,
+		MaxOwned:    1,
+	},
+}
+
+func findReward(id string) (RewardItem, bool) {
+	for _, item := range rewardCatalog {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return RewardItem{}, false
+}
+
+// GetPoints returns the balance and a page of ledger entries, newest first.
+func (s *service) GetPoints(ctx context.Context, userID string, before, limit int) (*PointsResponse, error) {
+	if limit <= 0 {
+		limit = defaultPointsPageSize
+	}
+	limit = min(limit, maxPointsPageSize)
+
+	profile, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListPointsEntries(ctx, userID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PointsResponse{PointsTotal: profile.PointsTotal, Entries: entries}
+	if resp.Entries == nil {
+		resp.Entries = []PointsEntry{}
+	}
+	if n := len(entries); n == limit && entries[n-1].Seq > 1 {
+		resp.NextBefore = entries[n-1].Seq
+	}
+	return resp, nil
+}
+
+// ListRewards returns the catalog with how many of each item the user holds.
+func (s *service) ListRewards(ctx context.Context, userID string) ([]RewardItem, error) {
+	owned, err := s.repo.GetInventory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]RewardItem, len(rewardCatalog))
+	for i, item := range rewardCatalog {
+		item.Owned = owned[item.ID]
+		items[i] = item
+	}
+	return items, nil
+}
+
+// Redeem spends points on a catalog item. Repeating a request with the same
+// idempotency key returns the first result without spending again.
+func (s *service) Redeem(ctx context.Context, userID string, input RedeemInput) (*RedeemResponse, error) {
+	if err := validateIdempotencyKey(input.IdempotencyKey); err != nil {
+		return nil, err
+	}
+	item, ok := findReward(input.ItemID)
+	if !ok {
+		return nil, ErrRewardNotFound
+	}
+	if input.Quantity == 0 {
+		input.Quantity = 1
+	}
+	if input.Quantity < 0 || input.Quantity > maxRedeemQuantity {
+		return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidPoints, maxRedeemQuantity)
+	}
+
+	return s.repo.Redeem(ctx, userID, Redemption{
+		IdempotencyKey: input.IdempotencyKey,
+		ItemID:         item.ID,
+		Quantity:       input.Quantity,
+		Cost:           item.Cost * input.Quantity,
+		CreatedAt:      time.Now().UTC(),
+	}, item.MaxOwned)
+}
+
+// AdjustPoints records a manual correction. The balance may go negative.
+func (s *service) AdjustPoints(ctx context.Context, userID string, amount int, reason string) (*PointsEntry, error) {
+	reason = strings.TrimSpace(reason)
+	if userID == "" || amount == 0 || reason == "" {
+		return nil, fmt.Errorf("%w: adjustments need a user, a non-zero amount and a reason", ErrInvalidPoints)
+	}
+	entry, err := s.repo.AppendPointsEntry(ctx, userID, PointsEntry{
+		Type:       PointsAdjust,
+		Amount:     amount,
+		Reason:     reason,
+		SourceType: PointsSourceManual,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry.Amount > 0 {
+		// The adjustment stands if this fails, as with challenge claims.
+		_ = s.awardPointBadges(ctx, userID, entry.BalanceAfter)
+	}
+	return &entry, nil
+}
+
+// ReversePoints cancels a ledger entry by appending its opposite.
+func (s *service) ReversePoints(ctx context.Context, userID, entryID, reason string) (*PointsEntry, error) {
+	reason = strings.TrimSpace(reason)
+	if userID == "" || entryID == "" || reason == "" {
+		return nil, fmt.Errorf("%w: reversals need a user, an entry and a reason", ErrInvalidPoints)
+	}
+	entry, err := s.repo.ReversePointsEntry(ctx, userID, entryID, reason, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if entry.Amount > 0 {
+		_ = s.awardPointBadges(ctx, userID, entry.BalanceAfter)
+	}
+	return &entry, nil
+}
+
+func validateIdempotencyKey(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: missing idempotency key", ErrInvalidPoints)
+	case len(key) > maxIdempotencyKeyLength, strings.Contains(key, "/"),
+		key == ".", key == "..", strings.HasPrefix(key, "__"):
+		return fmt.Errorf("%w: malformed idempotency key", ErrInvalidPoints)
+	}
+	return nil
+}
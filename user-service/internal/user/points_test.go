@@ -0,0 +1,266 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memPoints is an in-memory PointsRepository for a single user.
+type memPoints struct {
+	mu          sync.Mutex
+	entries     []PointsEntry
+	redemptions map[string]Redemption
+	inventory   map[string]int
+}
+
+func newMemPoints() *memPoints {
+	return &memPoints{redemptions: map[string]Redemption{}, inventory: map[string]int{}}
+}
+
+func (m *memPoints) balance() int {
+	if len(m.entries) == 0 {
+		return 0
+	}
+	return m.entries[len(m.entries)-1].BalanceAfter
+}
+
+func (m *memPoints) find(id string) (PointsEntry, bool) {
+	for _, e := range m.entries {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return PointsEntry{}, false
+}
+
+func (m *memPoints) append(entry PointsEntry) PointsEntry {
+	entry.Seq = len(m.entries) + 1
+	entry.BalanceAfter = m.balance() + entry.Amount
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("%08d", entry.Seq)
+	}
+	m.entries = append(m.entries, entry)
+	return entry
+}
+
+func (m *memPoints) AppendPointsEntry(_ context.Context, _ string, entry PointsEntry) (PointsEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.find(entry.ID); ok && entry.ID != "" {
+		return PointsEntry{}, ErrPointsEntryExists
+	}
+	return m.append(entry), nil
+}
+
+func (m *memPoints) ReversePointsEntry(_ context.Context, _ string, entryID, reason string, at time.Time) (PointsEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orig, ok := m.find(entryID)
+	if !ok {
+		return PointsEntry{}, ErrPointsEntryNotFound
+	}
+	if orig.Type == PointsReverse {
+		return PointsEntry{}, ErrInvalidPoints
+	}
+	if _, ok := m.find("reverse-" + entryID); ok {
+		return PointsEntry{}, ErrPointsEntryExists
+	}
+	if red, ok := m.redemptions[orig.SourceID]; ok && orig.SourceType == PointsSourceRedemption {
+		m.inventory[red.ItemID] = max(m.inventory[red.ItemID]-red.Quantity, 0)
+	}
+	return m.append(PointsEntry{
+		ID: "reverse-" + entryID, Type: PointsReverse, Amount: -orig.Amount, Reason: reason,
+		SourceType: PointsSourceLedger, SourceID: entryID, CreatedAt: at,
+	}), nil
+}
+
+func (m *memPoints) ListPointsEntries(_ context.Context, _ string, before, limit int) ([]PointsEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []PointsEntry
+	for i := len(m.entries) - 1; i >= 0 && len(out) < limit; i-- {
+		if before == 0 || m.entries[i].Seq < before {
+			out = append(out, m.entries[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *memPoints) ListPointsEntriesBetween(_ context.Context, _ string, from, to time.Time) ([]PointsEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []PointsEntry
+	for _, e := range m.entries {
+		if !e.CreatedAt.Before(from) && e.CreatedAt.Before(to) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *memPoints) Redeem(_ context.Context, _ string, red Redemption, maxOwned int) (*RedeemResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.redemptions[red.IdempotencyKey]; ok {
+		if stored.ItemID != red.ItemID || stored.Quantity != red.Quantity {
+			return nil, ErrIdempotencyKeyReused
+		}
+		return &RedeemResponse{Redemption: stored, PointsTotal: m.balance(), Owned: m.inventory[red.ItemID], Replayed: true}, nil
+	}
+	if maxOwned > 0 && m.inventory[red.ItemID]+red.Quantity > maxOwned {
+		return nil, ErrRewardLimitReached
+	}
+	if m.balance() < red.Cost {
+		return nil, ErrInsufficientPoints
+	}
+	entry := m.append(PointsEntry{
+		Type: PointsSpend, Amount: -red.Cost, SourceType: PointsSourceRedemption,
+		SourceID: red.IdempotencyKey, CreatedAt: red.CreatedAt,
+	})
+	red.EntryID = entry.ID
+	m.redemptions[red.IdempotencyKey] = red
+	m.inventory[red.ItemID] += red.Quantity
+	return &RedeemResponse{Redemption: red, PointsTotal: m.balance(), Owned: m.inventory[red.ItemID]}, nil
+}
+
+func (m *memPoints) GetInventory(context.Context, string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	owned := make(map[string]int, len(m.inventory))
+	for id, n := range m.inventory {
+		owned[id] = n
+	}
+	return owned, nil
+}
+
+func newPointsService(t *testing.T, opening int) (Service, *memPoints) {
+	t.Helper()
+	points := newMemPoints()
+	repo := &fakeRepo{
+		PointsRepository: points,
+		getProfileFn: func(_ context.Context, userID string) (*Profile, error) {
+			points.mu.Lock()
+			defer points.mu.Unlock()
+			return &Profile{UserID: userID, PointsTotal: points.balance()}, nil
+		},
+	}
+	svc := NewService(repo, nil)
+	if opening != 0 {
+		if _, err := svc.AdjustPoints(context.Background(), "u1", opening, "test balance"); err != nil {
+			t.Fatalf("AdjustPoints() error = %v", err)
+		}
+	}
+	return svc, points
+}
+
+func TestRedeemIsIdempotent(t *testing.T) {
+	svc, _ := newPointsService(t, 300)
+	ctx := context.Background()
+	input := RedeemInput{ItemID: "streak_freeze", Quantity: 2, IdempotencyKey: "key-1"}
+
+	first, err := svc.Redeem(ctx, "u1", input)
+	if err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	if first.Replayed || first.PointsTotal != 100 || first.Owned != 2 || first.Redemption.Cost != 200 {
+		t.Fatalf("Redeem() = %+v, want 200 spent and 2 owned", first)
+	}
+
+	again, err := svc.Redeem(ctx, "u1", input)
+	if err != nil {
+		t.Fatalf("replayed Redeem() error = %v", err)
+	}
+	if !again.Replayed || again.PointsTotal != 100 || again.Redemption.EntryID != first.Redemption.EntryID {
+		t.Fatalf("replayed Redeem() = %+v, want the first result", again)
+	}
+
+	input.Quantity = 1
+	if _, err := svc.Redeem(ctx, "u1", input); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Fatalf("Redeem() with a changed body error = %v, want ErrIdempotencyKeyReused", err)
+	}
+}
+
+func TestRedeemRejections(t *testing.T) {
+	svc, _ := newPointsService(t, 400)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		input RedeemInput
+		want  error
+	}{
+		{"missing key", RedeemInput{ItemID: "streak_freeze"}, ErrInvalidPoints},
+		{"malformed key", RedeemInput{ItemID: "streak_freeze", IdempotencyKey: "a/b"}, ErrInvalidPoints},
+		{"unknown item", RedeemInput{ItemID: "golden_owl", IdempotencyKey: "k1"}, ErrRewardNotFound},
+		{"too many", RedeemInput{ItemID: "streak_freeze", Quantity: 11, IdempotencyKey: "k2"}, ErrInvalidPoints},
+		{"over max owned", RedeemInput{ItemID: "streak_freeze", Quantity: 4, IdempotencyKey: "k3"}, ErrRewardLimitReached},
+		{"affordable", RedeemInput{ItemID: "theme_forest", Quantity: 1, IdempotencyKey: "k4"}, nil},
+		{"insufficient", RedeemInput{ItemID: "theme_ocean", Quantity: 1, IdempotencyKey: "k5"}, ErrInsufficientPoints},
+	}
+	for _, tc := range tests {
+		_, err := svc.Redeem(ctx, "u1", tc.input)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: Redeem() error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestReversePointsRestoresRedemption(t *testing.T) {
+	svc, points := newPointsService(t, 100)
+	ctx := context.Background()
+
+	resp, err := svc.Redeem(ctx, "u1", RedeemInput{ItemID: "streak_freeze", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	reversal, err := svc.ReversePoints(ctx, "u1", resp.Redemption.EntryID, "refund")
+	if err != nil {
+		t.Fatalf("ReversePoints() error = %v", err)
+	}
+	if reversal.Amount != 100 || reversal.BalanceAfter != 100 || points.inventory["streak_freeze"] != 0 {
+		t.Fatalf("ReversePoints() = %+v, inventory %v; want points and items returned", reversal, points.inventory)
+	}
+
+	if _, err := svc.ReversePoints(ctx, "u1", resp.Redemption.EntryID, "again"); !errors.Is(err, ErrPointsEntryExists) {
+		t.Fatalf("second ReversePoints() error = %v, want ErrPointsEntryExists", err)
+	}
+	if _, err := svc.ReversePoints(ctx, "u1", reversal.ID, "undo"); !errors.Is(err, ErrInvalidPoints) {
+		t.Fatalf("reversing a reversal error = %v, want ErrInvalidPoints", err)
+	}
+	if _, err := svc.ReversePoints(ctx, "u1", "missing", "undo"); !errors.Is(err, ErrPointsEntryNotFound) {
+		t.Fatalf("reversing an unknown entry error = %v, want ErrPointsEntryNotFound", err)
+	}
+	if _, err := svc.AdjustPoints(ctx, "u1", 10, " "); !errors.Is(err, ErrInvalidPoints) {
+		t.Fatalf("AdjustPoints() without a reason error = %v, want ErrInvalidPoints", err)
+	}
+}
+
+func TestGetPointsPagesNewestFirst(t *testing.T) {
+	svc, _ := newPointsService(t, 0)
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		if _, err := svc.AdjustPoints(ctx, "u1", i, "bonus"); err != nil {
+			t.Fatalf("AdjustPoints() error = %v", err)
+		}
+	}
+
+	page, err := svc.GetPoints(ctx, "u1", 0, 2)
+	if err != nil {
+		t.Fatalf("GetPoints() error = %v", err)
+	}
+	if page.PointsTotal != 15 || len(page.Entries) != 2 || page.Entries[0].Seq != 5 || page.NextBefore != 4 {
+		t.Fatalf("GetPoints() = %+v, want entries 5 and 4 of 15 points", page)
+	}
+
+	last, err := svc.GetPoints(ctx, "u1", 2, 2)
+	if err != nil {
+		t.Fatalf("GetPoints() error = %v", err)
+	}
+	if len(last.Entries) != 1 || last.Entries[0].Seq != 1 || last.NextBefore != 0 {
+		t.Fatalf("GetPoints(before=2) = %+v, want only entry 1 and no next page", last)
+	}
+}
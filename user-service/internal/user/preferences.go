@@ -0,0 +1,54 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/focusnest/shared-libs/preferences"
+)
+
+// GetPreferences returns the user's preferences with defaults applied.
+func (s *service) GetPreferences(ctx context.Context, userID string) (*preferences.Preferences, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("missing user id")
+	}
+	profile, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := resolvePreferences(profile)
+	return &prefs, nil
+}
+
+// UpdatePreferences applies patch and stores the full preferences with the
+// next revision. An empty patch returns the current preferences unchanged.
+func (s *service) UpdatePreferences(ctx context.Context, userID string, patch preferences.Patch, ifRevision *int) (*preferences.Preferences, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("missing user id")
+	}
+	if patch.IsEmpty() && ifRevision == nil {
+		return s.GetPreferences(ctx, userID)
+	}
+	return s.repo.UpdatePreferences(ctx, userID, func(current preferences.Preferences) (preferences.Preferences, error) {
+		if ifRevision != nil && *ifRevision != current.Revision {
+			return current, ErrPreferencesConflict
+		}
+		if patch.IsEmpty() {
+			return current, nil
+		}
+		next, err := patch.Apply(current)
+		if err != nil {
+			return current, err
+		}
+		now := time.Now().UTC()
+		next.Version = preferences.SchemaVersion
+		next.Revision = current.Revision + 1
+		next.UpdatedAt = &now
+		return next, nil
+	})
+}
+
+func resolvePreferences(profile *Profile) preferences.Preferences {
+	return preferences.Document{Timezone: profile.Timezone, Preferences: profile.Preferences}.Resolve()
+}
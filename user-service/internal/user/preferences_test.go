@@ -0,0 +1,150 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/focusnest/shared-libs/preferences"
+)
+
+// newPreferencesRepo keeps one profile in memory and stores preferences the
+// way the Firestore repository does: only when the revision moves.
+func newPreferencesRepo(profile *Profile) (*fakeRepo, *int) {
+	writes := 0
+	repo := &fakeRepo{
+		getProfileFn: func(context.Context, string) (*Profile, error) {
+			copy := *profile
+			return &copy, nil
+		},
+		updatePreferencesFn: func(_ context.Context, _ string, update func(preferences.Preferences) (preferences.Preferences, error)) (*preferences.Preferences, error) {
+			current := resolvePreferences(profile)
+			next, err := update(current)
+			if err != nil {
+				return nil, err
+			}
+			if next.Revision != current.Revision {
+				writes++
+				stored := next
+				profile.Preferences = &stored
+				profile.Timezone = next.Timezone
+			}
+			return &next, nil
+		},
+	}
+	return repo, &writes
+}
+
+func intPtr(v int) *int          { return &v }
+func boolPtr(v bool) *bool       { return &v }
+func stringPtr(v string) *string { return &v }
+
+func TestGetPreferences_DefaultsWhenUnset(t *testing.T) {
+	repo, _ := newPreferencesRepo(&Profile{UserID: "u1", Timezone: "Europe/Berlin"})
+	prefs, err := NewService(repo, nil).GetPreferences(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	want := preferences.Defaults()
+	want.Timezone = "Europe/Berlin"
+	if *prefs != want {
+		t.Fatalf("preferences = %+v, want %+v", *prefs, want)
+	}
+}
+
+func TestUpdatePreferences_PartialPatchesKeepOtherFields(t *testing.T) {
+	profile := &Profile{UserID: "u1"}
+	repo, writes := newPreferencesRepo(profile)
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	first, err := svc.UpdatePreferences(ctx, "u1", preferences.Patch{
+		FocusMinutes:  intPtr(50),
+		Notifications: &preferences.NotificationsPatch{DailyReminder: boolPtr(true)},
+	}, nil)
+	if err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if first.Revision != 1 || first.FocusMinutes != 50 || !first.Notifications.DailyReminder || first.UpdatedAt == nil {
+		t.Fatalf("first = %+v", first)
+	}
+
+	second, err := svc.UpdatePreferences(ctx, "u1", preferences.Patch{
+		Language:      stringPtr(" ID "),
+		WeekStart:     stringPtr("Sunday"),
+		Timezone:      stringPtr("Asia/Makassar"),
+		Notifications: &preferences.NotificationsPatch{WeeklySummary: boolPtr(true)},
+	}, intPtr(1))
+	if err != nil {
+		t.Fatalf("second update: %v", err)
+	}
+	if second.Revision != 2 || second.FocusMinutes != 50 || second.ShortBreakMinutes != 5 ||
+		second.Language != "id" || second.WeekStart != "sunday" || second.Timezone != "Asia/Makassar" ||
+		!second.Notifications.DailyReminder || !second.Notifications.WeeklySummary {
+		t.Fatalf("second = %+v", second)
+	}
+	if profile.Timezone != "Asia/Makassar" {
+		t.Fatalf("profile timezone = %q", profile.Timezone)
+	}
+
+	// An empty patch is a read.
+	if _, err := svc.UpdatePreferences(ctx, "u1", preferences.Patch{}, nil); err != nil {
+		t.Fatalf("empty update: %v", err)
+	}
+	if *writes != 2 {
+		t.Fatalf("writes = %d, want 2", *writes)
+	}
+}
+
+func TestUpdatePreferences_RejectsInvalidValues(t *testing.T) {
+	tests := map[string]preferences.Patch{
+		"language":        {Language: stringPtr("fr")},
+		"timezone":        {Timezone: stringPtr("Mars/Olympus")},
+		"focus too short": {FocusMinutes: intPtr(4)},
+		"break too long":  {LongBreakMinutes: intPtr(61)},
+		"negative goal":   {DailyGoalMinutes: intPtr(-1)},
+		"week start":      {WeekStart: stringPtr("tuesday")},
+	}
+	for name, patch := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, writes := newPreferencesRepo(&Profile{UserID: "u1"})
+			_, err := NewService(repo, nil).UpdatePreferences(context.Background(), "u1", patch, nil)
+			if !errors.Is(err, preferences.ErrInvalid) {
+				t.Fatalf("err = %v, want ErrInvalid", err)
+			}
+			if *writes != 0 {
+				t.Fatal("invalid patch was stored")
+			}
+		})
+	}
+}
+
+func TestUpdatePreferences_IfRevisionMismatch(t *testing.T) {
+	repo, writes := newPreferencesRepo(&Profile{UserID: "u1"})
+	svc := NewService(repo, nil)
+
+	_, err := svc.UpdatePreferences(context.Background(), "u1", preferences.Patch{DailyGoalMinutes: intPtr(0)}, intPtr(3))
+	if !errors.Is(err, ErrPreferencesConflict) {
+		t.Fatalf("err = %v, want ErrPreferencesConflict", err)
+	}
+	if *writes != 0 {
+		t.Fatal("conflicting patch was stored")
+	}
+
+	prefs, err := svc.UpdatePreferences(context.Background(), "u1", preferences.Patch{DailyGoalMinutes: intPtr(0)}, intPtr(0))
+	if err != nil || prefs.DailyGoalMinutes != 0 || prefs.Revision != 1 {
+		t.Fatalf("prefs=%+v err=%v", prefs, err)
+	}
+}
+
+func TestResolvePreferences_RepairsStoredValues(t *testing.T) {
+	stored := preferences.Preferences{Version: 0, Revision: 4, Language: "fr", FocusMinutes: 0, WeekStart: "sunday", DailyGoalMinutes: 30}
+	prefs := resolvePreferences(&Profile{UserID: "u1", Preferences: &stored})
+
+	def := preferences.Defaults()
+	if prefs.Version != preferences.SchemaVersion || prefs.Revision != 4 || prefs.Language != def.Language ||
+		prefs.FocusMinutes != def.FocusMinutes || prefs.ShortBreakMinutes != def.ShortBreakMinutes ||
+		prefs.WeekStart != "sunday" || prefs.DailyGoalMinutes != 30 {
+		t.Fatalf("resolved = %+v", prefs)
+	}
+}
@@ -0,0 +1,95 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/profilestats"
+)
+
+func TestProfileStatsIncrementalMatchesRebuild(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2025, 11, d, hour, 0, 0, 0, time.UTC) }
+	sessions := []profilestats.Session{
+		{StartTime: day(17, 9), Timezone: "UTC", NumCycle: 2, Category: "Work"},
+		{StartTime: day(18, 9), Timezone: "UTC", NumCycle: 1, Category: "Study"},
+		{StartTime: day(19, 9), Timezone: "UTC", Category: " Work "},
+		// 23:00 UTC is the 21st in Jakarta, where the session was recorded.
+		{StartTime: day(20, 23), Timezone: "Asia/Jakarta", NumCycle: 3, Category: "Read"},
+	}
+
+	var incremental profilestats.Counters
+	for i := range sessions {
+		incremental.Replace(nil, &sessions[i])
+	}
+	got := metadataFromStats(incremental)
+	want := ProfileMetadata{LongestStreak: 3, TotalProductivities: 3, TotalSessions: 4, TotalCycle: 7}
+	if got != want {
+		t.Fatalf("metadata = %+v, want %+v", got, want)
+	}
+	if !incremental.Equal(profilestats.Build(sessions, "UTC")) {
+		t.Fatalf("incremental counters differ from a rebuild")
+	}
+
+	// Moving the Study session off the 18th breaks the run; deleting the
+	// Read session drops its category.
+	moved := sessions[1]
+	moved.StartTime = day(25, 9)
+	incremental.Replace(&sessions[1], &moved)
+	incremental.Replace(&sessions[3], nil)
+	got = metadataFromStats(incremental)
+	want = ProfileMetadata{LongestStreak: 1, TotalProductivities: 2, TotalSessions: 3, TotalCycle: 4}
+	if got != want {
+		t.Fatalf("after edits metadata = %+v, want %+v", got, want)
+	}
+}
+
+func TestReconcileProfileMetadataReportsDrift(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2025, 11, d, 9, 0, 0, 0, time.UTC) }
+	counters := func(days ...int) *profilestats.Counters {
+		var c profilestats.Counters
+		for _, d := range days {
+			c.Replace(nil, &profilestats.Session{StartTime: at(d), Timezone: "UTC", NumCycle: 2, Category: "Work"})
+		}
+		return &c
+	}
+	actual := *counters(17, 18, 20)
+	tests := []struct {
+		name   string
+		stored *profilestats.Counters
+		drift  bool
+	}{
+		{name: "never counted", stored: nil, drift: true},
+		{name: "in sync", stored: counters(17, 18, 20), drift: false},
+		{name: "missing session", stored: counters(17, 18), drift: true},
+		// Same totals and streak, but one session sits on the wrong day.
+		{name: "shifted day", stored: counters(17, 18, 21), drift: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotLoc string
+			repo := &fakeRepo{
+				getProfileFn: func(_ context.Context, userID string) (*Profile, error) {
+					return &Profile{UserID: userID, Timezone: "Europe/Berlin"}, nil
+				},
+				rebuildProfileMetaFn: func(_ context.Context, _ string, loc *time.Location) (*profilestats.Counters, profilestats.Counters, error) {
+					gotLoc = loc.String()
+					return tt.stored, actual, nil
+				},
+			}
+			result, err := NewService(repo, nil).ReconcileProfileMetadata(context.Background(), "u1", "")
+			if err != nil {
+				t.Fatalf("ReconcileProfileMetadata: %v", err)
+			}
+			if result.Drift != tt.drift || result.Actual != metadataFromStats(actual) {
+				t.Fatalf("result = %+v, want drift %v", result, tt.drift)
+			}
+			if (result.Stored == nil) != (tt.stored == nil) {
+				t.Fatalf("stored = %+v, want %+v", result.Stored, tt.stored)
+			}
+			if gotLoc != "Europe/Berlin" {
+				t.Fatalf("rebuilt in %q, want the profile's timezone", gotLoc)
+			}
+		})
+	}
+}
@@ -3,45 +3,33 @@ package user
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"golang.org/x/sync/errgroup"
+
+	"github.com/focusnest/shared-libs/profilestats"
+	"github.com/focusnest/shared-libs/timezone"
 )
 
 func resolveLocation(tz string) *time.Location {
-	tz = strings.TrimSpace(tz)
-	if tz == "" {
-		loc, err := time.LoadLocation("Asia/Jakarta")
-		if err != nil {
-			return time.UTC
-		}
-		return loc
-	}
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
-		loc, _ = time.LoadLocation("Asia/Jakarta")
-		if loc == nil {
-			return time.UTC
-		}
-		return loc
-	}
-	return loc
+	return timezone.Load(tz)
 }
 
 type service struct {
-	repo Repository
+	repo    Repository
+	avatars AvatarStore // nil when no avatar bucket is configured
 }
 
-// NewService creates a new user service
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// NewService creates a new user service. avatars may be nil, in which case
+// avatar uploads return ErrAvatarsDisabled.
+func NewService(repo Repository, avatars AvatarStore) Service {
+	return &service{repo: repo, avatars: avatars}
 }
 
 func (s *service) GetProfile(ctx context.Context, userID string, timezone string) (*ProfileResponse, error) {
 	var (
-		profile  *Profile
-		metadata ProfileMetadata
+		profile *Profile
+		stats   profilestats.Counters
 	)
 
 	loc := resolveLocation(timezone)
@@ -57,11 +45,11 @@ func (s *service) GetProfile(ctx context.Context, userID string, timezone string
 	})
 
 	g.Go(func() error {
-		m, err := s.repo.GetProfileMetadata(ctx, userID, loc)
+		c, err := s.repo.GetProfileStats(ctx, userID, loc)
 		if err != nil {
 			return err
 		}
-		metadata = m
+		stats = c
 		return nil
 	})
 
@@ -69,15 +57,21 @@ func (s *service) GetProfile(ctx context.Context, userID string, timezone string
 		return nil, err
 	}
 
-	return buildProfileResponse(profile, metadata), nil
+	resp := s.profileResponse(ctx, profile, metadataFromStats(stats))
+	resp.XPProgress = newXPProgress(stats.XP)
+	return resp, nil
 }
 
 func (s *service) UpdateProfile(ctx context.Context, userID string, updates ProfileUpdateInput, timezone string) (*ProfileResponse, error) {
 	var (
-		updated  *Profile
-		metadata ProfileMetadata
+		updated *Profile
+		stats   profilestats.Counters
 	)
 
+	// A timezone change applies to the metadata returned with it.
+	if updates.Timezone != nil && *updates.Timezone != "" {
+		timezone = *updates.Timezone
+	}
 	loc := resolveLocation(timezone)
 	g, ctx := errgroup.WithContext(ctx)
 
@@ -91,11 +85,11 @@ func (s *service) UpdateProfile(ctx context.Context, userID string, updates Prof
 	})
 
 	g.Go(func() error {
-		m, err := s.repo.GetProfileMetadata(ctx, userID, loc)
+		c, err := s.repo.GetProfileStats(ctx, userID, loc)
 		if err != nil {
 			return err
 		}
-		metadata = m
+		stats = c
 		return nil
 	})
 
@@ -103,7 +97,43 @@ func (s *service) UpdateProfile(ctx context.Context, userID string, updates Prof
 		return nil, err
 	}
 
-	return buildProfileResponse(updated, metadata), nil
+	resp := s.profileResponse(ctx, updated, metadataFromStats(stats))
+	resp.XPProgress = newXPProgress(stats.XP)
+	return resp, nil
+}
+
+// ReconcileProfileMetadata recounts the profile counters from history,
+// replaces the stored ones and reports whether they had drifted. It also
+// awards any badge the points or counters unlock but the user lacks. Sessions
+// without a timezone are placed in the profile's timezone unless given one.
+func (s *service) ReconcileProfileMetadata(ctx context.Context, userID string, timezone string) (*MetadataReconciliation, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("missing user id")
+	}
+	profile, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if timezone == "" {
+		timezone = profile.Timezone
+	}
+	stored, actual, err := s.repo.RebuildProfileMetadata(ctx, userID, resolveLocation(timezone))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.awardEarnedBadges(ctx, userID, profile.PointsTotal, actual.Achievements()); err != nil {
+		return nil, fmt.Errorf("award badges: %w", err)
+	}
+	result := &MetadataReconciliation{
+		UserID: userID,
+		Actual: metadataFromStats(actual),
+		Drift:  stored == nil || !stored.Equal(actual),
+	}
+	if stored != nil {
+		m := metadataFromStats(*stored)
+		result.Stored = &m
+	}
+	return result, nil
 }
 
 func (s *service) ListChallenges(ctx context.Context) ([]ChallengeDefinition, error) {
@@ -113,6 +143,9 @@ func (s *service) ListChallenges(ctx context.Context) ([]ChallengeDefinition, er
 func (s *service) MigrateChallenges(ctx context.Context) error {
 	defs := challengeDefinitions()
 	for _, def := range defs {
+		if _, err := def.effectiveRule(); err != nil {
+			return err
+		}
 		if err := s.repo.CreateChallenge(ctx, def); err != nil {
 			return fmt.Errorf("failed to migrate challenge %s: %w", def.ID, err)
 		}
@@ -129,136 +162,101 @@ func (s *service) GetChallengesMe(ctx context.Context, userID string, timezone s
 	if err != nil {
 		return nil, err
 	}
-	loc := resolveLocation(timezone)
-	metadata, err := s.repo.GetProfileMetadata(ctx, userID, loc)
+
+	defs, err := s.repo.ListChallenges(ctx)
 	if err != nil {
 		return nil, err
 	}
-	now := time.Now().In(loc)
-	today := truncateToDay(now)
-
-	// Look back enough days to compute streaks safely.
-	// For now 45 days is more than enough for the current challenge set.
-	start := today.AddDate(0, 0, -45)
-	end := today.AddDate(0, 0, 1) // [start, end)
 
-	minsByDate, err := s.repo.GetDailyMinutesByDate(ctx, userID, start, end, loc)
+	statuses, err := s.challengeStatuses(ctx, userID, defs, time.Now().In(resolveLocation(timezone)))
 	if err != nil {
 		return nil, err
 	}
 
-	// Get week start (Monday) for weekly challenges
-	weekStart := getWeekStart(today)
-
-	defs, err := s.repo.ListChallenges(ctx)
+	badges, err := s.heldBadges(ctx, userID, BadgeKindPoints)
 	if err != nil {
 		return nil, err
 	}
 
-	statuses := make([]ChallengeStatus, 0, len(defs))
-	for _, def := range defs {
-		var progress ChallengeProgress
-		var completed bool
-
-		switch def.RuleType {
-		case ChallengeRuleDailyMinutesStreak:
-			progress = computeDailyMinutesStreakProgress(def, minsByDate, today)
-			completed = progress.CurrentStreakDays >= def.ConsecutiveDays
-
-		case ChallengeRuleWeeklyShares:
-			shareCount, err := s.repo.GetWeeklyShareCount(ctx, userID, weekStart)
-			if err != nil {
-				return nil, err
-			}
-			lastWeekCount, err := s.repo.GetWeeklyShareCount(ctx, userID, weekStart.AddDate(0, 0, -7))
-			if err != nil {
-				return nil, err
-			}
-			maxCount := shareCount
-			if lastWeekCount > maxCount {
-				maxCount = lastWeekCount
-			}
-			progress = computeWeeklySharesProgress(def, maxCount)
-			completed = maxCount >= def.TargetCount
-
-		case ChallengeRuleStreakMilestone:
-			progress = computeStreakMilestoneProgress(def, metadata.LongestStreak)
-			completed = metadata.LongestStreak >= def.TargetStreak
-
-		case ChallengeRuleCyclesAndMindfulness:
-			// Allow today or yesterday (to handle users opening the app after midnight)
-			todayCycles, err := s.repo.GetCyclesByDate(ctx, userID, today, loc)
-			if err != nil {
-				return nil, err
-			}
-			todayMindfulness, err := s.repo.GetMindfulnessMinutesByDate(ctx, userID, today, loc)
-			if err != nil {
-				return nil, err
-			}
-
-			yesterday := today.AddDate(0, 0, -1)
-			yesterdayCycles, err := s.repo.GetCyclesByDate(ctx, userID, yesterday, loc)
-			if err != nil {
-				return nil, err
-			}
-			yesterdayMindfulness, err := s.repo.GetMindfulnessMinutesByDate(ctx, userID, yesterday, loc)
-			if err != nil {
-				return nil, err
-			}
-
-			// Maximize progress from either today or yesterday
-			progressToday := computeCyclesAndMindfulnessProgress(def, todayCycles, todayMindfulness)
-			progressYesterday := computeCyclesAndMindfulnessProgress(def, yesterdayCycles, yesterdayMindfulness)
+	return &ChallengesMeResponse{
+		PointsTotal: profile.PointsTotal,
+		Badges:      badges,
+		Challenges:  statuses,
+	}, nil
+}
 
-			if progressYesterday.ProgressPercent > progressToday.ProgressPercent {
-				progress = progressYesterday
-				completed = yesterdayCycles >= def.TargetCycles && yesterdayMindfulness >= def.TargetMindfulnessMinutes
-			} else {
-				progress = progressToday
-				completed = todayCycles >= def.TargetCycles && todayMindfulness >= def.TargetMindfulnessMinutes
-			}
+// challengeStatuses evaluates the running definitions for the user at now.
+// Definitions that fail validation are skipped rather than failing the whole
+// list, so one bad document cannot hide every challenge.
+func (s *service) challengeStatuses(ctx context.Context, userID string, defs []ChallengeDefinition, now time.Time) ([]ChallengeStatus, error) {
+	type candidate struct {
+		def      ChallengeDefinition
+		rule     ChallengeRule
+		current  challengeEval
+		previous *challengeEval
+	}
 
-		default:
-			// Unknown challenge type; ignore for now.
+	today := truncateToDay(now)
+	var (
+		candidates []candidate
+		evals      []challengeEval
+	)
+	for _, def := range defs {
+		rule, err := def.effectiveRule()
+		if err != nil {
 			continue
 		}
+		period, ok := def.periodAt(rule, now)
+		if !ok {
+			continue // not started yet or already over
+		}
+		c := candidate{def: def, rule: rule, current: challengeEval{rule: rule, asOf: today, period: period}}
+		evals = append(evals, c.current)
+		if rule.IncludePrevious {
+			if prev, ok := def.previousPeriod(rule, period); ok {
+				asOf := truncateToDay(prev.EndsAt.Add(-time.Nanosecond).In(now.Location()))
+				c.previous = &challengeEval{rule: rule, asOf: asOf, period: prev}
+				evals = append(evals, *c.previous)
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	activity, err := s.loadChallengeActivity(ctx, userID, evals, today)
+	if err != nil {
+		return nil, err
+	}
 
-		claimedAt, err := s.repo.GetChallengeClaimedAt(ctx, userID, def.ID)
+	statuses := make([]ChallengeStatus, 0, len(candidates))
+	for _, c := range candidates {
+		claims, err := s.repo.ListChallengeClaims(ctx, userID, c.def.ID)
 		if err != nil {
 			return nil, err
 		}
-		
-		claimed := false
-		if !claimedAt.IsZero() {
-			switch def.RuleType {
-			case ChallengeRuleDailyMinutesStreak, ChallengeRuleStreakMilestone:
-				claimed = true
-			case ChallengeRuleWeeklyShares:
-				if claimedAt.After(weekStart) || claimedAt.Equal(weekStart) {
-					claimed = true
-				}
-			case ChallengeRuleCyclesAndMindfulness:
-				if claimedAt.After(today) || claimedAt.Equal(today) {
-					claimed = true
-				}
-			default:
-				claimed = true
-			}
-		}
+		history, claimed := c.def.claimHistory(c.rule, claims, now.Location())
 
-		statuses = append(statuses, ChallengeStatus{
-			Challenge: def,
+		progress, completed := c.current.evaluate(activity)
+		status := ChallengeStatus{
+			Challenge: c.def,
+			Period:    c.current.period,
 			Progress:  progress,
 			Completed: completed,
-			Claimed:   claimed,
-		})
+			Claimed:   claimed[c.current.period.Key],
+			History:   history,
+		}
+		// Until the current period is done, show a finished but unclaimed
+		// previous period so it can still be claimed.
+		if !completed && c.previous != nil && !claimed[c.previous.period.Key] {
+			if prevProgress, prevCompleted := c.previous.evaluate(activity); prevCompleted {
+				status.Period = c.previous.period
+				status.Progress = prevProgress
+				status.Completed = true
+			}
+		}
+		status.Period.RemainingSeconds = status.Period.remaining(now)
+		statuses = append(statuses, status)
 	}
-
-	return &ChallengesMeResponse{
-		PointsTotal: profile.PointsTotal,
-		Badges:      badgesForPoints(profile.PointsTotal),
-		Challenges:  statuses,
-	}, nil
+	return statuses, nil
 }
 
 func (s *service) ClaimChallenge(ctx context.Context, userID, challengeID string, timezone string) (*ClaimChallengeResponse, error) {
@@ -286,143 +284,63 @@ func (s *service) ClaimChallenge(ctx context.Context, userID, challengeID string
 	if def == nil {
 		return nil, fmt.Errorf("unknown challenge")
 	}
-
-	// Ensure it is completed before awarding points.
-	loc := resolveLocation(timezone)
-	now := time.Now().In(loc)
-	today := truncateToDay(now)
-
-	eligible := false
-
-	switch def.RuleType {
-	case ChallengeRuleDailyMinutesStreak:
-		start := today.AddDate(0, 0, -45)
-		end := today.AddDate(0, 0, 1)
-		minsByDate, err := s.repo.GetDailyMinutesByDate(ctx, userID, start, end, loc)
-		if err != nil {
-			return nil, err
-		}
-		progress := computeDailyMinutesStreakProgress(*def, minsByDate, today)
-		eligible = progress.CurrentStreakDays >= def.ConsecutiveDays
-
-	case ChallengeRuleWeeklyShares:
-		weekStart := getWeekStart(today)
-		shareCount, err := s.repo.GetWeeklyShareCount(ctx, userID, weekStart)
-		if err != nil {
-			return nil, err
-		}
-		lastWeekCount, err := s.repo.GetWeeklyShareCount(ctx, userID, weekStart.AddDate(0, 0, -7))
-		if err != nil {
-			return nil, err
-		}
-		maxCount := shareCount
-		if lastWeekCount > maxCount {
-			maxCount = lastWeekCount
-		}
-		eligible = maxCount >= def.TargetCount
-
-	case ChallengeRuleStreakMilestone:
-		metadata, err := s.repo.GetProfileMetadata(ctx, userID, loc)
-		if err != nil {
-			return nil, err
-		}
-		eligible = metadata.LongestStreak >= def.TargetStreak
-
-	case ChallengeRuleCyclesAndMindfulness:
-		// Check today
-		todayCycles, err := s.repo.GetCyclesByDate(ctx, userID, today, loc)
-		if err != nil {
-			return nil, err
-		}
-		todayMindfulness, err := s.repo.GetMindfulnessMinutesByDate(ctx, userID, today, loc)
-		if err != nil {
-			return nil, err
-		}
-		eligible = todayCycles >= def.TargetCycles && todayMindfulness >= def.TargetMindfulnessMinutes
-
-		if !eligible {
-			// Check yesterday as backup
-			yesterday := today.AddDate(0, 0, -1)
-			yesterdayCycles, err := s.repo.GetCyclesByDate(ctx, userID, yesterday, loc)
-			if err != nil {
-				return nil, err
-			}
-			yesterdayMindfulness, err := s.repo.GetMindfulnessMinutesByDate(ctx, userID, yesterday, loc)
-			if err != nil {
-				return nil, err
-			}
-			eligible = yesterdayCycles >= def.TargetCycles && yesterdayMindfulness >= def.TargetMindfulnessMinutes
-		}
-
-	default:
-		return nil, fmt.Errorf("unsupported challenge type")
+	if _, err := def.effectiveRule(); err != nil {
+		return nil, err
 	}
 
-	if eligible {
-		// Check if it's already claimed for the current period
-		claimedAt, err := s.repo.GetChallengeClaimedAt(ctx, userID, challengeID)
-		if err != nil {
-			return nil, err
-		}
-		if !claimedAt.IsZero() {
-			already := false
-			switch def.RuleType {
-			case ChallengeRuleDailyMinutesStreak, ChallengeRuleStreakMilestone:
-				already = true
-			case ChallengeRuleWeeklyShares:
-				weekStart := getWeekStart(today)
-				if claimedAt.After(weekStart) || claimedAt.Equal(weekStart) {
-					already = true
-				}
-			case ChallengeRuleCyclesAndMindfulness:
-				if claimedAt.After(today) || claimedAt.Equal(today) {
-					already = true
-				}
-			default:
-				already = true
-			}
-			if already {
-				profile, _ := s.repo.GetProfile(ctx, userID)
-				return &ClaimChallengeResponse{
-					ChallengeID:    challengeID,
-					Claimed:        false,
-					AlreadyClaimed: true,
-					PointsAwarded:  0,
-					PointsTotal:    func() int { if profile != nil { return profile.PointsTotal }; return 0 }(),
-				}, nil
-			}
-		}
+	// Ensure it is completed before awarding points.
+	now := time.Now().In(resolveLocation(timezone))
+	statuses, err := s.challengeStatuses(ctx, userID, []ChallengeDefinition{*def}, now)
+	if err != nil {
+		return nil, err
 	}
 
-	if !eligible {
-		// Not eligible yet; return current points for UI.
+	if len(statuses) == 0 || !statuses[0].Completed || statuses[0].Claimed {
+		// Not running, not eligible yet or already claimed for the period;
+		// return current points for UI.
 		profile, _ := s.repo.GetProfile(ctx, userID)
-		return &ClaimChallengeResponse{
+		resp := &ClaimChallengeResponse{
 			ChallengeID:    challengeID,
 			Claimed:        false,
 			AlreadyClaimed: false,
 			PointsAwarded:  0,
 			PointsTotal:    func() int { if profile != nil { return profile.PointsTotal }; return 0 }(),
-		}, nil
+		}
+		if len(statuses) > 0 {
+			resp.PeriodKey = statuses[0].Period.Key
+			resp.AlreadyClaimed = statuses[0].Completed && statuses[0].Claimed
+		}
+		return resp, nil
 	}
 
-	newTotal, claimedAt, already, err := s.repo.ClaimChallenge(ctx, userID, challengeID, def.RewardPoints)
+	period := statuses[0].Period
+	claim := ChallengeClaim{
+		ChallengeID:   challengeID,
+		PeriodKey:     period.Key,
+		PeriodStart:   period.StartsAt,
+		PeriodEnd:     period.EndsAt,
+		PointsAwarded: def.RewardPoints,
+		ClaimedAt:     now.UTC(),
+	}
+	newTotal, already, err := s.repo.ClaimChallenge(ctx, userID, claim)
 	if err != nil {
 		return nil, err
 	}
 
 	resp := &ClaimChallengeResponse{
 		ChallengeID:    challengeID,
+		PeriodKey:      period.Key,
 		Claimed:        !already,
 		AlreadyClaimed: already,
 		PointsAwarded:  0,
 		PointsTotal:    newTotal,
 	}
-	if already {
-		resp.PointsAwarded = 0
-	} else {
+	if !already {
 		resp.PointsAwarded = def.RewardPoints
-		resp.ClaimedAt = claimedAt
+		resp.ClaimedAt = claim.ClaimedAt
+		// The claim stands if this fails; reconcile_profile_stats.go
+		// backfills missed badges.
+		_ = s.awardPointBadges(ctx, userID, newTotal)
 	}
 	return resp, nil
 }
@@ -457,6 +375,11 @@ func buildProfileResponse(profile *Profile, metadata ProfileMetadata) *ProfileRe
 		Bio:             profile.Bio,
 		Birthdate:       profile.Birthdate,
 		PointsTotal:     profile.PointsTotal,
+		Timezone:        profile.Timezone,
+		Handle:          profile.Handle,
+		Email:           profile.Email,
+		DisplayName:     profile.DisplayName,
+		AvatarURL:       profile.AvatarURL,
 		ProfileMetadata: metadata,
 		CreatedAt:       profile.CreatedAt,
 		UpdatedAt:       profile.UpdatedAt,
@@ -468,93 +391,6 @@ func truncateToDay(t time.Time) time.Time {
 	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
 
-func computeDailyMinutesStreakProgress(def ChallengeDefinition, minsByDate map[string]int, today time.Time) ChallengeProgress {
-	minPerDay := def.MinMinutesPerDay
-	targetDays := def.ConsecutiveDays
-
-	// 1. Calculate maxStreak to see if they completed it
-	maxStreak := 0
-	tempStreak := 0
-	for i := 45; i >= 0; i-- { // iterate chronologically
-		day := today.AddDate(0, 0, -i)
-		key := day.Format("2006-01-02")
-		mins := minsByDate[key]
-		if mins >= minPerDay {
-			tempStreak++
-			if tempStreak > maxStreak {
-				maxStreak = tempStreak
-			}
-		} else {
-			tempStreak = 0
-		}
-	}
-
-	// 2. Calculate activeStreak
-	activeStreak := 0
-	for i := 0; i <= 45; i++ {
-		day := today.AddDate(0, 0, -i)
-		key := day.Format("2006-01-02")
-		mins := minsByDate[key]
-		if mins >= minPerDay {
-			activeStreak++
-		} else {
-			if i == 0 {
-				// missed today is fine, the streak is still active from yesterday
-				continue
-			}
-			// missed yesterday (or earlier), streak is broken
-			break
-		}
-	}
-
-	displayedStreak := activeStreak
-	if maxStreak >= targetDays {
-		displayedStreak = maxStreak
-	}
-
-	minsToday := minsByDate[today.Format("2006-01-02")]
-	percent := (displayedStreak * 100) / targetDays
-	if percent > 100 {
-		percent = 100
-	}
-
-	return ChallengeProgress{
-		CurrentStreakDays: displayedStreak,
-		TargetStreakDays:  targetDays,
-		MinMinutesPerDay:  minPerDay,
-		MinutesToday:      minsToday,
-		ProgressPercent:   percent,
-	}
-}
-
-func computeWeeklySharesProgress(def ChallengeDefinition, shareCount int) ChallengeProgress {
-	target := def.TargetCount
-	percent := (shareCount * 100) / target
-	if percent > 100 {
-		percent = 100
-	}
-
-	return ChallengeProgress{
-		CurrentCount:    shareCount,
-		TargetCount:     target,
-		ProgressPercent: percent,
-	}
-}
-
-func computeStreakMilestoneProgress(def ChallengeDefinition, currentStreak int) ChallengeProgress {
-	target := def.TargetStreak
-	percent := (currentStreak * 100) / target
-	if percent > 100 {
-		percent = 100
-	}
-
-	return ChallengeProgress{
-		CurrentStreak:   currentStreak,
-		TargetStreak:    target,
-		ProgressPercent: percent,
-	}
-}
-
 // getWeekStart returns the Monday of the week containing the given date.
 func getWeekStart(t time.Time) time.Time {
 	weekday := int(t.Weekday())
@@ -565,35 +401,3 @@ func getWeekStart(t time.Time) time.Time {
 	return truncateToDay(t.AddDate(0, 0, -(weekday - 1)))
 }
 
-func computeCyclesAndMindfulnessProgress(def ChallengeDefinition, currentCycles, currentMindfulness int) ChallengeProgress {
-	targetCycles := def.TargetCycles
-	targetMindfulness := def.TargetMindfulnessMinutes
-
-	// Calculate combined progress (average of both)
-	cyclePercent := 0
-	if targetCycles > 0 {
-		cyclePercent = (currentCycles * 100) / targetCycles
-		if cyclePercent > 100 {
-			cyclePercent = 100
-		}
-	}
-
-	mindfulnessPercent := 0
-	if targetMindfulness > 0 {
-		mindfulnessPercent = (currentMindfulness * 100) / targetMindfulness
-		if mindfulnessPercent > 100 {
-			mindfulnessPercent = 100
-		}
-	}
-
-	// Overall progress is the average of both components
-	percent := (cyclePercent + mindfulnessPercent) / 2
-
-	return ChallengeProgress{
-		CurrentCycles:             currentCycles,
-		TargetCycles:              targetCycles,
-		CurrentMindfulnessMinutes: currentMindfulness,
-		TargetMindfulnessMinutes:  targetMindfulness,
-		ProgressPercent:           percent,
-	}
-}
@@ -3,26 +3,41 @@ package user
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/focusnest/shared-libs/preferences"
+	"github.com/focusnest/shared-libs/profilestats"
 )
 
 type fakeRepo struct {
 	getProfileFn                 func(context.Context, string) (*Profile, error)
 	upsertProfileFn              func(context.Context, string, ProfileUpdateInput) (*Profile, error)
-	getProfileMetaFn             func(context.Context, string, *time.Location) (ProfileMetadata, error)
+	getProfileStatsFn            func(context.Context, string, *time.Location) (profilestats.Counters, error)
+	rebuildProfileMetaFn         func(context.Context, string, *time.Location) (*profilestats.Counters, profilestats.Counters, error)
 	getDailyMinutesByDateFn      func(context.Context, string, time.Time, time.Time, *time.Location) (map[string]int, error)
 	listChallengesFn             func(context.Context) ([]ChallengeDefinition, error)
 	createChallengeFn            func(context.Context, ChallengeDefinition) error
 	isChallengeClaimedFn         func(context.Context, string, string) (bool, error)
-	claimChallengeFn             func(context.Context, string, string, int) (int, time.Time, bool, error)
-	getWeeklyShareCountFn        func(context.Context, string, time.Time) (int, error)
+	claimChallengeFn             func(context.Context, string, ChallengeClaim) (int, bool, error)
+	listProductivityEntriesFn    func(context.Context, string, time.Time, time.Time) ([]ProductivityEntry, error)
+	listShareTimesFn             func(context.Context, string, time.Time, time.Time) ([]time.Time, error)
+	listMindfulnessSessionsFn    func(context.Context, string, time.Time, time.Time) ([]MindfulnessSession, error)
 	recordShareFn                func(context.Context, string, string) error
 	getCurrentStreakFn           func(context.Context, string, *time.Location) (int, error)
-	getCyclesByDateFn            func(context.Context, string, time.Time, *time.Location) (int, error)
-	getMindfulnessMinutesByDateFn func(context.Context, string, time.Time, *time.Location) (int, error)
 	recordMindfulnessFn          func(context.Context, string, int) error
-	getChallengeClaimedAtFn      func(context.Context, string, string) (time.Time, error)
+	listChallengeClaimsFn        func(context.Context, string, string) ([]ChallengeClaim, error)
+	listBadgeAwardsFn            func(context.Context, string) ([]BadgeAward, error)
+	awardBadgesFn                func(context.Context, string, []BadgeAward) error
+	markBadgesSeenFn             func(context.Context, string, time.Time) error
+	syncClerkUserFn              func(context.Context, string, string, ClerkUser) (SyncOutcome, error)
+	updatePreferencesFn          func(context.Context, string, func(preferences.Preferences) (preferences.Preferences, error)) (*preferences.Preferences, error)
+
+	FriendRepository
+	IdentityRepository
+	PointsRepository
+	MindfulnessRepository
 }
 
 func (f *fakeRepo) GetProfile(ctx context.Context, userID string) (*Profile, error) {
@@ -39,11 +54,29 @@ func (f *fakeRepo) UpsertProfile(ctx context.Context, userID string, updates Pro
 	return nil, errors.New("upsertProfileFn not provided")
 }
 
-func (f *fakeRepo) GetProfileMetadata(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-	if f.getProfileMetaFn != nil {
-		return f.getProfileMetaFn(ctx, userID, loc)
+func (f *fakeRepo) GetProfileStats(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+	if f.getProfileStatsFn != nil {
+		return f.getProfileStatsFn(ctx, userID, loc)
+	}
+	return profilestats.Counters{}, errors.New("getProfileStatsFn not provided")
+}
+
+// streakCounters returns counters with one session on each of days
+// consecutive days.
+func streakCounters(days int) profilestats.Counters {
+	var c profilestats.Counters
+	start := time.Date(2025, 11, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < days; i++ {
+		c.Replace(nil, &profilestats.Session{StartTime: start.AddDate(0, 0, i), Timezone: "UTC", NumCycle: 1})
+	}
+	return c
+}
+
+func (f *fakeRepo) RebuildProfileMetadata(ctx context.Context, userID string, loc *time.Location) (*profilestats.Counters, profilestats.Counters, error) {
+	if f.rebuildProfileMetaFn != nil {
+		return f.rebuildProfileMetaFn(ctx, userID, loc)
 	}
-	return ProfileMetadata{}, errors.New("getProfileMetaFn not provided")
+	return nil, profilestats.Counters{}, errors.New("rebuildProfileMetaFn not provided")
 }
 
 func (f *fakeRepo) GetDailyMinutesByDate(ctx context.Context, userID string, startDate, endDate time.Time, loc *time.Location) (map[string]int, error) {
@@ -74,25 +107,39 @@ func (f *fakeRepo) IsChallengeClaimed(ctx context.Context, userID, challengeID s
 	return false, nil
 }
 
-func (f *fakeRepo) GetChallengeClaimedAt(ctx context.Context, userID, challengeID string) (time.Time, error) {
-	if f.getChallengeClaimedAtFn != nil {
-		return f.getChallengeClaimedAtFn(ctx, userID, challengeID)
+func (f *fakeRepo) ListChallengeClaims(ctx context.Context, userID, challengeID string) ([]ChallengeClaim, error) {
+	if f.listChallengeClaimsFn != nil {
+		return f.listChallengeClaimsFn(ctx, userID, challengeID)
 	}
-	return time.Time{}, nil
+	return nil, nil
 }
 
-func (f *fakeRepo) ClaimChallenge(ctx context.Context, userID, challengeID string, points int) (int, time.Time, bool, error) {
+func (f *fakeRepo) ClaimChallenge(ctx context.Context, userID string, claim ChallengeClaim) (int, bool, error) {
 	if f.claimChallengeFn != nil {
-		return f.claimChallengeFn(ctx, userID, challengeID, points)
+		return f.claimChallengeFn(ctx, userID, claim)
 	}
-	return 0, time.Time{}, false, errors.New("claimChallengeFn not provided")
+	return 0, false, errors.New("claimChallengeFn not provided")
 }
 
-func (f *fakeRepo) GetWeeklyShareCount(ctx context.Context, userID string, weekStart time.Time) (int, error) {
-	if f.getWeeklyShareCountFn != nil {
-		return f.getWeeklyShareCountFn(ctx, userID, weekStart)
+func (f *fakeRepo) ListProductivityEntries(ctx context.Context, userID string, start, end time.Time) ([]ProductivityEntry, error) {
+	if f.listProductivityEntriesFn != nil {
+		return f.listProductivityEntriesFn(ctx, userID, start, end)
 	}
-	return 0, nil
+	return nil, nil
+}
+
+func (f *fakeRepo) ListShareTimes(ctx context.Context, userID string, start, end time.Time) ([]time.Time, error) {
+	if f.listShareTimesFn != nil {
+		return f.listShareTimesFn(ctx, userID, start, end)
+	}
+	return nil, nil
+}
+
+func (f *fakeRepo) ListMindfulnessSessions(ctx context.Context, userID string, start, end time.Time) ([]MindfulnessSession, error) {
+	if f.listMindfulnessSessionsFn != nil {
+		return f.listMindfulnessSessionsFn(ctx, userID, start, end)
+	}
+	return nil, nil
 }
 
 func (f *fakeRepo) RecordShare(ctx context.Context, userID string, shareType string) error {
@@ -109,38 +156,65 @@ func (f *fakeRepo) GetCurrentStreak(ctx context.Context, userID string, loc *tim
 	return 0, nil
 }
 
-func (f *fakeRepo) GetCyclesByDate(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error) {
-	if f.getCyclesByDateFn != nil {
-		return f.getCyclesByDateFn(ctx, userID, date, loc)
+func (f *fakeRepo) RecordMindfulness(ctx context.Context, userID string, minutes int) error {
+	if f.recordMindfulnessFn != nil {
+		return f.recordMindfulnessFn(ctx, userID, minutes)
 	}
-	return 0, nil
+	return nil
 }
 
-func (f *fakeRepo) GetMindfulnessMinutesByDate(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error) {
-	if f.getMindfulnessMinutesByDateFn != nil {
-		return f.getMindfulnessMinutesByDateFn(ctx, userID, date, loc)
+func (f *fakeRepo) ListBadgeAwards(ctx context.Context, userID string) ([]BadgeAward, error) {
+	if f.listBadgeAwardsFn != nil {
+		return f.listBadgeAwardsFn(ctx, userID)
 	}
-	return 0, nil
+	return nil, nil
 }
 
-func (f *fakeRepo) RecordMindfulness(ctx context.Context, userID string, minutes int) error {
-	if f.recordMindfulnessFn != nil {
-		return f.recordMindfulnessFn(ctx, userID, minutes)
+func (f *fakeRepo) AwardBadges(ctx context.Context, userID string, awards []BadgeAward) error {
+	if f.awardBadgesFn != nil {
+		return f.awardBadgesFn(ctx, userID, awards)
 	}
 	return nil
 }
 
+func (f *fakeRepo) MarkBadgesSeen(ctx context.Context, userID string, at time.Time) error {
+	if f.markBadgesSeenFn != nil {
+		return f.markBadgesSeenFn(ctx, userID, at)
+	}
+	return nil
+}
+
+func (f *fakeRepo) SyncClerkUser(ctx context.Context, eventID, eventType string, u ClerkUser) (SyncOutcome, error) {
+	if f.syncClerkUserFn != nil {
+		return f.syncClerkUserFn(ctx, eventID, eventType, u)
+	}
+	return SyncApplied, nil
+}
+
+func (f *fakeRepo) RecordWebhookEvent(ctx context.Context, eventID, eventType string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeRepo) UpdatePreferences(ctx context.Context, userID string, update func(preferences.Preferences) (preferences.Preferences, error)) (*preferences.Preferences, error) {
+	if f.updatePreferencesFn != nil {
+		return f.updatePreferencesFn(ctx, userID, update)
+	}
+	return nil, errors.New("updatePreferencesFn not provided")
+}
+
 func TestServiceGetProfile_DefaultsWhenMissing(t *testing.T) {
 	repo := &fakeRepo{
 		getProfileFn: func(ctx context.Context, userID string) (*Profile, error) {
 			return defaultProfile(userID), nil
 		},
-		getProfileMetaFn: func(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-			return ProfileMetadata{LongestStreak: 5, TotalSessions: 20, TotalCycle: 7}, nil
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			c := streakCounters(5)
+			c.TotalSessions, c.TotalCycle = 20, 7
+			return c, nil
 		},
 	}
 
-	svc := NewService(repo)
+	svc := NewService(repo, nil)
 	resp, err := svc.GetProfile(context.Background(), "user-123", "UTC")
 	if err != nil {
 		t.Fatalf("GetProfile returned error: %v", err)
@@ -160,12 +234,12 @@ func TestServiceUpdateProfile_ConcurrentFetches(t *testing.T) {
 		upsertProfileFn: func(ctx context.Context, userID string, updates ProfileUpdateInput) (*Profile, error) {
 			return profile, nil
 		},
-		getProfileMetaFn: func(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-			return ProfileMetadata{TotalProductivities: 3, TotalCycle: 9}, nil
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			return profilestats.Counters{TotalCycle: 9, Categories: map[string]int{"Work": 1, "Study": 1, "Read": 1}}, nil
 		},
 	}
 
-	svc := NewService(repo)
+	svc := NewService(repo, nil)
 	resp, err := svc.UpdateProfile(context.Background(), profile.UserID, ProfileUpdateInput{}, "UTC")
 	if err != nil {
 		t.Fatalf("UpdateProfile returned error: %v", err)
@@ -179,25 +253,48 @@ func TestServiceUpdateProfile_ConcurrentFetches(t *testing.T) {
 	}
 }
 
+func TestServiceUpdateProfile_Timezone(t *testing.T) {
+	var gotLoc string
+	repo := &fakeRepo{
+		upsertProfileFn: func(ctx context.Context, userID string, updates ProfileUpdateInput) (*Profile, error) {
+			return &Profile{UserID: userID, Timezone: *updates.Timezone}, nil
+		},
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			gotLoc = loc.String()
+			return profilestats.Counters{}, nil
+		},
+	}
+
+	tz := "Europe/Berlin"
+	svc := NewService(repo, nil)
+	resp, err := svc.UpdateProfile(context.Background(), "user-abc", ProfileUpdateInput{Timezone: &tz}, "Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("UpdateProfile returned error: %v", err)
+	}
+	if resp.Timezone != tz {
+		t.Fatalf("expected timezone %q, got %q", tz, resp.Timezone)
+	}
+	if gotLoc != tz {
+		t.Fatalf("expected metadata in the new timezone, got %q", gotLoc)
+	}
+}
+
 func TestServiceGetChallengesMe_ProgressLogic(t *testing.T) {
 	loc, _ := time.LoadLocation("UTC")
 	now := time.Now().In(loc)
-	todayStr := now.Format("2006-01-02")
-	yesterdayStr := now.AddDate(0, 0, -1).Format("2006-01-02")
-	dayBeforeStr := now.AddDate(0, 0, -2).Format("2006-01-02")
 
 	repo := &fakeRepo{
 		getProfileFn: func(ctx context.Context, userID string) (*Profile, error) {
 			return &Profile{UserID: userID, PointsTotal: 100}, nil
 		},
-		getProfileMetaFn: func(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-			return ProfileMetadata{LongestStreak: 12}, nil
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			return streakCounters(12), nil
 		},
-		getDailyMinutesByDateFn: func(ctx context.Context, userID string, start, end time.Time, loc *time.Location) (map[string]int, error) {
-			return map[string]int{
-				todayStr:     130,
-				yesterdayStr: 120,
-				dayBeforeStr: 125,
+		listProductivityEntriesFn: func(ctx context.Context, userID string, start, end time.Time) ([]ProductivityEntry, error) {
+			return []ProductivityEntry{
+				{StartTime: now, TimeElapsed: 130 * 60},
+				{StartTime: now.AddDate(0, 0, -1), TimeElapsed: 120 * 60},
+				{StartTime: now.AddDate(0, 0, -2), TimeElapsed: 125 * 60},
 			}, nil
 		},
 		listChallengesFn: func(ctx context.Context) ([]ChallengeDefinition, error) {
@@ -220,12 +317,12 @@ func TestServiceGetChallengesMe_ProgressLogic(t *testing.T) {
 		isChallengeClaimedFn: func(ctx context.Context, userID, challengeID string) (bool, error) {
 			return false, nil
 		},
-		claimChallengeFn: func(ctx context.Context, userID, challengeID string, points int) (int, time.Time, bool, error) {
-			return 150, time.Now(), false, nil
+		claimChallengeFn: func(ctx context.Context, userID string, claim ChallengeClaim) (int, bool, error) {
+			return 150, false, nil
 		},
 	}
 
-	svc := NewService(repo)
+	svc := NewService(repo, nil)
 	resp, err := svc.GetChallengesMe(context.Background(), "user-1", "UTC")
 	if err != nil {
 		t.Fatalf("GetChallengesMe failed: %v", err)
@@ -255,24 +352,22 @@ func TestServiceGetChallengesMe_ProgressLogic(t *testing.T) {
 }
 
 func TestServiceGetChallengesMe_ComplexRules(t *testing.T) {
+	now := time.Now().UTC()
 	repo := &fakeRepo{
 		getProfileFn: func(ctx context.Context, userID string) (*Profile, error) {
 			return &Profile{UserID: userID, PointsTotal: 100}, nil
 		},
-		getProfileMetaFn: func(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-			return ProfileMetadata{}, nil
-		},
-		getDailyMinutesByDateFn: func(ctx context.Context, userID string, start, end time.Time, loc *time.Location) (map[string]int, error) {
-			return map[string]int{}, nil
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			return profilestats.Counters{}, nil
 		},
-		getWeeklyShareCountFn: func(ctx context.Context, userID string, weekStart time.Time) (int, error) {
-			return 3, nil // Meets target
+		listShareTimesFn: func(ctx context.Context, userID string, start, end time.Time) ([]time.Time, error) {
+			return []time.Time{now, now, now}, nil // Meets target
 		},
-		getCyclesByDateFn: func(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error) {
-			return 4, nil // Meets target
+		listProductivityEntriesFn: func(ctx context.Context, userID string, start, end time.Time) ([]ProductivityEntry, error) {
+			return []ProductivityEntry{{StartTime: now, TimeElapsed: 3000, NumCycle: 4}}, nil // Meets target
 		},
-		getMindfulnessMinutesByDateFn: func(ctx context.Context, userID string, date time.Time, loc *time.Location) (int, error) {
-			return 2, nil // Meets target
+		listMindfulnessSessionsFn: func(ctx context.Context, userID string, start, end time.Time) ([]MindfulnessSession, error) {
+			return []MindfulnessSession{{Minutes: 2, CompletedAt: now}}, nil // Meets target
 		},
 		listChallengesFn: func(ctx context.Context) ([]ChallengeDefinition, error) {
 			return []ChallengeDefinition{
@@ -294,12 +389,12 @@ func TestServiceGetChallengesMe_ComplexRules(t *testing.T) {
 		isChallengeClaimedFn: func(ctx context.Context, userID, challengeID string) (bool, error) {
 			return false, nil
 		},
-		claimChallengeFn: func(ctx context.Context, userID, challengeID string, points int) (int, time.Time, bool, error) {
-			return 150, time.Now(), false, nil
+		claimChallengeFn: func(ctx context.Context, userID string, claim ChallengeClaim) (int, bool, error) {
+			return 150, false, nil
 		},
 	}
 
-	svc := NewService(repo)
+	svc := NewService(repo, nil)
 	resp, err := svc.GetChallengesMe(context.Background(), "user-1", "UTC")
 	if err != nil {
 		t.Fatalf("GetChallengesMe failed: %v", err)
@@ -340,12 +435,12 @@ func TestClaimChallenge_NotEligible(t *testing.T) {
 				},
 			}, nil
 		},
-		getProfileMetaFn: func(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-			return ProfileMetadata{LongestStreak: 5}, nil // Not eligible (5 < 10)
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			return streakCounters(5), nil // Not eligible (5 < 10)
 		},
 	}
 
-	svc := NewService(repo)
+	svc := NewService(repo, nil)
 	resp, err := svc.ClaimChallenge(context.Background(), "user-1", "streak_10_days", "UTC")
 	if err != nil {
 		t.Fatalf("ClaimChallenge failed: %v", err)
@@ -374,15 +469,15 @@ func TestClaimChallenge_Eligible_Success(t *testing.T) {
 				},
 			}, nil
 		},
-		getProfileMetaFn: func(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-			return ProfileMetadata{LongestStreak: 15}, nil // Eligible (15 >= 10)
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			return streakCounters(15), nil // Eligible (15 >= 10)
 		},
-		claimChallengeFn: func(ctx context.Context, userID, challengeID string, points int) (int, time.Time, bool, error) {
-			return 150, time.Now(), false, nil // Success, new total is 150
+		claimChallengeFn: func(ctx context.Context, userID string, claim ChallengeClaim) (int, bool, error) {
+			return 150, false, nil // Success, new total is 150
 		},
 	}
 
-	svc := NewService(repo)
+	svc := NewService(repo, nil)
 	resp, err := svc.ClaimChallenge(context.Background(), "user-1", "streak_10_days", "UTC")
 	if err != nil {
 		t.Fatalf("ClaimChallenge failed: %v", err)
@@ -414,15 +509,15 @@ func TestClaimChallenge_AlreadyClaimed(t *testing.T) {
 				},
 			}, nil
 		},
-		getProfileMetaFn: func(ctx context.Context, userID string, loc *time.Location) (ProfileMetadata, error) {
-			return ProfileMetadata{LongestStreak: 15}, nil // Eligible
+		getProfileStatsFn: func(ctx context.Context, userID string, loc *time.Location) (profilestats.Counters, error) {
+			return streakCounters(15), nil // Eligible
 		},
-		claimChallengeFn: func(ctx context.Context, userID, challengeID string, points int) (int, time.Time, bool, error) {
-			return 150, time.Now(), true, nil // Already claimed! True
+		claimChallengeFn: func(ctx context.Context, userID string, claim ChallengeClaim) (int, bool, error) {
+			return 150, true, nil // Already claimed! True
 		},
 	}
 
-	svc := NewService(repo)
+	svc := NewService(repo, nil)
 	resp, err := svc.ClaimChallenge(context.Background(), "user-1", "streak_10_days", "UTC")
 	if err != nil {
 		t.Fatalf("ClaimChallenge failed: %v", err)
@@ -441,3 +536,42 @@ func TestClaimChallenge_AlreadyClaimed(t *testing.T) {
 		t.Errorf("Expected PointsTotal to be 150, got %d", resp.PointsTotal)
 	}
 }
+
+func TestServiceSyncClerkUser_NormalizesAndValidates(t *testing.T) {
+	var got ClerkUser
+	repo := &fakeRepo{
+		syncClerkUserFn: func(_ context.Context, eventID, eventType string, u ClerkUser) (SyncOutcome, error) {
+			got = u
+			return SyncApplied, nil
+		},
+	}
+	svc := NewService(repo, nil)
+
+	updatedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	outcome, err := svc.SyncClerkUser(context.Background(), "msg_1", "user.updated", ClerkUser{
+		UserID:      " user_1 ",
+		Email:       " ayu@example.org ",
+		DisplayName: "  " + strings.Repeat("a", maxDisplayName+5),
+		UpdatedAt:   updatedAt,
+	})
+	if err != nil || outcome != SyncApplied {
+		t.Fatalf("outcome=%q err=%v", outcome, err)
+	}
+	if got.UserID != "user_1" || got.Email != "ayu@example.org" || len(got.DisplayName) != maxDisplayName {
+		t.Fatalf("synced %+v", got)
+	}
+	if got.UpdatedAt.Location() != time.UTC || !got.UpdatedAt.Equal(updatedAt) {
+		t.Fatalf("updated at = %v", got.UpdatedAt)
+	}
+
+	for _, tc := range []struct{ eventID, userID string }{
+		{"", "user_1"},
+		{"msg/1", "user_1"},
+		{"msg_1", ""},
+		{"msg_1", "users/user_1"},
+	} {
+		if _, err := svc.SyncClerkUser(context.Background(), tc.eventID, "user.updated", ClerkUser{UserID: tc.userID}); !errors.Is(err, ErrInvalidWebhookEvent) {
+			t.Errorf("event %q user %q: err = %v", tc.eventID, tc.userID, err)
+		}
+	}
+}
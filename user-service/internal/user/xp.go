@@ -0,0 +1,16 @@
+package user
+
+import "github.com/focusnest/shared-libs/profilestats"
+
+// newXPProgress places xp on the level curve. focus-service keeps the XP in
+// the profile counters as sessions are written; see profilestats for the
+// rules.
+func newXPProgress(xp int) XPProgress {
+	level := profilestats.LevelForXP(xp)
+	return XPProgress{
+		XP:          xp,
+		Level:       level,
+		LevelXP:     xp - profilestats.XPForLevel(level),
+		NextLevelXP: profilestats.XPForLevel(level+1) - profilestats.XPForLevel(level),
+	}
+}
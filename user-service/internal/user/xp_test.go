@@ -0,0 +1,44 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/focusnest/shared-libs/profilestats"
+)
+
+func TestNewXPProgress(t *testing.T) {
+	tests := []struct {
+		xp   int
+		want XPProgress
+	}{
+		{0, XPProgress{XP: 0, Level: 1, LevelXP: 0, NextLevelXP: 100}},
+		{99, XPProgress{XP: 99, Level: 1, LevelXP: 99, NextLevelXP: 100}},
+		{450, XPProgress{XP: 450, Level: 3, LevelXP: 150, NextLevelXP: 300}},
+	}
+	for _, tt := range tests {
+		if got := newXPProgress(tt.xp); got != tt.want {
+			t.Errorf("newXPProgress(%d) = %+v, want %+v", tt.xp, got, tt.want)
+		}
+	}
+}
+
+func TestGetProfileReadsXPFromCounters(t *testing.T) {
+	// The fake has no write functions, so any write from the read fails it.
+	repo := &fakeRepo{
+		getProfileFn: func(_ context.Context, userID string) (*Profile, error) {
+			return &Profile{UserID: userID, Timezone: "UTC"}, nil
+		},
+		getProfileStatsFn: func(context.Context, string, *time.Location) (profilestats.Counters, error) {
+			return profilestats.Counters{XP: 450, Level: 3}, nil
+		},
+	}
+	resp, err := NewService(repo, nil).GetProfile(context.Background(), "u1", "")
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if want := newXPProgress(450); resp.XPProgress != want {
+		t.Fatalf("xp = %+v, want %+v", resp.XPProgress, want)
+	}
+}
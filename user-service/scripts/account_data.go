@@ -0,0 +1,101 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/storage"
+	"github.com/focusnest/user-service/internal/account"
+)
+
+// Exports or deletes a user's data:
+//
+//	go run account_data.go export <USER_ID> <OUT.zip>
+//	go run account_data.go delete <USER_ID>
+//	go run account_data.go status <USER_ID>
+//
+// With FIRESTORE_EMULATOR_HOST set it runs against the emulator's default
+// database. FOCUS_STORAGE_BUCKET adds session images (STORAGE_EMULATOR_HOST
+// is honoured too).
+func main() {
+	if len(os.Args) < 3 {
+		log.Fatal("Usage: go run account_data.go export|delete|status <USER_ID> [OUT.zip]")
+	}
+	command, userID := os.Args[1], os.Args[2]
+
+	ctx := context.Background()
+	projectID := os.Getenv("GCP_PROJECT_ID")
+	if projectID == "" {
+		projectID = "focusnest-470308"
+	}
+	databaseID := "focusnest-prod"
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") != "" {
+		databaseID = "(default)"
+	}
+
+	client, err := firestore.NewClientWithDatabase(ctx, projectID, databaseID)
+	if err != nil {
+		log.Fatalf("Failed to create Firestore client: %v", err)
+	}
+	defer client.Close()
+
+	var objects account.ObjectStore
+	if bucket := os.Getenv("FOCUS_STORAGE_BUCKET"); bucket != "" {
+		storageClient, err := storage.NewClient(ctx)
+		if err != nil {
+			log.Fatalf("Failed to create Storage client: %v", err)
+		}
+		defer storageClient.Close()
+		objects = account.NewGCSStore(storageClient, bucket)
+	}
+	accounts := account.NewService(client, objects)
+
+	switch command {
+	case "export":
+		if len(os.Args) < 4 {
+			log.Fatal("Usage: go run account_data.go export <USER_ID> <OUT.zip>")
+		}
+		out, err := os.Create(os.Args[3])
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", os.Args[3], err)
+		}
+		manifest, err := accounts.Export(ctx, userID, out)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		fmt.Printf("✅ Exported %d files and %d images to %s\n", len(manifest.Files), len(manifest.Images), os.Args[3])
+	case "delete":
+		deletion, err := accounts.Delete(ctx, userID)
+		printJSON(deletion)
+		if err != nil {
+			log.Fatalf("Deletion failed: %v", err)
+		}
+		if deletion.Status != account.DeletionCompleted {
+			log.Fatalf("Deletion %s; run it again to retry", deletion.Status)
+		}
+	case "status":
+		deletion, err := accounts.GetDeletion(ctx, userID)
+		if err != nil {
+			log.Fatalf("Failed to load deletion: %v", err)
+		}
+		printJSON(deletion)
+	default:
+		log.Fatalf("Unknown command %q", command)
+	}
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
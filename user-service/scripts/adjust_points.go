@@ -0,0 +1,60 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// Operator tool for the points ledger:
+//
+//	go run adjust_points.go adjust <USER_ID> <AMOUNT> <REASON>
+//	go run adjust_points.go reverse <USER_ID> <ENTRY_ID> <REASON>
+func main() {
+	if len(os.Args) != 5 {
+		log.Fatal("Usage: go run adjust_points.go adjust|reverse <USER_ID> <AMOUNT|ENTRY_ID> <REASON>")
+	}
+	command, userID, target, reason := os.Args[1], os.Args[2], os.Args[3], os.Args[4]
+
+	ctx := context.Background()
+	projectID := os.Getenv("GCP_PROJECT_ID")
+	if projectID == "" {
+		projectID = "focusnest-470308"
+	}
+	databaseID := "focusnest-prod"
+
+	client, err := firestore.NewClientWithDatabase(ctx, projectID, databaseID)
+	if err != nil {
+		log.Fatalf("Failed to create Firestore client: %v", err)
+	}
+	defer client.Close()
+
+	service := user.NewService(user.NewFirestoreRepository(client), nil)
+
+	var entry *user.PointsEntry
+	switch command {
+	case "adjust":
+		amount, convErr := strconv.Atoi(target)
+		if convErr != nil {
+			log.Fatalf("Invalid amount %q: %v", target, convErr)
+		}
+		entry, err = service.AdjustPoints(ctx, userID, amount, reason)
+	case "reverse":
+		entry, err = service.ReversePoints(ctx, userID, target, reason)
+	default:
+		log.Fatalf("Unknown command %q", command)
+	}
+	if err != nil {
+		log.Fatalf("Failed to %s points: %v", command, err)
+	}
+
+	fmt.Printf("✅ Wrote %s entry %s: %+d points, balance %d\n", entry.Type, entry.ID, entry.Amount, entry.BalanceAfter)
+}
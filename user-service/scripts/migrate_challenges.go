@@ -14,41 +14,54 @@ import (
 )
 
 // We duplicate the challenge definitions here for the migration script.
+// Challenges are plain data: new ones only need a rule, no code change.
 func getInitialChallenges() []user.ChallengeDefinition {
 	return []user.ChallengeDefinition{
 		{
-			ID:               "focus_2h_3days",
-			Title:            "Fokus 2 jam selama 3 hari",
-			Description:      "Fokus minimal 2 jam tanpa distraksi selama 3 hari berturut-turut",
-			RewardPoints:     50,
-			RuleType:         user.ChallengeRuleDailyMinutesStreak,
-			MinMinutesPerDay: 120,
-			ConsecutiveDays:  3,
+			ID:           "focus_2h_3days",
+			Title:        "Fokus 2 jam selama 3 hari",
+			Description:  "Fokus minimal 2 jam tanpa distraksi selama 3 hari berturut-turut",
+			RewardPoints: 50,
+			Rule: &user.ChallengeRule{
+				Window:     user.ChallengeWindowConsecutiveDays,
+				Days:       3,
+				Conditions: []user.ChallengeCondition{{Metric: user.ChallengeMetricMinutes, Target: 120}},
+			},
 		},
 		{
 			ID:           "share_recap_3x_weekly",
 			Title:        "Bagikan Recap Mingguan",
 			Description:  "Bagikan recap hasil fokusmu 3 kali dalam seminggu",
 			RewardPoints: 50,
-			RuleType:     user.ChallengeRuleWeeklyShares,
-			TargetCount:  3,
+			Rule: &user.ChallengeRule{
+				Window:          user.ChallengeWindowWeek,
+				IncludePrevious: true,
+				Conditions:      []user.ChallengeCondition{{Metric: user.ChallengeMetricShares, Target: 3}},
+			},
 		},
 		{
 			ID:           "streak_10_days",
 			Title:        "Raih 10 Hari Streak",
 			Description:  "Raih 10 hari streak dan unggah recap ke media sosial",
 			RewardPoints: 50,
-			RuleType:     user.ChallengeRuleStreakMilestone,
-			TargetStreak: 10,
+			Rule: &user.ChallengeRule{
+				Window:     user.ChallengeWindowAllTime,
+				Conditions: []user.ChallengeCondition{{Metric: user.ChallengeMetricStreak, Target: 10}},
+			},
 		},
 		{
-			ID:                       "cycles_and_mindfulness",
-			Title:                    "Fokus & Mindfulness",
-			Description:              "Selesaikan 4 cycle kerja dan lakukan 2 menit mindfulness breathing",
-			RewardPoints:             50,
-			RuleType:                 user.ChallengeRuleCyclesAndMindfulness,
-			TargetCycles:             4,
-			TargetMindfulnessMinutes: 2,
+			ID:           "cycles_and_mindfulness",
+			Title:        "Fokus & Mindfulness",
+			Description:  "Selesaikan 4 cycle kerja dan lakukan 2 menit mindfulness breathing",
+			RewardPoints: 50,
+			Rule: &user.ChallengeRule{
+				Window:          user.ChallengeWindowDay,
+				IncludePrevious: true,
+				Conditions: []user.ChallengeCondition{
+					{Metric: user.ChallengeMetricCycles, Target: 4},
+					{Metric: user.ChallengeMetricMindfulnessMinutes, Target: 2},
+				},
+			},
 		},
 	}
 }
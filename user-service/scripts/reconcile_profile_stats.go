@@ -0,0 +1,79 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"cloud.google.com/go/firestore"
+	"github.com/focusnest/user-service/internal/user"
+)
+
+// Recounts the profile counters (sessions, cycles, categories, longest
+// streak, XP) from session history, for one user or every user, and reports
+// drift from the counters focus-service keeps:
+//
+//	go run reconcile_profile_stats.go [USER_ID]
+//
+// The recount replaces the stored counters. Run it after writing sessions
+// outside focus-service, such as with simulate_progress.go, and after a
+// profilestats.Version bump, so users are counted ahead of their next read
+// or session. Level-ups it causes are not announced.
+func main() {
+	ctx := context.Background()
+	projectID := os.Getenv("GCP_PROJECT_ID")
+	if projectID == "" {
+		projectID = "focusnest-470308"
+	}
+	databaseID := "focusnest-prod"
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") != "" {
+		databaseID = "(default)"
+	}
+
+	client, err := firestore.NewClientWithDatabase(ctx, projectID, databaseID)
+	if err != nil {
+		log.Fatalf("Failed to create Firestore client: %v", err)
+	}
+	defer client.Close()
+
+	service := user.NewService(user.NewFirestoreRepository(client), nil)
+
+	var userIDs []string
+	if len(os.Args) > 1 {
+		userIDs = os.Args[1:]
+	} else {
+		// Sessions live under users/{uid}, which may have no document of its own;
+		// DocumentRefs lists those too.
+		refs, err := client.Collection("users").DocumentRefs(ctx).GetAll()
+		if err != nil {
+			log.Fatalf("Failed to list users: %v", err)
+		}
+		for _, ref := range refs {
+			userIDs = append(userIDs, ref.ID)
+		}
+	}
+
+	failed, drifted := 0, 0
+	for _, userID := range userIDs {
+		result, err := service.ReconcileProfileMetadata(ctx, userID, "")
+		if err != nil {
+			failed++
+			log.Printf("❌ %s: %v", userID, err)
+			continue
+		}
+		if !result.Drift {
+			continue
+		}
+		drifted++
+		if result.Stored == nil {
+			fmt.Printf("%s: not counted yet → %+v\n", userID, result.Actual)
+			continue
+		}
+		fmt.Printf("%s: %+v → %+v\n", userID, *result.Stored, result.Actual)
+	}
+	fmt.Printf("✅ Reconciled %d users: %d drifted, %d failed\n", len(userIDs)-failed, drifted, failed)
+}